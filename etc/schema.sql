@@ -23,11 +23,16 @@ CREATE TABLE IF NOT EXISTS "connection" (
     group_id         TEXT NOT NULL DEFAULT '',
     ssh_enable       INTEGER NOT NULL DEFAULT 0,
     ssh_id           TEXT NOT NULL DEFAULT '',
+    ssh_jump_ids     TEXT NOT NULL DEFAULT '',
     proxy_enable     INTEGER NOT NULL DEFAULT 0,
     proxy_id         TEXT NOT NULL DEFAULT '',
     tls_enable       INTEGER NOT NULL DEFAULT 0,
     tls_id           TEXT NOT NULL DEFAULT '',
     read_only        INTEGER NOT NULL DEFAULT 0,
+    read_from        TEXT NOT NULL DEFAULT '',
+    cred_provider    TEXT NOT NULL DEFAULT '',
+    cred_source      TEXT NOT NULL DEFAULT '',
+    cred_ttl         INTEGER NOT NULL DEFAULT 0,
     created_at       DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
     updated_at       DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
     deleted_at       DATETIME
@@ -58,6 +63,14 @@ CREATE TABLE IF NOT EXISTS "tls" (
     ca_cert     TEXT NOT NULL DEFAULT '',
     cert        TEXT NOT NULL DEFAULT '',
     key         TEXT NOT NULL DEFAULT '',
+    ca_file        TEXT NOT NULL DEFAULT '',
+    cert_file      TEXT NOT NULL DEFAULT '',
+    key_file       TEXT NOT NULL DEFAULT '',
+    key_passphrase TEXT NOT NULL DEFAULT '',
+    min_version    TEXT NOT NULL DEFAULT '',
+    max_version    TEXT NOT NULL DEFAULT '',
+    cipher_suites  TEXT NOT NULL DEFAULT '',
+    pin_sha256     TEXT NOT NULL DEFAULT '',
     created_at  DATETIME,
     updated_at  DATETIME,
     deleted_at  DATETIME
@@ -103,6 +116,17 @@ CREATE TABLE IF NOT EXISTS "setting" (
     deleted_at  DATETIME
 );
 
+CREATE TABLE IF NOT EXISTS known_host (
+    id          TEXT PRIMARY KEY DEFAULT (lower(hex(randomblob(4))) || '-' || lower(hex(randomblob(2))) || '-4' || substr(lower(hex(randomblob(2))),2) || '-' || substr('89ab',abs(random()) % 4 + 1, 1) || substr(lower(hex(randomblob(2))),2) || '-' || lower(hex(randomblob(6)))),
+    host        TEXT NOT NULL DEFAULT '' UNIQUE,
+    key_type    TEXT NOT NULL DEFAULT '',
+    public_key  TEXT NOT NULL DEFAULT '',
+    fingerprint TEXT NOT NULL DEFAULT '',
+    created_at  DATETIME,
+    updated_at  DATETIME,
+    deleted_at  DATETIME
+);
+
 UPDATE "connection" SET
     group_id   = COALESCE(group_id, ''),
     ssh_id     = COALESCE(ssh_id, ''),
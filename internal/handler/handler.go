@@ -6,6 +6,7 @@ import (
 	"encoding/json"
 
 	"github.com/tradalab/rdms/internal/logic/client"
+	"github.com/tradalab/rdms/internal/logic/cluster"
 	"github.com/tradalab/rdms/internal/logic/conn"
 	"github.com/tradalab/rdms/internal/logic/connection"
 	"github.com/tradalab/rdms/internal/logic/console"
@@ -15,6 +16,8 @@ import (
 	"github.com/tradalab/rdms/internal/logic/preset"
 	"github.com/tradalab/rdms/internal/logic/proxy"
 	"github.com/tradalab/rdms/internal/logic/pubsub"
+	"github.com/tradalab/rdms/internal/logic/rdb"
+	"github.com/tradalab/rdms/internal/logic/sentinel"
 	"github.com/tradalab/rdms/internal/logic/setting"
 	"github.com/tradalab/rdms/internal/logic/ssh"
 	"github.com/tradalab/rdms/internal/logic/system"
@@ -132,6 +135,24 @@ func RegisterHandlers(a *app.App, svcCtx *svc.ServiceContext) {
 		}
 		return h(ctx, r)
 	})
+	app.RegisterServerStream(a, "client:keys-tree", func(ctx context.Context, req *types.ClientKeysTreeReq, out app.Sink[types.ClientKeysTreeEvent]) error {
+		return client.NewKeysTreeLogic(ctx, svcCtx).KeysTree(req, out)
+	})
+	app.RegisterServerStream(a, "client:keys-report", func(ctx context.Context, req *types.ClientKeysReportReq, out app.Sink[types.ClientKeysReportEvent]) error {
+		return client.NewKeysReportLogic(ctx, svcCtx).KeysReport(req, out)
+	})
+	app.RegisterServerStream(a, "client:keys-hot", func(ctx context.Context, req *types.ClientKeysHotReq, out app.Sink[types.ClientKeysHotEvent]) error {
+		return client.NewKeysHotLogic(ctx, svcCtx).KeysHot(req, out)
+	})
+	app.RegisterServerStream(a, "client:keys-ttl", func(ctx context.Context, req *types.ClientKeysTtlReq, out app.Sink[types.ClientKeysTtlEvent]) error {
+		return client.NewKeysTtlLogic(ctx, svcCtx).KeysTtl(req, out)
+	})
+	app.RegisterServerStream(a, "client:keys-export", func(ctx context.Context, req *types.ClientKeysExportReq, out app.Sink[types.ClientKeysExportProgressEvent]) error {
+		return client.NewKeysExportLogic(ctx, svcCtx).KeysExport(req, out)
+	})
+	app.RegisterServerStream(a, "client:keys-import", func(ctx context.Context, req *types.ClientKeysImportReq, out app.Sink[types.ClientKeysImportProgressEvent]) error {
+		return client.NewKeysImportLogic(ctx, svcCtx).KeysImport(req, out)
+	})
 	app.RegisterServerStream(a, "client:keys-search", func(ctx context.Context, req *types.ClientKeysSearchReq, out app.Sink[types.ClientKeysSearchEvent]) error {
 		return client.NewKeysSearchLogic(ctx, svcCtx).KeysSearch(req, out)
 	})
@@ -147,12 +168,24 @@ func RegisterHandlers(a *app.App, svcCtx *svc.ServiceContext) {
 		}
 		return h(ctx, r)
 	})
+	reg(a, "client:list-active", func(ctx context.Context, r *types.Empty) (any, error) {
+		h := func(ctx context.Context, a any) (any, error) {
+			return client.NewListActiveLogic(ctx, svcCtx).ListActive(a.(*types.Empty))
+		}
+		return h(ctx, r)
+	})
 	reg(a, "conn:test", func(ctx context.Context, r *types.ConnectionReq) (any, error) {
 		h := func(ctx context.Context, a any) (any, error) {
 			return conn.NewTestLogic(ctx, svcCtx).Test(a.(*types.ConnectionReq))
 		}
 		return h(ctx, r)
 	})
+	reg(a, "conn:map-preview", func(ctx context.Context, r *types.ConnectionReq) (any, error) {
+		h := func(ctx context.Context, a any) (any, error) {
+			return conn.NewMapPreviewLogic(ctx, svcCtx).MapPreview(a.(*types.ConnectionReq))
+		}
+		return h(ctx, r)
+	})
 	reg(a, "preset:list", func(ctx context.Context, r *types.Empty) (any, error) {
 		h := func(ctx context.Context, a any) (any, error) {
 			return preset.NewListLogic(ctx, svcCtx).List(a.(*types.Empty))
@@ -276,6 +309,12 @@ func RegisterHandlers(a *app.App, svcCtx *svc.ServiceContext) {
 		}
 		return h(ctx, r)
 	})
+	reg(a, "proxy:test", func(ctx context.Context, r *types.ProxyTestReq) (any, error) {
+		h := func(ctx context.Context, a any) (any, error) {
+			return proxy.NewTestLogic(ctx, svcCtx).Test(a.(*types.ProxyTestReq))
+		}
+		return h(ctx, r)
+	})
 	reg(a, "proxy:upsert", func(ctx context.Context, r *types.ProxyReq) (any, error) {
 		h := func(ctx context.Context, a any) (any, error) {
 			return proxy.NewUpsertLogic(ctx, svcCtx).Upsert(a.(*types.ProxyReq))
@@ -318,6 +357,12 @@ func RegisterHandlers(a *app.App, svcCtx *svc.ServiceContext) {
 		}
 		return h(ctx, r)
 	})
+	reg(a, "tls:test", func(ctx context.Context, r *types.TlsTestReq) (any, error) {
+		h := func(ctx context.Context, a any) (any, error) {
+			return tls.NewTestLogic(ctx, svcCtx).Test(a.(*types.TlsTestReq))
+		}
+		return h(ctx, r)
+	})
 	reg(a, "tls:upsert", func(ctx context.Context, r *types.TlsReq) (any, error) {
 		h := func(ctx context.Context, a any) (any, error) {
 			return tls.NewUpsertLogic(ctx, svcCtx).Upsert(a.(*types.TlsReq))
@@ -348,6 +393,42 @@ func RegisterHandlers(a *app.App, svcCtx *svc.ServiceContext) {
 		}
 		return h(ctx, r)
 	})
+	reg(a, "connection:parse-url", func(ctx context.Context, r *types.ConnectionParseUrlReq) (any, error) {
+		h := func(ctx context.Context, a any) (any, error) {
+			return connection.NewParseUrlLogic(ctx, svcCtx).ParseUrl(a.(*types.ConnectionParseUrlReq))
+		}
+		return h(ctx, r)
+	})
+	reg(a, "connection:export-url", func(ctx context.Context, r *types.ConnectionExportUrlReq) (any, error) {
+		h := func(ctx context.Context, a any) (any, error) {
+			return connection.NewExportUrlLogic(ctx, svcCtx).ExportUrl(a.(*types.ConnectionExportUrlReq))
+		}
+		return h(ctx, r)
+	})
+	reg(a, "connection:import-external-preview", func(ctx context.Context, r *types.ConnectionImportExternalReq) (any, error) {
+		h := func(ctx context.Context, a any) (any, error) {
+			return connection.NewImportExternalPreviewLogic(ctx, svcCtx).ImportExternalPreview(a.(*types.ConnectionImportExternalReq))
+		}
+		return h(ctx, r)
+	})
+	reg(a, "connection:import-external", func(ctx context.Context, r *types.ConnectionImportExternalReq) (any, error) {
+		h := func(ctx context.Context, a any) (any, error) {
+			return connection.NewImportExternalLogic(ctx, svcCtx).ImportExternal(a.(*types.ConnectionImportExternalReq))
+		}
+		return h(ctx, r)
+	})
+	reg(a, "connection:export", func(ctx context.Context, r *types.ConnectionExportReq) (any, error) {
+		h := func(ctx context.Context, a any) (any, error) {
+			return connection.NewExportLogic(ctx, svcCtx).Export(a.(*types.ConnectionExportReq))
+		}
+		return h(ctx, r)
+	})
+	reg(a, "connection:import", func(ctx context.Context, r *types.ConnectionImportReq) (any, error) {
+		h := func(ctx context.Context, a any) (any, error) {
+			return connection.NewImportLogic(ctx, svcCtx).Import(a.(*types.ConnectionImportReq))
+		}
+		return h(ctx, r)
+	})
 	reg(a, "system:info", func(ctx context.Context, r *types.Empty) (any, error) {
 		h := func(ctx context.Context, a any) (any, error) {
 			return system.NewInfoLogic(ctx, svcCtx).Info(a.(*types.Empty))
@@ -390,6 +471,30 @@ func RegisterHandlers(a *app.App, svcCtx *svc.ServiceContext) {
 		}
 		return h(ctx, r)
 	})
+	reg(a, "cluster:topology", func(ctx context.Context, r *types.ClusterReq) (any, error) {
+		h := func(ctx context.Context, a any) (any, error) {
+			return cluster.NewTopologyLogic(ctx, svcCtx).Topology(a.(*types.ClusterReq))
+		}
+		return h(ctx, r)
+	})
+	reg(a, "sentinel:topology", func(ctx context.Context, r *types.SentinelReq) (any, error) {
+		h := func(ctx context.Context, a any) (any, error) {
+			return sentinel.NewTopologyLogic(ctx, svcCtx).Topology(a.(*types.SentinelReq))
+		}
+		return h(ctx, r)
+	})
+	reg(a, "sentinel:failover", func(ctx context.Context, r *types.SentinelFailoverReq) (any, error) {
+		h := func(ctx context.Context, a any) (any, error) {
+			return sentinel.NewFailoverLogic(ctx, svcCtx).Failover(a.(*types.SentinelFailoverReq))
+		}
+		return h(ctx, r)
+	})
+	app.RegisterServerStream(a, "sentinel:events", func(ctx context.Context, req *types.SentinelReq, out app.Sink[types.SentinelEvent]) error {
+		return sentinel.NewEventsLogic(ctx, svcCtx).Events(req, out)
+	})
+	app.RegisterServerStream(a, "rdb:analyze", func(ctx context.Context, req *types.RdbAnalyzeReq, out app.Sink[types.RdbAnalyzeEvent]) error {
+		return rdb.NewAnalyzeLogic(ctx, svcCtx).Analyze(req, out)
+	})
 }
 
 var _ = types.Empty{}
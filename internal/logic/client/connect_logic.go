@@ -33,11 +33,11 @@ func (l *ConnectLogic) Connect(params *types.ClientConnectReq) (*types.Empty, er
 		return nil, fmt.Errorf("connection not found: %w", err)
 	}
 
-	var sshCfg *model.Ssh
+	var sshCfgs []*model.Ssh
 	if conn.SshEnable > 0 && conn.SshID != "" {
-		sshCfg, err = l.svcCtx.SshModel.FindOne(l.ctx, conn.SshID)
+		sshCfgs, err = l.svcCtx.SshModel.FindChain(l.ctx, conn.SshHops())
 		if err != nil {
-			return nil, fmt.Errorf("ssh config %s: %w", conn.SshID, err)
+			return nil, err
 		}
 	}
 	var proxyCfg *model.Proxy
@@ -63,7 +63,7 @@ func (l *ConnectLogic) Connect(params *types.ClientConnectReq) (*types.Empty, er
 		}
 	}
 
-	if _, err := l.svcCtx.RedisManager.Add(conn, sshCfg, proxyCfg, tlsCfg, int(params.DatabaseIndex)); err != nil {
+	if _, err := l.svcCtx.RedisManager.Add(conn, sshCfgs, proxyCfg, tlsCfg, int(params.DatabaseIndex)); err != nil {
 		return nil, err
 	}
 
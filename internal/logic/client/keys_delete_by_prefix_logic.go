@@ -4,13 +4,18 @@ package client
 import (
 	"context"
 	"fmt"
+	"sort"
 
+	"github.com/redis/go-redis/v9"
 	"github.com/tradalab/scorix/app"
 
 	"github.com/tradalab/rdms/internal/svc"
 	"github.com/tradalab/rdms/internal/types"
+	"github.com/tradalab/rdms/pkg/util"
 )
 
+const deleteBatchSize = 1000
+
 type KeysDeleteByPrefixLogic struct {
 	ctx    context.Context
 	svcCtx *svc.ServiceContext
@@ -28,71 +33,194 @@ func (l *KeysDeleteByPrefixLogic) KeysDeleteByPrefix(params *types.ClientKeysDel
 	if err != nil {
 		return err
 	}
+	defer cli.BeginStream()()
+	if !params.DryRun && cli.ReadOnly.Load() {
+		return svc.ErrReadOnly
+	}
+	if len(params.Keys) == 0 && params.Prefix == "" {
+		return fmt.Errorf("prefix or keys must be provided")
+	}
 
-	progress := func(deleted, total int, status string) error {
+	ctx := out.Context()
+	_, cluster := cli.Rdb.(*redis.ClusterClient)
+
+	var (
+		nodes   []types.DeleteNodeStat
+		nodeIdx = make(map[string]int)
+		matched int64
+		deleted int64
+		total   int64
+		node    string
+	)
+	stat := func(addr string) *types.DeleteNodeStat {
+		i, ok := nodeIdx[addr]
+		if !ok {
+			i = len(nodes)
+			nodeIdx[addr] = i
+			nodes = append(nodes, types.DeleteNodeStat{Addr: addr})
+		}
+		return &nodes[i]
+	}
+	progress := func(status string) error {
 		return out.Send(&types.ClientKeysDeleteProgressEvent{
 			ConnectionId: params.ConnectionId,
 			Prefix:       params.Prefix,
-			Deleted:      int64(deleted),
-			Total:        int64(total),
+			Deleted:      deleted,
+			Total:        total,
 			Status:       status,
+			Matched:      matched,
+			DryRun:       params.DryRun,
+			Node:         node,
+			Nodes:        append([]types.DeleteNodeStat(nil), nodes...),
 		})
 	}
+	apply := func(n svc.ScanNode, keys []string) error {
+		node = n.Addr
+		st := stat(n.Addr)
+		st.Matched += int64(len(keys))
+		matched += int64(len(keys))
+		if params.DryRun || len(keys) == 0 {
+			return nil
+		}
+		removed, err := unlinkBySlot(ctx, n.Rdb, keys, cluster)
+		if err != nil {
+			return err
+		}
+		st.Deleted += removed
+		deleted += removed
+		return nil
+	}
 
 	if len(params.Keys) > 0 {
-		batchSize := 1000
-		totalDeleted := 0
-		for i := 0; i < len(params.Keys); i += batchSize {
-			end := i + batchSize
-			if end > len(params.Keys) {
-				end = len(params.Keys)
-			}
-			batch := params.Keys[i:end]
-
-			if err = cli.Rdb.Del(l.ctx, batch...).Err(); err != nil {
-				return err
-			}
-			totalDeleted += len(batch)
-
-			if err = progress(totalDeleted, len(params.Keys), "processing"); err != nil {
-				return err
+		total = int64(len(params.Keys))
+		groups, err := groupKeysByNode(ctx, cli.Rdb, params.Keys)
+		if err != nil {
+			return err
+		}
+		for _, g := range groups {
+			stat(g.node.Addr)
+		}
+		for _, g := range groups {
+			for i := 0; i < len(g.keys); i += deleteBatchSize {
+				end := min(i+deleteBatchSize, len(g.keys))
+				if err := apply(g.node, g.keys[i:end]); err != nil {
+					return err
+				}
+				if err := progress("processing"); err != nil {
+					return err
+				}
 			}
+			stat(g.node.Addr).Done = true
 		}
-
-		return progress(totalDeleted, len(params.Keys), "done")
+		return progress("done")
 	}
 
-	if params.Prefix == "" {
-		return fmt.Errorf("prefix or keys must be provided")
+	plan, err := svc.PlanScan(ctx, cli.Rdb, svc.ScanMasters, "")
+	if err != nil {
+		return err
+	}
+	for _, step := range plan.Steps {
+		stat(step.Node.Addr)
 	}
 
 	match := params.Prefix + "*"
-	cursor := uint64(0)
-	batchSize := int64(1000)
-	totalDeleted := 0
-
-	for {
-		keys, nextCursor, err := cli.Rdb.Scan(l.ctx, cursor, match, batchSize).Result()
+	for !plan.Done() {
+		current := plan.Steps[0].Node
+		keys, err := plan.Page(ctx, match, "", deleteBatchSize)
 		if err != nil {
 			return err
 		}
+		if err := apply(current, keys); err != nil {
+			return err
+		}
+		if plan.Done() || plan.Steps[0].Node.Addr != current.Addr {
+			stat(current.Addr).Done = true
+		}
+		if len(keys) == 0 && !stat(current.Addr).Done {
+			continue
+		}
+		if err := progress("processing"); err != nil {
+			return err
+		}
+	}
 
-		if len(keys) > 0 {
-			if err = cli.Rdb.Del(l.ctx, keys...).Err(); err != nil {
-				return err
-			}
-			totalDeleted += len(keys)
+	if params.DryRun {
+		total = matched
+	}
+	return progress("done")
+}
 
-			if err = progress(totalDeleted, 0, "processing"); err != nil {
-				return err
-			}
+type nodeKeys struct {
+	node svc.ScanNode
+	keys []string
+}
+
+// groupKeysByNode splits an explicit key list by the master that owns each
+// key's slot. Non-cluster clients get a single group.
+func groupKeysByNode(ctx context.Context, rdb redis.UniversalClient, keys []string) ([]nodeKeys, error) {
+	cc, ok := rdb.(*redis.ClusterClient)
+	if !ok {
+		nodes, err := svc.ScanNodes(ctx, rdb, svc.ScanMasters)
+		if err != nil {
+			return nil, err
 		}
+		return []nodeKeys{{node: nodes[0], keys: keys}}, nil
+	}
+
+	bySlot := make(map[int][]string)
+	for _, k := range keys {
+		slot := util.HashSlot(k)
+		bySlot[slot] = append(bySlot[slot], k)
+	}
 
-		cursor = nextCursor
-		if cursor == 0 {
-			break
+	byAddr := make(map[string]*nodeKeys)
+	for _, ks := range bySlot {
+		master, err := cc.MasterForKey(ctx, ks[0])
+		if err != nil {
+			return nil, fmt.Errorf("locate master for %q: %w", ks[0], err)
+		}
+		addr := master.Options().Addr
+		g, ok := byAddr[addr]
+		if !ok {
+			g = &nodeKeys{node: svc.ScanNode{Addr: addr, Rdb: master}}
+			byAddr[addr] = g
 		}
+		g.keys = append(g.keys, ks...)
+	}
+
+	groups := make([]nodeKeys, 0, len(byAddr))
+	for _, g := range byAddr {
+		groups = append(groups, *g)
+	}
+	sort.Slice(groups, func(i, j int) bool { return groups[i].node.Addr < groups[j].node.Addr })
+	return groups, nil
+}
+
+// unlinkBySlot removes keys with UNLINK. On cluster nodes it sends one UNLINK
+// per hash slot in a single pipeline so a batch never trips CROSSSLOT.
+func unlinkBySlot(ctx context.Context, rdb redis.Cmdable, keys []string, cluster bool) (int64, error) {
+	if !cluster {
+		return rdb.Unlink(ctx, keys...).Result()
 	}
 
-	return progress(totalDeleted, 0, "done")
+	bySlot := make(map[int][]string)
+	for _, k := range keys {
+		slot := util.HashSlot(k)
+		bySlot[slot] = append(bySlot[slot], k)
+	}
+
+	pipe := rdb.Pipeline()
+	cmds := make([]*redis.IntCmd, 0, len(bySlot))
+	for _, ks := range bySlot {
+		cmds = append(cmds, pipe.Unlink(ctx, ks...))
+	}
+	if _, err := pipe.Exec(ctx); err != nil {
+		return 0, err
+	}
+
+	var removed int64
+	for _, c := range cmds {
+		removed += c.Val()
+	}
+	return removed, nil
 }
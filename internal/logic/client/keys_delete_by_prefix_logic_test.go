@@ -0,0 +1,47 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+)
+
+func TestUnlinkBySlotOnClusterNodes(t *testing.T) {
+	mr := miniredis.RunT(t)
+	rdb := redis.NewClusterClient(&redis.ClusterOptions{Addrs: []string{mr.Addr()}})
+	t.Cleanup(func() { _ = rdb.Close() })
+
+	var keys []string
+	for i := range 50 {
+		k := fmt.Sprintf("user:%d", i)
+		mr.Set(k, "v")
+		keys = append(keys, k)
+	}
+	keys = append(keys, "user:missing")
+
+	ctx := context.Background()
+	groups, err := groupKeysByNode(ctx, rdb, keys)
+	if err != nil {
+		t.Fatalf("groupKeysByNode: %v", err)
+	}
+	if len(groups) != 1 || groups[0].node.Addr != mr.Addr() {
+		t.Fatalf("got groups %+v, want everything on %s", groups, mr.Addr())
+	}
+	if len(groups[0].keys) != len(keys) {
+		t.Fatalf("grouped %d keys, want %d", len(groups[0].keys), len(keys))
+	}
+
+	removed, err := unlinkBySlot(ctx, groups[0].node.Rdb, groups[0].keys, true)
+	if err != nil {
+		t.Fatalf("unlinkBySlot: %v", err)
+	}
+	if removed != 50 {
+		t.Errorf("removed %d, want 50 (the missing key must not count)", removed)
+	}
+	if n := len(mr.Keys()); n != 0 {
+		t.Errorf("%d keys left after unlink", n)
+	}
+}
@@ -0,0 +1,381 @@
+// Code generated by scorix.
+package client
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/tradalab/scorix/app"
+
+	"github.com/tradalab/rdms/internal/svc"
+	"github.com/tradalab/rdms/internal/types"
+	"github.com/tradalab/rdms/pkg/keyfilter"
+)
+
+const defaultExportPageSize = 500
+
+type KeysExportLogic struct {
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+func NewKeysExportLogic(ctx context.Context, svcCtx *svc.ServiceContext) *KeysExportLogic {
+	return &KeysExportLogic{
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+func (l *KeysExportLogic) KeysExport(req *types.ClientKeysExportReq, out app.Sink[types.ClientKeysExportProgressEvent]) error {
+	if req.Path == "" {
+		return fmt.Errorf("path must be provided")
+	}
+	format := req.Format
+	if format == "" {
+		format = svc.KeyExportJSONL
+	}
+
+	clauses := make([]keyfilter.Clause, 0, len(req.Filters))
+	for _, f := range req.Filters {
+		clauses = append(clauses, keyfilter.Clause{
+			Pattern:    f.Pattern,
+			Mode:       f.Mode,
+			Exclude:    f.Exclude,
+			IgnoreCase: f.IgnoreCase,
+		})
+	}
+
+	filter, err := keyfilter.Compile(clauses, req.MatchAll)
+	if err != nil {
+		return err
+	}
+
+	cli, err := l.svcCtx.RedisManager.Get(req.ConnectionId, int(req.DatabaseIndex))
+	if err != nil {
+		return err
+	}
+	defer cli.BeginStream()()
+
+	// Write next to the target and rename once complete, so a failed or
+	// cancelled export never leaves a file that looks whole.
+	tmp := req.Path + ".part"
+	f, err := os.Create(tmp)
+	if err != nil {
+		return err
+	}
+
+	opts := exportOptions{
+		format:    format,
+		keys:      req.Keys,
+		keyType:   req.KeyType,
+		pageSize:  req.PageSize,
+		scanCount: req.ScanCount,
+		rate:      req.Rate,
+		budget:    time.Duration(req.BudgetMs) * time.Millisecond,
+	}
+
+	var done *types.ClientKeysExportProgressEvent
+	err = exportKeys(out.Context(), cli.Reader(), filter, opts, f, func(ev *types.ClientKeysExportProgressEvent) error {
+		ev.ConnectionId = req.ConnectionId
+		ev.Path = req.Path
+		if ev.Status == "done" {
+			done = ev // sent once the file is in place
+			return nil
+		}
+		return out.Send(ev)
+	})
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err == nil {
+		err = os.Rename(tmp, req.Path)
+	}
+	if err != nil {
+		_ = os.Remove(tmp)
+		return err
+	}
+	return out.Send(done)
+}
+
+type exportOptions struct {
+	format    string
+	keys      []string
+	keyType   string
+	pageSize  int64
+	scanCount int64
+	rate      int64
+	budget    time.Duration
+}
+
+// exportKeys writes opts.keys, or the keys scanFiltered yields when there
+// are none, to out in opts.format.
+func exportKeys(
+	ctx context.Context,
+	rdb redis.UniversalClient,
+	filter *keyfilter.Set,
+	opts exportOptions,
+	out io.Writer,
+	emit func(*types.ClientKeysExportProgressEvent) error,
+) error {
+	if opts.pageSize <= 0 {
+		opts.pageSize = defaultExportPageSize
+	}
+
+	var (
+		counter = &countingWriter{w: out}
+		ev      = &types.ClientKeysExportProgressEvent{}
+	)
+	w, err := svc.NewKeyWriter(opts.format, counter)
+	if err != nil {
+		return err
+	}
+
+	progress := func(status string) error {
+		if err := w.Flush(); err != nil {
+			return err
+		}
+		snap := *ev
+		snap.Status = status
+		snap.Bytes = counter.n
+		snap.Nodes = append([]types.ScanNodeStat(nil), ev.Nodes...)
+		return emit(&snap)
+	}
+	export := func(keys []string) error {
+		if err := exportBatch(ctx, rdb, opts, w, ev, keys); err != nil {
+			return err
+		}
+		ev.Matched += int64(len(keys))
+		return nil
+	}
+
+	if len(opts.keys) > 0 {
+		ev.Total = int64(len(opts.keys))
+		err := newPacedBatches(opts.rate).run(ctx, opts.keys, true, export, func() error {
+			return progress("processing")
+		})
+		if err != nil {
+			return err
+		}
+		return progress("done")
+	}
+
+	return pacedScan(ctx, rdb, filter, searchOptions{
+		match:     filter.Pushdown(),
+		keyType:   opts.keyType,
+		scanCount: opts.scanCount,
+		budget:    opts.budget,
+	}, opts.rate, export, func(sev *types.ClientKeysSearchEvent, done bool) error {
+		ev.Total = int64(sev.Matched)
+		ev.Nodes = sev.Nodes
+		if done {
+			ev.Truncated = sev.Truncated
+			return progress("done")
+		}
+		return progress("processing")
+	})
+}
+
+// exportBatch reads the type and TTL of keys in one pipeline, then writes
+// each key's value page by page. Keys gone since they matched, and types
+// none of the formats hold, count as skipped.
+func exportBatch(
+	ctx context.Context,
+	rdb redis.UniversalClient,
+	opts exportOptions,
+	w svc.KeyWriter,
+	ev *types.ClientKeysExportProgressEvent,
+	keys []string,
+) error {
+	typeCmds := make([]*redis.StatusCmd, len(keys))
+	ttlCmds := make([]*redis.DurationCmd, len(keys))
+	pipe := rdb.Pipeline()
+	for i, key := range keys {
+		typeCmds[i] = pipe.Type(ctx, key)
+		ttlCmds[i] = pipe.PTTL(ctx, key)
+	}
+	if _, err := pipe.Exec(ctx); err != nil && ctx.Err() != nil {
+		return ctx.Err()
+	}
+
+	for i, key := range keys {
+		typ := typeCmds[i].Val()
+		d, err := ttlCmds[i].Result()
+		if err != nil || d == -2 {
+			ev.Skipped++
+			continue
+		}
+		ttl := int64(-1)
+		if d >= 0 {
+			ttl = d.Milliseconds()
+		}
+
+		next := valuePager(ctx, rdb, key, typ, opts.pageSize)
+		if next == nil {
+			ev.Skipped++
+			continue
+		}
+		page, more, err := next()
+		if err != nil {
+			return fmt.Errorf("%s: %w", key, err)
+		}
+		if len(page) == 0 {
+			ev.Skipped++
+			continue
+		}
+
+		if err := w.Begin(key, typ, ttl); err != nil {
+			return err
+		}
+		for {
+			if err := w.Elements(page); err != nil {
+				return err
+			}
+			ev.Elements += int64(len(page))
+			if !more {
+				break
+			}
+			if page, more, err = next(); err != nil {
+				return fmt.Errorf("%s: %w", key, err)
+			}
+		}
+		if err := w.End(); err != nil {
+			return err
+		}
+		ev.Exported++
+		ev.Key = key
+	}
+	return ctx.Err()
+}
+
+// valuePager returns a function that reads the value of key one page at a
+// time, reporting whether more follow, or nil for types it cannot read.
+// SSCAN, HSCAN and ZSCAN may return an element twice while the server
+// rehashes; every format replays such repeats harmlessly.
+func valuePager(ctx context.Context, rdb redis.UniversalClient, key, typ string, size int64) func() ([]svc.KeyElement, bool, error) {
+	var (
+		offset int64
+		cursor uint64
+		id     = "-"
+	)
+
+	// scan pages through SSCAN, HSCAN or ZSCAN, skipping the empty pages
+	// they may return before the cursor comes back to 0.
+	scan := func(call func(uint64) *redis.ScanCmd, pairs bool) ([]svc.KeyElement, bool, error) {
+		for {
+			vals, next, err := call(cursor).Result()
+			if err != nil {
+				return nil, false, err
+			}
+			cursor = next
+			var page []svc.KeyElement
+			if pairs {
+				for i := 0; i+1 < len(vals); i += 2 {
+					page = append(page, svc.KeyElement{Field: vals[i], Value: vals[i+1]})
+				}
+			} else {
+				for _, v := range vals {
+					page = append(page, svc.KeyElement{Value: v})
+				}
+			}
+			if len(page) > 0 || cursor == 0 {
+				return page, cursor != 0, nil
+			}
+		}
+	}
+
+	switch typ {
+	case "string":
+		return func() ([]svc.KeyElement, bool, error) {
+			v, err := rdb.Get(ctx, key).Result()
+			if err == redis.Nil {
+				return nil, false, nil
+			}
+			if err != nil {
+				return nil, false, err
+			}
+			return []svc.KeyElement{{Value: v}}, false, nil
+		}
+	case "list":
+		return func() ([]svc.KeyElement, bool, error) {
+			vals, err := rdb.LRange(ctx, key, offset, offset+size-1).Result()
+			if err != nil {
+				return nil, false, err
+			}
+			offset += int64(len(vals))
+			page := make([]svc.KeyElement, len(vals))
+			for i, v := range vals {
+				page[i] = svc.KeyElement{Value: v}
+			}
+			return page, int64(len(vals)) == size, nil
+		}
+	case "set":
+		return func() ([]svc.KeyElement, bool, error) {
+			return scan(func(c uint64) *redis.ScanCmd { return rdb.SScan(ctx, key, c, "", size) }, false)
+		}
+	case "hash":
+		return func() ([]svc.KeyElement, bool, error) {
+			return scan(func(c uint64) *redis.ScanCmd { return rdb.HScan(ctx, key, c, "", size) }, true)
+		}
+	case "zset":
+		return func() ([]svc.KeyElement, bool, error) {
+			return scan(func(c uint64) *redis.ScanCmd { return rdb.ZScan(ctx, key, c, "", size) }, true)
+		}
+	case "stream":
+		return func() ([]svc.KeyElement, bool, error) {
+			// XRangeN would hand the fields over as a map, losing their
+			// order and any repeats, which XADD then could not recreate.
+			msgs, err := rdb.Do(ctx, "XRANGE", key, id, "+", "COUNT", size).Slice()
+			if err != nil {
+				return nil, false, err
+			}
+			page := make([]svc.KeyElement, len(msgs))
+			for i, msg := range msgs {
+				if page[i], err = streamEntry(msg); err != nil {
+					return nil, false, err
+				}
+			}
+			if len(msgs) > 0 {
+				id = incrementStreamID(page[len(page)-1].Field)
+			}
+			return page, int64(len(msgs)) == size, nil
+		}
+	}
+	return nil
+}
+
+// streamEntry reads one entry of an XRANGE reply, its fields and values
+// kept in the order the server sent them.
+func streamEntry(v any) (svc.KeyElement, error) {
+	entry, ok := v.([]any)
+	if !ok || len(entry) != 2 {
+		return svc.KeyElement{}, fmt.Errorf("unexpected XRANGE entry %T", v)
+	}
+	id, ok := entry[0].(string)
+	if !ok {
+		return svc.KeyElement{}, fmt.Errorf("unexpected XRANGE entry id %T", entry[0])
+	}
+	fields, _ := entry[1].([]any)
+	pairs := make([]string, len(fields))
+	for i, f := range fields {
+		if pairs[i], ok = f.(string); !ok {
+			return svc.KeyElement{}, fmt.Errorf("unexpected XRANGE field %T", f)
+		}
+	}
+	return svc.KeyElement{Field: id, Pairs: pairs}, nil
+}
+
+// countingWriter counts the bytes written through it.
+type countingWriter struct {
+	w io.Writer
+	n int64
+}
+
+func (c *countingWriter) Write(p []byte) (int, error) {
+	n, err := c.w.Write(p)
+	c.n += int64(n)
+	return n, err
+}
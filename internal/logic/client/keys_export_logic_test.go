@@ -0,0 +1,195 @@
+package client
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+
+	"github.com/tradalab/rdms/internal/types"
+	"github.com/tradalab/rdms/pkg/keyfilter"
+)
+
+func fillExportKeys(t *testing.T, mr *miniredis.Miniredis) {
+	t.Helper()
+	mr.Set("app:str", "hello")
+	mr.SetTTL("app:str", time.Hour)
+	mr.Set("app:bin\xff", "\x00\x01")
+	for i := range 7 {
+		mr.RPush("app:list", fmt.Sprintf("item%d", i))
+		mr.SAdd("app:set", fmt.Sprintf("m%d", i))
+		mr.HSet("app:hash", fmt.Sprintf("f%d", i), fmt.Sprintf("v%d", i))
+		mr.ZAdd("app:zset", float64(i)+0.5, fmt.Sprintf("z%d", i))
+		if _, err := mr.XAdd("app:stream", fmt.Sprintf("%d-0", i+1), []string{"a", "1", "b", strconv.Itoa(i)}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	mr.Set("other", "x")
+}
+
+// replayRESP runs every command of a RESP script against rdb.
+func replayRESP(t *testing.T, script []byte, do func(args ...any) error) {
+	t.Helper()
+	r := bufio.NewReader(bytes.NewReader(script))
+	line := func() string {
+		s, err := r.ReadString('\n')
+		if err != nil {
+			t.Fatalf("read: %v", err)
+		}
+		return strings.TrimSuffix(s, "\r\n")
+	}
+	for {
+		if _, err := r.Peek(1); err == io.EOF {
+			return
+		}
+		n, _ := strconv.Atoi(strings.TrimPrefix(line(), "*"))
+		args := make([]any, n)
+		for i := range args {
+			size, _ := strconv.Atoi(strings.TrimPrefix(line(), "$"))
+			buf := make([]byte, size+2)
+			if _, err := io.ReadFull(r, buf); err != nil {
+				t.Fatal(err)
+			}
+			args[i] = string(buf[:size])
+		}
+		if err := do(args...); err != nil {
+			t.Fatalf("%v: %v", args, err)
+		}
+	}
+}
+
+func TestExportKeysRESPRecreatesKeys(t *testing.T) {
+	mr, rdb := newRedis(t)
+	fillExportKeys(t, mr)
+
+	filter, _ := keyfilter.Compile([]keyfilter.Clause{{Pattern: "app:*"}}, false)
+	var buf bytes.Buffer
+	var events []*types.ClientKeysExportProgressEvent
+	err := exportKeys(context.Background(), rdb, filter, exportOptions{format: "resp", pageSize: 3, scanCount: 2, rate: -1}, &buf, func(ev *types.ClientKeysExportProgressEvent) error {
+		events = append(events, ev)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("exportKeys: %v", err)
+	}
+	last := events[len(events)-1]
+	if last.Status != "done" || last.Exported != 7 || last.Total != 7 || last.Skipped != 0 || last.Bytes != int64(buf.Len()) {
+		t.Fatalf("last event = %+v (%d bytes written)", last, buf.Len())
+	}
+	if last.Elements != 2+5*7 {
+		t.Errorf("elements = %d", last.Elements)
+	}
+
+	dst, dstRdb := newRedis(t)
+	replayRESP(t, buf.Bytes(), func(args ...any) error { return dstRdb.Do(context.Background(), args...).Err() })
+
+	if dst.Exists("other") {
+		t.Error("exported a key the filter excludes")
+	}
+	for _, key := range []string{"app:str", "app:bin\xff"} {
+		want, _ := mr.Get(key)
+		if got, err := dst.Get(key); err != nil || got != want {
+			t.Errorf("%q = %q, want %q (%v)", key, got, want, err)
+		}
+	}
+	same := func(key string, read func(*miniredis.Miniredis) (any, error)) {
+		want, _ := read(mr)
+		got, err := read(dst)
+		if err != nil || fmt.Sprint(got) != fmt.Sprint(want) {
+			t.Errorf("%q = %v, want %v (%v)", key, got, want, err)
+		}
+	}
+	same("app:list", func(m *miniredis.Miniredis) (any, error) { return m.List("app:list") })
+	same("app:set", func(m *miniredis.Miniredis) (any, error) { return m.Members("app:set") })
+	same("app:zset", func(m *miniredis.Miniredis) (any, error) { return m.SortedSet("app:zset") })
+	same("app:stream", func(m *miniredis.Miniredis) (any, error) { return m.Stream("app:stream") })
+	same("app:hash", func(m *miniredis.Miniredis) (any, error) { return m.HKeys("app:hash") })
+	if got := dst.HGet("app:hash", "f6"); got != "v6" {
+		t.Errorf("app:hash f6 = %q", got)
+	}
+	if ttl := dst.TTL("app:str"); ttl <= 0 || ttl > time.Hour {
+		t.Errorf("app:str ttl = %v", ttl)
+	}
+}
+
+func TestExportKeysJSONLExplicitKeys(t *testing.T) {
+	mr, rdb := newRedis(t)
+	fillExportKeys(t, mr)
+
+	var buf bytes.Buffer
+	var last *types.ClientKeysExportProgressEvent
+	opts := exportOptions{format: "jsonl", keys: []string{"app:list", "missing", "app:stream"}, pageSize: 2, rate: -1}
+	err := exportKeys(context.Background(), rdb, nil, opts, &buf, func(ev *types.ClientKeysExportProgressEvent) error {
+		last = ev
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("exportKeys: %v", err)
+	}
+	if last.Exported != 2 || last.Skipped != 1 || last.Total != 3 || last.Key != "app:stream" {
+		t.Fatalf("last event = %+v", last)
+	}
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("lines = %q", lines)
+	}
+	var list struct {
+		Key   string   `json:"key"`
+		Ttl   int64    `json:"ttl"`
+		Value []string `json:"value"`
+	}
+	if err := json.Unmarshal([]byte(lines[0]), &list); err != nil {
+		t.Fatal(err)
+	}
+	if list.Key != "app:list" || list.Ttl != -1 || len(list.Value) != 7 || list.Value[6] != "item6" {
+		t.Errorf("list = %+v", list)
+	}
+	var stream struct {
+		Value []struct {
+			Id     string   `json:"id"`
+			Fields []string `json:"fields"`
+		} `json:"value"`
+	}
+	if err := json.Unmarshal([]byte(lines[1]), &stream); err != nil {
+		t.Fatal(err)
+	}
+	if len(stream.Value) != 7 || stream.Value[6].Id != "7-0" || strings.Join(stream.Value[6].Fields, ",") != "a,1,b,6" {
+		t.Errorf("stream = %+v", stream)
+	}
+}
+
+func TestExportKeysStreamKeepsFieldOrder(t *testing.T) {
+	mr, rdb := newRedis(t)
+	if _, err := mr.XAdd("events", "1-0", []string{"zone", "eu", "action", "login", "zone", "us"}); err != nil {
+		t.Fatal(err)
+	}
+
+	var buf bytes.Buffer
+	opts := exportOptions{format: "jsonl", keys: []string{"events"}, rate: -1}
+	err := exportKeys(context.Background(), rdb, nil, opts, &buf, func(*types.ClientKeysExportProgressEvent) error { return nil })
+	if err != nil {
+		t.Fatalf("exportKeys: %v", err)
+	}
+
+	var stream struct {
+		Value []struct {
+			Id     string   `json:"id"`
+			Fields []string `json:"fields"`
+		} `json:"value"`
+	}
+	if err := json.Unmarshal(buf.Bytes(), &stream); err != nil {
+		t.Fatal(err)
+	}
+	if len(stream.Value) != 1 || strings.Join(stream.Value[0].Fields, ",") != "zone,eu,action,login,zone,us" {
+		t.Errorf("stream = %+v, want the fields as added", stream)
+	}
+}
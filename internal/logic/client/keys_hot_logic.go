@@ -0,0 +1,309 @@
+// Code generated by scorix.
+package client
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/tradalab/scorix/app"
+
+	"github.com/tradalab/rdms/internal/svc"
+	"github.com/tradalab/rdms/internal/types"
+	"github.com/tradalab/rdms/pkg/keyfilter"
+)
+
+const (
+	hotModeLFU     = "lfu"
+	hotModeMonitor = "monitor"
+
+	defaultHotWindow = 10 * time.Second
+	maxHotWindow     = time.Minute
+
+	hotMonitorWarning = "MONITOR sends every command the server runs to this client. " +
+		"On a busy server that can cost half its throughput or more, so sampling stops after the window."
+)
+
+type KeysHotLogic struct {
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+func NewKeysHotLogic(ctx context.Context, svcCtx *svc.ServiceContext) *KeysHotLogic {
+	return &KeysHotLogic{
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+func (l *KeysHotLogic) KeysHot(req *types.ClientKeysHotReq, out app.Sink[types.ClientKeysHotEvent]) error {
+	clauses := make([]keyfilter.Clause, 0, len(req.Filters))
+	for _, f := range req.Filters {
+		clauses = append(clauses, keyfilter.Clause{
+			Pattern:    f.Pattern,
+			Mode:       f.Mode,
+			Exclude:    f.Exclude,
+			IgnoreCase: f.IgnoreCase,
+		})
+	}
+
+	filter, err := keyfilter.Compile(clauses, req.MatchAll)
+	if err != nil {
+		return err
+	}
+
+	cli, err := l.svcCtx.RedisManager.Get(req.ConnectionId, int(req.DatabaseIndex))
+	if err != nil {
+		return err
+	}
+	defer cli.BeginStream()()
+
+	ctx := out.Context()
+	opts := hotOptions{
+		db:          int(req.DatabaseIndex),
+		separator:   req.Separator,
+		prefixDepth: int(req.PrefixDepth),
+		top:         int(req.Top),
+		window:      time.Duration(req.WindowMs) * time.Millisecond,
+		scanCount:   req.ScanCount,
+		rate:        req.Rate,
+	}
+	// Managed services may refuse CONFIG; the policy then stays unknown.
+	if cfg, err := cli.Rdb.ConfigGet(ctx, "maxmemory-policy").Result(); err == nil {
+		opts.policy = cfg["maxmemory-policy"]
+	}
+	lfu := strings.Contains(opts.policy, "lfu")
+
+	mode := req.Mode
+	if mode == "" {
+		mode = hotModeMonitor
+		if lfu {
+			mode = hotModeLFU
+		}
+	}
+
+	switch mode {
+	case hotModeLFU:
+		if opts.policy != "" && !lfu {
+			return fmt.Errorf("OBJECT FREQ needs an LFU maxmemory-policy, the server uses %s", opts.policy)
+		}
+		return hotKeysLFU(ctx, cli.Rdb, filter, opts, out.Send)
+
+	case hotModeMonitor:
+		rdb, ok := cli.Rdb.(*redis.Client)
+		if !ok {
+			return fmt.Errorf("MONITOR is only supported on standalone redis client")
+		}
+		table, err := svc.LoadCommandTable(ctx, rdb)
+		if err != nil {
+			return err
+		}
+
+		opts.window = hotWindow(opts.window)
+		ctx, cancel := context.WithTimeout(ctx, opts.window)
+		defer cancel()
+
+		conn, br, err := svc.DialMonitor(ctx, rdb.Options())
+		if err != nil {
+			return fmt.Errorf("monitor connect failed: %w", err)
+		}
+		go func() {
+			<-ctx.Done()
+			_ = conn.Close()
+		}()
+
+		return hotKeysMonitor(ctx, br, table, filter, opts, out.Send)
+	}
+	return fmt.Errorf("unknown hot-key mode %q", mode)
+}
+
+type hotOptions struct {
+	db          int
+	policy      string
+	separator   string
+	prefixDepth int
+	top         int
+	window      time.Duration
+	scanCount   int64
+	rate        int64
+}
+
+// hotWindow applies the default to a zero window and the hard cap to all.
+func hotWindow(w time.Duration) time.Duration {
+	if w <= 0 {
+		return defaultHotWindow
+	}
+	return min(w, maxHotWindow)
+}
+
+// hotKeysLFU ranks the keys scanFiltered yields by their OBJECT FREQ
+// counters. Both run on the masters: a replica's counters only see the
+// reads it serves itself.
+func hotKeysLFU(
+	ctx context.Context,
+	rdb redis.UniversalClient,
+	filter *keyfilter.Set,
+	opts hotOptions,
+	emit func(*types.ClientKeysHotEvent) error,
+) error {
+	h := svc.NewHotKeys(opts.separator, opts.prefixDepth, opts.top)
+	var (
+		start  = time.Now()
+		ranked uint64
+	)
+
+	return pacedScan(ctx, rdb, filter, searchOptions{
+		match:     filter.Pushdown(),
+		scanCount: opts.scanCount,
+		masters:   true,
+	}, opts.rate, func(keys []string) error {
+		cmds, err := objectFreqs(ctx, rdb, keys)
+		if err != nil {
+			return err
+		}
+		for i, cmd := range cmds {
+			if freq, err := cmd.Result(); err == nil {
+				h.Add(keys[i], freq, 0, 0)
+				ranked++
+			}
+		}
+		return nil
+	}, func(ev *types.ClientKeysSearchEvent, done bool) error {
+		hot := h.Snapshot()
+		hot.Mode = hotModeLFU
+		hot.Policy = opts.policy
+		hot.Scanned = ev.Scanned
+		hot.Matched = ranked
+		hot.NodeStats = ev.Nodes
+		hot.ElapsedMs = time.Since(start).Milliseconds()
+		hot.Done = done
+		hot.Truncated = hot.Truncated || done && ev.Truncated
+		return emit(hot)
+	})
+}
+
+// objectFreqs sends OBJECT FREQ for keys in one pipeline per master. A
+// cluster client set to read from replicas would route it to them.
+func objectFreqs(ctx context.Context, rdb redis.UniversalClient, keys []string) ([]*redis.IntCmd, error) {
+	cmds := make([]*redis.IntCmd, len(keys))
+	pipes := make(map[redis.Cmdable]redis.Pipeliner)
+	cc, cluster := rdb.(*redis.ClusterClient)
+	for i, key := range keys {
+		var node redis.Cmdable = rdb
+		if cluster {
+			master, err := cc.MasterForKey(ctx, key)
+			if err != nil {
+				return nil, fmt.Errorf("locate master for %q: %w", key, err)
+			}
+			node = master
+		}
+		pipe, ok := pipes[node]
+		if !ok {
+			pipe = node.Pipeline()
+			pipes[node] = pipe
+		}
+		cmds[i] = pipe.ObjectFreq(ctx, key)
+	}
+	for _, pipe := range pipes {
+		// A key gone since SCAN fails on its own; the rest still count.
+		if _, err := pipe.Exec(ctx); err != nil && ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+	}
+	return cmds, nil
+}
+
+// hotKeysMonitor counts the keys of every command read from r that ran on
+// opts.db, until r ends or ctx is done; the caller bounds ctx by the
+// window and closes the connection behind r when it ends.
+func hotKeysMonitor(
+	ctx context.Context,
+	r io.Reader,
+	table svc.CommandTable,
+	filter *keyfilter.Set,
+	opts hotOptions,
+	emit func(*types.ClientKeysHotEvent) error,
+) error {
+	lines := make(chan string, 1024)
+	go func() {
+		defer close(lines)
+		sc := bufio.NewScanner(r)
+		sc.Buffer(make([]byte, 64<<10), 64<<20)
+		for sc.Scan() {
+			select {
+			case lines <- sc.Text():
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	h := svc.NewHotKeys(opts.separator, opts.prefixDepth, opts.top)
+	var (
+		start   = time.Now()
+		scanned uint64
+		matched uint64
+	)
+
+	send := func(done bool) error {
+		hot := h.Snapshot()
+		hot.Mode = hotModeMonitor
+		hot.Policy = opts.policy
+		hot.Warning = hotMonitorWarning
+		hot.Scanned = scanned
+		hot.Matched = matched
+		hot.ElapsedMs = time.Since(start).Milliseconds()
+		hot.WindowMs = opts.window.Milliseconds()
+		hot.Done = done
+		return emit(hot)
+	}
+	if err := send(false); err != nil {
+		return err
+	}
+
+	tick := time.NewTicker(reportProgressInterval)
+	defer tick.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return send(true)
+		case <-tick.C:
+			if err := send(false); err != nil {
+				return err
+			}
+		case line, ok := <-lines:
+			if !ok {
+				return send(true)
+			}
+			e, err := svc.ParseMonitorLine(line)
+			if err != nil {
+				continue // "OK" and anything else that is not a command
+			}
+			scanned++
+			if e.DB != opts.db {
+				continue
+			}
+			keys, write := table.Keys(e.Args)
+			var reads, writes int64 = 1, 0
+			if write {
+				reads, writes = 0, 1
+			}
+			hit := false
+			for _, key := range keys {
+				if !filter.Match(key) {
+					continue
+				}
+				h.Add(key, 1, reads, writes)
+				matched++
+				hit = true
+			}
+			if hit {
+				h.AddClient(e.Addr, write)
+			}
+		}
+	}
+}
@@ -0,0 +1,59 @@
+package client
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/tradalab/rdms/internal/svc"
+	"github.com/tradalab/rdms/internal/types"
+	"github.com/tradalab/rdms/pkg/keyfilter"
+)
+
+func TestHotKeysMonitorCountsSampledCommands(t *testing.T) {
+	table := svc.CommandTable{
+		"get":  {FirstKeyPos: 1, LastKeyPos: 1, StepCount: 1, Flags: []string{"readonly"}},
+		"set":  {FirstKeyPos: 1, LastKeyPos: 1, StepCount: 1, Flags: []string{"write"}},
+		"mget": {FirstKeyPos: 1, LastKeyPos: -1, StepCount: 1, Flags: []string{"readonly"}},
+		"ping": {},
+	}
+	stream := strings.Join([]string{
+		"OK",
+		`1.0 [0 10.0.0.1:1] "GET" "user:1"`,
+		`1.0 [0 10.0.0.1:1] "GET" "user:1"`,
+		`1.0 [0 10.0.0.2:1] "SET" "user:1" "v"`,
+		`1.0 [0 10.0.0.2:1] "MGET" "user:2" "session:1"`,
+		`1.0 [0 10.0.0.3:1] "PING"`,
+		`1.0 [1 10.0.0.3:1] "GET" "user:1"`,
+	}, "\n")
+
+	filter, _ := keyfilter.Compile([]keyfilter.Clause{{Pattern: "user:*"}}, false)
+	var last *types.ClientKeysHotEvent
+	err := hotKeysMonitor(context.Background(), strings.NewReader(stream), table, filter, hotOptions{window: time.Second}, func(ev *types.ClientKeysHotEvent) error {
+		last = ev
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("hotKeysMonitor: %v", err)
+	}
+
+	if !last.Done || last.Mode != hotModeMonitor || last.Warning == "" || last.WindowMs != 1000 {
+		t.Fatalf("last = %+v", last)
+	}
+	if last.Scanned != 6 || last.Matched != 4 {
+		t.Errorf("scanned = %d matched = %d", last.Scanned, last.Matched)
+	}
+	if k := last.Keys[0]; k.Key != "user:1" || k.Hits != 3 || k.Reads != 2 || k.Writes != 1 {
+		t.Errorf("top key = %+v", k)
+	}
+	if len(last.Clients) != 2 || last.Clients[0].Addr != "10.0.0.1:1" || last.Clients[1].Writes != 1 || last.Clients[1].Reads != 1 {
+		t.Errorf("clients = %+v, PING and db 1 must not count", last.Clients)
+	}
+}
+
+func TestHotWindowIsCapped(t *testing.T) {
+	if hotWindow(0) != defaultHotWindow || hotWindow(time.Hour) != maxHotWindow || hotWindow(time.Second) != time.Second {
+		t.Error("window defaults or cap not applied")
+	}
+}
@@ -0,0 +1,278 @@
+// Code generated by scorix.
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"time"
+	"unicode/utf8"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/tradalab/scorix/app"
+
+	"github.com/tradalab/rdms/internal/svc"
+	"github.com/tradalab/rdms/internal/types"
+)
+
+const (
+	importSkip      = "skip"
+	importOverwrite = "overwrite"
+	importFail      = "fail"
+
+	defaultImportBatch    = 500
+	maxImportErrorSamples = 20
+)
+
+type KeysImportLogic struct {
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+func NewKeysImportLogic(ctx context.Context, svcCtx *svc.ServiceContext) *KeysImportLogic {
+	return &KeysImportLogic{
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+func (l *KeysImportLogic) KeysImport(req *types.ClientKeysImportReq, out app.Sink[types.ClientKeysImportProgressEvent]) error {
+	if req.Path == "" {
+		return fmt.Errorf("path must be provided")
+	}
+	conflict := req.Conflict
+	switch conflict {
+	case "":
+		conflict = importSkip
+	case importSkip, importOverwrite, importFail:
+	default:
+		return fmt.Errorf("unknown conflict policy %q", conflict)
+	}
+	format := req.Format
+	if format == "" {
+		format = svc.KeyExportJSONL
+	}
+
+	cli, err := l.svcCtx.RedisManager.Get(req.ConnectionId, int(req.DatabaseIndex))
+	if err != nil {
+		return err
+	}
+	defer cli.BeginStream()()
+	if cli.ReadOnly.Load() {
+		return svc.ErrReadOnly
+	}
+
+	ctx := out.Context()
+	opts := svc.ImportOptions{
+		KeyTemplate: req.KeyTemplate,
+		KeyType:     req.KeyType,
+		Fields:      req.Fields,
+		ValueColumn: req.ValueColumn,
+		DB:          int(req.DatabaseIndex),
+	}
+	if req.CsvDelimiter != "" {
+		r, size := utf8.DecodeRuneInString(req.CsvDelimiter)
+		if size != len(req.CsvDelimiter) {
+			return fmt.Errorf("csv delimiter must be a single character, got %q", req.CsvDelimiter)
+		}
+		opts.Delimiter = r
+	}
+	if format == svc.KeyExportRESP {
+		if opts.Commands, err = svc.LoadCommandTable(ctx, cli.Rdb); err != nil {
+			return err
+		}
+	}
+
+	f, err := os.Open(req.Path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	st, err := f.Stat()
+	if err != nil {
+		return err
+	}
+
+	counter := &countingReader{r: f}
+	src, err := svc.NewImportReader(format, counter, opts)
+	if err != nil {
+		return err
+	}
+
+	return importKeys(ctx, cli.Rdb, src, importOptions{conflict: conflict, batchSize: req.BatchSize}, func(ev *types.ClientKeysImportProgressEvent) error {
+		ev.ConnectionId = req.ConnectionId
+		ev.Path = req.Path
+		ev.Read = counter.n
+		ev.Size = st.Size()
+		return out.Send(ev)
+	})
+}
+
+type importOptions struct {
+	conflict  string
+	batchSize int64
+}
+
+// importKeys applies the ops src reads in pipelines of about
+// opts.batchSize commands. The conflict policy is settled the first time
+// a key comes up, with one EXISTS per new key in a batch, and holds for
+// every later op on that key. Under "fail" the import stops at the first
+// existing key; batches before it stay applied.
+func importKeys(
+	ctx context.Context,
+	rdb redis.UniversalClient,
+	src svc.ImportReader,
+	opts importOptions,
+	emit func(*types.ClientKeysImportProgressEvent) error,
+) error {
+	batchSize := opts.batchSize
+	if batchSize <= 0 {
+		batchSize = defaultImportBatch
+	}
+
+	var (
+		ev       = &types.ClientKeysImportProgressEvent{}
+		write    = make(map[string]bool) // settled keys: whether their ops run
+		lastEmit = time.Now()
+	)
+	fail := func(msg string) {
+		ev.Errors++
+		if len(ev.ErrorSamples) < maxImportErrorSamples {
+			ev.ErrorSamples = append(ev.ErrorSamples, msg)
+		}
+	}
+	progress := func(status string) error {
+		snap := *ev
+		snap.Status = status
+		snap.ErrorSamples = append([]string(nil), ev.ErrorSamples...)
+		lastEmit = time.Now()
+		return emit(&snap)
+	}
+
+	apply := func(batch []svc.ImportOp) error {
+		exists := make(map[string]*redis.IntCmd)
+		pipe := rdb.Pipeline()
+		for _, op := range batch {
+			if _, settled := write[op.Key]; op.Key != "" && !settled && exists[op.Key] == nil {
+				exists[op.Key] = pipe.Exists(ctx, op.Key)
+			}
+		}
+		if pipe.Len() > 0 {
+			if _, err := pipe.Exec(ctx); err != nil {
+				return err
+			}
+		}
+
+		var lines []int
+		pipe = rdb.Pipeline()
+		send := func(line int, args []string) {
+			a := make([]any, len(args))
+			for i, s := range args {
+				a[i] = s
+			}
+			pipe.Do(ctx, a...)
+			lines = append(lines, line)
+		}
+		for _, op := range batch {
+			if op.Key != "" {
+				ok, settled := write[op.Key]
+				if !settled {
+					found := exists[op.Key].Val() > 0
+					switch {
+					case !found:
+						ok = true
+					case opts.conflict == importFail:
+						return fmt.Errorf("line %d: key %q already exists", op.Line, op.Key)
+					case opts.conflict == importOverwrite:
+						send(op.Line, []string{"DEL", op.Key})
+						ev.Overwritten++
+						ok = true
+					default:
+						ev.Skipped++
+					}
+					write[op.Key] = ok
+					if ok {
+						ev.Imported++
+					}
+				}
+				if !ok {
+					continue
+				}
+			}
+			for _, c := range op.Cmds {
+				send(op.Line, c)
+			}
+		}
+		if pipe.Len() == 0 {
+			return nil
+		}
+
+		// Rejected commands are counted below; read-only mode switched on
+		// meanwhile fails the whole pipeline and ends the import.
+		cmds, err := pipe.Exec(ctx)
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		if errors.Is(err, svc.ErrReadOnly) {
+			return err
+		}
+		ev.Commands += int64(len(cmds))
+		for i, c := range cmds {
+			if err := c.Err(); err != nil && err != redis.Nil {
+				fail(fmt.Sprintf("line %d: %v", lines[i], err))
+			}
+		}
+		return nil
+	}
+
+	for {
+		var (
+			batch []svc.ImportOp
+			n     int64
+			eof   bool
+		)
+		for n < batchSize {
+			op, err := src.Next()
+			if err == io.EOF {
+				eof = true
+				break
+			}
+			var ie *svc.ImportError
+			if errors.As(err, &ie) {
+				fail(ie.Error())
+				continue
+			}
+			if err != nil {
+				return err
+			}
+			batch = append(batch, op)
+			n += int64(len(op.Cmds))
+		}
+
+		if err := apply(batch); err != nil {
+			return err
+		}
+		if eof {
+			return progress("done")
+		}
+		if time.Since(lastEmit) >= reportProgressInterval {
+			if err := progress("processing"); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// countingReader counts the bytes read through it.
+type countingReader struct {
+	r io.Reader
+	n int64
+}
+
+func (c *countingReader) Read(p []byte) (int, error) {
+	n, err := c.r.Read(p)
+	c.n += int64(n)
+	return n, err
+}
@@ -0,0 +1,122 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/tradalab/rdms/internal/svc"
+	"github.com/tradalab/rdms/internal/types"
+)
+
+func TestImportKeysConflictPolicies(t *testing.T) {
+	script := "SET a new\nRPUSH l x y\nSET b new\nINCR a\nPING\n"
+	for _, tc := range []struct {
+		conflict string
+		a, b     string
+		list     []string
+		ev       types.ClientKeysImportProgressEvent
+		err      bool
+	}{
+		{conflict: importSkip, a: "old", b: "new", list: []string{"x", "y"}, ev: types.ClientKeysImportProgressEvent{Imported: 2, Skipped: 1, Commands: 3}},
+		{conflict: importOverwrite, a: "new", b: "new", list: []string{"x", "y"}, ev: types.ClientKeysImportProgressEvent{Imported: 3, Overwritten: 1, Commands: 6, Errors: 1}},
+		{conflict: importFail, a: "old", err: true},
+	} {
+		t.Run(tc.conflict, func(t *testing.T) {
+			mr, rdb := newRedis(t)
+			mr.Set("a", "old")
+
+			table, err := svc.LoadCommandTable(context.Background(), rdb)
+			if err != nil {
+				t.Fatal(err)
+			}
+			src, err := svc.NewImportReader(svc.KeyExportRESP, strings.NewReader(script), svc.ImportOptions{Commands: table})
+			if err != nil {
+				t.Fatal(err)
+			}
+			var last *types.ClientKeysImportProgressEvent
+			err = importKeys(context.Background(), rdb, src, importOptions{conflict: tc.conflict, batchSize: 2}, func(ev *types.ClientKeysImportProgressEvent) error {
+				last = ev
+				return nil
+			})
+			if tc.err {
+				if err == nil || !strings.Contains(err.Error(), `"a" already exists`) {
+					t.Fatalf("err = %v, want a conflict on a", err)
+				}
+				if v, _ := mr.Get("a"); v != "old" || mr.Exists("b") {
+					t.Error("fail policy applied the batch holding the conflict")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("importKeys: %v", err)
+			}
+
+			if v, _ := mr.Get("a"); v != tc.a {
+				t.Errorf("a = %q, want %q", v, tc.a)
+			}
+			if v, _ := mr.Get("b"); v != tc.b {
+				t.Errorf("b = %q, want %q", v, tc.b)
+			}
+			if l, _ := mr.List("l"); strings.Join(l, ",") != strings.Join(tc.list, ",") {
+				t.Errorf("l = %q", l)
+			}
+			got := *last
+			if got.Status != "done" || got.Imported != tc.ev.Imported || got.Skipped != tc.ev.Skipped ||
+				got.Overwritten != tc.ev.Overwritten || got.Commands != tc.ev.Commands || got.Errors != tc.ev.Errors {
+				t.Errorf("event = %+v", got)
+			}
+			if got.Errors > 0 && (len(got.ErrorSamples) != 1 || !strings.HasPrefix(got.ErrorSamples[0], "line 4:")) {
+				t.Errorf("error samples = %q", got.ErrorSamples)
+			}
+		})
+	}
+}
+
+func TestImportKeysReadsExport(t *testing.T) {
+	mr, rdb := newRedis(t)
+	fillExportKeys(t, mr)
+
+	var buf bytes.Buffer
+	err := exportKeys(context.Background(), rdb, nil, exportOptions{format: svc.KeyExportJSONL, keys: mr.Keys(), rate: -1}, &buf, func(*types.ClientKeysExportProgressEvent) error { return nil })
+	if err != nil {
+		t.Fatalf("exportKeys: %v", err)
+	}
+
+	dst, dstRdb := newRedis(t)
+	src, err := svc.NewImportReader(svc.KeyExportJSONL, bytes.NewReader(buf.Bytes()), svc.ImportOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var last *types.ClientKeysImportProgressEvent
+	err = importKeys(context.Background(), dstRdb, src, importOptions{conflict: importFail}, func(ev *types.ClientKeysImportProgressEvent) error {
+		last = ev
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("importKeys: %v", err)
+	}
+	if last.Imported != int64(len(mr.Keys())) || last.Errors != 0 {
+		t.Fatalf("event = %+v", last)
+	}
+
+	if v, _ := dst.Get("app:bin\xff"); v != "\x00\x01" {
+		t.Errorf("binary key = %q", v)
+	}
+	if ttl := dst.TTL("app:str"); ttl <= 0 || ttl > time.Hour {
+		t.Errorf("app:str ttl = %v", ttl)
+	}
+	for _, key := range []string{"app:list", "app:set", "app:zset", "app:hash", "app:stream"} {
+		if dst.Type(key) != mr.Type(key) {
+			t.Errorf("%s type = %q", key, dst.Type(key))
+		}
+	}
+	if z, _ := dst.SortedSet("app:zset"); z["z6"] != 6.5 {
+		t.Errorf("app:zset = %v", z)
+	}
+	if s, _ := dst.Stream("app:stream"); len(s) != 7 || s[6].ID != "7-0" {
+		t.Errorf("app:stream = %v", s)
+	}
+}
@@ -27,22 +27,31 @@ func (l *KeysMetadataLogic) KeysMetadata(params *types.ClientKeysMetadataReq) (*
 		return nil, err
 	}
 
-	items := make([]types.KeyMetadata, 0)
+	return &types.ClientKeysMetadataRes{
+		Items: keysMetadata(l.ctx, cli.Reader(), params.Keys),
+	}, nil
+}
+
+// keysMetadata reads TYPE, PTTL and MEMORY USAGE for keys in one pipeline.
+// Ttl is a time.Duration, or -1 without expiry and -2 for a key that is
+// gone, which also reports type "none".
+func keysMetadata(ctx context.Context, rdb redis.Cmdable, keys []string) []types.KeyMetadata {
+	items := make([]types.KeyMetadata, 0, len(keys))
 
-	pipe := cli.Rdb.Pipeline()
+	pipe := rdb.Pipeline()
 	typeCmds := make(map[string]*redis.StatusCmd)
 	ttlCmds := make(map[string]*redis.DurationCmd)
 	sizeCmds := make(map[string]*redis.IntCmd)
 
-	for _, key := range params.Keys {
-		typeCmds[key] = pipe.Type(l.ctx, key)
-		ttlCmds[key] = pipe.PTTL(l.ctx, key)
-		sizeCmds[key] = pipe.MemoryUsage(l.ctx, key)
+	for _, key := range keys {
+		typeCmds[key] = pipe.Type(ctx, key)
+		ttlCmds[key] = pipe.PTTL(ctx, key)
+		sizeCmds[key] = pipe.MemoryUsage(ctx, key)
 	}
 
-	_, _ = pipe.Exec(l.ctx)
+	_, _ = pipe.Exec(ctx)
 
-	for _, key := range params.Keys {
+	for _, key := range keys {
 		item := types.KeyMetadata{
 			Key:  key,
 			Type: typeCmds[key].Val(),
@@ -51,8 +60,5 @@ func (l *KeysMetadataLogic) KeysMetadata(params *types.ClientKeysMetadataReq) (*
 		}
 		items = append(items, item)
 	}
-
-	return &types.ClientKeysMetadataRes{
-		Items: items,
-	}, nil
+	return items
 }
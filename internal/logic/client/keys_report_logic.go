@@ -0,0 +1,294 @@
+// Code generated by scorix.
+package client
+
+import (
+	"context"
+	"math"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/tradalab/scorix/app"
+
+	"github.com/tradalab/rdms/internal/svc"
+	"github.com/tradalab/rdms/internal/types"
+	"github.com/tradalab/rdms/pkg/keyfilter"
+)
+
+const (
+	defaultReportRate      = 5000
+	defaultReportBudget    = 30 * time.Minute
+	reportProgressInterval = time.Second
+	reportPipelineBatch    = 100
+)
+
+type KeysReportLogic struct {
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+func NewKeysReportLogic(ctx context.Context, svcCtx *svc.ServiceContext) *KeysReportLogic {
+	return &KeysReportLogic{
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+func (l *KeysReportLogic) KeysReport(req *types.ClientKeysReportReq, out app.Sink[types.ClientKeysReportEvent]) error {
+	clauses := make([]keyfilter.Clause, 0, len(req.Filters))
+	for _, f := range req.Filters {
+		clauses = append(clauses, keyfilter.Clause{
+			Pattern:    f.Pattern,
+			Mode:       f.Mode,
+			Exclude:    f.Exclude,
+			IgnoreCase: f.IgnoreCase,
+		})
+	}
+
+	filter, err := keyfilter.Compile(clauses, req.MatchAll)
+	if err != nil {
+		return err
+	}
+
+	cli, err := l.svcCtx.RedisManager.Get(req.ConnectionId, int(req.DatabaseIndex))
+	if err != nil {
+		return err
+	}
+	defer cli.BeginStream()()
+
+	opts := reportOptions{
+		keyType:       req.KeyType,
+		separator:     req.Separator,
+		prefixDepth:   int(req.PrefixDepth),
+		top:           int(req.Top),
+		memorySamples: int(req.MemorySamples),
+		scanCount:     req.ScanCount,
+		rate:          req.Rate,
+		budget:        time.Duration(req.BudgetMs) * time.Millisecond,
+	}
+
+	return reportKeys(out.Context(), cli.Reader(), filter, opts, out.Send)
+}
+
+type reportOptions struct {
+	keyType       string
+	separator     string
+	prefixDepth   int
+	top           int
+	memorySamples int
+	scanCount     int64
+	rate          int64
+	budget        time.Duration
+}
+
+// reportKeys sizes the keys scanFiltered yields over the whole keyspace.
+func reportKeys(
+	ctx context.Context,
+	rdb redis.UniversalClient,
+	filter *keyfilter.Set,
+	opts reportOptions,
+	emit func(*types.ClientKeysReportEvent) error,
+) error {
+	r := svc.NewKeyReport(opts.separator, opts.prefixDepth, opts.top)
+	start := time.Now()
+
+	return pacedScan(ctx, rdb, filter, searchOptions{
+		match:     filter.Pushdown(),
+		keyType:   opts.keyType,
+		scanCount: opts.scanCount,
+		budget:    opts.budget,
+	}, opts.rate, func(keys []string) error {
+		return measureKeys(ctx, rdb, r, opts, keys)
+	}, func(ev *types.ClientKeysSearchEvent, done bool) error {
+		rep := r.Snapshot()
+		rep.Scanned = ev.Scanned
+		rep.Matched = ev.Matched
+		rep.NodeStats = ev.Nodes
+		rep.ElapsedMs = time.Since(start).Milliseconds()
+		rep.Done = done
+		rep.Truncated = done && ev.Truncated
+		return emit(rep)
+	})
+}
+
+// pacedScan runs scanFiltered to the end of the keyspace, or of
+// opts.budget, and hands the keys it yields to measure through
+// pacedBatches. progress gets the latest scan event about once per
+// reportProgressInterval, and once more with done set when the scan ends.
+func pacedScan(
+	ctx context.Context,
+	rdb redis.UniversalClient,
+	filter *keyfilter.Set,
+	opts searchOptions,
+	rate int64,
+	measure func(keys []string) error,
+	progress func(ev *types.ClientKeysSearchEvent, done bool) error,
+) error {
+	opts.limit = math.MaxInt64
+	if opts.budget <= 0 {
+		opts.budget = defaultReportBudget
+	}
+
+	b := newPacedBatches(rate)
+	return scanFiltered(ctx, rdb, filter, opts, func(ev *types.ClientKeysSearchEvent) error {
+		send := func() error { return progress(ev, false) }
+		if err := b.run(ctx, ev.Keys, ev.Done, measure, send); err != nil {
+			return err
+		}
+		if ev.Done {
+			return progress(ev, true)
+		}
+		return b.tick(send)
+	})
+}
+
+// pacedBatches hands keys to a measure function in pipelines of
+// reportPipelineBatch, sleeping between them to hold rate keys per second.
+type pacedBatches struct {
+	pacer    keyPacer
+	lastEmit time.Time
+	measured int64
+}
+
+func newPacedBatches(rate int64) *pacedBatches {
+	start := time.Now()
+	return &pacedBatches{pacer: newKeyPacer(rate, start), lastEmit: start}
+}
+
+// run measures keys batch by batch, ticking progress between them. With
+// last set nothing follows the final batch, so it neither reports nor
+// waits after it.
+func (b *pacedBatches) run(ctx context.Context, keys []string, last bool, measure func([]string) error, progress func() error) error {
+	for len(keys) > 0 {
+		n := min(len(keys), reportPipelineBatch)
+		if err := measure(keys[:n]); err != nil {
+			return err
+		}
+		b.measured += int64(n)
+		keys = keys[n:]
+		if last && len(keys) == 0 {
+			break
+		}
+		if err := b.tick(progress); err != nil {
+			return err
+		}
+		if err := b.pacer.wait(ctx, b.measured); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// tick calls progress once reportProgressInterval has passed since it
+// last did.
+func (b *pacedBatches) tick(progress func() error) error {
+	if time.Since(b.lastEmit) < reportProgressInterval {
+		return nil
+	}
+	b.lastEmit = time.Now()
+	return progress()
+}
+
+// keyPacer holds work to rate keys per second counted from start.
+type keyPacer struct {
+	rate  int64
+	start time.Time
+}
+
+// newKeyPacer applies defaultReportRate to a zero rate; a negative one
+// never waits.
+func newKeyPacer(rate int64, start time.Time) keyPacer {
+	if rate == 0 {
+		rate = defaultReportRate
+	}
+	return keyPacer{rate: rate, start: start}
+}
+
+// wait sleeps until done keys are due, or ctx ends.
+func (p keyPacer) wait(ctx context.Context, done int64) error {
+	if p.rate <= 0 {
+		return nil
+	}
+	due := time.Duration(done) * time.Second / time.Duration(p.rate)
+	wait := due - time.Since(p.start)
+	if wait <= 0 {
+		return nil
+	}
+	t := time.NewTimer(wait)
+	defer t.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-t.C:
+		return nil
+	}
+}
+
+// measureKeys sizes keys in two round trips, TYPE, PTTL and MEMORY USAGE,
+// then the length command that fits each type, and adds them to r.
+func measureKeys(ctx context.Context, rdb redis.UniversalClient, r *svc.KeyReport, opts reportOptions, keys []string) error {
+	typeCmds := make([]*redis.StatusCmd, len(keys))
+	ttlCmds := make([]*redis.DurationCmd, len(keys))
+	sizeCmds := make([]*redis.IntCmd, len(keys))
+
+	pipe := rdb.Pipeline()
+	for i, key := range keys {
+		if opts.keyType == "" {
+			typeCmds[i] = pipe.Type(ctx, key)
+		}
+		ttlCmds[i] = pipe.PTTL(ctx, key)
+		if opts.memorySamples > 0 {
+			sizeCmds[i] = pipe.MemoryUsage(ctx, key, opts.memorySamples)
+		} else {
+			sizeCmds[i] = pipe.MemoryUsage(ctx, key)
+		}
+	}
+	// Per-command errors are read below; MEMORY USAGE may be disabled on
+	// managed services, and a key gone since SCAN reports type "none".
+	if _, err := pipe.Exec(ctx); err != nil && ctx.Err() != nil {
+		return ctx.Err()
+	}
+
+	keyTypes := make([]string, len(keys))
+	lenCmds := make([]*redis.IntCmd, len(keys))
+	pipe = rdb.Pipeline()
+	for i, key := range keys {
+		keyTypes[i] = opts.keyType
+		if typeCmds[i] != nil {
+			keyTypes[i] = typeCmds[i].Val()
+		}
+		switch keyTypes[i] {
+		case "string":
+			lenCmds[i] = pipe.StrLen(ctx, key)
+		case "list":
+			lenCmds[i] = pipe.LLen(ctx, key)
+		case "set":
+			lenCmds[i] = pipe.SCard(ctx, key)
+		case "zset":
+			lenCmds[i] = pipe.ZCard(ctx, key)
+		case "hash":
+			lenCmds[i] = pipe.HLen(ctx, key)
+		case "stream":
+			lenCmds[i] = pipe.XLen(ctx, key)
+		}
+	}
+	if pipe.Len() > 0 {
+		if _, err := pipe.Exec(ctx); err != nil && ctx.Err() != nil {
+			return ctx.Err()
+		}
+	}
+
+	for i, key := range keys {
+		if keyTypes[i] == "" || keyTypes[i] == "none" {
+			continue
+		}
+		k := types.KeyReportKey{Key: key, Type: keyTypes[i], Memory: sizeCmds[i].Val(), Ttl: -1}
+		if lenCmds[i] != nil {
+			k.Elements = lenCmds[i].Val()
+		}
+		if d := ttlCmds[i].Val(); d >= 0 {
+			k.Ttl = d.Milliseconds()
+		}
+		r.Add(k)
+	}
+	return nil
+}
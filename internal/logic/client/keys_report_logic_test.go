@@ -0,0 +1,109 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/tradalab/rdms/internal/types"
+	"github.com/tradalab/rdms/pkg/keyfilter"
+)
+
+func reportOf(ev *types.ClientKeysReportEvent, typ string) *types.KeyReportType {
+	for i := range ev.Types {
+		if ev.Types[i].Type == typ {
+			return &ev.Types[i]
+		}
+	}
+	return nil
+}
+
+func TestReportKeysRanksAndAggregates(t *testing.T) {
+	mr, rdb := newRedis(t)
+
+	for i := range 100 {
+		mr.Set(fmt.Sprintf("user:%d", i), "v")
+	}
+	mr.Set("user:big", strings.Repeat("x", 10000))
+	for i := range 5 {
+		for j := 0; j <= i*10; j++ {
+			mr.HSet(fmt.Sprintf("profile:%d", i), fmt.Sprint(j), "v")
+		}
+	}
+	mr.Lpush("queue", "a")
+	mr.SetTTL("queue", time.Minute)
+
+	filter, _ := keyfilter.Compile(nil, false)
+	var events []*types.ClientKeysReportEvent
+	err := reportKeys(context.Background(), rdb, filter, reportOptions{top: 3, scanCount: 20, rate: -1}, func(ev *types.ClientKeysReportEvent) error {
+		events = append(events, ev)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("reportKeys: %v", err)
+	}
+	last := events[len(events)-1]
+	if !last.Done || last.Truncated {
+		t.Fatalf("last event done = %v truncated = %v", last.Done, last.Truncated)
+	}
+	if last.Keys != 107 || last.Matched != 107 {
+		t.Errorf("keys = %d matched = %d, want 107", last.Keys, last.Matched)
+	}
+
+	str := reportOf(last, "string")
+	if str == nil || str.Keys != 101 || len(str.TopElements) != 3 || str.TopElements[0].Key != "user:big" || str.TopElements[0].Elements != 10000 {
+		t.Errorf("string = %+v", str)
+	}
+
+	hash := reportOf(last, "hash")
+	if hash == nil || hash.Keys != 5 || hash.Elements != 105 {
+		t.Fatalf("hash = %+v", hash)
+	}
+	var top []string
+	for _, k := range hash.TopElements {
+		top = append(top, k.Key)
+	}
+	if strings.Join(top, ",") != "profile:4,profile:3,profile:2" {
+		t.Errorf("hash top = %v", top)
+	}
+	var hist int64
+	for _, b := range hash.ElementsHist {
+		hist += b.Count
+	}
+	if hist != 5 || hash.ElementsHist[2] != (types.KeyReportBucket{Min: 10, Count: 4}) {
+		t.Errorf("hash histogram = %+v", hash.ElementsHist)
+	}
+
+	list := reportOf(last, "list")
+	if list == nil || list.TopMemory[0].Ttl <= 0 {
+		t.Errorf("list = %+v", list)
+	}
+
+	prefixes := make(map[string]int64)
+	for _, p := range last.Prefixes {
+		prefixes[p.Prefix] = p.Keys
+	}
+	if prefixes["user:"] != 101 || prefixes["profile:"] != 5 || prefixes[""] != 1 {
+		t.Errorf("prefixes = %v", prefixes)
+	}
+}
+
+func TestReportKeysThrottles(t *testing.T) {
+	mr, rdb := newRedis(t)
+	for i := range 600 {
+		mr.Set(fmt.Sprintf("k:%d", i), "v")
+	}
+
+	filter, _ := keyfilter.Compile(nil, false)
+	start := time.Now()
+	err := reportKeys(context.Background(), rdb, filter, reportOptions{scanCount: 10, rate: 2000}, func(*types.ClientKeysReportEvent) error { return nil })
+	if err != nil {
+		t.Fatalf("reportKeys: %v", err)
+	}
+	// Six pipelines of 100 at 2000/s; the last one is not waited for.
+	if elapsed := time.Since(start); elapsed < 200*time.Millisecond {
+		t.Errorf("took %v, want the rate to slow the scan", elapsed)
+	}
+}
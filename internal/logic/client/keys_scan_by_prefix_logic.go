@@ -31,7 +31,7 @@ func (l *KeysScanByPrefixLogic) KeysScanByPrefix(params *types.ClientKeysDeleteB
 	match := params.Prefix + "*"
 	limit := int64(1000)
 
-	keys, nextCursor, err := cli.Rdb.Scan(l.ctx, 0, match, limit).Result()
+	keys, nextCursor, err := cli.Reader().Scan(l.ctx, 0, match, limit).Result()
 	if err != nil {
 		return nil, err
 	}
@@ -3,7 +3,6 @@ package client
 
 import (
 	"context"
-	"strconv"
 	"time"
 
 	"github.com/redis/go-redis/v9"
@@ -12,7 +11,6 @@ import (
 	"github.com/tradalab/rdms/internal/svc"
 	"github.com/tradalab/rdms/internal/types"
 	"github.com/tradalab/rdms/pkg/keyfilter"
-	"github.com/tradalab/rdms/pkg/util"
 )
 
 const (
@@ -55,11 +53,7 @@ func (l *KeysSearchLogic) KeysSearch(req *types.ClientKeysSearchReq, out app.Sin
 	if err != nil {
 		return err
 	}
-
-	cursor, err := parseSearchCursor(req.Cursor)
-	if err != nil {
-		return err
-	}
+	defer cli.BeginStream()()
 
 	opts := searchOptions{
 		match:     filter.Pushdown(),
@@ -67,10 +61,10 @@ func (l *KeysSearchLogic) KeysSearch(req *types.ClientKeysSearchReq, out app.Sin
 		scanCount: req.ScanCount,
 		limit:     req.Limit,
 		budget:    time.Duration(req.BudgetMs) * time.Millisecond,
-		cursor:    cursor,
+		cursor:    req.Cursor,
 	}
 
-	return scanFiltered(out.Context(), cli.Rdb, filter, opts, out.Send)
+	return scanFiltered(out.Context(), cli.Reader(), filter, opts, out.Send)
 }
 
 type searchOptions struct {
@@ -79,7 +73,8 @@ type searchOptions struct {
 	scanCount int64
 	limit     int64
 	budget    time.Duration
-	cursor    uint64
+	cursor    string
+	masters   bool // walk the masters even where rdb routes reads to replicas
 }
 
 func scanFiltered(
@@ -107,8 +102,24 @@ func scanFiltered(
 		match = "*"
 	}
 
+	target := svc.ScanReadNodes
+	if opts.masters {
+		target = svc.ScanMasters
+	}
+	plan, err := svc.PlanScan(ctx, rdb, target, opts.cursor)
+	if err != nil {
+		return err
+	}
+
+	nodes := make([]types.ScanNodeStat, len(plan.Steps))
+	nodeIdx := make(map[string]int, len(plan.Steps))
+	for i, step := range plan.Steps {
+		nodes[i].Addr = step.Node.Addr
+		nodeIdx[step.Node.Addr] = i
+	}
+
 	var (
-		cursor    = opts.cursor
+		cursor    = plan.Cursor()
 		deadline  = time.Now().Add(budget)
 		batch     []string
 		scanned   uint64
@@ -122,9 +133,10 @@ func scanFiltered(
 			Keys:      batch,
 			Scanned:   scanned,
 			Matched:   matched,
-			Cursor:    strconv.FormatUint(cursor, 10),
+			Cursor:    cursor,
 			Done:      done,
 			Truncated: truncated,
+			Nodes:     append([]types.ScanNodeStat(nil), nodes...),
 		})
 		batch = nil
 		lastEmit = time.Now()
@@ -132,28 +144,23 @@ func scanFiltered(
 	}
 
 	for {
-		var (
-			keys       []string
-			nextCursor uint64
-			err        error
-		)
 		roundCursor := cursor
-		if opts.keyType != "" {
-			keys, nextCursor, err = rdb.ScanType(ctx, cursor, match, scanCount, opts.keyType).Result()
-		} else {
-			keys, nextCursor, err = rdb.Scan(ctx, cursor, match, scanCount).Result()
-		}
+		node := &nodes[nodeIdx[plan.Steps[0].Node.Addr]]
+
+		keys, err := plan.Page(ctx, match, opts.keyType, scanCount)
 		if err != nil {
 			return err
 		}
 
 		scanned += uint64(len(keys))
+		node.Scanned += uint64(len(keys))
 		for _, k := range keys {
 			if !filter.Match(k) {
 				continue
 			}
-			batch = append(batch, util.EncodeRedisKey(k).(string))
+			batch = append(batch, k)
 			matched++
+			node.Matched++
 			if matched >= uint64(limit) {
 				truncated = true
 				break
@@ -165,9 +172,12 @@ func scanFiltered(
 			return send(true)
 		}
 
-		cursor = nextCursor
+		cursor = plan.Cursor()
+		if len(plan.Steps) == 0 || plan.Steps[0].Node.Addr != node.Addr {
+			node.Done = true
+		}
 
-		if cursor == 0 {
+		if plan.Done() {
 			return send(true)
 		}
 
@@ -187,10 +197,3 @@ func scanFiltered(
 		}
 	}
 }
-
-func parseSearchCursor(s string) (uint64, error) {
-	if s == "" || s == "0" {
-		return 0, nil
-	}
-	return strconv.ParseUint(s, 10, 64)
-}
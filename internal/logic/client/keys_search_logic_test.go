@@ -3,6 +3,7 @@ package client
 import (
 	"context"
 	"fmt"
+	"strings"
 	"testing"
 
 	"github.com/alicebob/miniredis/v2"
@@ -179,3 +180,69 @@ func assertSameKeys(t *testing.T, got, want []string) {
 		}
 	}
 }
+
+func TestScanFilteredWalksEveryClusterMaster(t *testing.T) {
+	mr := miniredis.RunT(t)
+	rdb := redis.NewClusterClient(&redis.ClusterOptions{Addrs: []string{mr.Addr()}})
+	t.Cleanup(func() { _ = rdb.Close() })
+
+	for i := range 120 {
+		mr.Set(fmt.Sprintf("user:%d", i), "v")
+	}
+
+	keys, last := collect(t, rdb, []keyfilter.Clause{{Pattern: "user:*"}}, false, searchOptions{scanCount: 10})
+
+	if len(keys) != 120 {
+		t.Errorf("got %d keys, want 120", len(keys))
+	}
+	if len(last.Nodes) != 1 {
+		t.Fatalf("got %d node stats, want one per master", len(last.Nodes))
+	}
+	if n := last.Nodes[0]; !n.Done || n.Scanned != 120 || n.Matched != 120 {
+		t.Errorf("node stat = %+v, want done with 120 scanned and matched", n)
+	}
+	if last.Cursor != "0" {
+		t.Errorf("cursor = %q, want 0 after a complete scan", last.Cursor)
+	}
+}
+
+func TestScanFilteredResumesClusterCursor(t *testing.T) {
+	mr := miniredis.RunT(t)
+	rdb := redis.NewClusterClient(&redis.ClusterOptions{Addrs: []string{mr.Addr()}})
+	t.Cleanup(func() { _ = rdb.Close() })
+
+	for i := range 100 {
+		mr.Set(fmt.Sprintf("k:%d", i), "v")
+	}
+
+	first, last := collect(t, rdb, nil, false, searchOptions{limit: 30, scanCount: 10})
+	if !last.Truncated {
+		t.Fatal("hitting the limit must set truncated")
+	}
+	if !strings.HasPrefix(last.Cursor, mr.Addr()+"=") {
+		t.Fatalf("cursor = %q, want a per-node cursor for %s", last.Cursor, mr.Addr())
+	}
+
+	rest, last := collect(t, rdb, nil, false, searchOptions{cursor: last.Cursor, limit: 1000, scanCount: 10})
+	if last.Truncated {
+		t.Error("resumed scan must run to completion")
+	}
+
+	seen := make(map[string]bool)
+	for _, k := range append(first, rest...) {
+		seen[k] = true
+	}
+	if len(seen) != 100 {
+		t.Errorf("first page plus resume saw %d distinct keys, want 100", len(seen))
+	}
+}
+
+func TestScanFilteredPassesBinaryKeys(t *testing.T) {
+	mr, rdb := newRedis(t)
+	mr.Set("bin:\xff\x00", "v")
+	mr.Set("bin:text", "v")
+
+	keys, _ := collect(t, rdb, []keyfilter.Clause{{Pattern: "bin:*"}}, false, searchOptions{})
+	assertSameKeys(t, keys, []string{"bin:\xff\x00", "bin:text"})
+
+}
@@ -0,0 +1,296 @@
+// Code generated by scorix.
+package client
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/tradalab/scorix/app"
+
+	"github.com/tradalab/rdms/internal/svc"
+	"github.com/tradalab/rdms/internal/types"
+	"github.com/tradalab/rdms/pkg/keyfilter"
+)
+
+const (
+	defaultTreeSeparator     = ":"
+	defaultTreeBudget        = 60 * time.Second
+	defaultTreeMemorySamples = 16
+	defaultTreeLeafLimit     = 1000
+)
+
+type KeysTreeLogic struct {
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+func NewKeysTreeLogic(ctx context.Context, svcCtx *svc.ServiceContext) *KeysTreeLogic {
+	return &KeysTreeLogic{
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+func (l *KeysTreeLogic) KeysTree(req *types.ClientKeysTreeReq, out app.Sink[types.ClientKeysTreeEvent]) error {
+	clauses := make([]keyfilter.Clause, 0, len(req.Filters))
+	for _, f := range req.Filters {
+		clauses = append(clauses, keyfilter.Clause{
+			Pattern:    f.Pattern,
+			Mode:       f.Mode,
+			Exclude:    f.Exclude,
+			IgnoreCase: f.IgnoreCase,
+		})
+	}
+
+	filter, err := keyfilter.Compile(clauses, req.MatchAll)
+	if err != nil {
+		return err
+	}
+
+	cli, err := l.svcCtx.RedisManager.Get(req.ConnectionId, int(req.DatabaseIndex))
+	if err != nil {
+		return err
+	}
+	defer cli.BeginStream()()
+
+	opts := treeOptions{
+		prefix:        req.Prefix,
+		separator:     req.Separator,
+		keyType:       req.KeyType,
+		scanCount:     req.ScanCount,
+		budget:        time.Duration(req.BudgetMs) * time.Millisecond,
+		memorySamples: int(req.MemorySamples),
+		leafLimit:     req.LeafLimit,
+	}
+
+	return scanTree(out.Context(), cli.Reader(), filter, opts, out.Send)
+}
+
+type treeOptions struct {
+	prefix        string
+	separator     string
+	keyType       string
+	scanCount     int64
+	budget        time.Duration
+	memorySamples int
+	leafLimit     int64
+}
+
+// treeMatch narrows the SCAN MATCH to the expanded prefix, keeping the
+// filter's pushdown when it is the narrower of the two.
+func treeMatch(prefix, pushdown string) string {
+	if prefix == "" {
+		return pushdown
+	}
+	byPrefix := globEscape(prefix) + "*"
+	if strings.HasPrefix(pushdown, globEscape(prefix)) {
+		return pushdown
+	}
+	return byPrefix
+}
+
+func globEscape(s string) string {
+	var b strings.Builder
+	for _, r := range s {
+		switch r {
+		case '*', '?', '[', ']', '\\':
+			b.WriteByte('\\')
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+type treeAgg struct {
+	node     types.KeyTreeNode
+	types    map[string]int64
+	memSum   int64
+	sampling int
+}
+
+// scanTree walks the keys under opts.prefix and groups them by the next
+// separator-delimited segment. Every event carries the full set of child
+// namespaces so far, and the leaf keys found since the previous event.
+func scanTree(
+	ctx context.Context,
+	rdb redis.UniversalClient,
+	filter *keyfilter.Set,
+	opts treeOptions,
+	emit func(*types.ClientKeysTreeEvent) error,
+) error {
+	sep := opts.separator
+	if sep == "" {
+		sep = defaultTreeSeparator
+	}
+	scanCount := opts.scanCount
+	if scanCount <= 0 {
+		scanCount = defaultScanCount
+	}
+	budget := opts.budget
+	if budget <= 0 {
+		budget = defaultTreeBudget
+	}
+	samples := opts.memorySamples
+	if samples == 0 {
+		samples = defaultTreeMemorySamples
+	}
+	leafLimit := opts.leafLimit
+	if leafLimit <= 0 {
+		leafLimit = defaultTreeLeafLimit
+	}
+
+	plan, err := svc.PlanScan(ctx, rdb, svc.ScanReadNodes, "")
+	if err != nil {
+		return err
+	}
+	match := treeMatch(opts.prefix, filter.Pushdown())
+
+	nodes := make([]types.ScanNodeStat, len(plan.Steps))
+	nodeIdx := make(map[string]int, len(plan.Steps))
+	for i, step := range plan.Steps {
+		nodes[i].Addr = step.Node.Addr
+		nodeIdx[step.Node.Addr] = i
+	}
+
+	var (
+		aggs      = make(map[string]*treeAgg)
+		leaves    []string
+		leafCount int64
+		scanned   uint64
+		matched   uint64
+		truncated bool
+		deadline  = time.Now().Add(budget)
+		lastEmit  = time.Now()
+	)
+
+	send := func(done bool) error {
+		ev := &types.ClientKeysTreeEvent{
+			Prefix:    opts.prefix,
+			Leaves:    leaves,
+			LeafCount: leafCount,
+			Scanned:   scanned,
+			Matched:   matched,
+			Done:      done,
+			Truncated: truncated,
+			NodeStats: append([]types.ScanNodeStat(nil), nodes...),
+		}
+		for _, a := range aggs {
+			n := a.node
+			for t, c := range a.types {
+				n.Types = append(n.Types, types.KeyTreeTypeCount{Type: t, Count: c})
+			}
+			sort.Slice(n.Types, func(i, j int) bool { return n.Types[i].Count > n.Types[j].Count })
+			if n.MemorySampled > 0 {
+				n.Memory = a.memSum * n.Keys / n.MemorySampled
+			}
+			ev.Nodes = append(ev.Nodes, n)
+		}
+		sort.Slice(ev.Nodes, func(i, j int) bool { return ev.Nodes[i].Prefix < ev.Nodes[j].Prefix })
+		leaves = nil
+		lastEmit = time.Now()
+		return emit(ev)
+	}
+
+	for !plan.Done() {
+		node := plan.Steps[0].Node
+		stat := &nodes[nodeIdx[node.Addr]]
+
+		keys, err := plan.Page(ctx, match, opts.keyType, scanCount)
+		if err != nil {
+			return err
+		}
+		scanned += uint64(len(keys))
+		stat.Scanned += uint64(len(keys))
+		if len(plan.Steps) == 0 || plan.Steps[0].Node.Addr != node.Addr {
+			stat.Done = true
+		}
+
+		type pending struct {
+			agg  *treeAgg
+			typ  *redis.StatusCmd
+			ttl  *redis.DurationCmd
+			size *redis.IntCmd
+		}
+		var batch []pending
+		pipe := node.Rdb.Pipeline()
+		for _, k := range keys {
+			if !strings.HasPrefix(k, opts.prefix) || !filter.Match(k) {
+				continue
+			}
+			matched++
+			stat.Matched++
+
+			rest := k[len(opts.prefix):]
+			i := strings.Index(rest, sep)
+			if i < 0 {
+				leafCount++
+				if leafCount <= leafLimit {
+					leaves = append(leaves, k)
+				}
+				continue
+			}
+			name := rest[:i]
+			a, ok := aggs[name]
+			if !ok {
+				a = &treeAgg{
+					node:  types.KeyTreeNode{Prefix: opts.prefix + name + sep, Name: name},
+					types: make(map[string]int64),
+				}
+				aggs[name] = a
+			}
+			p := pending{agg: a, ttl: pipe.PTTL(ctx, k)}
+			if opts.keyType == "" {
+				p.typ = pipe.Type(ctx, k)
+			}
+			if samples > 0 && a.sampling < samples {
+				a.sampling++
+				p.size = pipe.MemoryUsage(ctx, k)
+			}
+			batch = append(batch, p)
+		}
+		if len(batch) > 0 {
+			// Per-command errors are read below; a key gone since SCAN
+			// reports type "none".
+			_, _ = pipe.Exec(ctx)
+		}
+		for _, p := range batch {
+			typ := opts.keyType
+			if p.typ != nil {
+				typ = p.typ.Val()
+			}
+			if typ == "none" || typ == "" {
+				continue
+			}
+			a := p.agg
+			a.node.Keys++
+			a.types[typ]++
+			if p.ttl.Err() == nil && p.ttl.Val() == -1 {
+				a.node.NoTtl++
+			}
+			if p.size != nil && p.size.Err() == nil {
+				a.memSum += p.size.Val()
+				a.node.MemorySampled++
+			}
+		}
+
+		if plan.Done() {
+			break
+		}
+		if time.Now().After(deadline) {
+			truncated = true
+			break
+		}
+		if time.Since(lastEmit) >= searchProgressInterval {
+			if err := send(false); err != nil {
+				return err
+			}
+		}
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+	}
+	return send(true)
+}
@@ -0,0 +1,142 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+
+	"github.com/tradalab/rdms/internal/types"
+	"github.com/tradalab/rdms/pkg/keyfilter"
+)
+
+func collectTree(t *testing.T, rdb redis.UniversalClient, clauses []keyfilter.Clause, opts treeOptions) ([]string, *types.ClientKeysTreeEvent) {
+	t.Helper()
+
+	filter, err := keyfilter.Compile(clauses, false)
+	if err != nil {
+		t.Fatalf("Compile: %v", err)
+	}
+
+	var (
+		leaves []string
+		last   *types.ClientKeysTreeEvent
+	)
+	err = scanTree(context.Background(), rdb, filter, opts, func(ev *types.ClientKeysTreeEvent) error {
+		leaves = append(leaves, ev.Leaves...)
+		cp := *ev
+		last = &cp
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("scanTree: %v", err)
+	}
+	if last == nil || !last.Done {
+		t.Fatal("the stream must end with a done event")
+	}
+	return leaves, last
+}
+
+func treeNode(ev *types.ClientKeysTreeEvent, name string) *types.KeyTreeNode {
+	for i := range ev.Nodes {
+		if ev.Nodes[i].Name == name {
+			return &ev.Nodes[i]
+		}
+	}
+	return nil
+}
+
+func TestScanTreeAggregatesNamespaces(t *testing.T) {
+	mr, rdb := newRedis(t)
+
+	for i := range 200 {
+		mr.Set(fmt.Sprintf("user:%d", i), "v")
+	}
+	for i := range 50 {
+		mr.HSet(fmt.Sprintf("user:%d:profile", i), "f", "v")
+	}
+	for i := range 30 {
+		mr.Set(fmt.Sprintf("session:%d", i), "v")
+		mr.SetTTL(fmt.Sprintf("session:%d", i), time.Hour)
+	}
+	mr.Set("config", "v")
+
+	leaves, last := collectTree(t, rdb, nil, treeOptions{scanCount: 20})
+
+	assertSameKeys(t, leaves, []string{"config"})
+	if len(last.Nodes) != 2 || last.Nodes[0].Name != "session" || last.Nodes[1].Name != "user" {
+		t.Fatalf("nodes = %+v, want session and user in order", last.Nodes)
+	}
+
+	user := treeNode(last, "user")
+	if user.Prefix != "user:" || user.Keys != 250 || user.NoTtl != 250 {
+		t.Errorf("user = %+v, want 250 keys without TTL", user)
+	}
+	if len(user.Types) != 2 || user.Types[0] != (types.KeyTreeTypeCount{Type: "string", Count: 200}) {
+		t.Errorf("user types = %+v", user.Types)
+	}
+
+	session := treeNode(last, "session")
+	if session.Keys != 30 || session.NoTtl != 0 {
+		t.Errorf("session = %+v, want 30 keys all with TTL", session)
+	}
+	if last.Matched != 281 || last.Truncated {
+		t.Errorf("matched = %d truncated = %v", last.Matched, last.Truncated)
+	}
+}
+
+func TestScanTreeExpandsPrefix(t *testing.T) {
+	mr, rdb := newRedis(t)
+
+	mr.Set("user:1", "v")
+	mr.Set("user:2", "v")
+	mr.Set("user:1:tmp", "v")
+	mr.Set("user:1:profile", "v")
+	mr.Set("user:2:profile", "v")
+	mr.Set("user*:x", "v")
+	mr.Set("order:1", "v")
+
+	leaves, last := collectTree(t, rdb, nil, treeOptions{prefix: "user:"})
+	assertSameKeys(t, leaves, []string{"user:1", "user:2"})
+	if n := treeNode(last, "1"); n == nil || n.Prefix != "user:1:" || n.Keys != 2 {
+		t.Errorf("user:1: = %+v", n)
+	}
+
+	t.Run("filter", func(t *testing.T) {
+		_, last := collectTree(t, rdb, []keyfilter.Clause{{Pattern: "*:tmp", Exclude: true}}, treeOptions{prefix: "user:"})
+		if n := treeNode(last, "1"); n == nil || n.Keys != 1 {
+			t.Errorf("user:1: with exclusion = %+v", n)
+		}
+	})
+
+	t.Run("separator", func(t *testing.T) {
+		leaves, last := collectTree(t, rdb, nil, treeOptions{prefix: "user:1", separator: ":p"})
+		if n := treeNode(last, ""); n == nil || n.Prefix != "user:1:p" || n.Keys != 1 {
+			t.Errorf("nodes = %+v", last.Nodes)
+		}
+		assertSameKeys(t, leaves, []string{"user:1", "user:1:tmp"})
+	})
+
+	t.Run("leaf limit", func(t *testing.T) {
+		leaves, last := collectTree(t, rdb, nil, treeOptions{leafLimit: 1, prefix: "user:"})
+		if len(leaves) != 1 || last.LeafCount != 2 {
+			t.Errorf("leaves = %v, count = %d", leaves, last.LeafCount)
+		}
+	})
+}
+
+func TestTreeMatch(t *testing.T) {
+	cases := []struct{ prefix, pushdown, want string }{
+		{"", "*", "*"},
+		{"user:", "*", "user:*"},
+		{"user:", "user:1*", "user:1*"},
+		{"a*b:", "*", `a\*b:*`},
+	}
+	for _, c := range cases {
+		if got := treeMatch(c.prefix, c.pushdown); got != c.want {
+			t.Errorf("treeMatch(%q, %q) = %q, want %q", c.prefix, c.pushdown, got, c.want)
+		}
+	}
+}
@@ -0,0 +1,116 @@
+// Code generated by scorix.
+package client
+
+import (
+	"context"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/tradalab/scorix/app"
+
+	"github.com/tradalab/rdms/internal/svc"
+	"github.com/tradalab/rdms/internal/types"
+	"github.com/tradalab/rdms/pkg/keyfilter"
+)
+
+type KeysTtlLogic struct {
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+func NewKeysTtlLogic(ctx context.Context, svcCtx *svc.ServiceContext) *KeysTtlLogic {
+	return &KeysTtlLogic{
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+func (l *KeysTtlLogic) KeysTtl(req *types.ClientKeysTtlReq, out app.Sink[types.ClientKeysTtlEvent]) error {
+	clauses := make([]keyfilter.Clause, 0, len(req.Filters))
+	for _, f := range req.Filters {
+		clauses = append(clauses, keyfilter.Clause{
+			Pattern:    f.Pattern,
+			Mode:       f.Mode,
+			Exclude:    f.Exclude,
+			IgnoreCase: f.IgnoreCase,
+		})
+	}
+
+	filter, err := keyfilter.Compile(clauses, req.MatchAll)
+	if err != nil {
+		return err
+	}
+
+	cli, err := l.svcCtx.RedisManager.Get(req.ConnectionId, int(req.DatabaseIndex))
+	if err != nil {
+		return err
+	}
+	defer cli.BeginStream()()
+
+	opts := ttlOptions{
+		keyType:     req.KeyType,
+		separator:   req.Separator,
+		prefixDepth: int(req.PrefixDepth),
+		samples:     int(req.Samples),
+		leakRatio:   req.LeakRatio,
+		leakMinKeys: req.LeakMinKeys,
+		scanCount:   req.ScanCount,
+		rate:        req.Rate,
+		budget:      time.Duration(req.BudgetMs) * time.Millisecond,
+	}
+
+	return ttlKeys(out.Context(), cli.Reader(), filter, opts, out.Send)
+}
+
+type ttlOptions struct {
+	keyType     string
+	separator   string
+	prefixDepth int
+	samples     int
+	leakRatio   float64
+	leakMinKeys int64
+	scanCount   int64
+	rate        int64
+	budget      time.Duration
+}
+
+// ttlKeys reads the TTL and memory of the keys scanFiltered yields over the
+// whole keyspace through keysMetadata.
+func ttlKeys(
+	ctx context.Context,
+	rdb redis.UniversalClient,
+	filter *keyfilter.Set,
+	opts ttlOptions,
+	emit func(*types.ClientKeysTtlEvent) error,
+) error {
+	r := svc.NewTtlReport(opts.separator, opts.prefixDepth, opts.samples, opts.leakRatio, opts.leakMinKeys)
+	start := time.Now()
+
+	return pacedScan(ctx, rdb, filter, searchOptions{
+		match:     filter.Pushdown(),
+		keyType:   opts.keyType,
+		scanCount: opts.scanCount,
+		budget:    opts.budget,
+	}, opts.rate, func(keys []string) error {
+		for _, m := range keysMetadata(ctx, rdb, keys) {
+			if m.Type == "" || m.Type == "none" {
+				continue // gone since SCAN, or the pipeline failed
+			}
+			ttl := int64(-1)
+			if m.Ttl >= 0 {
+				ttl = time.Duration(m.Ttl).Milliseconds()
+			}
+			r.Add(m.Key, ttl, m.Size)
+		}
+		return ctx.Err()
+	}, func(ev *types.ClientKeysSearchEvent, done bool) error {
+		rep := r.Snapshot()
+		rep.Scanned = ev.Scanned
+		rep.Matched = ev.Matched
+		rep.NodeStats = ev.Nodes
+		rep.ElapsedMs = time.Since(start).Milliseconds()
+		rep.Done = done
+		rep.Truncated = done && ev.Truncated
+		return emit(rep)
+	})
+}
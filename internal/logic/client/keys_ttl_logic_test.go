@@ -0,0 +1,57 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/tradalab/rdms/internal/types"
+	"github.com/tradalab/rdms/pkg/keyfilter"
+)
+
+func TestTtlKeysFindsPrefixesWithoutExpiry(t *testing.T) {
+	mr, rdb := newRedis(t)
+
+	for i := range 20 {
+		mr.Set(fmt.Sprintf("session:%d", i), "v")
+		mr.SetTTL(fmt.Sprintf("session:%d", i), 30*time.Minute)
+		mr.Set(fmt.Sprintf("cache:%d", i), "v")
+		if i < 2 {
+			mr.SetTTL(fmt.Sprintf("cache:%d", i), 2*time.Hour)
+		}
+	}
+	for i := range 3 {
+		mr.Set(fmt.Sprintf("config:%d", i), "v") // too few to flag
+	}
+	mr.Set("other", "v")
+
+	filter, _ := keyfilter.Compile([]keyfilter.Clause{{Pattern: "other", Exclude: true}}, false)
+	var last *types.ClientKeysTtlEvent
+	err := ttlKeys(context.Background(), rdb, filter, ttlOptions{samples: 2, scanCount: 10, rate: -1}, func(ev *types.ClientKeysTtlEvent) error {
+		last = ev
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("ttlKeys: %v", err)
+	}
+	if !last.Done || last.Keys != 43 || last.NoExpiry != 21 {
+		t.Fatalf("done = %v keys = %d no expiry = %d", last.Done, last.Keys, last.NoExpiry)
+	}
+
+	// Buckets: none, <1m, <1h, <1d, ...
+	if h := last.TtlHist; h[0].Count != 21 || h[2].Count != 20 || h[3].Count != 2 {
+		t.Errorf("ttl histogram = %+v", h)
+	}
+
+	if len(last.Leaks) != 1 {
+		t.Fatalf("leaks = %+v, want only cache:", last.Leaks)
+	}
+	leak := last.Leaks[0]
+	if leak.Prefix != "cache:" || leak.Keys != 20 || leak.NoExpiry != 18 || len(leak.Samples) != 2 || leak.NoExpiryMemory <= 0 {
+		t.Errorf("leak = %+v", leak)
+	}
+	if len(last.Prefixes) != 3 || last.Prefixes[0].TtlHist[2].Count+last.Prefixes[1].TtlHist[2].Count != 20 {
+		t.Errorf("prefixes = %+v", last.Prefixes)
+	}
+}
@@ -0,0 +1,42 @@
+// Code generated by scorix.
+package client
+
+import (
+	"context"
+	"time"
+
+	"github.com/tradalab/rdms/internal/svc"
+	"github.com/tradalab/rdms/internal/types"
+)
+
+type ListActiveLogic struct {
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+func NewListActiveLogic(ctx context.Context, svcCtx *svc.ServiceContext) *ListActiveLogic {
+	return &ListActiveLogic{
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+func (l *ListActiveLogic) ListActive(params *types.Empty) (*types.ClientListActiveRes, error) {
+	now := time.Now()
+	var items []types.ActiveClient
+	for _, c := range l.svcCtx.RedisManager.List() {
+		monitor, pubsub := c.StreamState()
+		items = append(items, types.ActiveClient{
+			ConnectionId:   c.Cfg.ID,
+			ConnectionName: c.Cfg.Name,
+			DatabaseIndex:  int32(c.DbIdx),
+			LastUsed:       c.LastUsed().UnixMilli(),
+			IdleSeconds:    int64(now.Sub(c.LastUsed()).Seconds()),
+			MonitorActive:  monitor,
+			PubsubActive:   pubsub,
+			ReadOnly:       c.ReadOnly.Load(),
+			SshHops:        int32(len(c.Ssh)),
+		})
+	}
+	return &types.ClientListActiveRes{Items: items}, nil
+}
@@ -3,7 +3,6 @@ package client
 
 import (
 	"context"
-	"strconv"
 	"strings"
 
 	"github.com/tradalab/rdms/internal/svc"
@@ -33,14 +32,17 @@ func (l *LoadAllKeysLogic) LoadAllKeys(params *types.ClientLoadAllKeysReq) (*typ
 		pattern += "*"
 	}
 
-	cursor, _ := strconv.ParseUint(params.Cursor, 10, 64)
-	keys, nextCursor, err := cli.Rdb.Scan(l.ctx, cursor, pattern, params.Count).Result()
+	plan, err := svc.PlanScan(l.ctx, cli.Reader(), svc.ScanReadNodes, params.Cursor)
+	if err != nil {
+		return nil, err
+	}
+	keys, err := plan.Page(l.ctx, pattern, "", params.Count)
 	if err != nil {
 		return nil, err
 	}
 
 	return &types.ClientLoadAllKeysRes{
 		Keys:   keys,
-		Cursor: strconv.FormatUint(nextCursor, 10),
+		Cursor: plan.Cursor(),
 	}, nil
 }
@@ -8,7 +8,6 @@ import (
 
 	"github.com/tradalab/rdms/internal/svc"
 	"github.com/tradalab/rdms/internal/types"
-	"github.com/tradalab/rdms/pkg/util"
 )
 
 type LoadKeyDetailLogic struct {
@@ -33,8 +32,9 @@ func (l *LoadKeyDetailLogic) LoadKeyDetail(params *types.ClientLoadKeyDetailReq)
 	var kind string
 	var total int64
 	var key = params.Key
+	rdb := cli.Reader()
 
-	kind, err = cli.Rdb.Type(l.ctx, key).Result()
+	kind, err = rdb.Type(l.ctx, key).Result()
 	if err != nil {
 		return nil, err
 	}
@@ -42,7 +42,7 @@ func (l *LoadKeyDetailLogic) LoadKeyDetail(params *types.ClientLoadKeyDetailReq)
 		return nil, fmt.Errorf("key not exists")
 	}
 
-	ttl, err := cli.Rdb.TTL(l.ctx, key).Result()
+	ttl, err := rdb.TTL(l.ctx, key).Result()
 	if err != nil {
 		return nil, err
 	}
@@ -57,21 +57,19 @@ func (l *LoadKeyDetailLogic) LoadKeyDetail(params *types.ClientLoadKeyDetailReq)
 
 	switch strings.ToLower(kind) {
 	case "string":
-		var str string
-		str, err = cli.Rdb.Get(l.ctx, key).Result()
-		valueStr = util.EncodeRedisKey(str).(string)
+		valueStr, err = rdb.Get(l.ctx, key).Result()
 	case "list":
-		total, err = cli.Rdb.LLen(l.ctx, key).Result()
+		total, err = rdb.LLen(l.ctx, key).Result()
 	case "hash":
-		total, err = cli.Rdb.HLen(l.ctx, key).Result()
+		total, err = rdb.HLen(l.ctx, key).Result()
 	case "set":
-		total, err = cli.Rdb.SCard(l.ctx, key).Result()
+		total, err = rdb.SCard(l.ctx, key).Result()
 	case "zset":
-		total, err = cli.Rdb.ZCard(l.ctx, key).Result()
+		total, err = rdb.ZCard(l.ctx, key).Result()
 	case "stream":
-		total, err = cli.Rdb.XLen(l.ctx, key).Result()
+		total, err = rdb.XLen(l.ctx, key).Result()
 	case "rejson-rl":
-		val, _ := cli.Rdb.JSONGet(l.ctx, key).Result()
+		val, _ := rdb.JSONGet(l.ctx, key).Result()
 		valueStr = val
 	case "json", "rejson":
 		// handled as string by the client
@@ -81,8 +79,8 @@ func (l *LoadKeyDetailLogic) LoadKeyDetail(params *types.ClientLoadKeyDetailReq)
 		return nil, err
 	}
 
-	encoding, _ := cli.Rdb.ObjectEncoding(l.ctx, key).Result()
-	size, _ := cli.Rdb.MemoryUsage(l.ctx, key).Result()
+	encoding, _ := rdb.ObjectEncoding(l.ctx, key).Result()
+	size, _ := rdb.MemoryUsage(l.ctx, key).Result()
 
 	return &types.ClientLoadKeyDetailRes{
 		Key:      key,
@@ -52,6 +52,7 @@ func (l *LoadKeyValuePageLogic) LoadKeyValuePage(params *types.ClientLoadKeyValu
 	if err != nil {
 		return nil, err
 	}
+	rdb := cli.Reader()
 
 	pageSize := params.PageSize
 	if pageSize <= 0 {
@@ -70,7 +71,7 @@ func (l *LoadKeyValuePageLogic) LoadKeyValuePage(params *types.ClientLoadKeyValu
 	switch strings.ToLower(params.Kind) {
 	case "hash":
 		cursorInt, _ := strconv.ParseUint(cursor, 10, 64)
-		keys, nextCursor, err := cli.Rdb.HScan(l.ctx, params.Key, cursorInt, "*", pageSize).Result()
+		keys, nextCursor, err := rdb.HScan(l.ctx, params.Key, cursorInt, "*", pageSize).Result()
 		if err != nil {
 			return nil, err
 		}
@@ -82,7 +83,7 @@ func (l *LoadKeyValuePageLogic) LoadKeyValuePage(params *types.ClientLoadKeyValu
 
 	case "set":
 		cursorInt, _ := strconv.ParseUint(cursor, 10, 64)
-		members, nextCursor, err := cli.Rdb.SScan(l.ctx, params.Key, cursorInt, "*", pageSize).Result()
+		members, nextCursor, err := rdb.SScan(l.ctx, params.Key, cursorInt, "*", pageSize).Result()
 		if err != nil {
 			return nil, err
 		}
@@ -94,7 +95,7 @@ func (l *LoadKeyValuePageLogic) LoadKeyValuePage(params *types.ClientLoadKeyValu
 
 	case "zset":
 		cursorInt, _ := strconv.ParseUint(cursor, 10, 64)
-		vals, nextCursor, err := cli.Rdb.ZScan(l.ctx, params.Key, cursorInt, "*", pageSize).Result()
+		vals, nextCursor, err := rdb.ZScan(l.ctx, params.Key, cursorInt, "*", pageSize).Result()
 		if err != nil {
 			return nil, err
 		}
@@ -107,11 +108,11 @@ func (l *LoadKeyValuePageLogic) LoadKeyValuePage(params *types.ClientLoadKeyValu
 
 	case "list":
 		offset, _ := strconv.ParseInt(cursor, 10, 64)
-		totalLen, err := cli.Rdb.LLen(l.ctx, params.Key).Result()
+		totalLen, err := rdb.LLen(l.ctx, params.Key).Result()
 		if err != nil {
 			return nil, err
 		}
-		vals, err := cli.Rdb.LRange(l.ctx, params.Key, offset, offset+pageSize-1).Result()
+		vals, err := rdb.LRange(l.ctx, params.Key, offset, offset+pageSize-1).Result()
 		if err != nil {
 			return nil, err
 		}
@@ -132,7 +133,7 @@ func (l *LoadKeyValuePageLogic) LoadKeyValuePage(params *types.ClientLoadKeyValu
 		} else {
 			start = incrementStreamID(start)
 		}
-		msgs, err := cli.Rdb.XRangeN(l.ctx, params.Key, start, "+", pageSize).Result()
+		msgs, err := rdb.XRangeN(l.ctx, params.Key, start, "+", pageSize).Result()
 		if err != nil {
 			return nil, err
 		}
@@ -33,7 +33,7 @@ func (l *SearchKeysLogic) SearchKeys(params *types.ClientSearchKeysReq) (*types.
 
 	match := params.Prefix + "*"
 
-	keys, _, err := cli.Rdb.Scan(l.ctx, 0, match, count).Result()
+	keys, _, err := cli.Reader().Scan(l.ctx, 0, match, count).Result()
 	if err != nil {
 		return nil, err
 	}
@@ -0,0 +1,93 @@
+// Code generated by scorix.
+package cluster
+
+import (
+	"context"
+
+	"github.com/tradalab/rdms/internal/svc"
+	"github.com/tradalab/rdms/internal/types"
+)
+
+type TopologyLogic struct {
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+func NewTopologyLogic(ctx context.Context, svcCtx *svc.ServiceContext) *TopologyLogic {
+	return &TopologyLogic{
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+func (l *TopologyLogic) Topology(params *types.ClusterReq) (*types.ClusterTopologyRes, error) {
+	c, err := l.svcCtx.RedisManager.Get(params.ConnectionId, int(params.DatabaseIndex))
+	if err != nil {
+		return nil, err
+	}
+	topo, err := c.ClusterTopology(l.ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	res := &types.ClusterTopologyRes{Source: topo.Source}
+	for _, s := range topo.Shards {
+		shard := types.ClusterShard{Slots: slotRanges(s.Slots), SlotCount: int32(s.SlotCount())}
+		if s.Master != nil {
+			shard.Master = node(s.Master)
+			shard.Keys = shard.Master.Stats.Keys
+			shard.UsedMemory = shard.Master.Stats.UsedMemory
+		}
+		for _, r := range s.Replicas {
+			shard.Replicas = append(shard.Replicas, node(r))
+		}
+		res.Shards = append(res.Shards, shard)
+	}
+	return res, nil
+}
+
+func node(n *svc.ClusterNode) types.ClusterNode {
+	out := types.ClusterNode{
+		Id:          n.ID,
+		Addr:        n.Addr,
+		DialAddr:    n.DialAddr,
+		Hostname:    n.Hostname,
+		Role:        "replica",
+		MasterId:    n.MasterID,
+		Flags:       n.Flags,
+		LinkState:   n.LinkState,
+		Health:      n.Health,
+		ConfigEpoch: n.ConfigEpoch,
+		ReplOffset:  n.ReplOffset,
+		Slots:       slotRanges(n.Slots),
+	}
+	if n.Master {
+		out.Role = "master"
+	}
+	if st := n.Stats; st != nil {
+		out.Stats = types.ClusterNodeStats{
+			UsedMemory:     st.UsedMemory,
+			MaxMemory:      st.MaxMemory,
+			FragRatio:      st.FragRatio,
+			OpsPerSec:      st.OpsPerSec,
+			KeyspaceHits:   st.KeyspaceHits,
+			KeyspaceMisses: st.KeyspaceMisses,
+			EvictedKeys:    st.EvictedKeys,
+			ExpiredKeys:    st.ExpiredKeys,
+			Keys:           st.Keys,
+			Expires:        st.Expires,
+		}
+	}
+	if n.Err != nil {
+		out.Error = n.Err.Error()
+	}
+	return out
+}
+
+func slotRanges(slots [][2]int) []types.ClusterSlotRange {
+	out := make([]types.ClusterSlotRange, 0, len(slots))
+	for _, r := range slots {
+		out = append(out, types.ClusterSlotRange{Start: int32(r[0]), End: int32(r[1])})
+	}
+	return out
+}
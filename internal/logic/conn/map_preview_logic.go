@@ -0,0 +1,52 @@
+// Code generated by scorix.
+package conn
+
+import (
+	"context"
+	"strings"
+
+	"github.com/tradalab/rdms/internal/svc"
+	"github.com/tradalab/rdms/internal/types"
+)
+
+type MapPreviewLogic struct {
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+func NewMapPreviewLogic(ctx context.Context, svcCtx *svc.ServiceContext) *MapPreviewLogic {
+	return &MapPreviewLogic{
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+func (l *MapPreviewLogic) MapPreview(params *types.ConnectionReq) (*types.AddrMapPreviewRes, error) {
+	conn, sshCfgs, proxyCfg, tlsCfg, err := resolveConnection(l.ctx, l.svcCtx, params)
+	if err != nil {
+		return nil, err
+	}
+
+	res := &types.AddrMapPreviewRes{}
+	if _, err := svc.ParseAddrMapping(conn.AddrMapping); err != nil {
+		res.Errors = strings.Split(err.Error(), "\n")
+	}
+
+	rows, err := l.svcCtx.RedisManager.PreviewAddrMapping(conn, sshCfgs, proxyCfg, tlsCfg)
+	if err != nil {
+		return nil, err
+	}
+	for _, r := range rows {
+		row := types.AddrMapRow{
+			Addr:     r.Addr,
+			DialAddr: r.DialAddr,
+			Rule:     r.Rule,
+			Master:   r.Master,
+		}
+		for _, s := range r.Slots {
+			row.Slots = append(row.Slots, types.ClusterSlotRange{Start: int32(s[0]), End: int32(s[1])})
+		}
+		res.Rows = append(res.Rows, row)
+	}
+	return res, nil
+}
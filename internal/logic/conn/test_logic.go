@@ -3,6 +3,7 @@ package conn
 
 import (
 	"context"
+	"strings"
 
 	"github.com/tradalab/rdms/internal/model"
 	"github.com/tradalab/rdms/internal/svc"
@@ -21,7 +22,49 @@ func NewTestLogic(ctx context.Context, svcCtx *svc.ServiceContext) *TestLogic {
 	}
 }
 
-func (l *TestLogic) Test(params *types.ConnectionReq) (*types.Empty, error) {
+func (l *TestLogic) Test(params *types.ConnectionReq) (*types.ConnTestRes, error) {
+	conn, sshCfgs, proxyCfg, tlsCfg, err := resolveConnection(l.ctx, l.svcCtx, params)
+	if err != nil {
+		return nil, err
+	}
+
+	d := l.svcCtx.RedisManager.Diagnose(conn, sshCfgs, proxyCfg, tlsCfg)
+	res := &types.ConnTestRes{
+		Ok:            d.OK(),
+		ServerVersion: d.ServerVersion,
+		ServerMode:    d.ServerMode,
+	}
+	for _, s := range d.Stages {
+		st := types.ConnTestStage{
+			Name:      s.Name,
+			Ok:        s.Err == nil,
+			Detail:    s.Detail,
+			LatencyMs: s.Latency.Milliseconds(),
+		}
+		if s.Err != nil {
+			st.Error = s.Err.Error()
+		}
+		res.Stages = append(res.Stages, st)
+	}
+	for _, n := range d.Nodes {
+		node := types.ConnTestNode{
+			Addr:      n.Addr,
+			DialAddr:  n.DialAddr,
+			Role:      n.Role,
+			Ok:        n.Err == nil,
+			LatencyMs: n.Latency.Milliseconds(),
+		}
+		if n.Err != nil {
+			node.Error = n.Err.Error()
+		}
+		res.Nodes = append(res.Nodes, node)
+	}
+	return res, nil
+}
+
+// resolveConnection turns an unsaved connection form into the connection and
+// profiles it uses, taking blank secrets from the stored connection.
+func resolveConnection(ctx context.Context, svcCtx *svc.ServiceContext, params *types.ConnectionReq) (*model.Connection, []*model.Ssh, *model.Proxy, *model.Tls, error) {
 	conn := model.Connection{
 		Mode:             params.Mode,
 		Name:             params.Name,
@@ -40,12 +83,18 @@ func (l *TestLogic) Test(params *types.ConnectionReq) (*types.Empty, error) {
 		DialTimeout:      params.DialTimeout,
 		KeySize:          params.KeySize,
 		SshEnable:        bToI(params.SshEnable),
+		SshID:            params.SshId,
+		SshJumpIds:       strings.Join(params.SshJumpIds, ","),
 		ProxyEnable:      bToI(params.ProxyEnable),
 		TlsEnable:        bToI(params.TlsEnable),
+		ReadFrom:         params.ReadFrom,
+		CredProvider:     params.CredProvider,
+		CredSource:       params.CredSource,
+		CredTtl:          params.CredTtl,
 	}
 
 	if params.Id != "" && (params.Password == "" || params.SentinelPassword == "") {
-		if existing, err := l.svcCtx.ConnectionModel.FindOne(l.ctx, params.Id); err == nil {
+		if existing, err := svcCtx.ConnectionModel.FindOne(ctx, params.Id); err == nil {
 			if params.Password == "" {
 				conn.Password = existing.Password
 			}
@@ -55,38 +104,34 @@ func (l *TestLogic) Test(params *types.ConnectionReq) (*types.Empty, error) {
 		}
 	}
 
-	var sshCfg *model.Ssh
+	var sshCfgs []*model.Ssh
 	if params.SshEnable && params.SshId != "" {
-		s, err := l.svcCtx.SshModel.FindOne(l.ctx, params.SshId)
+		s, err := svcCtx.SshModel.FindChain(ctx, conn.SshHops())
 		if err != nil {
-			return nil, err
+			return nil, nil, nil, nil, err
 		}
-		sshCfg = s
+		sshCfgs = s
 	}
 
 	var proxyCfg *model.Proxy
 	if params.ProxyEnable && params.ProxyId != "" {
-		p, err := l.svcCtx.ProxyModel.FindOne(l.ctx, params.ProxyId)
+		p, err := svcCtx.ProxyModel.FindOne(ctx, params.ProxyId)
 		if err != nil {
-			return nil, err
+			return nil, nil, nil, nil, err
 		}
 		proxyCfg = p
 	}
 
 	var tlsCfg *model.Tls
 	if params.TlsEnable && params.TlsId != "" {
-		tc, err := l.svcCtx.TlsModel.FindOne(l.ctx, params.TlsId)
+		tc, err := svcCtx.TlsModel.FindOne(ctx, params.TlsId)
 		if err != nil {
-			return nil, err
+			return nil, nil, nil, nil, err
 		}
 		tlsCfg = tc
 	}
 
-	if err := l.svcCtx.RedisManager.Test(&conn, sshCfg, proxyCfg, tlsCfg, 0); err != nil {
-		return nil, err
-	}
-
-	return &types.Empty{}, nil
+	return &conn, sshCfgs, proxyCfg, tlsCfg, nil
 }
 
 func bToI(b bool) int64 {
@@ -0,0 +1,33 @@
+// Code generated by scorix.
+package connection
+
+import (
+	"context"
+
+	"github.com/tradalab/rdms/internal/svc"
+	"github.com/tradalab/rdms/internal/types"
+)
+
+type ExportLogic struct {
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+func NewExportLogic(ctx context.Context, svcCtx *svc.ServiceContext) *ExportLogic {
+	return &ExportLogic{
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+func (l *ExportLogic) Export(params *types.ConnectionExportReq) (*types.ConnectionExportRes, error) {
+	b, err := l.svcCtx.ExportBundle(l.ctx, params.Ids)
+	if err != nil {
+		return nil, err
+	}
+	data, err := svc.SealBundle(b, params.Passphrase)
+	if err != nil {
+		return nil, err
+	}
+	return &types.ConnectionExportRes{Data: data, Connections: int32(len(b.Connections))}, nil
+}
@@ -0,0 +1,38 @@
+// Code generated by scorix.
+package connection
+
+import (
+	"context"
+
+	"github.com/tradalab/rdms/internal/model"
+	"github.com/tradalab/rdms/internal/svc"
+	"github.com/tradalab/rdms/internal/types"
+)
+
+type ExportUrlLogic struct {
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+func NewExportUrlLogic(ctx context.Context, svcCtx *svc.ServiceContext) *ExportUrlLogic {
+	return &ExportUrlLogic{
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+func (l *ExportUrlLogic) ExportUrl(params *types.ConnectionExportUrlReq) (*types.ConnectionExportUrlRes, error) {
+	c, err := l.svcCtx.ConnectionModel.FindOne(l.ctx, params.Id)
+	if err != nil {
+		return nil, err
+	}
+
+	var t *model.Tls
+	if c.TlsEnable > 0 && c.TlsID != "" {
+		if t, err = l.svcCtx.TlsModel.FindOne(l.ctx, c.TlsID); err != nil {
+			return nil, err
+		}
+	}
+
+	return &types.ConnectionExportUrlRes{Url: c.URL(t, params.IncludeSecrets)}, nil
+}
@@ -0,0 +1,33 @@
+// Code generated by scorix.
+package connection
+
+import (
+	"context"
+
+	"github.com/tradalab/rdms/internal/svc"
+	"github.com/tradalab/rdms/internal/types"
+)
+
+type ImportExternalLogic struct {
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+func NewImportExternalLogic(ctx context.Context, svcCtx *svc.ServiceContext) *ImportExternalLogic {
+	return &ImportExternalLogic{
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+func (l *ImportExternalLogic) ImportExternal(params *types.ConnectionImportExternalReq) (*types.ConnectionImportExternalRes, error) {
+	source, entries, err := l.svcCtx.PlanImport(l.ctx, params.Source, params.Data)
+	if err != nil {
+		return nil, err
+	}
+	created, err := l.svcCtx.ApplyImport(l.ctx, entries)
+	if err != nil {
+		return nil, err
+	}
+	return importRes(source, entries, created), nil
+}
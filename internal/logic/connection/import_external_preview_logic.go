@@ -0,0 +1,50 @@
+// Code generated by scorix.
+package connection
+
+import (
+	"context"
+
+	"github.com/tradalab/rdms/internal/svc"
+	"github.com/tradalab/rdms/internal/types"
+)
+
+type ImportExternalPreviewLogic struct {
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+func NewImportExternalPreviewLogic(ctx context.Context, svcCtx *svc.ServiceContext) *ImportExternalPreviewLogic {
+	return &ImportExternalPreviewLogic{
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+func (l *ImportExternalPreviewLogic) ImportExternalPreview(params *types.ConnectionImportExternalReq) (*types.ConnectionImportExternalRes, error) {
+	source, entries, err := l.svcCtx.PlanImport(l.ctx, params.Source, params.Data)
+	if err != nil {
+		return nil, err
+	}
+	return importRes(source, entries, 0), nil
+}
+
+func importRes(source string, entries []*svc.ImportEntry, created int) *types.ConnectionImportExternalRes {
+	res := &types.ConnectionImportExternalRes{Source: source, Created: int32(created)}
+	for _, e := range entries {
+		if e.DuplicateOf != "" {
+			res.Skipped++
+		}
+		res.Items = append(res.Items, types.ConnectionImportExternalItem{
+			Name:        e.Conn.Name,
+			Mode:        e.Conn.Mode,
+			Addr:        e.Conn.Addr(),
+			Db:          int32(e.Conn.LastDb),
+			Group:       e.Group,
+			Ssh:         e.Ssh != nil,
+			Tls:         e.Tls != nil,
+			DuplicateOf: e.DuplicateOf,
+			Warnings:    e.Warnings,
+		})
+	}
+	return res
+}
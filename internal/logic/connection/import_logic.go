@@ -0,0 +1,39 @@
+// Code generated by scorix.
+package connection
+
+import (
+	"context"
+
+	"github.com/tradalab/rdms/internal/svc"
+	"github.com/tradalab/rdms/internal/types"
+)
+
+type ImportLogic struct {
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+func NewImportLogic(ctx context.Context, svcCtx *svc.ServiceContext) *ImportLogic {
+	return &ImportLogic{
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+func (l *ImportLogic) Import(params *types.ConnectionImportReq) (*types.ConnectionImportRes, error) {
+	b, err := svc.OpenBundle(params.Data, params.Passphrase)
+	if err != nil {
+		return nil, err
+	}
+	res, err := l.svcCtx.ImportBundle(l.ctx, b)
+	if err != nil {
+		return nil, err
+	}
+	return &types.ConnectionImportRes{
+		Connections: int32(res.Connections),
+		Groups:      int32(res.Groups),
+		Ssh:         int32(res.Ssh),
+		Tls:         int32(res.Tls),
+		Proxies:     int32(res.Proxies),
+	}, nil
+}
@@ -50,11 +50,16 @@ func (l *ListLogic) List(params *types.Empty) (*types.ConnectionListRes, error)
 			GroupId:          c.GroupID,
 			SshEnable:        c.SshEnable > 0,
 			SshId:            c.SshID,
+			SshJumpIds:       c.SshJumps(),
 			ProxyEnable:      c.ProxyEnable > 0,
 			ProxyId:          c.ProxyID,
 			TlsEnable:        c.TlsEnable > 0,
 			TlsId:            c.TlsID,
 			ReadOnly:         c.ReadOnly > 0,
+			ReadFrom:         c.ReadFrom,
+			CredProvider:     c.CredProvider,
+			CredSource:       c.CredSource,
+			CredTtl:          c.CredTtl,
 		})
 	}
 
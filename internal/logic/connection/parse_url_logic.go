@@ -0,0 +1,64 @@
+// Code generated by scorix.
+package connection
+
+import (
+	"context"
+
+	"github.com/tradalab/rdms/internal/model"
+	"github.com/tradalab/rdms/internal/svc"
+	"github.com/tradalab/rdms/internal/types"
+)
+
+type ParseUrlLogic struct {
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+func NewParseUrlLogic(ctx context.Context, svcCtx *svc.ServiceContext) *ParseUrlLogic {
+	return &ParseUrlLogic{
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+func (l *ParseUrlLogic) ParseUrl(params *types.ConnectionParseUrlReq) (*types.ConnectionReq, error) {
+	c, t, err := model.ParseConnectionURL(params.Url)
+	if err != nil {
+		return nil, err
+	}
+	return urlReq(c, t), nil
+}
+
+// urlReq shapes a parsed URL as a connection form. A TLS URL carries its
+// profile in Tls; upsert stores it when no tls_id is picked.
+func urlReq(c *model.Connection, t *model.Tls) *types.ConnectionReq {
+	req := &types.ConnectionReq{
+		Mode:             c.Mode,
+		Name:             c.Name,
+		Network:          c.Network,
+		Host:             c.Host,
+		Port:             int32(c.Port),
+		Addrs:            c.Addrs,
+		SentinelMaster:   c.SentinelMaster,
+		SentinelUsername: c.SentinelUsername,
+		SentinelPassword: c.SentinelPassword,
+		Sock:             c.Sock,
+		Username:         c.Username,
+		Password:         c.Password,
+		LastDb:           int32(c.LastDb),
+		ExecTimeout:      c.ExecTimeout,
+		DialTimeout:      c.DialTimeout,
+		TlsEnable:        c.TlsEnable > 0,
+		ReadOnly:         c.ReadOnly > 0,
+		ReadFrom:         c.ReadFrom,
+	}
+	if t != nil {
+		req.Tls = types.TlsReq{
+			Name:       t.Name,
+			UseSni:     t.UseSni > 0,
+			ServerName: t.ServerName,
+			Verify:     t.Verify > 0,
+		}
+	}
+	return req
+}
@@ -5,6 +5,8 @@ import (
 	"context"
 	"database/sql"
 	"errors"
+	"fmt"
+	"strings"
 
 	"github.com/tradalab/rdms/internal/model"
 	"github.com/tradalab/rdms/internal/svc"
@@ -34,6 +36,48 @@ func (l *UpsertLogic) Upsert(params *types.ConnectionReq) (*types.UpsertRes, err
 		}
 	}
 
+	if params.Url != "" {
+		if c != nil {
+			params.ReadOnly = params.ReadOnly || c.ReadOnly > 0
+			if params.ReadFrom == model.ReadFromMaster {
+				params.ReadFrom = c.ReadFrom
+			}
+		}
+		if err := applyUrl(params); err != nil {
+			return nil, err
+		}
+	}
+	if !model.ValidReadFrom(params.ReadFrom) {
+		return nil, fmt.Errorf("unknown read_from %q", params.ReadFrom)
+	}
+	if !model.ValidCredProvider(params.CredProvider) {
+		return nil, fmt.Errorf("unknown cred_provider %q", params.CredProvider)
+	}
+	if params.CredProvider != model.CredProviderNone && params.CredSource == "" {
+		return nil, fmt.Errorf("cred_provider %s needs a command or file", params.CredProvider)
+	}
+	if _, err := svc.ParseAddrMapping(params.AddrMapping); err != nil {
+		return nil, fmt.Errorf("addr_mapping: %w", err)
+	}
+
+	// A URL carries TLS settings but no profile id; saving it again must not
+	// add a profile each time.
+	if params.TlsEnable && params.TlsId == "" && c != nil && c.TlsID != "" {
+		params.TlsId = c.TlsID
+	}
+	if params.TlsEnable && params.TlsId == "" && params.Tls.Name != "" {
+		t := &model.Tls{
+			Name:       params.Tls.Name,
+			UseSni:     bToI(params.Tls.UseSni),
+			ServerName: params.Tls.ServerName,
+			Verify:     bToI(params.Tls.Verify),
+		}
+		if _, err := l.svcCtx.TlsModel.Insert(l.ctx, t); err != nil {
+			return nil, err
+		}
+		params.TlsId = t.ID
+	}
+
 	isNew := c == nil
 	if isNew {
 		c = &model.Connection{
@@ -59,11 +103,16 @@ func (l *UpsertLogic) Upsert(params *types.ConnectionReq) (*types.UpsertRes, err
 	c.GroupID = params.GroupId
 	c.SshEnable = bToI(params.SshEnable)
 	c.SshID = params.SshId
+	c.SshJumpIds = strings.Join(params.SshJumpIds, ",")
 	c.ProxyEnable = bToI(params.ProxyEnable)
 	c.ProxyID = params.ProxyId
 	c.TlsEnable = bToI(params.TlsEnable)
 	c.TlsID = params.TlsId
 	c.ReadOnly = bToI(params.ReadOnly)
+	c.ReadFrom = params.ReadFrom
+	c.CredProvider = params.CredProvider
+	c.CredSource = params.CredSource
+	c.CredTtl = params.CredTtl
 
 	if isNew || params.Password != "" {
 		c.Password = params.Password
@@ -86,6 +135,43 @@ func (l *UpsertLogic) Upsert(params *types.ConnectionReq) (*types.UpsertRes, err
 	return &types.UpsertRes{Id: c.ID}, nil
 }
 
+// applyUrl overwrites the address, auth and TLS fields of params with those
+// of params.Url. Name, group, SSH, proxy and key settings are kept. The URL
+// may switch read-only on or pick a read_from, but never drops them: that
+// takes the connection's own setting.
+func applyUrl(params *types.ConnectionReq) error {
+	c, t, err := model.ParseConnectionURL(params.Url)
+	if err != nil {
+		return err
+	}
+	parsed := urlReq(c, t)
+	parsed.Id = params.Id
+	parsed.GroupId = params.GroupId
+	parsed.KeySize = params.KeySize
+	parsed.AddrMapping = params.AddrMapping
+	parsed.CredProvider, parsed.CredSource, parsed.CredTtl = params.CredProvider, params.CredSource, params.CredTtl
+	parsed.SshEnable, parsed.SshId, parsed.SshJumpIds = params.SshEnable, params.SshId, params.SshJumpIds
+	parsed.ProxyEnable, parsed.ProxyId = params.ProxyEnable, params.ProxyId
+	if params.Name != "" {
+		parsed.Name = params.Name
+	}
+	if parsed.DialTimeout == 0 {
+		parsed.DialTimeout = params.DialTimeout
+	}
+	if parsed.ExecTimeout == 0 {
+		parsed.ExecTimeout = params.ExecTimeout
+	}
+	parsed.ReadOnly = parsed.ReadOnly || params.ReadOnly
+	if parsed.ReadFrom == model.ReadFromMaster {
+		parsed.ReadFrom = params.ReadFrom
+	}
+	if params.TlsEnable && params.TlsId != "" && parsed.TlsEnable {
+		parsed.TlsId = params.TlsId
+	}
+	*params = *parsed
+	return nil
+}
+
 func bToI(b bool) int64 {
 	if b {
 		return 1
@@ -0,0 +1,34 @@
+package connection
+
+import (
+	"testing"
+
+	"github.com/tradalab/rdms/internal/model"
+	"github.com/tradalab/rdms/internal/types"
+)
+
+func TestApplyUrlKeepsReadOnly(t *testing.T) {
+	params := &types.ConnectionReq{
+		Id:       "c1",
+		Url:      "redis://new-host:6380/2",
+		ReadOnly: true,
+		ReadFrom: model.ReadFromReplica,
+	}
+	if err := applyUrl(params); err != nil {
+		t.Fatal(err)
+	}
+	if params.Host != "new-host" || params.Port != 6380 {
+		t.Errorf("address = %s:%d", params.Host, params.Port)
+	}
+	if !params.ReadOnly || params.ReadFrom != model.ReadFromReplica {
+		t.Errorf("read_only = %v, read_from = %q; a URL without them must keep both", params.ReadOnly, params.ReadFrom)
+	}
+
+	params = &types.ConnectionReq{Url: "redis://h:6379?read_only=true&read_from=latency"}
+	if err := applyUrl(params); err != nil {
+		t.Fatal(err)
+	}
+	if !params.ReadOnly || params.ReadFrom != model.ReadFromLatency {
+		t.Errorf("read_only = %v, read_from = %q; want the URL's", params.ReadOnly, params.ReadFrom)
+	}
+}
@@ -3,12 +3,9 @@ package key
 
 import (
 	"context"
-	"strconv"
 
-	"github.com/redis/go-redis/v9"
 	"github.com/tradalab/rdms/internal/svc"
 	"github.com/tradalab/rdms/internal/types"
-	"github.com/tradalab/rdms/pkg/util"
 )
 
 type LoadLogic struct {
@@ -29,12 +26,7 @@ func (l *LoadLogic) Load(params *types.KeyLoadReq) (*types.KeyLoadRes, error) {
 		return nil, err
 	}
 
-	cursorStr := params.Cursor
-	if cursorStr == "" {
-		cursorStr = "0"
-	}
-
-	num, err := strconv.ParseUint(cursorStr, 10, 64)
+	plan, err := svc.PlanScan(l.ctx, cli.Reader(), svc.ScanReadNodes, params.Cursor)
 	if err != nil {
 		return nil, err
 	}
@@ -44,41 +36,22 @@ func (l *LoadLogic) Load(params *types.KeyLoadReq) (*types.KeyLoadRes, error) {
 		count = 1000
 	}
 
-	keys, nextCursor, err := scanKeys(l.ctx, cli.Rdb, "", "", num, count)
+	keys, err := scanKeys(l.ctx, plan, "", "", count)
 	if err != nil {
 		return nil, err
 	}
 
 	return &types.KeyLoadRes{
 		Keys:   keys,
-		Cursor: strconv.FormatUint(nextCursor, 10),
+		Cursor: plan.Cursor(),
 	}, nil
 }
 
-func scanKeys(ctx context.Context, client redis.UniversalClient, match, keyType string, cursor uint64, count int64) ([]string, uint64, error) {
-	var err error
-	filterType := len(keyType) > 0
-
+func scanKeys(ctx context.Context, plan *svc.ScanPlan, match, keyType string, count int64) ([]string, error) {
 	scanSize := count
 	if scanSize <= 0 {
 		scanSize = 500
 	}
 
-	var keys []string
-	var loadedKey []string
-
-	if filterType {
-		loadedKey, cursor, err = client.ScanType(ctx, cursor, match, scanSize, keyType).Result()
-	} else {
-		loadedKey, cursor, err = client.Scan(ctx, cursor, match, scanSize).Result()
-	}
-	if err != nil {
-		return nil, cursor, err
-	}
-
-	for _, v := range loadedKey {
-		keys = append(keys, util.EncodeRedisKey(v).(string))
-	}
-
-	return keys, cursor, nil
+	return plan.Page(ctx, match, keyType, scanSize)
 }
@@ -2,12 +2,8 @@
 package monitor
 
 import (
-	"bufio"
 	"context"
-	"crypto/tls"
 	"fmt"
-	"io"
-	"net"
 	"strings"
 
 	"github.com/redis/go-redis/v9"
@@ -42,7 +38,7 @@ func (l *StartLogic) Start(params *types.MonitorReq, out app.Sink[types.MonitorF
 
 	ctx, cancel := context.WithCancel(out.Context())
 
-	conn, br, err := dialMonitor(ctx, rdb.Options())
+	conn, br, err := svc.DialMonitor(ctx, rdb.Options())
 	if err != nil {
 		cancel()
 		return fmt.Errorf("monitor connect failed: %w", err)
@@ -99,76 +95,3 @@ func (l *StartLogic) Start(params *types.MonitorReq, out app.Sink[types.MonitorF
 		}
 	}
 }
-
-func dialMonitor(ctx context.Context, opt *redis.Options) (net.Conn, *bufio.Reader, error) {
-	dialer := opt.Dialer
-	if dialer == nil {
-		d := &net.Dialer{}
-		dialer = func(ctx context.Context, network, addr string) (net.Conn, error) {
-			return d.DialContext(ctx, network, addr)
-		}
-	}
-	conn, err := dialer(ctx, opt.Network, opt.Addr)
-	if err != nil {
-		return nil, nil, err
-	}
-	if opt.TLSConfig != nil {
-		tc := tls.Client(conn, opt.TLSConfig)
-		if err := tc.HandshakeContext(ctx); err != nil {
-			_ = conn.Close()
-			return nil, nil, err
-		}
-		conn = tc
-	}
-
-	br := bufio.NewReader(conn)
-	readReply := func() (string, error) {
-		l, err := br.ReadString('\n')
-		return strings.TrimRight(l, "\r\n"), err
-	}
-	expectOK := func(what string) error {
-		l, err := readReply()
-		if err != nil {
-			return err
-		}
-		if strings.HasPrefix(l, "-") {
-			return fmt.Errorf("%s rejected: %s", what, strings.TrimPrefix(l, "-"))
-		}
-		return nil
-	}
-
-	if opt.Password != "" {
-		args := []string{"AUTH", opt.Password}
-		if opt.Username != "" {
-			args = []string{"AUTH", opt.Username, opt.Password}
-		}
-		if err := writeRESP(conn, args...); err != nil {
-			_ = conn.Close()
-			return nil, nil, err
-		}
-		if err := expectOK("AUTH"); err != nil {
-			_ = conn.Close()
-			return nil, nil, err
-		}
-	}
-
-	if err := writeRESP(conn, "MONITOR"); err != nil {
-		_ = conn.Close()
-		return nil, nil, err
-	}
-	if err := expectOK("MONITOR"); err != nil {
-		_ = conn.Close()
-		return nil, nil, err
-	}
-	return conn, br, nil
-}
-
-func writeRESP(w io.Writer, args ...string) error {
-	var b strings.Builder
-	fmt.Fprintf(&b, "*%d\r\n", len(args))
-	for _, a := range args {
-		fmt.Fprintf(&b, "$%d\r\n%s\r\n", len(a), a)
-	}
-	_, err := io.WriteString(w, b.String())
-	return err
-}
@@ -0,0 +1,70 @@
+// Code generated by scorix.
+package proxy
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"time"
+
+	"github.com/tradalab/rdms/internal/model"
+	"github.com/tradalab/rdms/internal/svc"
+	"github.com/tradalab/rdms/internal/types"
+)
+
+const testTimeout = 15 * time.Second
+
+type TestLogic struct {
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+func NewTestLogic(ctx context.Context, svcCtx *svc.ServiceContext) *TestLogic {
+	return &TestLogic{
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+func (l *TestLogic) Test(params *types.ProxyTestReq) (*types.ProxyTestRes, error) {
+	if params.Target != "" {
+		if _, _, err := net.SplitHostPort(params.Target); err != nil {
+			return nil, fmt.Errorf("target must be host:port: %w", err)
+		}
+	}
+	p := params.Proxy
+	pr := &model.Proxy{
+		Protocol: p.Protocol,
+		Host:     p.Host,
+		Port:     int64(p.Port),
+		Username: p.Username,
+		Password: p.Password,
+	}
+	if p.Id != "" && pr.Password == "" {
+		if stored, err := l.svcCtx.ProxyModel.FindOne(l.ctx, p.Id); err == nil {
+			pr.Password = stored.Password
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(l.ctx, testTimeout)
+	defer cancel()
+	rep, err := svc.TestProxy(ctx, pr, params.Target, testTimeout)
+	if err != nil {
+		return nil, err
+	}
+
+	res := &types.ProxyTestRes{Via: rep.Via}
+	for _, s := range rep.Steps {
+		step := types.ProxyTestStep{
+			Name:      s.Name,
+			Ok:        s.Err == nil,
+			Detail:    s.Detail,
+			LatencyMs: s.Latency.Milliseconds(),
+		}
+		if s.Err != nil {
+			step.Error = s.Err.Error()
+		}
+		res.Steps = append(res.Steps, step)
+	}
+	return res, nil
+}
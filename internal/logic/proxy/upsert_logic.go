@@ -5,6 +5,7 @@ import (
 	"context"
 	"database/sql"
 	"errors"
+	"fmt"
 
 	"github.com/tradalab/rdms/internal/model"
 	"github.com/tradalab/rdms/internal/svc"
@@ -24,6 +25,10 @@ func NewUpsertLogic(ctx context.Context, svcCtx *svc.ServiceContext) *UpsertLogi
 }
 
 func (l *UpsertLogic) Upsert(params *types.ProxyReq) (*types.UpsertRes, error) {
+	if !model.ValidProxyProtocol(params.Protocol) {
+		return nil, fmt.Errorf("unknown proxy protocol %q", params.Protocol)
+	}
+
 	var p *model.Proxy
 	if params.Id != "" {
 		existing, err := l.svcCtx.ProxyModel.FindOne(l.ctx, params.Id)
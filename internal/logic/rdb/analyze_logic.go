@@ -0,0 +1,140 @@
+// Code generated by scorix.
+package rdb
+
+import (
+	"context"
+	"io"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/tradalab/scorix/app"
+
+	"github.com/tradalab/rdms/internal/svc"
+	"github.com/tradalab/rdms/internal/types"
+	"github.com/tradalab/rdms/pkg/keyfilter"
+	rdbfile "github.com/tradalab/rdms/pkg/rdb"
+)
+
+const analyzeProgressInterval = 500 * time.Millisecond
+
+type AnalyzeLogic struct {
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+func NewAnalyzeLogic(ctx context.Context, svcCtx *svc.ServiceContext) *AnalyzeLogic {
+	return &AnalyzeLogic{
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+func (l *AnalyzeLogic) Analyze(req *types.RdbAnalyzeReq, out app.Sink[types.RdbAnalyzeEvent]) error {
+	clauses := make([]keyfilter.Clause, 0, len(req.Filters))
+	for _, f := range req.Filters {
+		clauses = append(clauses, keyfilter.Clause{
+			Pattern:    f.Pattern,
+			Mode:       f.Mode,
+			Exclude:    f.Exclude,
+			IgnoreCase: f.IgnoreCase,
+		})
+	}
+
+	filter, err := keyfilter.Compile(clauses, req.MatchAll)
+	if err != nil {
+		return err
+	}
+
+	f, err := os.Open(req.Path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	st, err := f.Stat()
+	if err != nil {
+		return err
+	}
+
+	return analyze(out.Context(), f, st.Size(), st.ModTime(), filter, req, out.Send)
+}
+
+// analyze decodes the snapshot in r and streams the report every
+// analyzeProgressInterval and once at the end. TTLs are counted from the
+// dump's creation time, so keys already expired then show under zero.
+func analyze(
+	ctx context.Context,
+	r io.Reader,
+	size int64,
+	mtime time.Time,
+	filter *keyfilter.Set,
+	req *types.RdbAnalyzeReq,
+	emit func(*types.RdbAnalyzeEvent) error,
+) error {
+	d, err := rdbfile.NewDecoder(r)
+	if err != nil {
+		return err
+	}
+
+	var (
+		report   = svc.NewKeyReport(req.Separator, int(req.PrefixDepth), int(req.Top))
+		start    = time.Now()
+		lastEmit = start
+		created  = mtime.Unix()
+		scanned  uint64
+		matched  uint64
+	)
+
+	send := func(done bool) error {
+		rep := report.Snapshot()
+		rep.Scanned = scanned
+		rep.Matched = matched
+		rep.ElapsedMs = time.Since(start).Milliseconds()
+		rep.Done = done
+		lastEmit = time.Now()
+		return emit(&types.RdbAnalyzeEvent{
+			Version:      int32(d.Version),
+			RedisVersion: d.Aux["redis-ver"],
+			CreatedAt:    created,
+			Read:         d.Offset(),
+			Size:         size,
+			Report:       *rep,
+		})
+	}
+
+	for {
+		e, err := d.Next()
+		if err == io.EOF {
+			return send(true)
+		}
+		if err != nil {
+			return err
+		}
+		if scanned == 0 {
+			// AUX fields precede the first key.
+			if ctime, err := strconv.ParseInt(d.Aux["ctime"], 10, 64); err == nil && ctime > 0 {
+				created = ctime
+			}
+		}
+		scanned++
+
+		if (req.DatabaseIndex < 0 || e.DB == int(req.DatabaseIndex)) &&
+			(req.KeyType == "" || e.Type == req.KeyType) && filter.Match(e.Key) {
+			matched++
+			k := types.KeyReportKey{Key: e.Key, Type: e.Type, Memory: e.Size, Elements: e.Elements, Ttl: -1}
+			if e.Expiry > 0 {
+				k.Ttl = max(e.Expiry-created*1000, 0)
+			}
+			report.Add(k)
+		}
+
+		if time.Since(lastEmit) >= analyzeProgressInterval {
+			if err := send(false); err != nil {
+				return err
+			}
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+		}
+	}
+}
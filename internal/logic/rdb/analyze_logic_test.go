@@ -0,0 +1,48 @@
+package rdb
+
+import (
+	"bytes"
+	"context"
+	"testing"
+	"time"
+
+	"github.com/tradalab/rdms/internal/types"
+	"github.com/tradalab/rdms/pkg/keyfilter"
+)
+
+func TestAnalyzeReportsFromDumpTime(t *testing.T) {
+	var b bytes.Buffer
+	b.WriteString("REDIS0011")
+	b.Write([]byte{0xfa, 5, 'c', 't', 'i', 'm', 'e', 0xc2, 0x00, 0xf1, 0x53, 0x65}) // ctime 1700000000 as int32
+	b.Write([]byte{0xfe, 0})
+	b.Write([]byte{0, 6, 'u', ':', '1', ':', 'a', 'b', 1, 'x'})
+	// Expires 90 s after the dump.
+	b.Write([]byte{0xfc, 0x90, 0xc7, 0xe6, 0xcf, 0x8b, 0x01, 0x00, 0x00})
+	b.Write([]byte{0, 3, 'u', ':', '2', 2, 'x', 'y'})
+	b.Write([]byte{0xfe, 1})
+	b.Write([]byte{0, 3, 'o', ':', '1', 1, 'z'})
+	b.Write([]byte{0xff, 0, 0, 0, 0, 0, 0, 0, 0})
+
+	filter, _ := keyfilter.Compile(nil, false)
+	var last *types.RdbAnalyzeEvent
+	err := analyze(context.Background(), &b, int64(b.Len()), time.Now(), filter, &types.RdbAnalyzeReq{DatabaseIndex: 0}, func(ev *types.RdbAnalyzeEvent) error {
+		last = ev
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("analyze: %v", err)
+	}
+	if !last.Report.Done || last.Version != 11 || last.CreatedAt != 1700000000 || last.Read != last.Size {
+		t.Fatalf("last = %+v", last)
+	}
+	if last.Report.Scanned != 3 || last.Report.Matched != 2 {
+		t.Errorf("scanned = %d matched = %d, want database 1 left out", last.Report.Scanned, last.Report.Matched)
+	}
+	if len(last.Report.Prefixes) != 1 || last.Report.Prefixes[0].Prefix != "u:" || last.Report.Prefixes[0].Keys != 2 {
+		t.Errorf("prefixes = %+v", last.Report.Prefixes)
+	}
+	// One key without expiry, one in the minute-to-hour band.
+	if hist := last.Report.TtlHist; hist[0].Count != 1 || hist[2].Count != 1 {
+		t.Errorf("ttl histogram = %+v", hist)
+	}
+}
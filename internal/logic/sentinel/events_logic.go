@@ -0,0 +1,41 @@
+// Code generated by scorix.
+package sentinel
+
+import (
+	"context"
+
+	"github.com/tradalab/scorix/app"
+
+	"github.com/tradalab/rdms/internal/svc"
+	"github.com/tradalab/rdms/internal/types"
+)
+
+type EventsLogic struct {
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+func NewEventsLogic(ctx context.Context, svcCtx *svc.ServiceContext) *EventsLogic {
+	return &EventsLogic{
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+func (l *EventsLogic) Events(req *types.SentinelReq, out app.Sink[types.SentinelEvent]) error {
+	c, err := l.svcCtx.RedisManager.Get(req.ConnectionId, int(req.DatabaseIndex))
+	if err != nil {
+		return err
+	}
+	defer c.BeginStream()()
+	return c.WatchSentinels(out.Context(), func(ev *svc.SentinelEvent) error {
+		return out.Send(&types.SentinelEvent{
+			ConnectionId: req.ConnectionId,
+			Sentinel:     ev.Sentinel,
+			Event:        ev.Event,
+			Master:       ev.Master,
+			Message:      ev.Message,
+			Time:         ev.Time.UnixMilli(),
+		})
+	})
+}
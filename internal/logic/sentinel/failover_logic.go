@@ -0,0 +1,32 @@
+// Code generated by scorix.
+package sentinel
+
+import (
+	"context"
+
+	"github.com/tradalab/rdms/internal/svc"
+	"github.com/tradalab/rdms/internal/types"
+)
+
+type FailoverLogic struct {
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+func NewFailoverLogic(ctx context.Context, svcCtx *svc.ServiceContext) *FailoverLogic {
+	return &FailoverLogic{
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+func (l *FailoverLogic) Failover(params *types.SentinelFailoverReq) (*types.Empty, error) {
+	c, err := l.svcCtx.RedisManager.Get(params.ConnectionId, int(params.DatabaseIndex))
+	if err != nil {
+		return nil, err
+	}
+	if err := c.SentinelFailover(l.ctx, params.Master); err != nil {
+		return nil, err
+	}
+	return &types.Empty{}, nil
+}
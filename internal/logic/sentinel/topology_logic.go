@@ -0,0 +1,79 @@
+// Code generated by scorix.
+package sentinel
+
+import (
+	"context"
+	"net"
+	"strconv"
+
+	"github.com/tradalab/rdms/internal/svc"
+	"github.com/tradalab/rdms/internal/types"
+)
+
+type TopologyLogic struct {
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+func NewTopologyLogic(ctx context.Context, svcCtx *svc.ServiceContext) *TopologyLogic {
+	return &TopologyLogic{
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+func (l *TopologyLogic) Topology(params *types.SentinelReq) (*types.SentinelTopologyRes, error) {
+	c, err := l.svcCtx.RedisManager.Get(params.ConnectionId, int(params.DatabaseIndex))
+	if err != nil {
+		return nil, err
+	}
+	topo, err := c.SentinelTopology(l.ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	res := &types.SentinelTopologyRes{}
+	for _, n := range topo.Nodes {
+		node := types.SentinelNode{Addr: n.Addr, Ok: n.Err == nil, LatencyMs: n.Latency.Milliseconds()}
+		if n.Err != nil {
+			node.Error = n.Err.Error()
+		}
+		res.Nodes = append(res.Nodes, node)
+	}
+	for _, m := range topo.Masters {
+		master := types.SentinelMaster{
+			Name:              m.Name(),
+			Addr:              m.Addr(),
+			Flags:             m.Info["flags"],
+			Quorum:            int32(atoi(m.Info["quorum"])),
+			NumReplicas:       int32(atoi(m.Info["num-slaves"])),
+			NumOtherSentinels: int32(atoi(m.Info["num-other-sentinels"])),
+			ConfigEpoch:       atoi(m.Info["config-epoch"]),
+			ReportedBy:        m.ReportedBy,
+		}
+		for _, r := range m.Replicas {
+			master.Replicas = append(master.Replicas, peer(r))
+		}
+		for _, s := range m.Sentinels {
+			master.Sentinels = append(master.Sentinels, peer(s))
+		}
+		res.Masters = append(res.Masters, master)
+	}
+	return res, nil
+}
+
+func peer(info map[string]string) types.SentinelPeer {
+	return types.SentinelPeer{
+		Addr:         net.JoinHostPort(info["ip"], info["port"]),
+		RunId:        info["runid"],
+		Flags:        info["flags"],
+		LinkStatus:   info["master-link-status"],
+		ReplOffset:   atoi(info["slave-repl-offset"]),
+		LastOkPingMs: atoi(info["last-ok-ping-reply"]),
+	}
+}
+
+func atoi(s string) int64 {
+	n, _ := strconv.ParseInt(s, 10, 64)
+	return n
+}
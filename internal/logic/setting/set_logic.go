@@ -37,6 +37,7 @@ func (l *SetLogic) Set(params *types.SettingSetReq) (*types.Empty, error) {
 		if _, err := l.svcCtx.SettingModel.Insert(l.ctx, s); err != nil {
 			return nil, err
 		}
+		l.applyLimits(params.Key)
 		return &types.Empty{}, nil
 	}
 
@@ -44,5 +45,12 @@ func (l *SetLogic) Set(params *types.SettingSetReq) (*types.Empty, error) {
 	if err := l.svcCtx.SettingModel.Update(l.ctx, existing); err != nil {
 		return nil, err
 	}
+	l.applyLimits(params.Key)
 	return &types.Empty{}, nil
 }
+
+func (l *SetLogic) applyLimits(key string) {
+	if key == svc.SettingClientIdleTimeout || key == svc.SettingClientMaxActive {
+		l.svcCtx.ApplyClientLimits(l.ctx)
+	}
+}
@@ -4,6 +4,8 @@ package ssh
 import (
 	"context"
 	"fmt"
+	"net"
+	"time"
 
 	"github.com/tradalab/rdms/internal/model"
 	"github.com/tradalab/rdms/internal/svc"
@@ -23,7 +25,7 @@ func NewTestLogic(ctx context.Context, svcCtx *svc.ServiceContext) *TestLogic {
 	}
 }
 
-func (l *TestLogic) Test(params *types.SshReq) (*types.Empty, error) {
+func (l *TestLogic) Test(params *types.SshReq) (*types.SshTestRes, error) {
 	s := model.Ssh{
 		Host:       params.Host,
 		Port:       int64(params.Port),
@@ -49,17 +51,71 @@ func (l *TestLogic) Test(params *types.SshReq) (*types.Empty, error) {
 		}
 	}
 
-	config, err := s.BuildClientCfg()
-	if err != nil {
-		return nil, err
+	hops := []*model.Ssh{&s}
+	if len(params.JumpIds) > 0 {
+		jumps, err := l.svcCtx.SshModel.FindChain(l.ctx, params.JumpIds)
+		if err != nil {
+			return nil, err
+		}
+		hops = append(jumps, &s)
 	}
 
-	addr := s.Addr()
-	conn, err := ssh.Dial("tcp", addr, config)
+	var timeout time.Duration
+	for _, h := range hops {
+		timeout += time.Duration(h.Timeout) * time.Second
+	}
+	if timeout <= 0 {
+		timeout = time.Minute
+	}
+	ctx, cancel := context.WithTimeout(l.ctx, timeout)
+	defer cancel()
+
+	// Unknown or changed host keys abort the handshake and are reported back;
+	// the caller approves one by echoing its fingerprint in AcceptHostKey.
+	store := svc.NewHostKeyStore(l.svcCtx.KnownHostModel)
+	var (
+		check *svc.HostKeyCheck
+		hop   int
+	)
+	chain, err := svc.DialSshChain(ctx, &net.Dialer{}, hops, func(i int) ssh.HostKeyCallback {
+		return func(hostname string, _ net.Addr, key ssh.PublicKey) error {
+			res, err := store.Check(ctx, hostname, key)
+			if err != nil {
+				return err
+			}
+			check, hop = res, i
+			if res.Status == svc.HostKeyTrusted {
+				return nil
+			}
+			if params.AcceptHostKey != "" && params.AcceptHostKey == res.Fingerprint {
+				if err := store.Trust(ctx, hostname, key); err != nil {
+					return err
+				}
+				res.Status = svc.HostKeyTrusted
+				return nil
+			}
+			return fmt.Errorf("host key not approved")
+		}
+	})
 	if err != nil {
+		if check != nil && check.Status != svc.HostKeyTrusted {
+			return hostKeyRes(check, hop, hops[hop]), nil
+		}
 		return nil, fmt.Errorf("ssh connect failed: %w", err)
 	}
-	defer conn.Close()
+	defer chain.Close()
 
-	return &types.Empty{}, nil
+	return hostKeyRes(check, hop, hops[hop]), nil
+}
+
+func hostKeyRes(c *svc.HostKeyCheck, hop int, s *model.Ssh) *types.SshTestRes {
+	return &types.SshTestRes{
+		Host:             c.Host,
+		HostKeyStatus:    c.Status,
+		KeyType:          c.KeyType,
+		Fingerprint:      c.Fingerprint,
+		KnownFingerprint: c.KnownFingerprint,
+		Hop:              int32(hop + 1),
+		HopAddr:          s.Addr(),
+	}
 }
@@ -29,15 +29,23 @@ func (l *ListLogic) List(params *types.Empty) (*types.TlsListRes, error) {
 	var items []types.TlsReq
 	for _, t := range tlss {
 		items = append(items, types.TlsReq{
-			Id:         t.ID,
-			Name:       t.Name,
-			UseSni:     t.UseSni > 0,
-			ServerName: t.ServerName,
-			Verify:     t.Verify > 0,
-			ClientAuth: t.ClientAuth > 0,
-			CaCert:     t.CaCert,
-			Cert:       t.Cert,
-			Key:        "",
+			Id:            t.ID,
+			Name:          t.Name,
+			UseSni:        t.UseSni > 0,
+			ServerName:    t.ServerName,
+			Verify:        t.Verify > 0,
+			ClientAuth:    t.ClientAuth > 0,
+			CaCert:        t.CaCert,
+			Cert:          t.Cert,
+			Key:           "",
+			CaFile:        t.CaFile,
+			CertFile:      t.CertFile,
+			KeyFile:       t.KeyFile,
+			KeyPassphrase: "",
+			MinVersion:    t.MinVersion,
+			MaxVersion:    t.MaxVersion,
+			CipherSuites:  t.CipherSuites,
+			PinSha256:     t.PinSha256,
 		})
 	}
 
@@ -0,0 +1,96 @@
+// Code generated by scorix.
+package tls
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"strconv"
+	"time"
+
+	"github.com/tradalab/rdms/internal/model"
+	"github.com/tradalab/rdms/internal/svc"
+	"github.com/tradalab/rdms/internal/types"
+)
+
+const testTimeout = 15 * time.Second
+
+type TestLogic struct {
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+func NewTestLogic(ctx context.Context, svcCtx *svc.ServiceContext) *TestLogic {
+	return &TestLogic{
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+func (l *TestLogic) Test(params *types.TlsTestReq) (*types.TlsTestRes, error) {
+	if params.Host == "" || params.Port <= 0 {
+		return nil, fmt.Errorf("host and port are required")
+	}
+	p := params.Tls
+	t := &model.Tls{
+		UseSni:        bToI(p.UseSni),
+		ServerName:    p.ServerName,
+		Verify:        bToI(p.Verify),
+		ClientAuth:    bToI(p.ClientAuth),
+		CaCert:        p.CaCert,
+		Cert:          p.Cert,
+		Key:           p.Key,
+		CaFile:        p.CaFile,
+		CertFile:      p.CertFile,
+		KeyFile:       p.KeyFile,
+		KeyPassphrase: p.KeyPassphrase,
+		MinVersion:    p.MinVersion,
+		MaxVersion:    p.MaxVersion,
+		CipherSuites:  p.CipherSuites,
+		PinSha256:     p.PinSha256,
+	}
+	if p.Id != "" && (t.Key == "" || t.KeyPassphrase == "") {
+		if stored, err := l.svcCtx.TlsModel.FindOne(l.ctx, p.Id); err == nil {
+			if t.Key == "" {
+				t.Key = stored.Key
+			}
+			if t.KeyPassphrase == "" {
+				t.KeyPassphrase = stored.KeyPassphrase
+			}
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(l.ctx, testTimeout)
+	defer cancel()
+	addr := net.JoinHostPort(params.Host, strconv.Itoa(int(params.Port)))
+	rep, err := svc.InspectTls(ctx, &net.Dialer{}, t, addr)
+	if err != nil {
+		return nil, err
+	}
+
+	res := &types.TlsTestRes{
+		Version:     rep.Version,
+		CipherSuite: rep.CipherSuite,
+		Verified:    rep.VerifyErr == nil,
+		Pinned:      rep.Pinned,
+		PinOk:       rep.Pinned && rep.PinErr == nil,
+	}
+	if rep.VerifyErr != nil {
+		res.VerifyError = rep.VerifyErr.Error()
+	}
+	if rep.PinErr != nil {
+		res.PinError = rep.PinErr.Error()
+	}
+	for _, c := range rep.Chain {
+		res.Chain = append(res.Chain, types.TlsCertInfo{
+			Subject:     c.Subject,
+			Issuer:      c.Issuer,
+			Sans:        c.SANs,
+			NotBefore:   c.NotBefore.UnixMilli(),
+			NotAfter:    c.NotAfter.UnixMilli(),
+			Fingerprint: c.Fingerprint,
+			IsCa:        c.IsCA,
+		})
+	}
+	return res, nil
+}
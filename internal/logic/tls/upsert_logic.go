@@ -48,10 +48,23 @@ func (l *UpsertLogic) Upsert(params *types.TlsReq) (*types.UpsertRes, error) {
 	t.ClientAuth = bToI(params.ClientAuth)
 	t.CaCert = params.CaCert
 	t.Cert = params.Cert
+	t.CaFile = params.CaFile
+	t.CertFile = params.CertFile
+	t.KeyFile = params.KeyFile
+	t.MinVersion = params.MinVersion
+	t.MaxVersion = params.MaxVersion
+	t.CipherSuites = params.CipherSuites
+	t.PinSha256 = params.PinSha256
 
 	if isNew || params.Key != "" {
 		t.Key = params.Key
 	}
+	if isNew || params.KeyPassphrase != "" {
+		t.KeyPassphrase = params.KeyPassphrase
+	}
+	if err := t.CheckOptions(); err != nil {
+		return nil, err
+	}
 
 	if isNew {
 		if _, err := l.svcCtx.TlsModel.Insert(l.ctx, t); err != nil {
@@ -3,6 +3,7 @@ package model
 import (
 	"net"
 	"strconv"
+	"strings"
 
 	scorixsqlx "github.com/tradalab/scorix/module/sqlx"
 )
@@ -25,9 +26,68 @@ func NewConnectionModel(conn func() scorixsqlx.Conn) ConnectionModel {
 	}
 }
 
+// Read-from policies for browsing a cluster or sentinel connection. Writes
+// always go to the master.
+const (
+	ReadFromMaster  = ""
+	ReadFromReplica = "replica"
+	ReadFromLatency = "latency"
+	ReadFromRandom  = "random"
+)
+
+func ValidReadFrom(v string) bool {
+	switch v {
+	case ReadFromMaster, ReadFromReplica, ReadFromLatency, ReadFromRandom:
+		return true
+	}
+	return false
+}
+
+// Credential providers replace the stored username and password with what
+// a local command prints or a file holds, for short-lived tokens.
+const (
+	CredProviderNone    = ""
+	CredProviderCommand = "command"
+	CredProviderFile    = "file"
+)
+
+func ValidCredProvider(v string) bool {
+	switch v {
+	case CredProviderNone, CredProviderCommand, CredProviderFile:
+		return true
+	}
+	return false
+}
+
+// ReadsFromReplicas reports whether browsing should leave the master.
+func (c *Connection) ReadsFromReplicas() bool {
+	return c.ReadFrom != ReadFromMaster && (c.Mode == "cluster" || c.Mode == "sentinel")
+}
+
 func (c *Connection) Addr() string {
 	if (c.Mode == "sentinel" || c.Mode == "cluster") && c.Addrs != "" {
 		return c.Addrs
 	}
 	return net.JoinHostPort(c.Host, strconv.Itoa(int(c.Port)))
 }
+
+// SshJumps lists the jump host ssh profile IDs in dial order.
+func (c *Connection) SshJumps() []string {
+	var ids []string
+	for _, id := range strings.Split(c.SshJumpIds, ",") {
+		if id = strings.TrimSpace(id); id != "" {
+			ids = append(ids, id)
+		}
+	}
+	return ids
+}
+
+// SshHops is the full tunnel: the jump hosts, then the profile that reaches
+// Redis.
+func (c *Connection) SshHops() []string {
+	ids := c.SshJumps()
+	if c.SshID != "" {
+		ids = append(ids, c.SshID)
+	}
+	return ids
+}
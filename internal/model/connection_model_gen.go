@@ -13,11 +13,11 @@ import (
 )
 
 const (
-	connectionFindOneSQL  = "SELECT `id`,`mode`,`name`,`network`,`host`,`port`,`addrs`,`sentinel_master`,`sentinel_username`,`sentinel_password`,`sock`,`username`,`password`,`addr_mapping`,`last_db`,`exec_timeout`,`dial_timeout`,`key_size`,`group_id`,`ssh_enable`,`ssh_id`,`proxy_enable`,`proxy_id`,`tls_enable`,`tls_id`,`read_only`,`created_at`,`updated_at`,`deleted_at` FROM `connection` WHERE `id` = ? AND `deleted_at` IS NULL LIMIT 1"
-	connectionFindAllSQL  = "SELECT `id`,`mode`,`name`,`network`,`host`,`port`,`addrs`,`sentinel_master`,`sentinel_username`,`sentinel_password`,`sock`,`username`,`password`,`addr_mapping`,`last_db`,`exec_timeout`,`dial_timeout`,`key_size`,`group_id`,`ssh_enable`,`ssh_id`,`proxy_enable`,`proxy_id`,`tls_enable`,`tls_id`,`read_only`,`created_at`,`updated_at`,`deleted_at` FROM `connection` WHERE `deleted_at` IS NULL"
-	connectionFindManySQL = "SELECT `id`,`mode`,`name`,`network`,`host`,`port`,`addrs`,`sentinel_master`,`sentinel_username`,`sentinel_password`,`sock`,`username`,`password`,`addr_mapping`,`last_db`,`exec_timeout`,`dial_timeout`,`key_size`,`group_id`,`ssh_enable`,`ssh_id`,`proxy_enable`,`proxy_id`,`tls_enable`,`tls_id`,`read_only`,`created_at`,`updated_at`,`deleted_at` FROM `connection` WHERE `id` IN (?) AND `deleted_at` IS NULL"
-	connectionInsertSQL   = "INSERT INTO `connection` (`id`,`mode`,`name`,`network`,`host`,`port`,`addrs`,`sentinel_master`,`sentinel_username`,`sentinel_password`,`sock`,`username`,`password`,`addr_mapping`,`last_db`,`exec_timeout`,`dial_timeout`,`key_size`,`group_id`,`ssh_enable`,`ssh_id`,`proxy_enable`,`proxy_id`,`tls_enable`,`tls_id`,`read_only`,`created_at`,`updated_at`,`deleted_at`) VALUES (?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?)"
-	connectionUpdateSQL   = "UPDATE `connection` SET `mode` = ?, `name` = ?, `network` = ?, `host` = ?, `port` = ?, `addrs` = ?, `sentinel_master` = ?, `sentinel_username` = ?, `sentinel_password` = ?, `sock` = ?, `username` = ?, `password` = ?, `addr_mapping` = ?, `last_db` = ?, `exec_timeout` = ?, `dial_timeout` = ?, `key_size` = ?, `group_id` = ?, `ssh_enable` = ?, `ssh_id` = ?, `proxy_enable` = ?, `proxy_id` = ?, `tls_enable` = ?, `tls_id` = ?, `read_only` = ?, `updated_at` = ?, `deleted_at` = ? WHERE `id` = ?"
+	connectionFindOneSQL  = "SELECT `id`,`mode`,`name`,`network`,`host`,`port`,`addrs`,`sentinel_master`,`sentinel_username`,`sentinel_password`,`sock`,`username`,`password`,`addr_mapping`,`last_db`,`exec_timeout`,`dial_timeout`,`key_size`,`group_id`,`ssh_enable`,`ssh_id`,`ssh_jump_ids`,`proxy_enable`,`proxy_id`,`tls_enable`,`tls_id`,`read_only`,`read_from`,`cred_provider`,`cred_source`,`cred_ttl`,`created_at`,`updated_at`,`deleted_at` FROM `connection` WHERE `id` = ? AND `deleted_at` IS NULL LIMIT 1"
+	connectionFindAllSQL  = "SELECT `id`,`mode`,`name`,`network`,`host`,`port`,`addrs`,`sentinel_master`,`sentinel_username`,`sentinel_password`,`sock`,`username`,`password`,`addr_mapping`,`last_db`,`exec_timeout`,`dial_timeout`,`key_size`,`group_id`,`ssh_enable`,`ssh_id`,`ssh_jump_ids`,`proxy_enable`,`proxy_id`,`tls_enable`,`tls_id`,`read_only`,`read_from`,`cred_provider`,`cred_source`,`cred_ttl`,`created_at`,`updated_at`,`deleted_at` FROM `connection` WHERE `deleted_at` IS NULL"
+	connectionFindManySQL = "SELECT `id`,`mode`,`name`,`network`,`host`,`port`,`addrs`,`sentinel_master`,`sentinel_username`,`sentinel_password`,`sock`,`username`,`password`,`addr_mapping`,`last_db`,`exec_timeout`,`dial_timeout`,`key_size`,`group_id`,`ssh_enable`,`ssh_id`,`ssh_jump_ids`,`proxy_enable`,`proxy_id`,`tls_enable`,`tls_id`,`read_only`,`read_from`,`cred_provider`,`cred_source`,`cred_ttl`,`created_at`,`updated_at`,`deleted_at` FROM `connection` WHERE `id` IN (?) AND `deleted_at` IS NULL"
+	connectionInsertSQL   = "INSERT INTO `connection` (`id`,`mode`,`name`,`network`,`host`,`port`,`addrs`,`sentinel_master`,`sentinel_username`,`sentinel_password`,`sock`,`username`,`password`,`addr_mapping`,`last_db`,`exec_timeout`,`dial_timeout`,`key_size`,`group_id`,`ssh_enable`,`ssh_id`,`ssh_jump_ids`,`proxy_enable`,`proxy_id`,`tls_enable`,`tls_id`,`read_only`,`read_from`,`cred_provider`,`cred_source`,`cred_ttl`,`created_at`,`updated_at`,`deleted_at`) VALUES (?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?)"
+	connectionUpdateSQL   = "UPDATE `connection` SET `mode` = ?, `name` = ?, `network` = ?, `host` = ?, `port` = ?, `addrs` = ?, `sentinel_master` = ?, `sentinel_username` = ?, `sentinel_password` = ?, `sock` = ?, `username` = ?, `password` = ?, `addr_mapping` = ?, `last_db` = ?, `exec_timeout` = ?, `dial_timeout` = ?, `key_size` = ?, `group_id` = ?, `ssh_enable` = ?, `ssh_id` = ?, `ssh_jump_ids` = ?, `proxy_enable` = ?, `proxy_id` = ?, `tls_enable` = ?, `tls_id` = ?, `read_only` = ?, `read_from` = ?, `cred_provider` = ?, `cred_source` = ?, `cred_ttl` = ?, `updated_at` = ?, `deleted_at` = ? WHERE `id` = ?"
 	connectionDeleteSQL   = "UPDATE `connection` SET `deleted_at` = ? WHERE `id` = ?"
 )
 
@@ -61,11 +61,16 @@ type (
 		GroupID          string       `db:"group_id" json:"group_id"`
 		SshEnable        int64        `db:"ssh_enable" json:"ssh_enable"`
 		SshID            string       `db:"ssh_id" json:"ssh_id"`
+		SshJumpIds       string       `db:"ssh_jump_ids" json:"ssh_jump_ids"`
 		ProxyEnable      int64        `db:"proxy_enable" json:"proxy_enable"`
 		ProxyID          string       `db:"proxy_id" json:"proxy_id"`
 		TlsEnable        int64        `db:"tls_enable" json:"tls_enable"`
 		TlsID            string       `db:"tls_id" json:"tls_id"`
 		ReadOnly         int64        `db:"read_only" json:"read_only"`
+		ReadFrom         string       `db:"read_from" json:"read_from"`
+		CredProvider     string       `db:"cred_provider" json:"cred_provider"`
+		CredSource       string       `db:"cred_source" json:"cred_source"`
+		CredTtl          int64        `db:"cred_ttl" json:"cred_ttl"`
 		CreatedAt        time.Time    `db:"created_at" json:"created_at"`
 		UpdatedAt        time.Time    `db:"updated_at" json:"updated_at"`
 		DeletedAt        sql.NullTime `db:"deleted_at" json:"deleted_at"`
@@ -106,11 +111,16 @@ func (m *defaultConnectionModel) Insert(ctx context.Context, data *Connection) (
 		data.GroupID,
 		data.SshEnable,
 		data.SshID,
+		data.SshJumpIds,
 		data.ProxyEnable,
 		data.ProxyID,
 		data.TlsEnable,
 		data.TlsID,
 		data.ReadOnly,
+		data.ReadFrom,
+		data.CredProvider,
+		data.CredSource,
+		data.CredTtl,
 		data.CreatedAt,
 		data.UpdatedAt,
 		data.DeletedAt,
@@ -167,11 +177,16 @@ func (m *defaultConnectionModel) Update(ctx context.Context, data *Connection) e
 		data.GroupID,
 		data.SshEnable,
 		data.SshID,
+		data.SshJumpIds,
 		data.ProxyEnable,
 		data.ProxyID,
 		data.TlsEnable,
 		data.TlsID,
 		data.ReadOnly,
+		data.ReadFrom,
+		data.CredProvider,
+		data.CredSource,
+		data.CredTtl,
 		data.UpdatedAt,
 		data.DeletedAt,
 		data.ID,
@@ -0,0 +1,375 @@
+package model
+
+import (
+	"fmt"
+	"math"
+	"net"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	defaultRedisPort    = 6379
+	defaultSentinelPort = 26379
+)
+
+// ignoredURLOptions are go-redis client tuning options that services put in
+// their URLs but that mean nothing for an interactive connection.
+var ignoredURLOptions = map[string]bool{
+	"protocol":                 true,
+	"pool_fifo":                true,
+	"pool_size":                true,
+	"pool_timeout":             true,
+	"min_idle_conns":           true,
+	"max_idle_conns":           true,
+	"max_active_conns":         true,
+	"conn_max_idle_time":       true,
+	"conn_max_lifetime":        true,
+	"max_retries":              true,
+	"min_retry_backoff":        true,
+	"max_retry_backoff":        true,
+	"max_redirects":            true,
+	"context_timeout_enabled":  true,
+	"disable_identity":         true,
+	"unstable_resp3":           true,
+	"identity_suffix":          true,
+	"use_disconnected_replica": true,
+}
+
+// ParseConnectionURL fills a connection from a Redis URL:
+//
+//	redis[s]://[user[:pass]@]host[:port][/db][?options]
+//	redis[s]-sentinel://[user[:pass]@]host[:port][,host[:port]...][/master[/db]][?options]
+//	redis[s]-cluster://[user[:pass]@]host[:port][,host[:port]...][?options]
+//	unix://[user[:pass]@]/path/to/redis.sock[?db=N]
+//
+// A plain redis:// URL with several hosts (comma separated or extra addr=
+// options) is a cluster seed list. The returned Tls profile is nil unless
+// the scheme asks for TLS.
+func ParseConnectionURL(raw string) (*Connection, *Tls, error) {
+	raw, hosts := cutURLHosts(strings.TrimSpace(raw))
+	u, err := url.Parse(raw)
+	if err != nil {
+		return nil, nil, fmt.Errorf("invalid redis url: %w", err)
+	}
+	if hosts == "" {
+		hosts = u.Host
+	}
+
+	c := &Connection{Mode: "standalone", Network: "tcp"}
+	useTls := false
+	port := defaultRedisPort
+	switch strings.ToLower(u.Scheme) {
+	case "redis", "valkey":
+	case "rediss", "valkeys":
+		useTls = true
+	case "redis-sentinel":
+		c.Mode, port = "sentinel", defaultSentinelPort
+	case "rediss-sentinel":
+		c.Mode, port, useTls = "sentinel", defaultSentinelPort, true
+	case "redis-cluster":
+		c.Mode = "cluster"
+	case "rediss-cluster":
+		c.Mode, useTls = "cluster", true
+	case "unix", "redis+unix", "redis-socket":
+		c.Network = "unix"
+	default:
+		return nil, nil, fmt.Errorf("unsupported redis url scheme %q", u.Scheme)
+	}
+
+	if u.User != nil {
+		c.Username = u.User.Username()
+		c.Password, _ = u.User.Password()
+	}
+
+	q := u.Query()
+	path := strings.Trim(u.Path, "/")
+
+	if c.Network == "unix" {
+		if u.Path == "" {
+			return nil, nil, fmt.Errorf("unix url needs a socket path")
+		}
+		c.Sock = u.Path
+		path = ""
+	} else {
+		addrs, err := splitURLHosts(hosts, port)
+		if err != nil {
+			return nil, nil, err
+		}
+		for _, a := range q["addr"] {
+			extra, err := splitURLHosts(a, port)
+			if err != nil {
+				return nil, nil, err
+			}
+			addrs = append(addrs, extra...)
+		}
+		q.Del("addr")
+		if len(addrs) == 0 {
+			return nil, nil, fmt.Errorf("redis url has no host")
+		}
+		if c.Mode == "standalone" && len(addrs) > 1 {
+			c.Mode = "cluster"
+		}
+		if c.Mode == "standalone" {
+			host, p, _ := net.SplitHostPort(addrs[0])
+			c.Host = host
+			c.Port, _ = strconv.ParseInt(p, 10, 64)
+		} else {
+			c.Addrs = strings.Join(addrs, ",")
+		}
+	}
+
+	if c.Mode == "sentinel" && path != "" {
+		master, rest, _ := strings.Cut(path, "/")
+		if _, err := strconv.Atoi(master); err != nil {
+			c.SentinelMaster = master
+			path = rest
+		}
+	}
+	if path != "" {
+		db, err := strconv.Atoi(path)
+		if err != nil || db < 0 {
+			return nil, nil, fmt.Errorf("invalid database number %q", path)
+		}
+		c.LastDb = int64(db)
+	}
+
+	var t *Tls
+	if useTls {
+		t = &Tls{Verify: 1}
+	}
+
+	for key, values := range q {
+		v := values[len(values)-1]
+		switch key {
+		case "db":
+			db, err := strconv.Atoi(v)
+			if err != nil || db < 0 {
+				return nil, nil, fmt.Errorf("invalid db option %q", v)
+			}
+			c.LastDb = int64(db)
+		case "dial_timeout":
+			if c.DialTimeout, err = parseURLSeconds(key, v); err != nil {
+				return nil, nil, err
+			}
+		case "read_timeout", "write_timeout", "timeout":
+			s, err := parseURLSeconds(key, v)
+			if err != nil {
+				return nil, nil, err
+			}
+			c.ExecTimeout = max(c.ExecTimeout, s)
+		case "client_name", "name":
+			c.Name = v
+		case "master_name", "sentinel_master":
+			c.SentinelMaster = v
+		case "sentinel_username":
+			c.SentinelUsername = v
+		case "sentinel_password":
+			c.SentinelPassword = v
+		case "read_only", "readonly":
+			b, err := strconv.ParseBool(v)
+			if err != nil {
+				return nil, nil, fmt.Errorf("invalid %s option %q", key, v)
+			}
+			c.ReadOnly = boolToInt(b)
+		case "read_from":
+			if !ValidReadFrom(v) {
+				return nil, nil, fmt.Errorf("invalid read_from option %q", v)
+			}
+			c.ReadFrom = v
+		case "route_by_latency", "route_randomly", "replica_only":
+			b, err := strconv.ParseBool(v)
+			if err != nil {
+				return nil, nil, fmt.Errorf("invalid %s option %q", key, v)
+			}
+			if b {
+				c.ReadFrom = map[string]string{
+					"route_by_latency": ReadFromLatency,
+					"route_randomly":   ReadFromRandom,
+					"replica_only":     ReadFromReplica,
+				}[key]
+			}
+		case "skip_verify", "insecure_skip_verify":
+			b, err := strconv.ParseBool(v)
+			if err != nil {
+				return nil, nil, fmt.Errorf("invalid %s option %q", key, v)
+			}
+			if t != nil {
+				t.Verify = boolToInt(!b)
+			}
+		case "server_name", "sni":
+			if t != nil && v != "" {
+				t.UseSni = 1
+				t.ServerName = v
+			}
+		default:
+			if !ignoredURLOptions[key] {
+				return nil, nil, fmt.Errorf("unknown redis url option %q", key)
+			}
+		}
+	}
+
+	if c.Mode == "sentinel" && c.SentinelMaster == "" {
+		return nil, nil, fmt.Errorf("sentinel url needs a master name (path or master_name option)")
+	}
+	if c.Name == "" {
+		switch {
+		case c.Network == "unix":
+			c.Name = c.Sock
+		case c.Mode == "sentinel":
+			c.Name = c.SentinelMaster
+		case c.Mode == "cluster":
+			c.Name = strings.Split(c.Addrs, ",")[0]
+		default:
+			c.Name = c.Addr()
+		}
+	}
+	if t != nil {
+		t.Name = c.Name
+		c.TlsEnable = 1
+	}
+	return c, t, nil
+}
+
+// URL renders the connection back into the form ParseConnectionURL reads.
+// Passwords are left out unless withSecrets is set. SSH, proxy, address
+// mapping and certificates have no URL form and are dropped.
+func (c *Connection) URL(t *Tls, withSecrets bool) string {
+	u := &url.URL{}
+	q := url.Values{}
+	useTls := c.TlsEnable > 0 && t != nil
+
+	switch {
+	case c.Network == "unix":
+		u.Scheme = "unix"
+		u.Path = c.Sock
+		if c.LastDb > 0 {
+			q.Set("db", strconv.FormatInt(c.LastDb, 10))
+		}
+	case c.Mode == "sentinel":
+		u.Scheme = "redis-sentinel"
+		u.Host = strings.Join(splitAddrList(c.Addrs), ",")
+		u.Path = "/" + c.SentinelMaster
+		if c.LastDb > 0 {
+			u.Path += "/" + strconv.FormatInt(c.LastDb, 10)
+		}
+		if c.SentinelUsername != "" {
+			q.Set("sentinel_username", c.SentinelUsername)
+		}
+		if withSecrets && c.SentinelPassword != "" {
+			q.Set("sentinel_password", c.SentinelPassword)
+		}
+	case c.Mode == "cluster":
+		u.Scheme = "redis-cluster"
+		u.Host = strings.Join(splitAddrList(c.Addrs), ",")
+	default:
+		u.Scheme = "redis"
+		u.Host = c.Addr()
+		if c.LastDb > 0 {
+			u.Path = "/" + strconv.FormatInt(c.LastDb, 10)
+		}
+	}
+
+	if useTls {
+		u.Scheme = strings.Replace(u.Scheme, "redis", "rediss", 1)
+		if t.Verify == 0 {
+			q.Set("skip_verify", "true")
+		}
+		if t.UseSni > 0 && t.ServerName != "" {
+			q.Set("server_name", t.ServerName)
+		}
+	}
+
+	switch {
+	case withSecrets && c.Password != "":
+		u.User = url.UserPassword(c.Username, c.Password)
+	case c.Username != "":
+		u.User = url.User(c.Username)
+	}
+
+	if c.Name != "" {
+		q.Set("client_name", c.Name)
+	}
+	if c.DialTimeout > 0 {
+		q.Set("dial_timeout", strconv.FormatInt(c.DialTimeout, 10)+"s")
+	}
+	if c.ExecTimeout > 0 {
+		q.Set("read_timeout", strconv.FormatInt(c.ExecTimeout, 10)+"s")
+	}
+	if c.ReadOnly > 0 {
+		q.Set("read_only", "true")
+	}
+	if c.ReadsFromReplicas() {
+		q.Set("read_from", c.ReadFrom)
+	}
+	u.RawQuery = q.Encode()
+	return u.String()
+}
+
+// cutURLHosts lifts a comma separated host list out of the authority, which
+// net/url would reject, and leaves a single placeholder host in its place.
+func cutURLHosts(raw string) (string, string) {
+	scheme, rest, ok := strings.Cut(raw, "://")
+	if !ok {
+		return raw, ""
+	}
+	end := strings.IndexAny(rest, "/?#")
+	if end < 0 {
+		end = len(rest)
+	}
+	authority := rest[:end]
+	at := strings.LastIndex(authority, "@")
+	hosts := authority[at+1:]
+	if !strings.Contains(hosts, ",") {
+		return raw, ""
+	}
+	return scheme + "://" + authority[:at+1] + "seed" + rest[end:], hosts
+}
+
+func splitURLHosts(hosts string, defaultPort int) ([]string, error) {
+	var out []string
+	for _, h := range strings.Split(hosts, ",") {
+		h = strings.TrimSpace(h)
+		if h == "" {
+			continue
+		}
+		host, port, err := net.SplitHostPort(h)
+		if err != nil {
+			// No port given; brackets are only legal together with one.
+			host, port = strings.Trim(h, "[]"), strconv.Itoa(defaultPort)
+		}
+		if p, err := strconv.Atoi(port); err != nil || p <= 0 || p > 65535 {
+			return nil, fmt.Errorf("invalid port in %q", h)
+		}
+		out = append(out, net.JoinHostPort(host, port))
+	}
+	return out, nil
+}
+
+func splitAddrList(s string) []string {
+	return strings.FieldsFunc(s, func(r rune) bool {
+		return r == ',' || r == '\n' || r == '\r' || r == ' ' || r == '\t'
+	})
+}
+
+// parseURLSeconds reads a go-redis style duration ("5s", "500ms", or bare
+// seconds) and rounds it up to whole seconds, the unit the model stores.
+func parseURLSeconds(key, v string) (int64, error) {
+	if n, err := strconv.ParseInt(v, 10, 64); err == nil {
+		return max(n, 0), nil
+	}
+	d, err := time.ParseDuration(v)
+	if err != nil {
+		return 0, fmt.Errorf("invalid %s option %q", key, v)
+	}
+	return int64(math.Ceil(max(d, 0).Seconds())), nil
+}
+
+func boolToInt(b bool) int64 {
+	if b {
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,21 @@
+package model
+
+import scorixsqlx "github.com/tradalab/scorix/module/sqlx"
+
+var _ KnownHostModel = (*customKnownHostModel)(nil)
+
+type (
+	KnownHostModel interface {
+		knownHostModel
+	}
+
+	customKnownHostModel struct {
+		*defaultKnownHostModel
+	}
+)
+
+func NewKnownHostModel(conn func() scorixsqlx.Conn) KnownHostModel {
+	return &customKnownHostModel{
+		defaultKnownHostModel: newDefaultKnownHostModel(conn),
+	}
+}
@@ -25,6 +25,27 @@ func NewProxyModel(conn func() scorixsqlx.Conn) ProxyModel {
 	}
 }
 
+// Proxy protocols follow curl's naming: socks5 resolves target names
+// locally and socks5h lets the proxy resolve them, likewise socks4 and
+// socks4a. System takes the proxy from HTTPS_PROXY/ALL_PROXY and NO_PROXY.
+const (
+	ProxyHttp    = "http"
+	ProxyHttps   = "https"
+	ProxySocks5  = "socks5"
+	ProxySocks5h = "socks5h"
+	ProxySocks4  = "socks4"
+	ProxySocks4a = "socks4a"
+	ProxySystem  = "system"
+)
+
+func ValidProxyProtocol(v string) bool {
+	switch v {
+	case ProxyHttp, ProxyHttps, ProxySocks5, ProxySocks5h, ProxySocks4, ProxySocks4a, ProxySystem:
+		return true
+	}
+	return false
+}
+
 func (p *Proxy) Addr() string {
 	return net.JoinHostPort(p.Host, strconv.Itoa(int(p.Port)))
 }
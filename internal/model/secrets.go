@@ -94,12 +94,18 @@ func sealTls(d *Tls) (*Tls, error) {
 	if c.Key, err = sealSecret(c.Key); err != nil {
 		return nil, err
 	}
+	if c.KeyPassphrase, err = sealSecret(c.KeyPassphrase); err != nil {
+		return nil, err
+	}
 	return &c, nil
 }
 
 func openTls(d *Tls) error {
 	var err error
-	d.Key, err = openSecret(d.Key)
+	if d.Key, err = openSecret(d.Key); err != nil {
+		return err
+	}
+	d.KeyPassphrase, err = openSecret(d.KeyPassphrase)
 	return err
 }
 
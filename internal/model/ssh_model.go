@@ -1,6 +1,7 @@
 package model
 
 import (
+	"context"
 	"fmt"
 	"net"
 	"os"
@@ -18,6 +19,7 @@ var _ SshModel = (*customSshModel)(nil)
 type (
 	SshModel interface {
 		sshModel
+		FindChain(ctx context.Context, ids []string) ([]*Ssh, error)
 	}
 
 	customSshModel struct {
@@ -31,6 +33,19 @@ func NewSshModel(conn func() scorixsqlx.Conn) SshModel {
 	}
 }
 
+// FindChain loads the ssh profiles of a jump chain, keeping the given order.
+func (m *customSshModel) FindChain(ctx context.Context, ids []string) ([]*Ssh, error) {
+	chain := make([]*Ssh, 0, len(ids))
+	for _, id := range ids {
+		s, err := m.FindOne(ctx, id)
+		if err != nil {
+			return nil, fmt.Errorf("ssh config %s: %w", id, err)
+		}
+		chain = append(chain, s)
+	}
+	return chain, nil
+}
+
 func (s *Ssh) BuildAuthMethod() (ssh.AuthMethod, error) {
 	switch s.Kind {
 	case "password", "PASSWORD":
@@ -71,7 +86,7 @@ func (s *Ssh) BuildAuthMethod() (ssh.AuthMethod, error) {
 	}
 }
 
-func (s *Ssh) BuildClientCfg() (*ssh.ClientConfig, error) {
+func (s *Ssh) BuildClientCfg(hostKeyCallback ssh.HostKeyCallback) (*ssh.ClientConfig, error) {
 	authMethod, err := s.BuildAuthMethod()
 	if err != nil {
 		return nil, err
@@ -81,7 +96,7 @@ func (s *Ssh) BuildClientCfg() (*ssh.ClientConfig, error) {
 		Auth: []ssh.AuthMethod{
 			authMethod,
 		},
-		HostKeyCallback: ssh.InsecureIgnoreHostKey(),
+		HostKeyCallback: hostKeyCallback,
 		Timeout:         time.Duration(s.Timeout) * time.Second,
 	}, nil
 }
@@ -0,0 +1,157 @@
+package model
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/des"
+	"crypto/pbkdf2"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/sha512"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"hash"
+)
+
+var ErrKeyPassphrase = errors.New("incorrect private key passphrase")
+
+var (
+	oidPBES2  = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 5, 13}
+	oidPBKDF2 = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 5, 12}
+
+	pbkdf2PRFs = map[string]func() hash.Hash{
+		"1.2.840.113549.2.7":  sha1.New,
+		"1.2.840.113549.2.9":  sha256.New,
+		"1.2.840.113549.2.10": sha512.New384,
+		"1.2.840.113549.2.11": sha512.New,
+	}
+
+	pbes2Ciphers = map[string]struct {
+		keyLen int
+		block  func([]byte) (cipher.Block, error)
+	}{
+		"2.16.840.1.101.3.4.1.2":  {16, aes.NewCipher},
+		"2.16.840.1.101.3.4.1.22": {24, aes.NewCipher},
+		"2.16.840.1.101.3.4.1.42": {32, aes.NewCipher},
+		"1.2.840.113549.3.7":      {24, des.NewTripleDESCipher},
+	}
+)
+
+type encryptedPrivateKeyInfo struct {
+	Algo pkix.AlgorithmIdentifier
+	Data []byte
+}
+
+type pbes2Params struct {
+	KDF    pkix.AlgorithmIdentifier
+	Scheme pkix.AlgorithmIdentifier
+}
+
+type pbkdf2Params struct {
+	Salt       []byte
+	Iterations int
+	KeyLength  int                      `asn1:"optional"`
+	PRF        pkix.AlgorithmIdentifier `asn1:"optional"`
+}
+
+// tlsKeyPair is tls.X509KeyPair that also takes encrypted keys: legacy PEM
+// with a DEK-Info header, and PKCS#8 "ENCRYPTED PRIVATE KEY" with PBES2,
+// which is what openssl writes by default.
+func tlsKeyPair(certPEM, keyPEM []byte, passphrase string) (tls.Certificate, error) {
+	block, _ := pem.Decode(keyPEM)
+	// x509's legacy PEM encryption is deprecated, but many tools still
+	// write it.
+	legacy := block != nil && x509.IsEncryptedPEMBlock(block)
+	if block == nil || (block.Type != "ENCRYPTED PRIVATE KEY" && !legacy) {
+		return tls.X509KeyPair(certPEM, keyPEM)
+	}
+	if passphrase == "" {
+		return tls.Certificate{}, errors.New("private key is encrypted but no passphrase is set")
+	}
+
+	var (
+		der []byte
+		typ = block.Type
+		err error
+	)
+	if legacy {
+		der, err = x509.DecryptPEMBlock(block, []byte(passphrase))
+		if errors.Is(err, x509.IncorrectPasswordError) {
+			err = ErrKeyPassphrase
+		}
+	} else {
+		der, err = decryptPKCS8(block.Bytes, passphrase)
+		typ = "PRIVATE KEY"
+	}
+	if err != nil {
+		return tls.Certificate{}, fmt.Errorf("decrypt private key: %w", err)
+	}
+	return tls.X509KeyPair(certPEM, pem.EncodeToMemory(&pem.Block{Type: typ, Bytes: der}))
+}
+
+func decryptPKCS8(data []byte, passphrase string) ([]byte, error) {
+	var info encryptedPrivateKeyInfo
+	if _, err := asn1.Unmarshal(data, &info); err != nil {
+		return nil, fmt.Errorf("parse encrypted key: %w", err)
+	}
+	if !info.Algo.Algorithm.Equal(oidPBES2) {
+		return nil, fmt.Errorf("unsupported key encryption %s, only PBES2 is supported", info.Algo.Algorithm)
+	}
+	var params pbes2Params
+	if _, err := asn1.Unmarshal(info.Algo.Parameters.FullBytes, &params); err != nil {
+		return nil, fmt.Errorf("parse PBES2 parameters: %w", err)
+	}
+	if !params.KDF.Algorithm.Equal(oidPBKDF2) {
+		return nil, fmt.Errorf("unsupported key derivation %s, only PBKDF2 is supported", params.KDF.Algorithm)
+	}
+	var kdf pbkdf2Params
+	if _, err := asn1.Unmarshal(params.KDF.Parameters.FullBytes, &kdf); err != nil {
+		return nil, fmt.Errorf("parse PBKDF2 parameters: %w", err)
+	}
+	prf := sha1.New
+	if len(kdf.PRF.Algorithm) > 0 {
+		var ok bool
+		if prf, ok = pbkdf2PRFs[kdf.PRF.Algorithm.String()]; !ok {
+			return nil, fmt.Errorf("unsupported PBKDF2 hash %s", kdf.PRF.Algorithm)
+		}
+	}
+	c, ok := pbes2Ciphers[params.Scheme.Algorithm.String()]
+	if !ok {
+		return nil, fmt.Errorf("unsupported key cipher %s", params.Scheme.Algorithm)
+	}
+	var iv []byte
+	if _, err := asn1.Unmarshal(params.Scheme.Parameters.FullBytes, &iv); err != nil {
+		return nil, fmt.Errorf("parse cipher IV: %w", err)
+	}
+
+	key, err := pbkdf2.Key(prf, passphrase, kdf.Salt, kdf.Iterations, c.keyLen)
+	if err != nil {
+		return nil, err
+	}
+	block, err := c.block(key)
+	if err != nil {
+		return nil, err
+	}
+	if len(iv) != block.BlockSize() || len(info.Data) == 0 || len(info.Data)%block.BlockSize() != 0 {
+		return nil, errors.New("malformed encrypted key")
+	}
+	out := make([]byte, len(info.Data))
+	cipher.NewCBCDecrypter(block, iv).CryptBlocks(out, info.Data)
+
+	// A wrong passphrase shows up as bad padding or a key that does not parse.
+	pad := int(out[len(out)-1])
+	if pad == 0 || pad > block.BlockSize() || !bytes.Equal(out[len(out)-pad:], bytes.Repeat([]byte{byte(pad)}, pad)) {
+		return nil, ErrKeyPassphrase
+	}
+	out = out[:len(out)-pad]
+	if _, err := x509.ParsePKCS8PrivateKey(out); err != nil {
+		return nil, ErrKeyPassphrase
+	}
+	return out, nil
+}
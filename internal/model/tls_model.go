@@ -1,9 +1,13 @@
 package model
 
 import (
+	"crypto/sha256"
 	"crypto/tls"
 	"crypto/x509"
+	"encoding/hex"
 	"fmt"
+	"os"
+	"strings"
 
 	scorixsqlx "github.com/tradalab/scorix/module/sqlx"
 )
@@ -26,6 +30,16 @@ func NewTlsModel(conn func() scorixsqlx.Conn) TlsModel {
 	}
 }
 
+var tlsVersions = map[string]uint16{
+	"1.0": tls.VersionTLS10,
+	"1.1": tls.VersionTLS11,
+	"1.2": tls.VersionTLS12,
+	"1.3": tls.VersionTLS13,
+}
+
+// BuildTlsConfig turns the profile into a client config. Cert and key
+// files are read on every handshake, so a pair rotated on disk is used by
+// the next connection without editing the profile.
 func (t *Tls) BuildTlsConfig() (*tls.Config, error) {
 	cfg := &tls.Config{
 		InsecureSkipVerify: t.Verify == 0,
@@ -35,25 +49,169 @@ func (t *Tls) BuildTlsConfig() (*tls.Config, error) {
 		cfg.ServerName = t.ServerName
 	}
 
-	if t.CaCert != "" {
+	if t.CaCert != "" || t.CaFile != "" {
 		caCertPool := x509.NewCertPool()
-		if ok := caCertPool.AppendCertsFromPEM([]byte(t.CaCert)); !ok {
-			return nil, fmt.Errorf("failed to append ca cert")
+		if t.CaCert != "" {
+			if ok := caCertPool.AppendCertsFromPEM([]byte(t.CaCert)); !ok {
+				return nil, fmt.Errorf("failed to append ca cert")
+			}
+		}
+		if t.CaFile != "" {
+			pem, err := os.ReadFile(t.CaFile)
+			if err != nil {
+				return nil, fmt.Errorf("failed to read ca file: %w", err)
+			}
+			if ok := caCertPool.AppendCertsFromPEM(pem); !ok {
+				return nil, fmt.Errorf("no certificates in ca file %s", t.CaFile)
+			}
 		}
 		cfg.RootCAs = caCertPool
 	}
 
 	if t.ClientAuth > 0 {
-		if t.Cert != "" && t.Key != "" {
-			cert, err := tls.X509KeyPair([]byte(t.Cert), []byte(t.Key))
+		switch {
+		case t.CertFile != "" || t.KeyFile != "":
+			if t.CertFile == "" || t.KeyFile == "" {
+				return nil, fmt.Errorf("client auth needs both a cert file and a key file")
+			}
+			certFile, keyFile, passphrase := t.CertFile, t.KeyFile, t.KeyPassphrase
+			load := func() (*tls.Certificate, error) {
+				certPEM, err := os.ReadFile(certFile)
+				if err != nil {
+					return nil, fmt.Errorf("failed to read client cert: %w", err)
+				}
+				keyPEM, err := os.ReadFile(keyFile)
+				if err != nil {
+					return nil, fmt.Errorf("failed to read client key: %w", err)
+				}
+				cert, err := tlsKeyPair(certPEM, keyPEM, passphrase)
+				if err != nil {
+					return nil, fmt.Errorf("failed to load client cert: %w", err)
+				}
+				return &cert, nil
+			}
+			if _, err := load(); err != nil {
+				return nil, err
+			}
+			cfg.GetClientCertificate = func(*tls.CertificateRequestInfo) (*tls.Certificate, error) {
+				return load()
+			}
+		case t.Cert != "" && t.Key != "":
+			cert, err := tlsKeyPair([]byte(t.Cert), []byte(t.Key), t.KeyPassphrase)
 			if err != nil {
 				return nil, fmt.Errorf("failed to load client cert: %w", err)
 			}
 			cfg.Certificates = []tls.Certificate{cert}
-		} else {
+		default:
 			return nil, fmt.Errorf("client auth enabled but cert/key is missing")
 		}
 	}
 
+	if err := t.applyOptions(cfg); err != nil {
+		return nil, err
+	}
 	return cfg, nil
 }
+
+// CheckOptions validates the versions, cipher suites and pins without
+// reading any file.
+func (t *Tls) CheckOptions() error {
+	return t.applyOptions(&tls.Config{})
+}
+
+func (t *Tls) applyOptions(cfg *tls.Config) error {
+	if t.MinVersion != "" {
+		v, ok := tlsVersions[t.MinVersion]
+		if !ok {
+			return fmt.Errorf("unknown min tls version %q", t.MinVersion)
+		}
+		cfg.MinVersion = v
+	}
+	if t.MaxVersion != "" {
+		v, ok := tlsVersions[t.MaxVersion]
+		if !ok {
+			return fmt.Errorf("unknown max tls version %q", t.MaxVersion)
+		}
+		cfg.MaxVersion = v
+	}
+	if cfg.MinVersion != 0 && cfg.MaxVersion != 0 && cfg.MinVersion > cfg.MaxVersion {
+		return fmt.Errorf("min tls version %s is above max %s", t.MinVersion, t.MaxVersion)
+	}
+
+	// Go does not let TLS 1.3 suites be chosen; the list applies to 1.2 and
+	// below.
+	if t.CipherSuites != "" {
+		ids, err := parseCipherSuites(t.CipherSuites)
+		if err != nil {
+			return err
+		}
+		cfg.CipherSuites = ids
+	}
+
+	if t.PinSha256 != "" {
+		pins, err := ParseTlsPins(t.PinSha256)
+		if err != nil {
+			return err
+		}
+		// Runs after the chain check, and also when verify is off, so a pin
+		// alone can be the trust anchor for a self-signed server.
+		cfg.VerifyConnection = func(cs tls.ConnectionState) error {
+			if len(cs.PeerCertificates) == 0 {
+				return fmt.Errorf("server sent no certificate")
+			}
+			fp := CertFingerprint(cs.PeerCertificates[0])
+			for _, p := range pins {
+				if p == fp {
+					return nil
+				}
+			}
+			return fmt.Errorf("server certificate %s does not match the pinned fingerprint", fp)
+		}
+	}
+	return nil
+}
+
+func parseCipherSuites(s string) ([]uint16, error) {
+	byName := make(map[string]uint16)
+	for _, c := range append(tls.CipherSuites(), tls.InsecureCipherSuites()...) {
+		byName[c.Name] = c.ID
+	}
+	var ids []uint16
+	for _, name := range strings.FieldsFunc(s, func(r rune) bool { return r == ',' || r == ' ' || r == '\n' }) {
+		id, ok := byName[strings.ToUpper(name)]
+		if !ok {
+			return nil, fmt.Errorf("unknown cipher suite %q", name)
+		}
+		ids = append(ids, id)
+	}
+	return ids, nil
+}
+
+// CertFingerprint is the SHA-256 of a certificate's DER, as colon separated
+// upper-case hex like openssl x509 -fingerprint -sha256 prints it.
+func CertFingerprint(c *x509.Certificate) string {
+	sum := sha256.Sum256(c.Raw)
+	return colonHex(sum[:])
+}
+
+// ParseTlsPins reads comma or newline separated SHA-256 fingerprints, with
+// or without colons, into CertFingerprint form.
+func ParseTlsPins(s string) ([]string, error) {
+	var pins []string
+	for _, p := range strings.FieldsFunc(s, func(r rune) bool { return r == ',' || r == ' ' || r == '\n' }) {
+		raw, err := hex.DecodeString(strings.ReplaceAll(p, ":", ""))
+		if err != nil || len(raw) != sha256.Size {
+			return nil, fmt.Errorf("pin %q is not a SHA-256 fingerprint", p)
+		}
+		pins = append(pins, colonHex(raw))
+	}
+	return pins, nil
+}
+
+func colonHex(b []byte) string {
+	parts := make([]string, len(b))
+	for i, c := range b {
+		parts[i] = fmt.Sprintf("%02X", c)
+	}
+	return strings.Join(parts, ":")
+}
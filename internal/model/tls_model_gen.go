@@ -13,11 +13,11 @@ import (
 )
 
 const (
-	tlsFindOneSQL  = "SELECT `id`,`name`,`use_sni`,`server_name`,`verify`,`client_auth`,`ca_cert`,`cert`,`key`,`created_at`,`updated_at`,`deleted_at` FROM `tls` WHERE `id` = ? AND `deleted_at` IS NULL LIMIT 1"
-	tlsFindAllSQL  = "SELECT `id`,`name`,`use_sni`,`server_name`,`verify`,`client_auth`,`ca_cert`,`cert`,`key`,`created_at`,`updated_at`,`deleted_at` FROM `tls` WHERE `deleted_at` IS NULL"
-	tlsFindManySQL = "SELECT `id`,`name`,`use_sni`,`server_name`,`verify`,`client_auth`,`ca_cert`,`cert`,`key`,`created_at`,`updated_at`,`deleted_at` FROM `tls` WHERE `id` IN (?) AND `deleted_at` IS NULL"
-	tlsInsertSQL   = "INSERT INTO `tls` (`id`,`name`,`use_sni`,`server_name`,`verify`,`client_auth`,`ca_cert`,`cert`,`key`,`created_at`,`updated_at`,`deleted_at`) VALUES (?,?,?,?,?,?,?,?,?,?,?,?)"
-	tlsUpdateSQL   = "UPDATE `tls` SET `name` = ?, `use_sni` = ?, `server_name` = ?, `verify` = ?, `client_auth` = ?, `ca_cert` = ?, `cert` = ?, `key` = ?, `updated_at` = ?, `deleted_at` = ? WHERE `id` = ?"
+	tlsFindOneSQL  = "SELECT `id`,`name`,`use_sni`,`server_name`,`verify`,`client_auth`,`ca_cert`,`cert`,`key`,`ca_file`,`cert_file`,`key_file`,`key_passphrase`,`min_version`,`max_version`,`cipher_suites`,`pin_sha256`,`created_at`,`updated_at`,`deleted_at` FROM `tls` WHERE `id` = ? AND `deleted_at` IS NULL LIMIT 1"
+	tlsFindAllSQL  = "SELECT `id`,`name`,`use_sni`,`server_name`,`verify`,`client_auth`,`ca_cert`,`cert`,`key`,`ca_file`,`cert_file`,`key_file`,`key_passphrase`,`min_version`,`max_version`,`cipher_suites`,`pin_sha256`,`created_at`,`updated_at`,`deleted_at` FROM `tls` WHERE `deleted_at` IS NULL"
+	tlsFindManySQL = "SELECT `id`,`name`,`use_sni`,`server_name`,`verify`,`client_auth`,`ca_cert`,`cert`,`key`,`ca_file`,`cert_file`,`key_file`,`key_passphrase`,`min_version`,`max_version`,`cipher_suites`,`pin_sha256`,`created_at`,`updated_at`,`deleted_at` FROM `tls` WHERE `id` IN (?) AND `deleted_at` IS NULL"
+	tlsInsertSQL   = "INSERT INTO `tls` (`id`,`name`,`use_sni`,`server_name`,`verify`,`client_auth`,`ca_cert`,`cert`,`key`,`ca_file`,`cert_file`,`key_file`,`key_passphrase`,`min_version`,`max_version`,`cipher_suites`,`pin_sha256`,`created_at`,`updated_at`,`deleted_at`) VALUES (?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?)"
+	tlsUpdateSQL   = "UPDATE `tls` SET `name` = ?, `use_sni` = ?, `server_name` = ?, `verify` = ?, `client_auth` = ?, `ca_cert` = ?, `cert` = ?, `key` = ?, `ca_file` = ?, `cert_file` = ?, `key_file` = ?, `key_passphrase` = ?, `min_version` = ?, `max_version` = ?, `cipher_suites` = ?, `pin_sha256` = ?, `updated_at` = ?, `deleted_at` = ? WHERE `id` = ?"
 	tlsDeleteSQL   = "UPDATE `tls` SET `deleted_at` = ? WHERE `id` = ?"
 )
 
@@ -40,18 +40,26 @@ type (
 	}
 
 	Tls struct {
-		ID         string       `db:"id" json:"id"`
-		Name       string       `db:"name" json:"name"`
-		UseSni     int64        `db:"use_sni" json:"use_sni"`
-		ServerName string       `db:"server_name" json:"server_name"`
-		Verify     int64        `db:"verify" json:"verify"`
-		ClientAuth int64        `db:"client_auth" json:"client_auth"`
-		CaCert     string       `db:"ca_cert" json:"ca_cert"`
-		Cert       string       `db:"cert" json:"cert"`
-		Key        string       `db:"key" json:"key"`
-		CreatedAt  time.Time    `db:"created_at" json:"created_at"`
-		UpdatedAt  time.Time    `db:"updated_at" json:"updated_at"`
-		DeletedAt  sql.NullTime `db:"deleted_at" json:"deleted_at"`
+		ID            string       `db:"id" json:"id"`
+		Name          string       `db:"name" json:"name"`
+		UseSni        int64        `db:"use_sni" json:"use_sni"`
+		ServerName    string       `db:"server_name" json:"server_name"`
+		Verify        int64        `db:"verify" json:"verify"`
+		ClientAuth    int64        `db:"client_auth" json:"client_auth"`
+		CaCert        string       `db:"ca_cert" json:"ca_cert"`
+		Cert          string       `db:"cert" json:"cert"`
+		Key           string       `db:"key" json:"key"`
+		CaFile        string       `db:"ca_file" json:"ca_file"`
+		CertFile      string       `db:"cert_file" json:"cert_file"`
+		KeyFile       string       `db:"key_file" json:"key_file"`
+		KeyPassphrase string       `db:"key_passphrase" json:"key_passphrase"`
+		MinVersion    string       `db:"min_version" json:"min_version"`
+		MaxVersion    string       `db:"max_version" json:"max_version"`
+		CipherSuites  string       `db:"cipher_suites" json:"cipher_suites"`
+		PinSha256     string       `db:"pin_sha256" json:"pin_sha256"`
+		CreatedAt     time.Time    `db:"created_at" json:"created_at"`
+		UpdatedAt     time.Time    `db:"updated_at" json:"updated_at"`
+		DeletedAt     sql.NullTime `db:"deleted_at" json:"deleted_at"`
 	}
 )
 
@@ -77,6 +85,14 @@ func (m *defaultTlsModel) Insert(ctx context.Context, data *Tls) (sql.Result, er
 		data.CaCert,
 		data.Cert,
 		data.Key,
+		data.CaFile,
+		data.CertFile,
+		data.KeyFile,
+		data.KeyPassphrase,
+		data.MinVersion,
+		data.MaxVersion,
+		data.CipherSuites,
+		data.PinSha256,
 		data.CreatedAt,
 		data.UpdatedAt,
 		data.DeletedAt,
@@ -121,6 +137,14 @@ func (m *defaultTlsModel) Update(ctx context.Context, data *Tls) error {
 		data.CaCert,
 		data.Cert,
 		data.Key,
+		data.CaFile,
+		data.CertFile,
+		data.KeyFile,
+		data.KeyPassphrase,
+		data.MinVersion,
+		data.MaxVersion,
+		data.CipherSuites,
+		data.PinSha256,
 		data.UpdatedAt,
 		data.DeletedAt,
 		data.ID,
@@ -0,0 +1,271 @@
+package svc
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+
+	"github.com/tradalab/rdms/internal/model"
+	"github.com/tradalab/rdms/pkg/netx"
+)
+
+// addrRule is one line of AddrMapping, "source => target" or the older
+// "source=target". The source host is a literal, a glob with * and ? or a
+// CIDR; its port is a number, a range lo-hi or *. The target host may use
+// {host}, and its port {port}, {port+N} or {port-N}; a target without port
+// keeps the source port.
+type addrRule struct {
+	Line string
+
+	host           string
+	cidr           *net.IPNet
+	portLo, portHi int
+
+	toHost   string
+	toPort   int
+	toOffset int
+	keepPort bool
+}
+
+// AddrMapper rewrites the addresses a connection dials. The first matching
+// rule wins.
+type AddrMapper []*addrRule
+
+// ParseAddrMapping parses an AddrMapping text. Lines that do not parse are
+// left out of the mapper and reported together in the error, so callers
+// that must not fail can still use the rest.
+func ParseAddrMapping(s string) (AddrMapper, error) {
+	var (
+		m    AddrMapper
+		errs []error
+	)
+	for i, line := range strings.Split(s, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		r, err := parseAddrRule(line)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("line %d: %w", i+1, err))
+			continue
+		}
+		m = append(m, r)
+	}
+	return m, errors.Join(errs...)
+}
+
+func parseAddrRule(line string) (*addrRule, error) {
+	sep := "=>"
+	if !strings.Contains(line, sep) {
+		sep = "="
+	}
+	kv := netx.SplitKV(line, sep)
+	if len(kv) != 2 || kv[0] == "" || kv[1] == "" {
+		return nil, fmt.Errorf("%q is not source => target", line)
+	}
+	r := &addrRule{Line: line}
+
+	host, port, err := splitAddrPattern(kv[0])
+	if err != nil {
+		return nil, err
+	}
+	if strings.Contains(host, "/") {
+		if _, r.cidr, err = net.ParseCIDR(host); err != nil {
+			return nil, fmt.Errorf("bad CIDR %q", host)
+		}
+	} else {
+		r.host = strings.ToLower(host)
+		if _, err := path.Match(r.host, ""); err != nil {
+			return nil, fmt.Errorf("bad host pattern %q", host)
+		}
+	}
+	if r.portLo, r.portHi, err = parsePortRange(port); err != nil {
+		return nil, err
+	}
+
+	toHost, toPort := kv[1], ""
+	switch {
+	case strings.HasPrefix(toHost, "[") && strings.HasSuffix(toHost, "]"):
+		toHost = toHost[1 : len(toHost)-1]
+	case strings.HasPrefix(toHost, "[") || strings.Count(toHost, ":") == 1:
+		if toHost, toPort, err = splitAddrPattern(kv[1]); err != nil {
+			return nil, err
+		}
+	}
+	if toHost == "" || strings.ContainsAny(strings.ReplaceAll(toHost, "{host}", ""), "{}") {
+		return nil, fmt.Errorf("bad target host %q", toHost)
+	}
+	r.toHost = toHost
+	switch {
+	case toPort == "" || toPort == "*" || toPort == "{port}":
+		r.keepPort = true
+	case strings.HasPrefix(toPort, "{port") && strings.HasSuffix(toPort, "}"):
+		off, err := strconv.Atoi(strings.TrimPrefix(toPort[len("{port"):len(toPort)-1], "+"))
+		if err != nil {
+			return nil, fmt.Errorf("bad port offset %q", toPort)
+		}
+		r.keepPort, r.toOffset = true, off
+	default:
+		if r.toPort, err = strconv.Atoi(toPort); err != nil || r.toPort < 1 || r.toPort > 65535 {
+			return nil, fmt.Errorf("bad target port %q", toPort)
+		}
+	}
+	return r, nil
+}
+
+// splitAddrPattern is net.SplitHostPort for patterns, which may hold a CIDR
+// or a range and so are not addresses.
+func splitAddrPattern(s string) (string, string, error) {
+	if strings.HasPrefix(s, "[") {
+		end := strings.Index(s, "]")
+		if end < 0 || !strings.HasPrefix(s[end+1:], ":") {
+			return "", "", fmt.Errorf("%q is not [host]:port", s)
+		}
+		return s[1:end], s[end+2:], nil
+	}
+	i := strings.LastIndex(s, ":")
+	if i < 0 {
+		return "", "", fmt.Errorf("%q is not host:port", s)
+	}
+	return s[:i], s[i+1:], nil
+}
+
+func parsePortRange(s string) (int, int, error) {
+	if s == "*" {
+		return 1, 65535, nil
+	}
+	lo, hi, isRange := strings.Cut(s, "-")
+	a, err1 := strconv.Atoi(lo)
+	b := a
+	var err2 error
+	if isRange {
+		b, err2 = strconv.Atoi(hi)
+	}
+	if err1 != nil || err2 != nil || a < 1 || b > 65535 || a > b {
+		return 0, 0, fmt.Errorf("bad port %q", s)
+	}
+	return a, b, nil
+}
+
+func (r *addrRule) apply(host string, port int) (string, bool) {
+	if port < r.portLo || port > r.portHi {
+		return "", false
+	}
+	if r.cidr != nil {
+		ip := net.ParseIP(host)
+		if ip == nil || !r.cidr.Contains(ip) {
+			return "", false
+		}
+	} else if ok, _ := path.Match(r.host, strings.ToLower(host)); !ok {
+		return "", false
+	}
+
+	toHost := strings.ReplaceAll(r.toHost, "{host}", host)
+	toPort := r.toPort
+	if r.keepPort {
+		toPort = port + r.toOffset
+	}
+	// An offset that leaves the port range is a rule that does not apply.
+	if toPort < 1 || toPort > 65535 {
+		return "", false
+	}
+	return net.JoinHostPort(toHost, strconv.Itoa(toPort)), true
+}
+
+// Map returns where addr is dialled and the line of the rule that sent it
+// there, or addr itself and "".
+func (m AddrMapper) Map(addr string) (string, string) {
+	host, portStr, err := net.SplitHostPort(addr)
+	if err != nil {
+		return addr, ""
+	}
+	port, err := strconv.Atoi(portStr)
+	if err != nil {
+		return addr, ""
+	}
+	for _, r := range m {
+		if mapped, ok := r.apply(host, port); ok {
+			return mapped, r.Line
+		}
+	}
+	return addr, ""
+}
+
+// AddrMapRow is one node of CLUSTER SLOTS and where AddrMapping sends it.
+// Rule is empty for nodes no rule matches.
+type AddrMapRow struct {
+	Addr     string
+	DialAddr string
+	Rule     string
+	Master   bool
+	Slots    [][2]int
+}
+
+// PreviewAddrMapping connects to the seed of a cluster connection and lists
+// how every node of CLUSTER SLOTS is rewritten by cfg.AddrMapping. Only the
+// seed is dialled, so it works while the mapping is still wrong.
+func (m *ClientManager) PreviewAddrMapping(cfg *model.Connection, sshCfgs []*model.Ssh, proxyCfg *model.Proxy, tlsCfg *model.Tls) ([]*AddrMapRow, error) {
+	if cfg.Mode != "cluster" {
+		return nil, ErrNotCluster
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), time.Duration(cfg.DialTimeout)*time.Second)
+	defer cancel()
+
+	options, tr, err := m.buildOptions(ctx, cfg, sshCfgs, proxyCfg, tlsCfg, 0)
+	if err != nil {
+		return nil, err
+	}
+	defer tr.close()
+	if len(options.Addrs) == 0 {
+		return nil, fmt.Errorf("no seed address")
+	}
+	rdb := redis.NewClient(&redis.Options{
+		Addr:                       options.Addrs[0],
+		Username:                   options.Username,
+		Password:                   options.Password,
+		CredentialsProviderContext: options.CredentialsProviderContext,
+		Dialer:                     options.Dialer,
+		DialTimeout:                options.DialTimeout,
+		ReadTimeout:                options.ReadTimeout,
+		Protocol:                   options.Protocol,
+		PoolSize:                   1,
+	})
+	defer rdb.Close()
+
+	slots, err := rdb.ClusterSlots(ctx).Result()
+	if err != nil {
+		return nil, fmt.Errorf("cluster slots from %s: %w", options.Addrs[0], err)
+	}
+
+	var rows []*AddrMapRow
+	byAddr := make(map[string]*AddrMapRow)
+	for _, s := range slots {
+		for i, n := range s.Nodes {
+			row, ok := byAddr[n.Addr]
+			if !ok {
+				row = &AddrMapRow{Addr: n.Addr, Master: i == 0}
+				row.DialAddr, row.Rule = tr.addrMap.Map(n.Addr)
+				byAddr[n.Addr] = row
+				rows = append(rows, row)
+			}
+			if i == 0 {
+				row.Slots = append(row.Slots, [2]int{s.Start, s.End})
+			}
+		}
+	}
+	sort.SliceStable(rows, func(i, j int) bool {
+		if rows[i].Master != rows[j].Master {
+			return rows[i].Master
+		}
+		return rows[i].Addr < rows[j].Addr
+	})
+	return rows, nil
+}
@@ -0,0 +1,73 @@
+package svc
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestAddrMapping(t *testing.T) {
+	m, err := ParseAddrMapping(strings.Join([]string{
+		"# exact rules keep working",
+		"10.0.0.1:6379=1.2.3.4:6379",
+		"10.0.0.0/16:* => 127.0.0.1:{port+10000}",
+		"redis-*.svc.cluster.local:7000-7005 => {host}.example:{port-1000}",
+		"[fd00::/8]:6379 => [::1]",
+		"*:6379 => 127.0.0.1",
+	}, "\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for addr, want := range map[string]string{
+		"10.0.0.1:6379":                       "1.2.3.4:6379",
+		"10.0.3.7:7001":                       "127.0.0.1:17001",
+		"REDIS-2.svc.cluster.local:7003":      "REDIS-2.svc.cluster.local.example:6003",
+		"redis-2.svc.cluster.local:7006":      "redis-2.svc.cluster.local:7006",
+		"[fd00::5]:6379":                      "[::1]:6379",
+		"192.168.1.9:6379":                    "127.0.0.1:6379",
+		"192.168.1.9:6380":                    "192.168.1.9:6380",
+		"10.1.0.1:7001":                       "10.1.0.1:7001",
+		"not-an-address":                      "not-an-address",
+		"redis-0.svc.cluster.local:7000":      "redis-0.svc.cluster.local.example:6000",
+		"redis-0.other.cluster.local:7000":    "redis-0.other.cluster.local:7000",
+		"redis-0.svc.cluster.local.evil:7000": "redis-0.svc.cluster.local.evil:7000",
+		"10.0.255.255:60000":                  "10.0.255.255:60000", // offset leaves the port range
+	} {
+		if got, _ := m.Map(addr); got != want {
+			t.Errorf("%s => %s, want %s", addr, got, want)
+		}
+	}
+	if _, rule := m.Map("10.0.3.7:7001"); rule != "10.0.0.0/16:* => 127.0.0.1:{port+10000}" {
+		t.Errorf("rule = %q", rule)
+	}
+
+	m, err = ParseAddrMapping("10.0.0.1:6379=1.2.3.4:6379\nbad-line-no-eq\n10.0.0.0/33:* => x\n*:1-0 => x\n*:* => x:{port*2}")
+	if len(m) != 1 || err == nil || strings.Count(err.Error(), "\n") != 3 {
+		t.Errorf("bad lines must be reported and skipped: %d rules, %v", len(m), err)
+	}
+}
+
+func TestPreviewAddrMapping(t *testing.T) {
+	_, cfg := newMiniredis(t)
+	m := NewManager()
+	defer m.CloseAll()
+
+	if _, err := m.PreviewAddrMapping(cfg, nil, nil, nil); !errors.Is(err, ErrNotCluster) {
+		t.Fatalf("standalone: %v", err)
+	}
+
+	cfg.Mode = "cluster"
+	cfg.Addrs = cfg.Addr()
+	cfg.AddrMapping = "127.0.0.1:* => {host}"
+	rows, err := m.PreviewAddrMapping(cfg, nil, nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rows) != 1 || !rows[0].Master || rows[0].DialAddr != cfg.Addrs || rows[0].Rule != cfg.AddrMapping {
+		t.Fatalf("rows = %+v", rows)
+	}
+	if len(rows[0].Slots) != 1 || rows[0].Slots[0] != [2]int{0, 16383} {
+		t.Errorf("slots = %v", rows[0].Slots)
+	}
+}
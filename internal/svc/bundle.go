@@ -0,0 +1,342 @@
+package svc
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"golang.org/x/crypto/argon2"
+
+	scorixsecrets "github.com/tradalab/scorix/secrets"
+
+	"github.com/tradalab/rdms/internal/model"
+)
+
+const (
+	bundleFormat  = "redishub-bundle"
+	bundleVersion = 1
+)
+
+// Argon2id cost of the bundle key. Stored in the envelope so it can be
+// raised later without breaking old files.
+var (
+	bundleKdfTime    uint32 = 3
+	bundleKdfMemory  uint32 = 64 * 1024
+	bundleKdfThreads uint8  = 4
+)
+
+var ErrBundlePassphrase = errors.New("wrong passphrase or corrupted bundle")
+
+// Bundle is the plaintext of an export: connections plus every group and
+// profile they reference, with secrets in the clear.
+type Bundle struct {
+	ExportedAt  time.Time           `json:"exported_at"`
+	Groups      []*model.Group      `json:"groups"`
+	Connections []*model.Connection `json:"connections"`
+	Ssh         []*model.Ssh        `json:"ssh"`
+	Tls         []*model.Tls        `json:"tls"`
+	Proxies     []*model.Proxy      `json:"proxies"`
+}
+
+// bundleEnvelope is the file on disk. The key is derived from the
+// passphrase, never from the machine-bound secrets store, so the file opens
+// anywhere.
+type bundleEnvelope struct {
+	Format     string `json:"format"`
+	Version    int    `json:"version"`
+	Kdf        string `json:"kdf"`
+	Time       uint32 `json:"time"`
+	Memory     uint32 `json:"memory"`
+	Threads    uint8  `json:"threads"`
+	Salt       []byte `json:"salt"`
+	Nonce      []byte `json:"nonce"`
+	Ciphertext []byte `json:"ciphertext"`
+}
+
+func (e *bundleEnvelope) aad() []byte {
+	return fmt.Appendf(nil, "%s/%d/%s/%d/%d/%d", e.Format, e.Version, e.Kdf, e.Time, e.Memory, e.Threads)
+}
+
+func (e *bundleEnvelope) aead(passphrase string) (cipher.AEAD, error) {
+	key := argon2.IDKey([]byte(passphrase), e.Salt, e.Time, e.Memory, e.Threads, 32)
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// SealBundle encrypts b with AES-256-GCM under an Argon2id key.
+func SealBundle(b *Bundle, passphrase string) ([]byte, error) {
+	if passphrase == "" {
+		return nil, errors.New("passphrase is required")
+	}
+	plain, err := json.Marshal(b)
+	if err != nil {
+		return nil, err
+	}
+	env := &bundleEnvelope{
+		Format:  bundleFormat,
+		Version: bundleVersion,
+		Kdf:     "argon2id",
+		Time:    bundleKdfTime,
+		Memory:  bundleKdfMemory,
+		Threads: bundleKdfThreads,
+		Salt:    make([]byte, 16),
+	}
+	if _, err := rand.Read(env.Salt); err != nil {
+		return nil, err
+	}
+	aead, err := env.aead(passphrase)
+	if err != nil {
+		return nil, err
+	}
+	env.Nonce = make([]byte, aead.NonceSize())
+	if _, err := rand.Read(env.Nonce); err != nil {
+		return nil, err
+	}
+	env.Ciphertext = aead.Seal(nil, env.Nonce, plain, env.aad())
+	return json.MarshalIndent(env, "", "  ")
+}
+
+// OpenBundle decrypts a file written by SealBundle.
+func OpenBundle(data []byte, passphrase string) (*Bundle, error) {
+	var env bundleEnvelope
+	if err := json.Unmarshal(data, &env); err != nil || env.Format != bundleFormat {
+		return nil, errors.New("not a redishub bundle")
+	}
+	if env.Version != bundleVersion || env.Kdf != "argon2id" {
+		return nil, fmt.Errorf("unsupported bundle version %d (%s)", env.Version, env.Kdf)
+	}
+	if env.Memory > 1<<20 || env.Time > 16 || env.Threads == 0 {
+		return nil, errors.New("bundle key derivation parameters out of range")
+	}
+	aead, err := env.aead(passphrase)
+	if err != nil {
+		return nil, err
+	}
+	if len(env.Nonce) != aead.NonceSize() {
+		return nil, ErrBundlePassphrase
+	}
+	plain, err := aead.Open(nil, env.Nonce, env.Ciphertext, env.aad())
+	if err != nil {
+		return nil, ErrBundlePassphrase
+	}
+	var b Bundle
+	if err := json.Unmarshal(plain, &b); err != nil {
+		return nil, fmt.Errorf("bundle payload: %w", err)
+	}
+	return &b, nil
+}
+
+// ExportBundle collects the connections with the given IDs (all of them
+// when ids is empty) and the rows they reference.
+func (s *ServiceContext) ExportBundle(ctx context.Context, ids []string) (*Bundle, error) {
+	var (
+		conns []*model.Connection
+		err   error
+	)
+	if len(ids) == 0 {
+		conns, err = s.ConnectionModel.FindAll(ctx)
+	} else {
+		conns, err = s.ConnectionModel.FindMany(ctx, ids)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if len(conns) == 0 {
+		return nil, errors.New("no connections to export")
+	}
+
+	var groupIDs, sshIDs, tlsIDs, proxyIDs []string
+	seen := make(map[string]bool)
+	add := func(list *[]string, id string) {
+		if id != "" && !seen[id] {
+			seen[id] = true
+			*list = append(*list, id)
+		}
+	}
+	for _, c := range conns {
+		add(&groupIDs, c.GroupID)
+		for _, id := range c.SshHops() {
+			add(&sshIDs, id)
+		}
+		add(&tlsIDs, c.TlsID)
+		add(&proxyIDs, c.ProxyID)
+	}
+
+	b := &Bundle{ExportedAt: time.Now().UTC(), Connections: conns}
+	if b.Groups, err = s.GroupModel.FindMany(ctx, groupIDs); err != nil {
+		return nil, err
+	}
+	if b.Ssh, err = s.SshModel.FindMany(ctx, sshIDs); err != nil {
+		return nil, err
+	}
+	if b.Tls, err = s.TlsModel.FindMany(ctx, tlsIDs); err != nil {
+		return nil, err
+	}
+	if b.Proxies, err = s.ProxyModel.FindMany(ctx, proxyIDs); err != nil {
+		return nil, err
+	}
+	if err := b.checkOpen(); err != nil {
+		return nil, err
+	}
+	return b, nil
+}
+
+// checkOpen refuses to export values the local store could not decrypt;
+// they would be useless on any other machine.
+func (b *Bundle) checkOpen() error {
+	sealed := func(kind, name string, values ...string) error {
+		for _, v := range values {
+			if scorixsecrets.IsEncrypted(v) {
+				return fmt.Errorf("%s %q has a secret that cannot be decrypted on this machine", kind, name)
+			}
+		}
+		return nil
+	}
+	for _, c := range b.Connections {
+		if err := sealed("connection", c.Name, c.Password, c.SentinelPassword); err != nil {
+			return err
+		}
+	}
+	for _, h := range b.Ssh {
+		if err := sealed("ssh profile", h.Addr(), h.Password, h.PrivateKey, h.Passphrase); err != nil {
+			return err
+		}
+	}
+	for _, t := range b.Tls {
+		if err := sealed("tls profile", t.Name, t.Key, t.KeyPassphrase); err != nil {
+			return err
+		}
+	}
+	for _, p := range b.Proxies {
+		if err := sealed("proxy", p.Host, p.Password); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+type BundleImportResult struct {
+	Connections int
+	Groups      int
+	Ssh         int
+	Tls         int
+	Proxies     int
+}
+
+// ImportBundle inserts every row of b under fresh IDs and rewrites the
+// references between them, all in one transaction. Groups with a name that
+// already exists are reused. A connection that uses an SSH, TLS or proxy
+// profile missing from b fails the import. Secrets are sealed with the
+// local key by the model inserts.
+func (s *ServiceContext) ImportBundle(ctx context.Context, b *Bundle) (*BundleImportResult, error) {
+	var res *BundleImportResult
+	err := s.WithTx(ctx, func(ctx context.Context) error {
+		var err error
+		res, err = s.importBundle(ctx, b)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+func (s *ServiceContext) importBundle(ctx context.Context, b *Bundle) (*BundleImportResult, error) {
+	res := &BundleImportResult{}
+	ids := make(map[string]string)
+
+	existing, err := s.GroupModel.FindAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+	groupByName := make(map[string]string, len(existing))
+	for _, g := range existing {
+		groupByName[g.Name] = g.ID
+	}
+	for _, g := range b.Groups {
+		if id, ok := groupByName[g.Name]; ok {
+			ids[g.ID] = id
+			continue
+		}
+		row := &model.Group{Name: g.Name}
+		if _, err := s.GroupModel.Insert(ctx, row); err != nil {
+			return res, fmt.Errorf("import group %q: %w", g.Name, err)
+		}
+		ids[g.ID], groupByName[g.Name] = row.ID, row.ID
+		res.Groups++
+	}
+
+	for _, h := range b.Ssh {
+		row := *h
+		row.ID, row.CreatedAt, row.UpdatedAt, row.DeletedAt = "", time.Time{}, time.Time{}, sql.NullTime{}
+		if _, err := s.SshModel.Insert(ctx, &row); err != nil {
+			return res, fmt.Errorf("import ssh profile %s: %w", h.Addr(), err)
+		}
+		ids[h.ID] = row.ID
+		res.Ssh++
+	}
+	for _, t := range b.Tls {
+		row := *t
+		row.ID, row.CreatedAt, row.UpdatedAt, row.DeletedAt = "", time.Time{}, time.Time{}, sql.NullTime{}
+		if _, err := s.TlsModel.Insert(ctx, &row); err != nil {
+			return res, fmt.Errorf("import tls profile %q: %w", t.Name, err)
+		}
+		ids[t.ID] = row.ID
+		res.Tls++
+	}
+	for _, p := range b.Proxies {
+		row := *p
+		row.ID, row.CreatedAt, row.UpdatedAt, row.DeletedAt = "", time.Time{}, time.Time{}, sql.NullTime{}
+		if _, err := s.ProxyModel.Insert(ctx, &row); err != nil {
+			return res, fmt.Errorf("import proxy %s: %w", p.Host, err)
+		}
+		ids[p.ID] = row.ID
+		res.Proxies++
+	}
+
+	remap := func(id string) string { return ids[id] }
+	for _, c := range b.Connections {
+		row := *c
+		row.ID, row.CreatedAt, row.UpdatedAt, row.DeletedAt = "", time.Time{}, time.Time{}, sql.NullTime{}
+		row.GroupID = remap(c.GroupID)
+		row.SshID = remap(c.SshID)
+		row.TlsID = remap(c.TlsID)
+		row.ProxyID = remap(c.ProxyID)
+		var jumps []string
+		for _, id := range c.SshJumps() {
+			if to := remap(id); to != "" {
+				jumps = append(jumps, to)
+			} else if c.SshEnable > 0 {
+				return res, fmt.Errorf("import connection %q: ssh jump host %q is not in the bundle", c.Name, id)
+			}
+		}
+		row.SshJumpIds = strings.Join(jumps, ",")
+		missing := ""
+		switch {
+		case row.SshEnable > 0 && row.SshID == "":
+			missing = "ssh profile"
+		case row.TlsEnable > 0 && row.TlsID == "":
+			missing = "tls profile"
+		case row.ProxyEnable > 0 && row.ProxyID == "":
+			missing = "proxy"
+		}
+		if missing != "" {
+			return res, fmt.Errorf("import connection %q: its %s is not in the bundle", c.Name, missing)
+		}
+		if _, err := s.ConnectionModel.Insert(ctx, &row); err != nil {
+			return res, fmt.Errorf("import connection %q: %w", c.Name, err)
+		}
+		res.Connections++
+	}
+	return res, nil
+}
@@ -0,0 +1,149 @@
+package svc
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/tradalab/rdms/internal/model"
+)
+
+func cheapBundleKdf(t *testing.T) {
+	t.Helper()
+	tm, mem := bundleKdfTime, bundleKdfMemory
+	bundleKdfTime, bundleKdfMemory = 1, 1024
+	t.Cleanup(func() { bundleKdfTime, bundleKdfMemory = tm, mem })
+}
+
+func TestBundleSealOpen(t *testing.T) {
+	cheapBundleKdf(t)
+	b := &Bundle{Connections: []*model.Connection{{ID: "c1", Name: "prod", Password: "hunter2"}}}
+
+	data, err := SealBundle(b, "correct horse")
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := OpenBundle(data, "correct horse")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got.Connections) != 1 || got.Connections[0].Password != "hunter2" {
+		t.Fatalf("round trip lost data: %+v", got.Connections)
+	}
+
+	if _, err := OpenBundle(data, "wrong"); !errors.Is(err, ErrBundlePassphrase) {
+		t.Errorf("wrong passphrase: got %v", err)
+	}
+	if _, err := SealBundle(b, ""); err == nil {
+		t.Error("empty passphrase must be refused")
+	}
+	if _, err := OpenBundle([]byte(`{"format":"other"}`), "x"); err == nil {
+		t.Error("foreign file must be refused")
+	}
+}
+
+type memGroups struct {
+	model.GroupModel
+	rows []*model.Group
+}
+
+func (m *memGroups) FindAll(context.Context) ([]*model.Group, error) { return m.rows, nil }
+func (m *memGroups) Insert(_ context.Context, g *model.Group) (sql.Result, error) {
+	g.ID = fmt.Sprintf("g%d", len(m.rows)+100)
+	m.rows = append(m.rows, g)
+	return nil, nil
+}
+
+type memSshes struct {
+	model.SshModel
+	rows []*model.Ssh
+}
+
+func (m *memSshes) Insert(_ context.Context, h *model.Ssh) (sql.Result, error) {
+	h.ID = fmt.Sprintf("s%d", len(m.rows)+100)
+	m.rows = append(m.rows, h)
+	return nil, nil
+}
+
+type memTlses struct {
+	model.TlsModel
+	rows []*model.Tls
+}
+
+func (m *memTlses) Insert(_ context.Context, t *model.Tls) (sql.Result, error) {
+	t.ID = fmt.Sprintf("t%d", len(m.rows)+100)
+	m.rows = append(m.rows, t)
+	return nil, nil
+}
+
+type memConns struct {
+	model.ConnectionModel
+	rows []*model.Connection
+}
+
+func (m *memConns) Insert(_ context.Context, c *model.Connection) (sql.Result, error) {
+	c.ID = fmt.Sprintf("c%d", len(m.rows)+100)
+	m.rows = append(m.rows, c)
+	return nil, nil
+}
+
+func TestImportBundleRemapsIDs(t *testing.T) {
+	groups := &memGroups{rows: []*model.Group{{ID: "local-team", Name: "Team"}}}
+	sshes, tlses, conns := &memSshes{}, &memTlses{}, &memConns{}
+	s := &ServiceContext{GroupModel: groups, SshModel: sshes, TlsModel: tlses, ConnectionModel: conns}
+
+	b := &Bundle{
+		Groups: []*model.Group{{ID: "g1", Name: "Team"}, {ID: "g2", Name: "Ops"}},
+		Ssh:    []*model.Ssh{{ID: "jump", Host: "j"}, {ID: "bastion", Host: "b"}},
+		Tls:    []*model.Tls{{ID: "tls1", Name: "prod tls", Key: "KEY"}},
+		Connections: []*model.Connection{
+			{ID: "c1", Name: "a", GroupID: "g1", SshID: "bastion", SshJumpIds: "jump", TlsID: "tls1"},
+			{ID: "c2", Name: "b", GroupID: "g2"},
+		},
+	}
+	res, err := s.ImportBundle(context.Background(), b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Connections != 2 || res.Groups != 1 || res.Ssh != 2 || res.Tls != 1 {
+		t.Errorf("counts = %+v", res)
+	}
+
+	a, bc := conns.rows[0], conns.rows[1]
+	if a.ID == "c1" || a.GroupID != "local-team" {
+		t.Errorf("existing group must be reused and ids fresh: %+v", a)
+	}
+	if a.SshID != sshes.rows[1].ID || a.SshJumpIds != sshes.rows[0].ID || a.TlsID != tlses.rows[0].ID {
+		t.Errorf("profile references not remapped: %+v", a)
+	}
+	if bc.GroupID != groups.rows[1].ID {
+		t.Errorf("new group not linked: %+v", bc)
+	}
+}
+
+func TestImportBundleRejectsMissingProfile(t *testing.T) {
+	conns := &memConns{}
+	s := &ServiceContext{GroupModel: &memGroups{}, SshModel: &memSshes{}, TlsModel: &memTlses{}, ConnectionModel: conns}
+
+	b := &Bundle{
+		Tls: []*model.Tls{{ID: "tls1", Name: "prod tls"}},
+		Connections: []*model.Connection{
+			{ID: "c1", Name: "ok", TlsEnable: 1, TlsID: "tls1"},
+			{ID: "c2", Name: "broken", SshEnable: 1, SshID: "gone"},
+		},
+	}
+	if _, err := s.ImportBundle(context.Background(), b); err == nil || !strings.Contains(err.Error(), `"broken"`) {
+		t.Fatalf("err = %v, want the connection with a missing ssh profile named", err)
+	}
+
+	b.Connections[1].SshEnable = 0
+	if _, err := s.ImportBundle(context.Background(), b); err != nil {
+		t.Fatalf("a disabled, dangling reference must not fail the import: %v", err)
+	}
+	if got := conns.rows[len(conns.rows)-1]; got.SshID != "" || got.SshEnable != 0 {
+		t.Errorf("broken = %+v", got)
+	}
+}
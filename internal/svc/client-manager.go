@@ -12,23 +12,52 @@ import (
 
 	"github.com/redis/go-redis/v9"
 	"github.com/tradalab/rdms/internal/model"
-	"github.com/tradalab/rdms/pkg/netx"
 	"golang.org/x/crypto/ssh"
-	"golang.org/x/net/proxy"
 )
 
 type ClientManager struct {
-	mu      sync.RWMutex
-	clients map[string]*Client
+	mu       sync.RWMutex
+	clients  map[string]*Client
+	hostKeys *HostKeyStore
+	emit     func(name string, data any)
+
+	trMu       sync.Mutex
+	transports map[string]*transport
+
+	idleTimeout time.Duration
+	maxActive   int
+	evictOnce   sync.Once
+	stopEvict   context.CancelFunc
 }
 
 func NewManager() *ClientManager {
 	return &ClientManager{
-		clients: make(map[string]*Client),
+		clients:    make(map[string]*Client),
+		transports: make(map[string]*transport),
 	}
 }
 
-func (m *ClientManager) Add(cfg *model.Connection, sshCfg *model.Ssh, proxyCfg *model.Proxy, tlsCfg *model.Tls, dbIdx int) (*Client, error) {
+// SetHostKeys installs the store used to verify SSH tunnel host keys. Without
+// one every SSH connect is refused.
+func (m *ClientManager) SetHostKeys(s *HostKeyStore) {
+	m.hostKeys = s
+}
+
+// SetEmitter installs the sink for connection-state events.
+func (m *ClientManager) SetEmitter(emit func(name string, data any)) {
+	m.emit = emit
+}
+
+func (m *ClientManager) hostKeyCallback(ctx context.Context) ssh.HostKeyCallback {
+	if m.hostKeys == nil {
+		return func(hostname string, _ net.Addr, _ ssh.PublicKey) error {
+			return fmt.Errorf("cannot verify ssh host key for %s: no host key store", hostname)
+		}
+	}
+	return m.hostKeys.Callback(ctx)
+}
+
+func (m *ClientManager) Add(cfg *model.Connection, sshCfgs []*model.Ssh, proxyCfg *model.Proxy, tlsCfg *model.Tls, dbIdx int) (*Client, error) {
 	key := fmt.Sprintf("%s:%d", cfg.ID, dbIdx)
 
 	m.mu.RLock()
@@ -36,7 +65,7 @@ func (m *ClientManager) Add(cfg *model.Connection, sshCfg *model.Ssh, proxyCfg *
 		isStale := false
 		if !cfg.UpdatedAt.Equal(c.Cfg.UpdatedAt) {
 			isStale = true
-		} else if cfg.SshEnable > 0 && sshCfg != nil && c.Ssh != nil && !sshCfg.UpdatedAt.Equal(c.Ssh.UpdatedAt) {
+		} else if cfg.SshEnable > 0 && sshChainChanged(c.Ssh, sshCfgs) {
 			isStale = true
 		} else if cfg.ProxyEnable > 0 && proxyCfg != nil && c.Proxy != nil && !proxyCfg.UpdatedAt.Equal(c.Proxy.UpdatedAt) {
 			isStale = true
@@ -46,6 +75,7 @@ func (m *ClientManager) Add(cfg *model.Connection, sshCfg *model.Ssh, proxyCfg *
 
 		if !isStale {
 			m.mu.RUnlock()
+			c.Touch()
 			return c, nil
 		}
 
@@ -58,48 +88,98 @@ func (m *ClientManager) Add(cfg *model.Connection, sshCfg *model.Ssh, proxyCfg *
 	ctx, cancel := context.WithTimeout(context.Background(), time.Duration(cfg.DialTimeout)*time.Second)
 	defer cancel()
 
-	rdb, err := m.init(ctx, cfg, sshCfg, proxyCfg, tlsCfg, dbIdx)
+	options, err := redisOptions(cfg, sshCfgs, proxyCfg, tlsCfg, dbIdx)
+	if err != nil {
+		return nil, err
+	}
+	tr, err := m.acquireTransport(ctx, cfg, sshCfgs, proxyCfg, tlsCfg)
 	if err != nil {
 		return nil, err
 	}
+	tr.apply(options)
+	rdb := redis.NewUniversalClient(options)
+
+	cli := NewClient(rdb, cfg, sshCfgs, proxyCfg, tlsCfg, dbIdx)
+	cli.transport = tr
+	cli.ReadOnly.Store(cfg.ReadOnly != 0)
+	if cc, ok := rdb.(*redis.ClusterClient); ok {
+		// Per-node clients (ForEachMaster, MasterForKey) bypass ClusterClient
+		// hooks, so the guard must be on every node as well.
+		cc.OnNewNode(func(node *redis.Client) { node.AddHook(&readOnlyHook{cli: cli}) })
+	}
 
 	if err := rdb.Ping(ctx).Err(); err != nil {
-		rdb.Close()
+		cli.close()
 		return nil, fmt.Errorf("cannot connect to redis %s: %w", cfg.Addr(), err)
 	}
 
 	_ = rdb.Do(ctx, "CLIENT", "SETNAME", url.QueryEscape(cfg.Name)).Err()
 
-	cli := NewClient(rdb, cfg, sshCfg, proxyCfg, tlsCfg, dbIdx)
-	cli.ReadOnly.Store(cfg.ReadOnly != 0)
 	cli.writeCmds = buildWriteCmds(ctx, rdb)
 	rdb.AddHook(&readOnlyHook{cli: cli})
 
-	m.mu.Lock()
-	defer m.mu.Unlock()
+	if cfg.Mode == "sentinel" && cfg.ReadsFromReplicas() {
+		// A failover client talks to one node only, so browsing gets a
+		// second, replica-only client. It falls back to the master when no
+		// replica is up.
+		ro := *options
+		ro.ReadOnly = true
+		cli.reader = redis.NewFailoverClient(ro.Failover())
+		cli.reader.AddHook(&readOnlyHook{cli: cli})
+	}
 
+	m.mu.Lock()
 	if c, ok := m.clients[key]; ok {
-		rdb.Close()
+		m.mu.Unlock()
+		cli.close()
 		return c, nil
 	}
 
+	cli.Touch()
 	m.clients[key] = cli
 
+	superviseCtx, stop := context.WithCancel(context.Background())
+	cli.stopSupervise = stop
+	go m.supervise(superviseCtx, cli)
+	m.mu.Unlock()
+
+	m.enforceCap(key)
 	return cli, nil
 }
 
-func (m *ClientManager) init(ctx context.Context, cfg *model.Connection, sshCfg *model.Ssh, proxyCfg *model.Proxy, tlsCfg *model.Tls, dbIdx int) (redis.UniversalClient, error) {
-	options, err := m.buildOptions(ctx, cfg, sshCfg, proxyCfg, tlsCfg, dbIdx)
-	if err != nil {
-		return nil, err
+func sshChainChanged(cur, next []*model.Ssh) bool {
+	if len(next) == 0 || len(cur) == 0 {
+		return false
 	}
+	if len(cur) != len(next) {
+		return true
+	}
+	for i := range next {
+		if cur[i].ID != next[i].ID || !cur[i].UpdatedAt.Equal(next[i].UpdatedAt) {
+			return true
+		}
+	}
+	return false
+}
 
-	rdb := redis.NewUniversalClient(options)
-
-	return rdb, nil
+// buildOptions resolves options on a private transport that is not shared
+// with other clients; the caller closes it.
+func (m *ClientManager) buildOptions(ctx context.Context, cfg *model.Connection, sshCfgs []*model.Ssh, proxyCfg *model.Proxy, tlsCfg *model.Tls, dbIdx int) (*redis.UniversalOptions, *transport, error) {
+	options, err := redisOptions(cfg, sshCfgs, proxyCfg, tlsCfg, dbIdx)
+	if err != nil {
+		return nil, nil, err
+	}
+	tr, err := m.buildTransport(ctx, cfg, sshCfgs, proxyCfg, tlsCfg)
+	if err != nil {
+		return nil, nil, err
+	}
+	tr.apply(options)
+	return options, tr, nil
 }
 
-func (m *ClientManager) buildOptions(ctx context.Context, cfg *model.Connection, sshCfg *model.Ssh, proxyCfg *model.Proxy, tlsCfg *model.Tls, dbIdx int) (*redis.UniversalOptions, error) {
+// redisOptions holds everything that is per logical client: addresses, auth,
+// DB index and timeouts. The network path comes from the transport.
+func redisOptions(cfg *model.Connection, sshCfgs []*model.Ssh, proxyCfg *model.Proxy, tlsCfg *model.Tls, dbIdx int) (*redis.UniversalOptions, error) {
 	options := &redis.UniversalOptions{
 		Addrs:           []string{cfg.Addr()},
 		Username:        cfg.Username,
@@ -113,13 +193,19 @@ func (m *ClientManager) buildOptions(ctx context.Context, cfg *model.Connection,
 		PoolSize:        10,
 	}
 
+	// A provider takes precedence over the stored username and password.
+	// Sentinels keep their own static credentials.
+	if p := newCredProvider(cfg); p != nil {
+		options.CredentialsProviderContext = p.Credentials
+	}
+
 	if cfg.SshEnable > 0 {
 		options.Protocol = 2
 		options.ReadTimeout = -1
 		options.WriteTimeout = -1
 	}
 
-	if cfg.SshEnable > 0 && sshCfg == nil {
+	if cfg.SshEnable > 0 && len(sshCfgs) == 0 {
 		return nil, fmt.Errorf("ssh enabled but ssh config missing (caller must pre-load)")
 	}
 	if cfg.ProxyEnable > 0 && proxyCfg == nil {
@@ -147,6 +233,16 @@ func (m *ClientManager) buildOptions(ctx context.Context, cfg *model.Connection,
 		if len(options.Addrs) == 0 {
 			options.Addrs = []string{net.JoinHostPort(cfg.Host, strconv.Itoa(int(cfg.Port)))}
 		}
+		// The cluster client sends read-only commands by these and every
+		// write to the master, so one client serves both.
+		switch cfg.ReadFrom {
+		case model.ReadFromReplica:
+			options.ReadOnly = true
+		case model.ReadFromLatency:
+			options.RouteByLatency = true
+		case model.ReadFromRandom:
+			options.RouteRandomly = true
+		}
 	default:
 		switch cfg.Network {
 		case "unix":
@@ -174,107 +270,54 @@ func (m *ClientManager) buildOptions(ctx context.Context, cfg *model.Connection,
 		options.Addrs = []string{cfg.Addr()}
 	}
 
-	addrMap := make(map[string]string)
-	if cfg.AddrMapping != "" {
-		lines := append([]string{}, netx.SplitLines(cfg.AddrMapping)...)
-		for _, line := range lines {
-			if kv := netx.SplitKV(line, "="); len(kv) == 2 {
-				addrMap[kv[0]] = kv[1]
-			}
-		}
-	}
+	return options, nil
+}
 
-	var baseDialer interface {
-		DialContext(ctx context.Context, network, addr string) (net.Conn, error)
+// buildTransport dials the network path of a connection: proxy, SSH chain and
+// address mapping, plus the TLS config its dial applies on top.
+func (m *ClientManager) buildTransport(ctx context.Context, cfg *model.Connection, sshCfgs []*model.Ssh, proxyCfg *model.Proxy, tlsCfg *model.Tls) (*transport, error) {
+	dialTimeout := time.Duration(cfg.DialTimeout) * time.Second
+	keepAlive := time.Duration(cfg.ExecTimeout) * time.Second
+	if cfg.SshEnable > 0 {
+		keepAlive = -1
 	}
 
+	// Rules that do not parse are skipped here; upsert rejects them.
+	addrMap, _ := ParseAddrMapping(cfg.AddrMapping)
+
+	var baseDialer ContextDialer
+
 	if cfg.ProxyEnable > 0 && proxyCfg != nil {
-		switch proxyCfg.Protocol {
-		case "socks5":
-			auth := &proxy.Auth{
-				User:     proxyCfg.Username,
-				Password: proxyCfg.Password,
-			}
-			pd, err := proxy.SOCKS5("tcp", proxyCfg.Addr(), auth, proxy.Direct)
-			if err != nil {
-				return nil, fmt.Errorf("socks5 proxy setup failed: %w", err)
-			}
-			if cd, ok := pd.(proxy.ContextDialer); ok {
-				baseDialer = cd
-			} else {
-				baseDialer = netx.NewContextWrapper(pd)
-			}
-		case "http":
-			baseDialer = &netx.HttpConnectDialer{
-				ProxyAddr: proxyCfg.Addr(),
-				Username:  proxyCfg.Username,
-				Password:  proxyCfg.Password,
-				Timeout:   options.DialTimeout,
-			}
-		default:
-			baseDialer = &net.Dialer{
-				Timeout:   options.DialTimeout,
-				KeepAlive: options.ReadTimeout,
-			}
+		d, err := proxyDialer(proxyCfg, dialTimeout)
+		if err != nil {
+			return nil, err
 		}
+		baseDialer = d
 	} else {
 		baseDialer = &net.Dialer{
-			Timeout:   options.DialTimeout,
-			KeepAlive: options.ReadTimeout,
+			Timeout:   dialTimeout,
+			KeepAlive: keepAlive,
 		}
 	}
 
-	var sshClient *ssh.Client
+	var tunnel *sshTunnel
 	if cfg.SshEnable > 0 {
-		sshConfig, err := sshCfg.BuildClientCfg()
-		if err != nil {
+		tunnel = &sshTunnel{dial: func(ctx context.Context) (*SshChain, error) {
+			return DialSshChain(ctx, baseDialer, sshCfgs, func(int) ssh.HostKeyCallback {
+				return m.hostKeyCallback(ctx)
+			})
+		}}
+		if _, err := tunnel.current(ctx); err != nil {
 			return nil, err
 		}
-		sshAddr := sshCfg.Addr()
-
-		sshConn, err := baseDialer.DialContext(ctx, "tcp", sshAddr)
-		if err != nil {
-			return nil, fmt.Errorf("ssh dial failed: %w", err)
-		}
-
-		if d, ok := ctx.Deadline(); ok {
-			_ = sshConn.SetDeadline(d)
-		}
-
-		c, chans, reqs, err := ssh.NewClientConn(sshConn, sshAddr, sshConfig)
-		if err != nil {
-			sshConn.Close()
-			return nil, fmt.Errorf("ssh handshake failed: %w", err)
-		}
-		sshClient = ssh.NewClient(c, chans, reqs)
 	}
 
-	options.Dialer = func(ctx context.Context, network, addr string) (net.Conn, error) {
-		dialAddr := addr
-		if mapped, ok := addrMap[addr]; ok {
-			dialAddr = mapped
-		}
+	tr := &transport{tunnel: tunnel, addrMap: addrMap}
+	tr.rawDial = func(ctx context.Context, network, addr string) (net.Conn, error) {
+		dialAddr := tr.mapAddr(addr)
 
-		if cfg.SshEnable > 0 && sshClient != nil {
-			type dialResult struct {
-				conn net.Conn
-				err  error
-			}
-			ch := make(chan dialResult, 1)
-			go func() {
-				conn, err := sshClient.Dial(network, dialAddr)
-				ch <- dialResult{conn, err}
-			}()
-
-			select {
-			case <-ctx.Done():
-				return nil, ctx.Err()
-			case res := <-ch:
-				if res.err != nil {
-					return nil, res.err
-				}
-				return &netx.IgnoreDeadlineConn{Conn: res.conn}, nil
-			}
+		if tunnel != nil {
+			return tunnel.Dial(ctx, network, dialAddr)
 		}
 
 		conn, err := baseDialer.DialContext(ctx, network, dialAddr)
@@ -287,29 +330,13 @@ func (m *ClientManager) buildOptions(ctx context.Context, cfg *model.Connection,
 	if cfg.TlsEnable > 0 && tlsCfg != nil {
 		tlsConfig, err := tlsCfg.BuildTlsConfig()
 		if err != nil {
+			tr.close()
 			return nil, fmt.Errorf("tls build failed: %w", err)
 		}
-		options.TLSConfig = tlsConfig
+		tr.tls = tlsConfig
 	}
 
-	return options, nil
-}
-
-func (m *ClientManager) Test(cfg *model.Connection, sshCfg *model.Ssh, proxyCfg *model.Proxy, tlsCfg *model.Tls, dbIdx int) error {
-	ctx, cancel := context.WithTimeout(context.Background(), time.Duration(cfg.DialTimeout)*time.Second)
-	defer cancel()
-
-	rdb, err := m.init(ctx, cfg, sshCfg, proxyCfg, tlsCfg, dbIdx)
-	if err != nil {
-		return err
-	}
-	defer rdb.Close()
-
-	if err := rdb.Ping(ctx).Err(); err != nil {
-		return fmt.Errorf("cannot connect to redis %s: %w", cfg.Addr(), err)
-	}
-
-	return nil
+	return tr, nil
 }
 
 func (m *ClientManager) Get(id string, dbIdx int) (*Client, error) {
@@ -319,6 +346,7 @@ func (m *ClientManager) Get(id string, dbIdx int) (*Client, error) {
 	key := fmt.Sprintf("%s:%d", id, dbIdx)
 
 	if c, ok := m.clients[key]; ok {
+		c.Touch()
 		return c, nil
 	}
 
@@ -344,8 +372,7 @@ func (m *ClientManager) Remove(id string, dbIdx int) error {
 	key := fmt.Sprintf("%s:%d", id, dbIdx)
 
 	if c, ok := m.clients[key]; ok {
-		c.closeStreams()
-		_ = c.Rdb.Close()
+		c.close()
 		delete(m.clients, key)
 		return nil
 	}
@@ -355,9 +382,11 @@ func (m *ClientManager) Remove(id string, dbIdx int) error {
 func (m *ClientManager) CloseAll() {
 	m.mu.Lock()
 	defer m.mu.Unlock()
+	if m.stopEvict != nil {
+		m.stopEvict()
+	}
 	for key, c := range m.clients {
-		c.closeStreams()
-		_ = c.Rdb.Close()
+		c.close()
 		delete(m.clients, key)
 	}
 }
@@ -2,7 +2,9 @@ package svc
 
 import (
 	"context"
+	gotls "crypto/tls"
 	"net"
+	"strings"
 	"testing"
 	"time"
 
@@ -23,7 +25,7 @@ func TestClientManager_BuildOptions(t *testing.T) {
 			Username:         "redis_user",
 			Password:         "redis_pass",
 		}
-		opts, err := m.buildOptions(context.TODO(), cfg, nil, nil, nil, 1)
+		opts, _, err := m.buildOptions(context.TODO(), cfg, nil, nil, nil, 1)
 		if err != nil {
 			t.Fatalf("expected no error, got %v", err)
 		}
@@ -61,7 +63,7 @@ func TestClientManager_BuildOptions(t *testing.T) {
 			Mode:  "cluster",
 			Addrs: "127.0.0.1:7000,127.0.0.1:7001",
 		}
-		opts, err := m.buildOptions(context.TODO(), cfg, nil, nil, nil, 0)
+		opts, _, err := m.buildOptions(context.TODO(), cfg, nil, nil, nil, 0)
 		if err != nil {
 			t.Fatalf("expected no error, got %v", err)
 		}
@@ -78,7 +80,7 @@ func TestClientManager_BuildOptions(t *testing.T) {
 
 	t.Run("DialerAlwaysSet", func(t *testing.T) {
 		cfg := &model.Connection{Mode: "standalone", Network: "tcp", Host: "127.0.0.1", Port: 6379}
-		opts, err := m.buildOptions(context.TODO(), cfg, nil, nil, nil, 0)
+		opts, _, err := m.buildOptions(context.TODO(), cfg, nil, nil, nil, 0)
 		if err != nil {
 			t.Fatal(err)
 		}
@@ -97,7 +99,7 @@ func TestClientManager_BuildOptions(t *testing.T) {
 			{"tls", &model.Connection{Network: "tcp", TlsEnable: 1}},
 		}
 		for _, tc := range cases {
-			if _, err := m.buildOptions(context.TODO(), tc.cfg, nil, nil, nil, 0); err == nil {
+			if _, _, err := m.buildOptions(context.TODO(), tc.cfg, nil, nil, nil, 0); err == nil {
 				t.Errorf("%s enabled with nil config must error", tc.name)
 			}
 		}
@@ -106,7 +108,7 @@ func TestClientManager_BuildOptions(t *testing.T) {
 	t.Run("SSHTunnelOptions", func(t *testing.T) {
 		cfg := &model.Connection{Network: "tcp", Host: "10.0.0.5", Port: 6379, SshEnable: 1}
 		ssh := &model.Ssh{Host: "bastion", Port: 22, Username: "u", Kind: "password", Password: "p"}
-		opts, err := m.buildOptions(context.TODO(), cfg, ssh, nil, nil, 0)
+		opts, _, err := m.buildOptions(context.TODO(), cfg, []*model.Ssh{ssh}, nil, nil, 0)
 		if err != nil {
 			t.Skipf("ssh build returned %v (no bastion in unit env) — option-shape check skipped", err)
 		}
@@ -121,16 +123,19 @@ func TestClientManager_BuildOptions(t *testing.T) {
 	t.Run("TLSConfigBuilt", func(t *testing.T) {
 		cfg := &model.Connection{Network: "tcp", Host: "127.0.0.1", Port: 6379, TlsEnable: 1}
 		tls := &model.Tls{Verify: 0}
-		opts, err := m.buildOptions(context.TODO(), cfg, nil, nil, tls, 0)
+		opts, tr, err := m.buildOptions(context.TODO(), cfg, nil, nil, tls, 0)
 		if err != nil {
 			t.Fatalf("tls build: %v", err)
 		}
-		if opts.TLSConfig == nil {
-			t.Fatal("expected TLSConfig to be set when tls enabled")
+		if tr.tls == nil {
+			t.Fatal("expected the transport to carry a TLS config when tls enabled")
 		}
-		if !opts.TLSConfig.InsecureSkipVerify {
+		if !tr.tls.InsecureSkipVerify {
 			t.Error("Verify=0 should yield InsecureSkipVerify=true")
 		}
+		if opts.TLSConfig != nil {
+			t.Error("go-redis ignores TLSConfig next to a Dialer; the dialer must do the handshake")
+		}
 	})
 
 	t.Run("AddrMappingParsed", func(t *testing.T) {
@@ -138,7 +143,7 @@ func TestClientManager_BuildOptions(t *testing.T) {
 			Mode: "standalone", Network: "tcp", Host: "127.0.0.1", Port: 6379,
 			AddrMapping: "10.0.0.1:6379=1.2.3.4:6379\nbad-line-no-eq",
 		}
-		if _, err := m.buildOptions(context.TODO(), cfg, nil, nil, nil, 0); err != nil {
+		if _, _, err := m.buildOptions(context.TODO(), cfg, nil, nil, nil, 0); err != nil {
 			t.Fatalf("addr mapping must parse leniently, got %v", err)
 		}
 	})
@@ -239,3 +244,155 @@ func TestClientManager_CloseAll(t *testing.T) {
 		t.Error("CloseAll must drop every client")
 	}
 }
+
+func TestClientManager_LRUCap(t *testing.T) {
+	_, cfg := newMiniredis(t)
+	m := NewManager()
+	defer m.CloseAll()
+	m.SetLimits(0, 2)
+
+	for db := 0; db < 2; db++ {
+		if _, err := m.Add(cfg, nil, nil, nil, db); err != nil {
+			t.Fatalf("Add db%d: %v", db, err)
+		}
+	}
+	time.Sleep(time.Millisecond)
+	if _, err := m.Get(cfg.ID, 0); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := m.Add(cfg, nil, nil, nil, 2); err != nil {
+		t.Fatalf("Add db2: %v", err)
+	}
+
+	if _, err := m.Get(cfg.ID, 1); err == nil {
+		t.Error("least recently used client (db1) must be evicted")
+	}
+	for _, db := range []int{0, 2} {
+		if _, err := m.Get(cfg.ID, db); err != nil {
+			t.Errorf("db%d must survive: %v", db, err)
+		}
+	}
+}
+
+func TestClientManager_IdleEviction(t *testing.T) {
+	_, cfg := newMiniredis(t)
+	m := NewManager()
+	defer m.CloseAll()
+	m.SetLimits(time.Minute, 0)
+
+	if _, err := m.Add(cfg, nil, nil, nil, 0); err != nil {
+		t.Fatal(err)
+	}
+	streaming, err := m.Add(cfg, nil, nil, nil, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	streaming.MonitorActive = true
+	reporting, err := m.Add(cfg, nil, nil, nil, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	end := reporting.BeginStream()
+
+	m.evictIdle(time.Now().Add(2 * time.Minute))
+
+	if _, err := m.Get(cfg.ID, 0); err == nil {
+		t.Error("idle client must be evicted")
+	}
+	if _, err := m.Get(cfg.ID, 1); err != nil {
+		t.Error("client with a running monitor must not be evicted")
+	}
+	if _, err := m.Get(cfg.ID, 2); err != nil {
+		t.Error("client with a running report stream must not be evicted")
+	}
+	if got := len(m.List()); got != 2 {
+		t.Errorf("List() = %d clients, want 2", got)
+	}
+
+	end()
+	m.evictIdle(time.Now().Add(2 * time.Minute))
+	if _, err := m.Get(cfg.ID, 2); err == nil {
+		t.Error("client must be evictable once its stream ended")
+	}
+}
+
+func TestClientManager_SharedTransport(t *testing.T) {
+	_, cfg := newMiniredis(t)
+	cfg.SshEnable = 1
+	hop := startSshServer(t)
+	m := NewManager()
+	m.SetHostKeys(NewHostKeyStore(&memKnownHosts{rows: map[string]*model.KnownHost{}}, ""))
+	defer m.CloseAll()
+
+	db0, err := m.Add(cfg, []*model.Ssh{hop}, nil, nil, 0)
+	if err != nil {
+		t.Fatalf("Add db0: %v", err)
+	}
+	db1, err := m.Add(cfg, []*model.Ssh{hop}, nil, nil, 1)
+	if err != nil {
+		t.Fatalf("Add db1: %v", err)
+	}
+	if db0.transport != db1.transport {
+		t.Fatal("clients of one connection must share a transport")
+	}
+	if db0.transport.refs != 2 {
+		t.Errorf("refs = %d, want 2", db0.transport.refs)
+	}
+
+	tunnel := db0.tunnel()
+	if err := m.Remove(cfg.ID, 0); err != nil {
+		t.Fatal(err)
+	}
+	if err := tunnel.Keepalive(time.Second); err != nil {
+		t.Fatalf("tunnel must stay up while db1 uses it: %v", err)
+	}
+	if err := db1.Rdb.Ping(context.Background()).Err(); err != nil {
+		t.Fatalf("db1 ping after db0 removed: %v", err)
+	}
+
+	if err := m.Remove(cfg.ID, 1); err != nil {
+		t.Fatal(err)
+	}
+	if err := tunnel.Keepalive(time.Second); err != errTunnelDown {
+		t.Errorf("tunnel must close with its last client, keepalive = %v", err)
+	}
+	if len(m.transports) != 0 {
+		t.Errorf("%d transports left after last client removed", len(m.transports))
+	}
+}
+
+func TestClientManager_TLS(t *testing.T) {
+	cert, caPEM := selfSignedCert(t)
+	mr, err := miniredis.RunTLS(&gotls.Config{Certificates: []gotls.Certificate{cert}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(mr.Close)
+	_, port, _ := net.SplitHostPort(mr.Addr())
+	p, _ := parsePort(port)
+	// The cert is for redis.test: reach it through an address mapping so
+	// the name checked is the unmapped host.
+	cfg := &model.Connection{
+		ID: "tls", Mode: "standalone", Network: "tcp", Host: "redis.test", Port: p,
+		TlsEnable: 1, AddrMapping: "redis.test:" + port + "=" + mr.Addr(), DialTimeout: 5, ExecTimeout: 5,
+	}
+	m := NewManager()
+	defer m.CloseAll()
+
+	cli, err := m.Add(cfg, nil, nil, &model.Tls{Verify: 1, CaCert: caPEM}, 0)
+	if err != nil {
+		t.Fatalf("Add over TLS: %v", err)
+	}
+	if err := cli.Rdb.Set(context.Background(), "k", "v", 0).Err(); err != nil || !mr.DB(0).Exists("k") {
+		t.Fatalf("set over TLS: %v", err)
+	}
+
+	pinned, untrusted := *cfg, *cfg
+	pinned.ID, untrusted.ID = "tls-pin", "tls-untrusted"
+	if _, err := m.Add(&pinned, nil, nil, &model.Tls{PinSha256: strings.Repeat("00", 32)}, 0); err == nil || !strings.Contains(err.Error(), "pinned") {
+		t.Errorf("a wrong pin must fail the connection, got %v", err)
+	}
+	if _, err := m.Add(&untrusted, nil, nil, &model.Tls{Verify: 1}, 0); err == nil || !strings.Contains(err.Error(), "certificate") {
+		t.Errorf("an untrusted certificate must fail the connection, got %v", err)
+	}
+}
@@ -6,6 +6,7 @@ import (
 	"strings"
 	"sync"
 	"sync/atomic"
+	"time"
 
 	"github.com/redis/go-redis/v9"
 	"github.com/tradalab/rdms/internal/model"
@@ -14,7 +15,7 @@ import (
 type Client struct {
 	Rdb           redis.UniversalClient
 	Cfg           *model.Connection
-	Ssh           *model.Ssh
+	Ssh           []*model.Ssh
 	Proxy         *model.Proxy
 	Tls           *model.Tls
 	DbIdx         int
@@ -27,12 +28,86 @@ type Client struct {
 	MonitorMu     sync.Mutex
 	ReadOnly      atomic.Bool
 	writeCmds     map[string]struct{}
+	transport     *transport
+	reader        redis.UniversalClient
+	stopSupervise context.CancelFunc
+	lastUsed      atomic.Int64
+	streams       atomic.Int32
 }
 
-func NewClient(rdb redis.UniversalClient, cfg *model.Connection, ssh *model.Ssh, proxy *model.Proxy, tls *model.Tls, dbIdx int) *Client {
+func NewClient(rdb redis.UniversalClient, cfg *model.Connection, ssh []*model.Ssh, proxy *model.Proxy, tls *model.Tls, dbIdx int) *Client {
 	return &Client{Rdb: rdb, Cfg: cfg, Ssh: ssh, Proxy: proxy, Tls: tls, DbIdx: dbIdx}
 }
 
+// Reader is the client for browsing: key scans, types, sizes and values. It
+// follows the connection's read-from policy; edits must use Rdb.
+func (c *Client) Reader() redis.UniversalClient {
+	if c.reader != nil {
+		return c.reader
+	}
+	return c.Rdb
+}
+
+func (c *Client) tunnel() *sshTunnel {
+	if c.transport == nil {
+		return nil
+	}
+	return c.transport.tunnel
+}
+
+// Touch marks the client as used now; idle eviction and the LRU cap go by it.
+func (c *Client) Touch() {
+	c.lastUsed.Store(time.Now().UnixNano())
+}
+
+func (c *Client) LastUsed() time.Time {
+	return time.Unix(0, c.lastUsed.Load())
+}
+
+// BeginStream marks a long-running stream (a scan report, an export or
+// import, sentinel events) so eviction leaves the client alone until the
+// returned func is called.
+func (c *Client) BeginStream() (end func()) {
+	c.streams.Add(1)
+	c.Touch()
+	return func() {
+		c.streams.Add(-1)
+		c.Touch()
+	}
+}
+
+// StreamState reports whether a monitor and a pubsub stream are running.
+func (c *Client) StreamState() (monitor, pubsub bool) {
+	c.MonitorMu.Lock()
+	monitor = c.MonitorActive
+	c.MonitorMu.Unlock()
+	c.PubSubMu.Lock()
+	pubsub = c.PubSubActive
+	c.PubSubMu.Unlock()
+	return monitor, pubsub
+}
+
+func (c *Client) Streaming() bool {
+	monitor, pubsub := c.StreamState()
+	return monitor || pubsub || c.streams.Load() > 0
+}
+
+// close stops supervision and streams, then the pool, and releases the
+// shared transport.
+func (c *Client) close() {
+	if c.stopSupervise != nil {
+		c.stopSupervise()
+	}
+	c.closeStreams()
+	_ = c.Rdb.Close()
+	if c.reader != nil {
+		_ = c.reader.Close()
+	}
+	if c.transport != nil {
+		c.transport.release()
+	}
+}
+
 func (c *Client) closeStreams() {
 	c.MonitorMu.Lock()
 	if c.MonitorCancel != nil {
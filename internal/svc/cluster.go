@@ -0,0 +1,337 @@
+package svc
+
+import (
+	"context"
+	"errors"
+	"net"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/redis/go-redis/v9"
+)
+
+var ErrNotCluster = errors.New("connection is not in cluster mode")
+
+// clusterInfoWorkers bounds the per-node INFO calls of a topology request.
+const clusterInfoWorkers = 8
+
+// ClusterNode is one line of CLUSTER NODES, completed from CLUSTER SHARDS
+// where the server has it. Addr is what the cluster announces; DialAddr is
+// where AddrMapping sends it.
+type ClusterNode struct {
+	ID          string
+	Addr        string
+	DialAddr    string
+	Hostname    string
+	Master      bool
+	MasterID    string
+	Flags       []string
+	LinkState   string
+	Health      string
+	ConfigEpoch int64
+	ReplOffset  int64
+	Slots       [][2]int
+	Stats       *ClusterNodeStats
+	Err         error
+}
+
+// ClusterNodeStats is the part of INFO memory, stats and keyspace the
+// topology view shows.
+type ClusterNodeStats struct {
+	UsedMemory     int64
+	MaxMemory      int64
+	FragRatio      float64
+	OpsPerSec      int64
+	KeyspaceHits   int64
+	KeyspaceMisses int64
+	EvictedKeys    int64
+	ExpiredKeys    int64
+	Keys           int64
+	Expires        int64
+}
+
+type ClusterShard struct {
+	Master   *ClusterNode
+	Replicas []*ClusterNode
+	Slots    [][2]int
+}
+
+func (s *ClusterShard) SlotCount() int {
+	n := 0
+	for _, r := range s.Slots {
+		n += r[1] - r[0] + 1
+	}
+	return n
+}
+
+type ClusterTopology struct {
+	Shards []*ClusterShard
+	// Source is "shards" when CLUSTER SHARDS answered, "nodes" otherwise.
+	Source string
+}
+
+// ClusterTopology reads the cluster layout and the INFO of every node. Nodes
+// are dialled over the connection's transport, so AddrMapping applies to
+// them as it does to the cluster client.
+func (c *Client) ClusterTopology(ctx context.Context) (*ClusterTopology, error) {
+	cc, ok := c.Rdb.(*redis.ClusterClient)
+	if !ok || c.Cfg.Mode != "cluster" {
+		return nil, ErrNotCluster
+	}
+	text, err := cc.ClusterNodes(ctx).Result()
+	if err != nil {
+		return nil, err
+	}
+	nodes := ParseClusterNodes(text)
+	topo := &ClusterTopology{Source: "nodes"}
+	if shards, err := cc.ClusterShards(ctx).Result(); err == nil {
+		mergeClusterShards(nodes, shards)
+		topo.Source = "shards"
+	}
+	for _, n := range nodes {
+		n.DialAddr = n.Addr
+		if c.transport != nil {
+			n.DialAddr = c.transport.mapAddr(n.Addr)
+		}
+	}
+	topo.Shards = groupClusterShards(nodes)
+
+	opts, err := redisOptions(c.Cfg, c.Ssh, c.Proxy, c.Tls, 0)
+	if err != nil {
+		return nil, err
+	}
+	sem := make(chan struct{}, clusterInfoWorkers)
+	var wg sync.WaitGroup
+	for _, n := range nodes {
+		if n.Addr == "" || hasFlag(n.Flags, "noaddr") {
+			continue
+		}
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(n *ClusterNode) {
+			defer func() { <-sem; wg.Done() }()
+			n.Stats, n.Err = c.clusterNodeStats(ctx, opts, n.Addr)
+		}(n)
+	}
+	wg.Wait()
+	return topo, nil
+}
+
+func (c *Client) clusterNodeStats(ctx context.Context, opts *redis.UniversalOptions, addr string) (*ClusterNodeStats, error) {
+	rdb := redis.NewClient(c.nodeOptions(opts, addr))
+	defer rdb.Close()
+
+	sections := []string{"memory", "stats", "keyspace"}
+	cmds := make([]*redis.StringCmd, len(sections))
+	_, _ = rdb.Pipelined(ctx, func(p redis.Pipeliner) error {
+		for i, s := range sections {
+			cmds[i] = p.Info(ctx, s)
+		}
+		return nil
+	})
+
+	st := &ClusterNodeStats{}
+	var (
+		firstErr error
+		failed   int
+	)
+	for _, cmd := range cmds {
+		if cmd.Err() != nil {
+			if firstErr == nil {
+				firstErr = cmd.Err()
+			}
+			failed++
+			continue
+		}
+		info := parseInfo(cmd.Val())
+		st.UsedMemory = infoInt(info, "used_memory", st.UsedMemory)
+		st.MaxMemory = infoInt(info, "maxmemory", st.MaxMemory)
+		st.OpsPerSec = infoInt(info, "instantaneous_ops_per_sec", st.OpsPerSec)
+		st.KeyspaceHits = infoInt(info, "keyspace_hits", st.KeyspaceHits)
+		st.KeyspaceMisses = infoInt(info, "keyspace_misses", st.KeyspaceMisses)
+		st.EvictedKeys = infoInt(info, "evicted_keys", st.EvictedKeys)
+		st.ExpiredKeys = infoInt(info, "expired_keys", st.ExpiredKeys)
+		if v, ok := info["mem_fragmentation_ratio"]; ok {
+			st.FragRatio, _ = strconv.ParseFloat(v, 64)
+		}
+		for k, v := range info {
+			if !strings.HasPrefix(k, "db") {
+				continue
+			}
+			for _, kv := range strings.Split(v, ",") {
+				name, n, _ := strings.Cut(kv, "=")
+				count, _ := strconv.ParseInt(n, 10, 64)
+				switch name {
+				case "keys":
+					st.Keys += count
+				case "expires":
+					st.Expires += count
+				}
+			}
+		}
+	}
+	if failed == len(cmds) {
+		return nil, firstErr
+	}
+	// Sections the server does not know leave their fields zero; the
+	// error is still reported next to the partial stats.
+	return st, firstErr
+}
+
+// parseInfo reads INFO output into key/value pairs, skipping section headers.
+func parseInfo(text string) map[string]string {
+	out := make(map[string]string)
+	for _, line := range strings.Split(text, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || line[0] == '#' {
+			continue
+		}
+		if k, v, ok := strings.Cut(line, ":"); ok {
+			out[k] = v
+		}
+	}
+	return out
+}
+
+func infoInt(info map[string]string, key string, def int64) int64 {
+	v, ok := info[key]
+	if !ok {
+		return def
+	}
+	n, err := strconv.ParseInt(v, 10, 64)
+	if err != nil {
+		return def
+	}
+	return n
+}
+
+// ParseClusterNodes reads CLUSTER NODES output:
+//
+//	<id> <ip:port@cport[,hostname]> <flags> <master> <ping> <pong> <epoch> <link> <slot>...
+//
+// Slots being imported or migrated ("[...]") are skipped.
+func ParseClusterNodes(text string) []*ClusterNode {
+	var nodes []*ClusterNode
+	for _, line := range strings.Split(text, "\n") {
+		f := strings.Fields(line)
+		if len(f) < 8 {
+			continue
+		}
+		n := &ClusterNode{ID: f[0], Flags: strings.Split(f[2], ","), LinkState: f[7]}
+		addr, host, _ := strings.Cut(f[1], ",")
+		addr, _, _ = strings.Cut(addr, "@")
+		if h, _, err := net.SplitHostPort(addr); err == nil && h != "" {
+			n.Addr = addr
+		}
+		n.Hostname = host
+		n.Master = hasFlag(n.Flags, "master")
+		if f[3] != "-" {
+			n.MasterID = f[3]
+		}
+		n.ConfigEpoch, _ = strconv.ParseInt(f[6], 10, 64)
+		for _, s := range f[8:] {
+			if strings.HasPrefix(s, "[") {
+				continue
+			}
+			lo, hi, isRange := strings.Cut(s, "-")
+			start, err := strconv.Atoi(lo)
+			if err != nil {
+				continue
+			}
+			end := start
+			if isRange {
+				if end, err = strconv.Atoi(hi); err != nil {
+					continue
+				}
+			}
+			n.Slots = append(n.Slots, [2]int{start, end})
+		}
+		nodes = append(nodes, n)
+	}
+	return nodes
+}
+
+// mergeClusterShards copies health, offset and hostname from CLUSTER SHARDS
+// onto the parsed nodes, matching by ID and then by address.
+func mergeClusterShards(nodes []*ClusterNode, shards []redis.ClusterShard) {
+	byID := make(map[string]*ClusterNode, len(nodes))
+	byAddr := make(map[string]*ClusterNode, len(nodes))
+	for _, n := range nodes {
+		byID[n.ID] = n
+		if n.Addr != "" {
+			byAddr[n.Addr] = n
+		}
+	}
+	for _, s := range shards {
+		for _, sn := range s.Nodes {
+			n, ok := byID[sn.ID]
+			if !ok {
+				ip := sn.IP
+				if ip == "" {
+					ip = sn.Endpoint
+				}
+				if n, ok = byAddr[net.JoinHostPort(ip, strconv.FormatInt(sn.Port, 10))]; !ok {
+					continue
+				}
+			}
+			n.Health = sn.Health
+			n.ReplOffset = sn.ReplicationOffset
+			if n.Hostname == "" {
+				n.Hostname = sn.Hostname
+			}
+		}
+	}
+}
+
+// groupClusterShards puts every replica under its master. Replicas whose
+// master is unknown form a shard without a master, so they stay visible.
+func groupClusterShards(nodes []*ClusterNode) []*ClusterShard {
+	var shards []*ClusterShard
+	byMaster := make(map[string]*ClusterShard)
+	for _, n := range nodes {
+		if n.Master {
+			s := &ClusterShard{Master: n, Slots: n.Slots}
+			byMaster[n.ID] = s
+			shards = append(shards, s)
+		}
+	}
+	var orphans *ClusterShard
+	for _, n := range nodes {
+		if n.Master {
+			continue
+		}
+		if s, ok := byMaster[n.MasterID]; ok {
+			s.Replicas = append(s.Replicas, n)
+			continue
+		}
+		if orphans == nil {
+			orphans = &ClusterShard{}
+		}
+		orphans.Replicas = append(orphans.Replicas, n)
+	}
+	sort.SliceStable(shards, func(i, j int) bool {
+		return firstSlot(shards[i]) < firstSlot(shards[j])
+	})
+	if orphans != nil {
+		shards = append(shards, orphans)
+	}
+	return shards
+}
+
+func firstSlot(s *ClusterShard) int {
+	if len(s.Slots) == 0 {
+		return 1 << 30
+	}
+	return s.Slots[0][0]
+}
+
+func hasFlag(flags []string, flag string) bool {
+	for _, f := range flags {
+		if f == flag {
+			return true
+		}
+	}
+	return false
+}
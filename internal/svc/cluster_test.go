@@ -0,0 +1,87 @@
+package svc
+
+import (
+	"context"
+	"testing"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+
+	"github.com/tradalab/rdms/internal/model"
+)
+
+const clusterNodesSample = `07c3 10.0.0.1:6379@16379,redis-a myself,master - 0 0 1 connected 0-5460
+67ed 10.0.0.2:6379@16379 master - 0 1426238316232 2 connected 5461-10922 [5462->-292f]
+292f 10.0.0.3:6379@16379 master - 0 1426238318243 3 connected 10923-16383
+6ec2 10.0.0.4:6379@16379 slave 67ed 0 1426238316232 2 connected
+824f 10.0.0.5:6379@16379 slave,fail 07c3 0 1426238317741 1 disconnected
+e7d1 :0@0 slave,noaddr 9999 0 0 0 disconnected
+`
+
+func TestParseClusterNodes(t *testing.T) {
+	nodes := ParseClusterNodes(clusterNodesSample)
+	if len(nodes) != 6 {
+		t.Fatalf("got %d nodes", len(nodes))
+	}
+	a := nodes[0]
+	if a.Addr != "10.0.0.1:6379" || a.Hostname != "redis-a" || !a.Master || a.ConfigEpoch != 1 || a.Slots[0] != [2]int{0, 5460} {
+		t.Errorf("node a parsed wrong: %+v", a)
+	}
+	if b := nodes[1]; len(b.Slots) != 1 || b.Slots[0] != [2]int{5461, 10922} {
+		t.Errorf("migrating slot must be skipped: %+v", b.Slots)
+	}
+	if e := nodes[4]; e.Master || e.MasterID != "07c3" || e.LinkState != "disconnected" || !hasFlag(e.Flags, "fail") {
+		t.Errorf("failed replica parsed wrong: %+v", e)
+	}
+	if nodes[5].Addr != "" {
+		t.Errorf("noaddr node must have no address: %q", nodes[5].Addr)
+	}
+
+	shards := groupClusterShards(nodes)
+	if len(shards) != 4 {
+		t.Fatalf("got %d shards", len(shards))
+	}
+	if shards[0].Master.ID != "07c3" || len(shards[0].Replicas) != 1 || shards[0].SlotCount() != 5461 {
+		t.Errorf("shard 0 grouped wrong: %+v", shards[0])
+	}
+	if shards[1].Master.ID != "67ed" || shards[1].Replicas[0].ID != "6ec2" {
+		t.Errorf("shard 1 grouped wrong: %+v", shards[1])
+	}
+	if last := shards[3]; last.Master != nil || last.Replicas[0].ID != "e7d1" {
+		t.Errorf("orphan replica must form its own shard: %+v", last)
+	}
+}
+
+func TestParseInfo(t *testing.T) {
+	info := parseInfo("# Memory\r\nused_memory:1024\r\nmem_fragmentation_ratio:1.5\r\n\r\n# Keyspace\r\ndb0:keys=3,expires=1,avg_ttl=0\r\n")
+	if info["used_memory"] != "1024" || info["db0"] != "keys=3,expires=1,avg_ttl=0" || len(info) != 3 {
+		t.Errorf("got %v", info)
+	}
+}
+
+func TestClusterTopologyMiniredis(t *testing.T) {
+	mr := miniredis.RunT(t)
+	cc := redis.NewClusterClient(&redis.ClusterOptions{Addrs: []string{mr.Addr()}})
+	defer cc.Close()
+	c := &Client{Rdb: cc, Cfg: &model.Connection{Mode: "cluster", Addrs: mr.Addr(), DialTimeout: 5, ExecTimeout: 5}}
+
+	topo, err := c.ClusterTopology(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if topo.Source != "shards" || len(topo.Shards) != 1 {
+		t.Fatalf("got %+v", topo)
+	}
+	m := topo.Shards[0].Master
+	if m.Addr != mr.Addr() || m.DialAddr != mr.Addr() || m.Health != "online" || topo.Shards[0].SlotCount() != 16384 {
+		t.Errorf("master = %+v", m)
+	}
+	// miniredis only knows INFO stats, so the node has partial stats and an error.
+	if m.Stats == nil || m.Err == nil {
+		t.Errorf("stats = %+v, err = %v", m.Stats, m.Err)
+	}
+
+	if _, err := (&Client{Rdb: cc, Cfg: &model.Connection{Mode: "standalone"}}).ClusterTopology(context.Background()); err != ErrNotCluster {
+		t.Errorf("standalone: got %v", err)
+	}
+}
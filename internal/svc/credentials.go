@@ -0,0 +1,180 @@
+package svc
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/tradalab/rdms/internal/model"
+)
+
+// credCommandTimeout bounds a credential command when the connection has no
+// dial timeout.
+const credCommandTimeout = 30 * time.Second
+
+// credProvider fetches a connection's username and password from a command
+// or a file. go-redis calls it for every new pool connection, so a token is
+// re-read once the TTL has passed, never mid-connection. Output without a
+// username, such as a bare IAM token, authenticates as the connection's own
+// username: go-redis would otherwise fall back to the default user.
+type credProvider struct {
+	kind     string
+	source   string
+	ttl      time.Duration
+	timeout  time.Duration
+	username string
+}
+
+type credEntry struct {
+	mu       sync.Mutex
+	user     string
+	pass     string
+	fetched  time.Time
+	fetchErr error
+}
+
+// credCache is shared by every client of the same provider, so the DB
+// clients of one connection do not each run the command.
+var credCache = struct {
+	sync.Mutex
+	m map[string]*credEntry
+}{m: make(map[string]*credEntry)}
+
+func newCredProvider(cfg *model.Connection) *credProvider {
+	if cfg.CredProvider == model.CredProviderNone {
+		return nil
+	}
+	timeout := time.Duration(cfg.DialTimeout) * time.Second
+	if timeout <= 0 {
+		timeout = credCommandTimeout
+	}
+	return &credProvider{
+		kind:     cfg.CredProvider,
+		source:   cfg.CredSource,
+		ttl:      time.Duration(cfg.CredTtl) * time.Second,
+		timeout:  timeout,
+		username: cfg.Username,
+	}
+}
+
+// Credentials returns cached credentials while they are younger than the
+// TTL, and fetches them otherwise. A zero TTL fetches every time.
+func (p *credProvider) Credentials(ctx context.Context) (string, string, error) {
+	key := p.kind + "\x00" + p.source
+	credCache.Lock()
+	e, ok := credCache.m[key]
+	if !ok {
+		e = &credEntry{}
+		credCache.m[key] = e
+	}
+	credCache.Unlock()
+
+	// Holding the entry while fetching makes a pool that dials many
+	// connections at once wait for one run of the command.
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if p.ttl <= 0 || e.fetched.IsZero() || time.Since(e.fetched) >= p.ttl {
+		user, pass, err := p.fetch(ctx)
+		if err != nil {
+			e.fetched = time.Time{}
+			return "", "", fmt.Errorf("credential %s provider: %w", p.kind, err)
+		}
+		e.user, e.pass, e.fetched = user, pass, time.Now()
+	}
+	if e.user == "" {
+		return p.username, e.pass, nil
+	}
+	return e.user, e.pass, nil
+}
+
+func (p *credProvider) fetch(ctx context.Context) (string, string, error) {
+	if strings.TrimSpace(p.source) == "" {
+		return "", "", errors.New("no command or file configured")
+	}
+	var out []byte
+	switch p.kind {
+	case model.CredProviderCommand:
+		ctx, cancel := context.WithTimeout(ctx, p.timeout)
+		defer cancel()
+		cmd := credCommand(ctx, p.source)
+		var stderr bytes.Buffer
+		cmd.Stderr = &stderr
+		var err error
+		if out, err = cmd.Output(); err != nil {
+			if ctx.Err() == context.DeadlineExceeded {
+				return "", "", fmt.Errorf("command timed out after %s", p.timeout)
+			}
+			if msg := strings.TrimSpace(stderr.String()); msg != "" {
+				return "", "", fmt.Errorf("command failed: %w: %s", err, truncate(msg, 512))
+			}
+			return "", "", fmt.Errorf("command failed: %w", err)
+		}
+	case model.CredProviderFile:
+		var err error
+		if out, err = os.ReadFile(p.source); err != nil {
+			return "", "", err
+		}
+	default:
+		return "", "", fmt.Errorf("unknown provider %q", p.kind)
+	}
+	return parseCredentials(out)
+}
+
+func credCommand(ctx context.Context, line string) *exec.Cmd {
+	if runtime.GOOS == "windows" {
+		return exec.CommandContext(ctx, "cmd", "/C", line)
+	}
+	return exec.CommandContext(ctx, "sh", "-c", line)
+}
+
+// parseCredentials reads provider output in one of three shapes:
+//
+//	{"username": "app", "password": "..."}   ("token" is taken for password)
+//	app\n<password>
+//	<password>
+func parseCredentials(out []byte) (string, string, error) {
+	text := strings.TrimSpace(string(out))
+	if text == "" {
+		return "", "", errors.New("provider returned nothing")
+	}
+	if strings.HasPrefix(text, "{") {
+		var v struct {
+			Username string `json:"username"`
+			Password string `json:"password"`
+			Token    string `json:"token"`
+		}
+		if err := json.Unmarshal([]byte(text), &v); err != nil {
+			return "", "", fmt.Errorf("parse provider output: %w", err)
+		}
+		if v.Password == "" {
+			v.Password = v.Token
+		}
+		if v.Password == "" {
+			return "", "", errors.New("provider output has no password or token")
+		}
+		return v.Username, v.Password, nil
+	}
+	lines := strings.Split(strings.ReplaceAll(text, "\r\n", "\n"), "\n")
+	switch len(lines) {
+	case 1:
+		return "", lines[0], nil
+	case 2:
+		return strings.TrimSpace(lines[0]), strings.TrimSpace(lines[1]), nil
+	}
+	return "", "", fmt.Errorf("provider output has %d lines, want a password or username and password", len(lines))
+}
+
+func truncate(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	return s[:n] + "..."
+}
@@ -0,0 +1,125 @@
+package svc
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+
+	"github.com/tradalab/rdms/internal/model"
+)
+
+func TestParseCredentials(t *testing.T) {
+	for _, tc := range []struct {
+		in, user, pass string
+	}{
+		{"s3cret\n", "", "s3cret"},
+		{"app\r\ns3cret\r\n", "app", "s3cret"},
+		{`{"username":"app","password":"s3cret"}`, "app", "s3cret"},
+		{`{"token":"tok"}`, "", "tok"},
+	} {
+		user, pass, err := parseCredentials([]byte(tc.in))
+		if err != nil || user != tc.user || pass != tc.pass {
+			t.Errorf("%q: got %q/%q, %v", tc.in, user, pass, err)
+		}
+	}
+	for _, bad := range []string{"", "  \n", `{"username":"app"}`, "a\nb\nc", `{bad`} {
+		if _, _, err := parseCredentials([]byte(bad)); err == nil {
+			t.Errorf("%q must fail", bad)
+		}
+	}
+}
+
+func TestCredProviderFileTTL(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "token")
+	if err := os.WriteFile(path, []byte("first"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	p := newCredProvider(&model.Connection{CredProvider: model.CredProviderFile, CredSource: path, CredTtl: 60})
+
+	_, pass, err := p.Credentials(context.Background())
+	if err != nil || pass != "first" {
+		t.Fatalf("got %q, %v", pass, err)
+	}
+	_ = os.WriteFile(path, []byte("second"), 0o600)
+	if _, pass, _ = p.Credentials(context.Background()); pass != "first" {
+		t.Errorf("within TTL got %q, want the cached token", pass)
+	}
+
+	p.ttl = 0
+	if _, pass, _ = p.Credentials(context.Background()); pass != "second" {
+		t.Errorf("without TTL got %q, want a fresh read", pass)
+	}
+
+	_ = os.Remove(path)
+	if _, _, err = p.Credentials(context.Background()); err == nil || !strings.Contains(err.Error(), "credential file provider") {
+		t.Errorf("missing file: got %v", err)
+	}
+}
+
+func TestCredProviderCommand(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses sh")
+	}
+	mr := miniredis.RunT(t)
+	mr.RequireUserAuth("app", "tok-1")
+
+	cfg := &model.Connection{Network: "tcp", CredProvider: model.CredProviderCommand, CredSource: `printf 'app\ntok-1\n'`, DialTimeout: 5}
+	opts, err := redisOptions(cfg, nil, nil, nil, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rdb := redis.NewClient(&redis.Options{Addr: mr.Addr(), CredentialsProviderContext: opts.CredentialsProviderContext})
+	defer rdb.Close()
+	if err := rdb.Ping(context.Background()).Err(); err != nil {
+		t.Fatalf("ping with provided credentials: %v", err)
+	}
+
+	p := newCredProvider(&model.Connection{CredProvider: model.CredProviderCommand, CredSource: "echo denied >&2; exit 3"})
+	_, _, err = p.Credentials(context.Background())
+	if err == nil || !strings.Contains(err.Error(), "exit status 3: denied") {
+		t.Errorf("failing command: got %v", err)
+	}
+
+	p = &credProvider{kind: model.CredProviderCommand, source: "sleep 5", timeout: 50 * time.Millisecond}
+	if _, _, err = p.Credentials(context.Background()); err == nil || !strings.Contains(err.Error(), "timed out") {
+		t.Errorf("slow command: got %v", err)
+	}
+}
+
+func TestCredProviderTokenKeepsUsername(t *testing.T) {
+	mr := miniredis.RunT(t)
+	mr.RequireUserAuth("app", "iam-token")
+	path := filepath.Join(t.TempDir(), "token")
+	if err := os.WriteFile(path, []byte("iam-token\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg := &model.Connection{Network: "tcp", Username: "app", CredProvider: model.CredProviderFile, CredSource: path}
+	opts, err := redisOptions(cfg, nil, nil, nil, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rdb := redis.NewClient(&redis.Options{Addr: mr.Addr(), CredentialsProviderContext: opts.CredentialsProviderContext})
+	defer rdb.Close()
+	if err := rdb.Ping(context.Background()).Err(); err != nil {
+		t.Fatalf("ping with a token for the configured user: %v", err)
+	}
+
+	// MONITOR connections authenticate through the provider as well; the
+	// server then rejects MONITOR itself, which miniredis lacks.
+	conn, _, err := DialMonitor(context.Background(), rdb.Options())
+	if err == nil {
+		conn.Close()
+		t.Fatal("miniredis has no MONITOR; want it rejected")
+	}
+	if !strings.HasPrefix(err.Error(), "MONITOR rejected") {
+		t.Errorf("DialMonitor = %v, want AUTH to pass", err)
+	}
+}
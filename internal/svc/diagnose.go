@@ -0,0 +1,368 @@
+package svc
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"net"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+
+	"github.com/tradalab/rdms/internal/model"
+)
+
+// DiagStage is one step of a connection test. Skipped stages are not
+// listed; the first failing stage ends the trace.
+type DiagStage struct {
+	Name    string
+	Detail  string
+	Err     error
+	Latency time.Duration
+}
+
+// DiagNode is a node discovered from a sentinel or cluster seed, and
+// whether it answers PING through the connection's transport.
+type DiagNode struct {
+	Addr     string
+	DialAddr string
+	Role     string
+	Err      error
+	Latency  time.Duration
+}
+
+type Diagnosis struct {
+	Stages        []*DiagStage
+	ServerVersion string
+	ServerMode    string
+	Nodes         []*DiagNode
+}
+
+func (d *Diagnosis) OK() bool {
+	for _, s := range d.Stages {
+		if s.Err != nil {
+			return false
+		}
+	}
+	return len(d.Stages) > 0
+}
+
+func (d *Diagnosis) stage(name string, start time.Time, detail string, err error) bool {
+	d.Stages = append(d.Stages, &DiagStage{Name: name, Detail: detail, Err: err, Latency: time.Since(start)})
+	return err == nil
+}
+
+// Diagnose tests a connection stage by stage: DNS, proxy, SSH, TCP, TLS,
+// AUTH and PING against the first seed, then the server version and mode,
+// and for sentinel and cluster every discovered node.
+func (m *ClientManager) Diagnose(cfg *model.Connection, sshCfgs []*model.Ssh, proxyCfg *model.Proxy, tlsCfg *model.Tls) *Diagnosis {
+	timeout := time.Duration(cfg.DialTimeout) * time.Second
+	if timeout <= 0 {
+		timeout = 10 * time.Second
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 4*timeout)
+	defer cancel()
+
+	d := &Diagnosis{}
+	start := time.Now()
+	options, err := redisOptions(cfg, sshCfgs, proxyCfg, tlsCfg, 0)
+	if err != nil {
+		d.stage("config", start, "", err)
+		return d
+	}
+	seed := options.Addrs[0]
+	network := "tcp"
+	if cfg.Mode != "sentinel" && cfg.Mode != "cluster" && cfg.Network == "unix" {
+		network = "unix"
+	}
+
+	if host := firstHopHost(cfg, sshCfgs, proxyCfg, seed); network == "tcp" && host != "" && net.ParseIP(host) == nil {
+		start = time.Now()
+		addrs, err := net.DefaultResolver.LookupHost(ctx, host)
+		if !d.stage("dns", start, host+" → "+strings.Join(addrs, ", "), err) {
+			return d
+		}
+	}
+
+	if cfg.ProxyEnable > 0 && proxyCfg != nil {
+		start = time.Now()
+		rep, err := TestProxy(ctx, proxyCfg, "", timeout)
+		detail := ""
+		if rep != nil {
+			detail = rep.Via
+			for _, s := range rep.Steps {
+				if s.Err != nil && err == nil {
+					err = fmt.Errorf("%s: %w", s.Name, s.Err)
+				}
+			}
+		}
+		if !d.stage("proxy", start, detail, err) {
+			return d
+		}
+	}
+
+	start = time.Now()
+	tr, err := m.buildTransport(ctx, cfg, sshCfgs, proxyCfg, tlsCfg)
+	if cfg.SshEnable > 0 {
+		if !d.stage("ssh", start, fmt.Sprintf("%d hop(s)", len(sshCfgs)), err) {
+			return d
+		}
+	} else if err != nil {
+		d.stage("config", start, "", err)
+		return d
+	}
+	defer tr.close()
+
+	start = time.Now()
+	raw, err := tr.rawDial(ctx, network, seed)
+	if !d.stage("tcp", start, tr.mapAddr(seed), err) {
+		return d
+	}
+	if tr.tls != nil {
+		start = time.Now()
+		conn := tls.Client(raw, tr.tlsFor(seed))
+		err := conn.HandshakeContext(ctx)
+		detail := ""
+		if err == nil {
+			cs := conn.ConnectionState()
+			detail = tls.VersionName(cs.Version) + " " + tls.CipherSuiteName(cs.CipherSuite)
+		}
+		if !d.stage("tls", start, detail, err) {
+			_ = raw.Close()
+			return d
+		}
+		raw = conn
+	}
+
+	// A bare client, so AUTH and PING show up as stages of their own
+	// instead of failing together inside the connection setup. Its first
+	// connection is the one traced above; should it be replaced, the
+	// transport dials the same way again.
+	traced := make(chan net.Conn, 1)
+	traced <- raw
+	rdb := redis.NewClient(&redis.Options{
+		Network: network,
+		Addr:    seed,
+		Dialer: func(ctx context.Context, network, addr string) (net.Conn, error) {
+			select {
+			case c := <-traced:
+				return c, nil
+			default:
+				return tr.dial(ctx, network, addr)
+			}
+		},
+		DialTimeout:     timeout,
+		ReadTimeout:     timeout,
+		WriteTimeout:    timeout,
+		Protocol:        2,
+		DisableIdentity: true,
+		PoolSize:        1,
+	})
+	defer rdb.Close()
+	conn := rdb.Conn()
+	defer conn.Close()
+
+	start = time.Now()
+	user, pass, err := diagCredentials(ctx, cfg, options)
+	if err != nil {
+		d.stage("auth", start, "credential provider", err)
+		return d
+	}
+	if pass != "" {
+		args := []any{"AUTH", pass}
+		detail := "default user"
+		if user != "" {
+			args = []any{"AUTH", user, pass}
+			detail = "user " + user
+		}
+		if !d.stage("auth", start, detail, conn.Do(ctx, args...).Err()) {
+			return d
+		}
+	}
+
+	start = time.Now()
+	if !d.stage("ping", start, "", conn.Ping(ctx).Err()) {
+		return d
+	}
+
+	start = time.Now()
+	info, err := conn.Info(ctx, "server").Result()
+	if err != nil {
+		// Managed services may rename or block INFO; that is no failure.
+		d.stage("server", start, "INFO unavailable: "+err.Error(), nil)
+	} else {
+		kv := parseInfo(info)
+		d.ServerVersion, d.ServerMode = kv["redis_version"], kv["redis_mode"]
+		if d.ServerMode == "" {
+			d.ServerMode = "standalone"
+		}
+		want := cfg.Mode
+		if want == "" {
+			want = "standalone"
+		}
+		var err error
+		if d.ServerMode != want {
+			err = fmt.Errorf("server runs in %s mode but the connection is %s", d.ServerMode, want)
+		}
+		if !d.stage("server", start, "Redis "+d.ServerVersion+", "+d.ServerMode, err) {
+			return d
+		}
+	}
+
+	switch cfg.Mode {
+	case "sentinel":
+		start = time.Now()
+		d.Nodes, err = sentinelNodes(ctx, conn, cfg.SentinelMaster)
+	case "cluster":
+		start = time.Now()
+		d.Nodes, err = clusterNodes(ctx, conn)
+	default:
+		return d
+	}
+	if !d.stage("discover", start, fmt.Sprintf("%d node(s)", len(d.Nodes)), err) {
+		return d
+	}
+
+	start = time.Now()
+	failed := probeNodes(ctx, tr, options, d.Nodes)
+	err = nil
+	if failed > 0 {
+		err = fmt.Errorf("%d of %d node(s) unreachable", failed, len(d.Nodes))
+	}
+	d.stage("nodes", start, "", err)
+	return d
+}
+
+// firstHopHost is the host name the first dial resolves locally: the
+// proxy's, else the first SSH hop's, else the seed's. System proxies
+// resolve elsewhere.
+func firstHopHost(cfg *model.Connection, sshCfgs []*model.Ssh, proxyCfg *model.Proxy, seed string) string {
+	switch {
+	case cfg.ProxyEnable > 0 && proxyCfg != nil:
+		if proxyCfg.Protocol == model.ProxySystem {
+			return ""
+		}
+		return proxyCfg.Host
+	case cfg.SshEnable > 0 && len(sshCfgs) > 0:
+		return sshCfgs[0].Host
+	}
+	host, _, _ := net.SplitHostPort(seed)
+	return host
+}
+
+// diagCredentials returns what AUTH sends to the seed: the sentinel's own
+// credentials in sentinel mode, else the provider's or the stored ones.
+func diagCredentials(ctx context.Context, cfg *model.Connection, o *redis.UniversalOptions) (string, string, error) {
+	if cfg.Mode == "sentinel" {
+		return o.SentinelUsername, o.SentinelPassword, nil
+	}
+	if o.CredentialsProviderContext != nil {
+		return o.CredentialsProviderContext(ctx)
+	}
+	return o.Username, o.Password, nil
+}
+
+func sentinelNodes(ctx context.Context, conn *redis.Conn, master string) ([]*DiagNode, error) {
+	addr, err := conn.Do(ctx, "SENTINEL", "GET-MASTER-ADDR-BY-NAME", master).StringSlice()
+	if err == redis.Nil || (err == nil && len(addr) != 2) {
+		return nil, fmt.Errorf("sentinel does not know master %q", master)
+	}
+	if err != nil {
+		return nil, err
+	}
+	nodes := []*DiagNode{{Addr: net.JoinHostPort(addr[0], addr[1]), Role: "master"}}
+
+	replicas, err := conn.Do(ctx, "SENTINEL", "REPLICAS", master).Slice()
+	if err != nil {
+		return nodes, nil
+	}
+	for _, r := range replicas {
+		kv := sentinelKV(r)
+		if kv["ip"] == "" || strings.Contains(kv["flags"], "disconnected") {
+			continue
+		}
+		nodes = append(nodes, &DiagNode{Addr: net.JoinHostPort(kv["ip"], kv["port"]), Role: "replica"})
+	}
+	return nodes, nil
+}
+
+// sentinelKV reads one entry of SENTINEL REPLICAS, a flat list in RESP2
+// and a map in RESP3.
+func sentinelKV(v any) map[string]string {
+	out := make(map[string]string)
+	switch e := v.(type) {
+	case []any:
+		for i := 0; i+1 < len(e); i += 2 {
+			out[fmt.Sprint(e[i])] = fmt.Sprint(e[i+1])
+		}
+	case map[any]any:
+		for k, v := range e {
+			out[fmt.Sprint(k)] = fmt.Sprint(v)
+		}
+	}
+	return out
+}
+
+func clusterNodes(ctx context.Context, conn *redis.Conn) ([]*DiagNode, error) {
+	text, err := conn.ClusterNodes(ctx).Result()
+	if err != nil {
+		return nil, err
+	}
+	var nodes []*DiagNode
+	for _, n := range ParseClusterNodes(text) {
+		if n.Addr == "" || hasFlag(n.Flags, "noaddr") {
+			continue
+		}
+		role := "replica"
+		if n.Master {
+			role = "master"
+		}
+		nodes = append(nodes, &DiagNode{Addr: n.Addr, Role: role})
+	}
+	return nodes, nil
+}
+
+// probeNodes PINGs every node with the data credentials over tr and
+// returns how many failed.
+func probeNodes(ctx context.Context, tr *transport, o *redis.UniversalOptions, nodes []*DiagNode) int {
+	var (
+		wg     sync.WaitGroup
+		mu     sync.Mutex
+		failed int
+		sem    = make(chan struct{}, clusterInfoWorkers)
+	)
+	for _, n := range nodes {
+		n.DialAddr = tr.mapAddr(n.Addr)
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(n *DiagNode) {
+			defer func() { <-sem; wg.Done() }()
+			rdb := redis.NewClient(&redis.Options{
+				Addr:                       n.Addr,
+				Username:                   o.Username,
+				Password:                   o.Password,
+				CredentialsProviderContext: o.CredentialsProviderContext,
+				Dialer:                     tr.dial,
+				DialTimeout:                o.DialTimeout,
+				ReadTimeout:                o.DialTimeout,
+				Protocol:                   2,
+				DisableIdentity:            true,
+				PoolSize:                   1,
+				MaxRetries:                 -1,
+			})
+			defer rdb.Close()
+			start := time.Now()
+			n.Err = rdb.Ping(ctx).Err()
+			n.Latency = time.Since(start)
+			if n.Err != nil {
+				mu.Lock()
+				failed++
+				mu.Unlock()
+			}
+		}(n)
+	}
+	wg.Wait()
+	return failed
+}
@@ -0,0 +1,76 @@
+package svc
+
+import (
+	"crypto/tls"
+	"net"
+	"strings"
+	"testing"
+
+	"github.com/alicebob/miniredis/v2"
+
+	"github.com/tradalab/rdms/internal/model"
+)
+
+func stageNames(d *Diagnosis) string {
+	var names []string
+	for _, s := range d.Stages {
+		name := s.Name
+		if s.Err != nil {
+			name += "!"
+		}
+		names = append(names, name)
+	}
+	return strings.Join(names, ",")
+}
+
+func TestDiagnose(t *testing.T) {
+	s, cfg := newMiniredis(t)
+	s.RequireAuth("secret")
+	m := NewManager()
+	defer m.CloseAll()
+
+	cfg.Password = "secret"
+	d := m.Diagnose(cfg, nil, nil, nil)
+	if !d.OK() || !strings.HasPrefix(stageNames(d), "tcp,auth,ping,server") {
+		t.Fatalf("stages = %s", stageNames(d))
+	}
+
+	cfg.Password = "wrong"
+	d = m.Diagnose(cfg, nil, nil, nil)
+	if d.OK() || stageNames(d) != "tcp,auth!" {
+		t.Errorf("wrong password: %s", stageNames(d))
+	}
+
+	cfg.Mode, cfg.Addrs, cfg.Password = "cluster", cfg.Addr(), "secret"
+	d = m.Diagnose(cfg, nil, nil, nil)
+	if len(d.Nodes) != 1 || d.Nodes[0].Err != nil || d.Nodes[0].Role != "master" {
+		t.Errorf("cluster nodes = %s %+v", stageNames(d), d.Nodes)
+	}
+
+	s.Close()
+	d = m.Diagnose(cfg, nil, nil, nil)
+	if stageNames(d) != "tcp!" {
+		t.Errorf("closed server: %s", stageNames(d))
+	}
+}
+
+func TestDiagnoseTLS(t *testing.T) {
+	cert, _ := selfSignedCert(t)
+	s, err := miniredis.RunTLS(&tls.Config{Certificates: []tls.Certificate{cert}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(s.Close)
+	s.RequireAuth("secret")
+	_, cfg := newMiniredis(t)
+	host, port, _ := net.SplitHostPort(s.Addr())
+	p, _ := parsePort(port)
+	cfg.Host, cfg.Port, cfg.Password, cfg.TlsEnable = host, p, "secret", 1
+	m := NewManager()
+	defer m.CloseAll()
+
+	d := m.Diagnose(cfg, nil, nil, &model.Tls{})
+	if !d.OK() || !strings.HasPrefix(stageNames(d), "tcp,tls,auth,ping,server") {
+		t.Fatalf("stages over TLS = %s", stageNames(d))
+	}
+}
@@ -0,0 +1,137 @@
+package svc
+
+import (
+	"context"
+	"sort"
+	"strconv"
+	"time"
+)
+
+const (
+	ClientStateEvicted = "evicted"
+
+	SettingClientIdleTimeout = "client_idle_timeout" // seconds, 0 disables
+	SettingClientMaxActive   = "client_max_active"   // 0 disables
+
+	DefaultClientIdleTimeout = 30 * time.Minute
+	DefaultClientMaxActive   = 64
+)
+
+// SetLimits configures idle eviction and the cap on live clients, and starts
+// the eviction loop on first use. Zero disables the respective limit.
+func (m *ClientManager) SetLimits(idle time.Duration, maxActive int) {
+	m.mu.Lock()
+	m.idleTimeout = idle
+	m.maxActive = maxActive
+	m.mu.Unlock()
+
+	m.evictOnce.Do(func() {
+		ctx, stop := context.WithCancel(context.Background())
+		m.stopEvict = stop
+		go m.evictLoop(ctx)
+	})
+	m.enforceCap("")
+}
+
+func (m *ClientManager) evictLoop(ctx context.Context) {
+	ticker := time.NewTicker(time.Minute)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			m.evictIdle(time.Now())
+		}
+	}
+}
+
+// evictIdle closes clients unused for longer than the idle timeout. Clients
+// with a running monitor, pubsub or other stream are never idle.
+func (m *ClientManager) evictIdle(now time.Time) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.idleTimeout <= 0 {
+		return
+	}
+	for key, c := range m.clients {
+		if c.Streaming() || now.Sub(c.LastUsed()) < m.idleTimeout {
+			continue
+		}
+		m.evictLocked(key, c)
+	}
+}
+
+// enforceCap evicts least recently used clients until the cap holds. keep is
+// a client key that must survive (the one just added).
+func (m *ClientManager) enforceCap(keep string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.maxActive <= 0 || len(m.clients) <= m.maxActive {
+		return
+	}
+
+	type entry struct {
+		key string
+		c   *Client
+	}
+	var candidates []entry
+	for key, c := range m.clients {
+		if key != keep && !c.Streaming() {
+			candidates = append(candidates, entry{key, c})
+		}
+	}
+	sort.Slice(candidates, func(i, j int) bool {
+		return candidates[i].c.LastUsed().Before(candidates[j].c.LastUsed())
+	})
+	for _, e := range candidates {
+		if len(m.clients) <= m.maxActive {
+			return
+		}
+		m.evictLocked(e.key, e.c)
+	}
+}
+
+func (m *ClientManager) evictLocked(key string, c *Client) {
+	c.close()
+	delete(m.clients, key)
+	m.emitState(c, ClientStateEvicted, 0, nil)
+}
+
+// List returns the live clients, most recently used first.
+func (m *ClientManager) List() []*Client {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	out := make([]*Client, 0, len(m.clients))
+	for _, c := range m.clients {
+		out = append(out, c)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].LastUsed().After(out[j].LastUsed()) })
+	return out
+}
+
+// ApplyClientLimits loads the eviction settings, falling back to defaults for
+// missing or malformed values.
+func (s *ServiceContext) ApplyClientLimits(ctx context.Context) {
+	idle := DefaultClientIdleTimeout
+	if v, ok := s.intSetting(ctx, SettingClientIdleTimeout); ok {
+		idle = time.Duration(v) * time.Second
+	}
+	maxActive := DefaultClientMaxActive
+	if v, ok := s.intSetting(ctx, SettingClientMaxActive); ok {
+		maxActive = v
+	}
+	s.RedisManager.SetLimits(idle, maxActive)
+}
+
+func (s *ServiceContext) intSetting(ctx context.Context, key string) (int, bool) {
+	row, err := s.SettingModel.FindOneByKey(ctx, key)
+	if err != nil {
+		return 0, false
+	}
+	v, err := strconv.Atoi(row.Value)
+	if err != nil || v < 0 {
+		return 0, false
+	}
+	return v, true
+}
@@ -0,0 +1,195 @@
+package svc
+
+import (
+	"context"
+	"database/sql"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/knownhosts"
+
+	"github.com/tradalab/rdms/internal/model"
+)
+
+const (
+	HostKeyTrusted  = "trusted"
+	HostKeyUnknown  = "unknown"
+	HostKeyMismatch = "mismatch"
+)
+
+// HostKeyMismatchError is returned by the connect callback when a server
+// presents a key that differs from the one on record.
+type HostKeyMismatchError struct {
+	Host     string
+	Got      string
+	Expected string
+}
+
+func (e *HostKeyMismatchError) Error() string {
+	return fmt.Sprintf("ssh host key for %s has changed: server presented %s, expected %s "+
+		"(verify the server and approve the new key with ssh test)", e.Host, e.Got, e.Expected)
+}
+
+// HostKeyCheck is the outcome of matching a presented key against the store.
+type HostKeyCheck struct {
+	Host             string
+	Status           string
+	KeyType          string
+	Fingerprint      string
+	KnownFingerprint string
+}
+
+// HostKeyStore verifies SSH host keys against the app database, falling back
+// to the user's known_hosts files for hosts the app has not seen yet.
+type HostKeyStore struct {
+	model model.KnownHostModel
+	files []string
+}
+
+func NewHostKeyStore(m model.KnownHostModel, files ...string) *HostKeyStore {
+	if len(files) == 0 {
+		files = defaultKnownHostsFiles()
+	}
+	return &HostKeyStore{model: m, files: files}
+}
+
+func defaultKnownHostsFiles() []string {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return nil
+	}
+	return []string{filepath.Join(home, ".ssh", "known_hosts")}
+}
+
+// Check reports whether key is the known key for addr (host:port). A match
+// found only in a known_hosts file is imported so later checks hit the DB.
+func (s *HostKeyStore) Check(ctx context.Context, addr string, key ssh.PublicKey) (*HostKeyCheck, error) {
+	host := knownhosts.Normalize(addr)
+	res := &HostKeyCheck{
+		Host:        host,
+		KeyType:     key.Type(),
+		Fingerprint: ssh.FingerprintSHA256(key),
+	}
+
+	row, err := s.model.FindOneByHost(ctx, host)
+	switch {
+	case err == nil:
+		res.KnownFingerprint = row.Fingerprint
+		if row.Fingerprint == res.Fingerprint {
+			res.Status = HostKeyTrusted
+		} else {
+			res.Status = HostKeyMismatch
+		}
+		return res, nil
+	case !errors.Is(err, sql.ErrNoRows):
+		return nil, fmt.Errorf("load known host %s: %w", host, err)
+	}
+
+	res.Status = HostKeyUnknown
+	known, err := s.checkFiles(addr, key)
+	if err != nil {
+		return nil, err
+	}
+	if known == nil {
+		return res, nil
+	}
+	res.KnownFingerprint = ssh.FingerprintSHA256(known)
+	if res.KnownFingerprint != res.Fingerprint {
+		res.Status = HostKeyMismatch
+		return res, nil
+	}
+	if err := s.Trust(ctx, addr, key); err != nil {
+		return nil, err
+	}
+	res.Status = HostKeyTrusted
+	return res, nil
+}
+
+// checkFiles looks addr up in the known_hosts files. It returns the presented
+// key on a match, the recorded key on a mismatch and nil when the host is not
+// listed.
+func (s *HostKeyStore) checkFiles(addr string, key ssh.PublicKey) (ssh.PublicKey, error) {
+	var files []string
+	for _, f := range s.files {
+		if _, err := os.Stat(f); err == nil {
+			files = append(files, f)
+		}
+	}
+	if len(files) == 0 {
+		return nil, nil
+	}
+	cb, err := knownhosts.New(files...)
+	if err != nil {
+		return nil, fmt.Errorf("read known_hosts: %w", err)
+	}
+
+	// knownhosts prefers the hostname; the remote address only has to parse.
+	err = cb(addr, &net.TCPAddr{IP: net.IPv4zero}, key)
+	if err == nil {
+		return key, nil
+	}
+	var keyErr *knownhosts.KeyError
+	if errors.As(err, &keyErr) {
+		if len(keyErr.Want) == 0 {
+			return nil, nil
+		}
+		return keyErr.Want[0].Key, nil
+	}
+	var revoked *knownhosts.RevokedError
+	if errors.As(err, &revoked) {
+		return nil, fmt.Errorf("ssh host key for %s is revoked in %s", addr, revoked.Revoked.Filename)
+	}
+	return nil, err
+}
+
+// Trust records key as the known key for addr, replacing any previous one.
+func (s *HostKeyStore) Trust(ctx context.Context, addr string, key ssh.PublicKey) error {
+	host := knownhosts.Normalize(addr)
+	row, err := s.model.FindOneByHost(ctx, host)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return fmt.Errorf("load known host %s: %w", host, err)
+	}
+
+	data := &model.KnownHost{
+		Host:        host,
+		KeyType:     key.Type(),
+		PublicKey:   base64.StdEncoding.EncodeToString(key.Marshal()),
+		Fingerprint: ssh.FingerprintSHA256(key),
+	}
+	if err == nil {
+		data.ID = row.ID
+		data.CreatedAt = row.CreatedAt
+		if err := s.model.Update(ctx, data); err != nil {
+			return fmt.Errorf("update known host %s: %w", host, err)
+		}
+		return nil
+	}
+	if _, err := s.model.Insert(ctx, data); err != nil {
+		return fmt.Errorf("save known host %s: %w", host, err)
+	}
+	return nil
+}
+
+// Callback is the trust-on-first-use verifier used when connecting: unknown
+// hosts are recorded, changed keys are refused.
+func (s *HostKeyStore) Callback(ctx context.Context) ssh.HostKeyCallback {
+	return func(hostname string, _ net.Addr, key ssh.PublicKey) error {
+		res, err := s.Check(ctx, hostname, key)
+		if err != nil {
+			return err
+		}
+		switch res.Status {
+		case HostKeyTrusted:
+			return nil
+		case HostKeyUnknown:
+			return s.Trust(ctx, hostname, key)
+		default:
+			return &HostKeyMismatchError{Host: res.Host, Got: res.Fingerprint, Expected: res.KnownFingerprint}
+		}
+	}
+}
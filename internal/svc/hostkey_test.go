@@ -0,0 +1,131 @@
+package svc
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"database/sql"
+	"errors"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/knownhosts"
+
+	"github.com/tradalab/rdms/internal/model"
+)
+
+type memKnownHosts struct {
+	model.KnownHostModel
+	rows map[string]*model.KnownHost
+}
+
+func (m *memKnownHosts) FindOneByHost(_ context.Context, host string) (*model.KnownHost, error) {
+	if r, ok := m.rows[host]; ok {
+		return r, nil
+	}
+	return nil, sql.ErrNoRows
+}
+
+func (m *memKnownHosts) Insert(_ context.Context, data *model.KnownHost) (sql.Result, error) {
+	m.rows[data.Host] = data
+	return nil, nil
+}
+
+func (m *memKnownHosts) Update(_ context.Context, data *model.KnownHost) error {
+	m.rows[data.Host] = data
+	return nil
+}
+
+func newHostKey(t *testing.T) ssh.PublicKey {
+	t.Helper()
+	pub, _, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	key, err := ssh.NewPublicKey(pub)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return key
+}
+
+func TestHostKeyStoreTrustOnFirstUse(t *testing.T) {
+	ctx := context.Background()
+	db := &memKnownHosts{rows: map[string]*model.KnownHost{}}
+	store := NewHostKeyStore(db, filepath.Join(t.TempDir(), "missing"))
+	cb := store.Callback(ctx)
+	remote := &net.TCPAddr{IP: net.IPv4(10, 0, 0, 1), Port: 2222}
+
+	first := newHostKey(t)
+	if err := cb("bastion:2222", remote, first); err != nil {
+		t.Fatalf("first connect: %v", err)
+	}
+	row := db.rows["[bastion]:2222"]
+	if row == nil || row.Fingerprint != ssh.FingerprintSHA256(first) {
+		t.Fatalf("first connect did not record key: %+v", row)
+	}
+	if err := cb("bastion:2222", remote, first); err != nil {
+		t.Fatalf("reconnect with same key: %v", err)
+	}
+
+	second := newHostKey(t)
+	err := cb("bastion:2222", remote, second)
+	var mismatch *HostKeyMismatchError
+	if !errors.As(err, &mismatch) {
+		t.Fatalf("expected mismatch error, got %v", err)
+	}
+	msg := err.Error()
+	if !strings.Contains(msg, ssh.FingerprintSHA256(first)) || !strings.Contains(msg, ssh.FingerprintSHA256(second)) {
+		t.Fatalf("mismatch error should show both fingerprints: %s", msg)
+	}
+
+	if err := store.Trust(ctx, "bastion:2222", second); err != nil {
+		t.Fatal(err)
+	}
+	if err := cb("bastion:2222", remote, second); err != nil {
+		t.Fatalf("connect after approval: %v", err)
+	}
+}
+
+func TestHostKeyStoreImportsKnownHostsFile(t *testing.T) {
+	ctx := context.Background()
+	key := newHostKey(t)
+	file := filepath.Join(t.TempDir(), "known_hosts")
+	line := knownhosts.Line([]string{"jump.example.com"}, key)
+	if err := os.WriteFile(file, []byte(line+"\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	db := &memKnownHosts{rows: map[string]*model.KnownHost{}}
+	store := NewHostKeyStore(db, file)
+
+	res, err := store.Check(ctx, "jump.example.com:22", key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Status != HostKeyTrusted {
+		t.Fatalf("expected trusted, got %s", res.Status)
+	}
+	if db.rows["jump.example.com"] == nil {
+		t.Fatal("known_hosts match was not imported")
+	}
+
+	res, err = store.Check(ctx, "jump.example.com:22", newHostKey(t))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Status != HostKeyMismatch || res.KnownFingerprint != ssh.FingerprintSHA256(key) {
+		t.Fatalf("expected mismatch against imported key, got %+v", res)
+	}
+
+	res, err = store.Check(ctx, "other.example.com:22", key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Status != HostKeyUnknown {
+		t.Fatalf("expected unknown, got %s", res.Status)
+	}
+}
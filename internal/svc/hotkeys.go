@@ -0,0 +1,142 @@
+package svc
+
+import (
+	"sort"
+
+	"github.com/tradalab/rdms/internal/types"
+)
+
+const maxHotKeysTracked = 100000
+
+// HotKeys ranks keys by access count, from OBJECT FREQ counters or from
+// commands sampled off MONITOR, with sums per key prefix and per client.
+type HotKeys struct {
+	separator string
+	depth     int
+	top       int
+	truncated bool
+	keys      map[string]*types.HotKey
+	prefixes  map[string]*types.HotKeyPrefix
+	clients   map[string]*types.HotKeyClient
+}
+
+// NewHotKeys groups prefixes by the first depth segments split on
+// separator and keeps top rows per ranking. Zero values take the defaults
+// of the memory report.
+func NewHotKeys(separator string, depth, top int) *HotKeys {
+	if separator == "" {
+		separator = defaultKeyReportSeparator
+	}
+	if depth <= 0 {
+		depth = defaultKeyReportDepth
+	}
+	if top <= 0 {
+		top = defaultKeyReportTop
+	}
+	return &HotKeys{
+		separator: separator,
+		depth:     depth,
+		top:       top,
+		keys:      make(map[string]*types.HotKey),
+		prefixes:  make(map[string]*types.HotKeyPrefix),
+		clients:   make(map[string]*types.HotKeyClient),
+	}
+}
+
+// Add counts hits on key, reads and writes being the split where it is
+// known. Keys past maxHotKeysTracked still count towards their prefix but
+// mark the report truncated.
+func (h *HotKeys) Add(key string, hits, reads, writes int64) {
+	k, ok := h.keys[key]
+	if !ok && len(h.keys) < maxHotKeysTracked {
+		k = &types.HotKey{Key: key}
+		h.keys[key] = k
+	}
+	if k != nil {
+		k.Hits += hits
+		k.Reads += reads
+		k.Writes += writes
+	} else {
+		h.truncated = true
+	}
+
+	prefix := KeyPrefix(key, h.separator, h.depth)
+	p, found := h.prefixes[prefix]
+	if !found {
+		if len(h.prefixes) >= maxKeyReportPrefixes {
+			prefix = keyReportOtherPrefix
+			p = h.prefixes[prefix]
+		}
+		if p == nil {
+			p = &types.HotKeyPrefix{Prefix: prefix}
+			h.prefixes[prefix] = p
+		}
+	}
+	if !ok && k != nil {
+		p.Keys++
+	}
+	p.Hits += hits
+	p.Reads += reads
+	p.Writes += writes
+}
+
+// AddClient counts one command from addr that touched a matching key.
+func (h *HotKeys) AddClient(addr string, write bool) {
+	c, ok := h.clients[addr]
+	if !ok {
+		c = &types.HotKeyClient{Addr: addr}
+		h.clients[addr] = c
+	}
+	c.Commands++
+	if write {
+		c.Writes++
+	} else {
+		c.Reads++
+	}
+}
+
+// Snapshot returns the rankings so far, most hits first. It shares nothing
+// with h, so h may keep counting.
+func (h *HotKeys) Snapshot() *types.ClientKeysHotEvent {
+	ev := &types.ClientKeysHotEvent{Truncated: h.truncated}
+
+	for _, k := range h.keys {
+		ev.Keys = append(ev.Keys, *k)
+	}
+	sort.Slice(ev.Keys, func(i, j int) bool {
+		if ev.Keys[i].Hits != ev.Keys[j].Hits {
+			return ev.Keys[i].Hits > ev.Keys[j].Hits
+		}
+		return ev.Keys[i].Key < ev.Keys[j].Key
+	})
+	if len(ev.Keys) > h.top {
+		ev.Keys = ev.Keys[:h.top]
+	}
+
+	for _, p := range h.prefixes {
+		ev.Prefixes = append(ev.Prefixes, *p)
+	}
+	sort.Slice(ev.Prefixes, func(i, j int) bool {
+		if ev.Prefixes[i].Hits != ev.Prefixes[j].Hits {
+			return ev.Prefixes[i].Hits > ev.Prefixes[j].Hits
+		}
+		return ev.Prefixes[i].Prefix < ev.Prefixes[j].Prefix
+	})
+	if len(ev.Prefixes) > keyReportPrefixRows {
+		ev.Prefixes = ev.Prefixes[:keyReportPrefixRows]
+	}
+
+	for _, c := range h.clients {
+		ev.Clients = append(ev.Clients, *c)
+	}
+	sort.Slice(ev.Clients, func(i, j int) bool {
+		if ev.Clients[i].Commands != ev.Clients[j].Commands {
+			return ev.Clients[i].Commands > ev.Clients[j].Commands
+		}
+		return ev.Clients[i].Addr < ev.Clients[j].Addr
+	})
+	if len(ev.Clients) > h.top {
+		ev.Clients = ev.Clients[:h.top]
+	}
+	return ev
+}
@@ -0,0 +1,47 @@
+package svc
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestHotKeysRanks(t *testing.T) {
+	h := NewHotKeys(":", 1, 2)
+	for i := range 5 {
+		h.Add("user:hot", 1, 1, 0)
+		h.AddClient("10.0.0.1:1", false)
+		if i < 2 {
+			h.Add("user:warm", 1, 0, 1)
+			h.AddClient("10.0.0.2:1", true)
+		}
+	}
+	h.Add("cold", 1, 1, 0)
+
+	ev := h.Snapshot()
+	if len(ev.Keys) != 2 || ev.Keys[0].Key != "user:hot" || ev.Keys[0].Hits != 5 || ev.Keys[1].Writes != 2 {
+		t.Errorf("keys = %+v", ev.Keys)
+	}
+	if p := ev.Prefixes[0]; p.Prefix != "user:" || p.Keys != 2 || p.Hits != 7 || p.Reads != 5 || p.Writes != 2 {
+		t.Errorf("prefix = %+v", p)
+	}
+	if c := ev.Clients[0]; c.Addr != "10.0.0.1:1" || c.Commands != 5 || c.Reads != 5 {
+		t.Errorf("client = %+v", c)
+	}
+	if ev.Truncated {
+		t.Error("truncated below the cap")
+	}
+}
+
+func TestHotKeysCapsTrackedKeys(t *testing.T) {
+	h := NewHotKeys(":", 1, 1)
+	for i := range maxHotKeysTracked + 10 {
+		h.Add(fmt.Sprintf("k:%d", i), 1, 1, 0)
+	}
+	ev := h.Snapshot()
+	if !ev.Truncated || len(h.keys) != maxHotKeysTracked {
+		t.Errorf("truncated = %v tracked = %d", ev.Truncated, len(h.keys))
+	}
+	if p := ev.Prefixes[0]; p.Keys != maxHotKeysTracked || p.Hits != maxHotKeysTracked+10 {
+		t.Errorf("prefix = %+v, want every hit but only tracked keys", p)
+	}
+}
@@ -0,0 +1,298 @@
+package svc
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/tradalab/rdms/internal/model"
+)
+
+const (
+	ImportSourceARDM         = "ardm"
+	ImportSourceRedisInsight = "redisinsight"
+	ImportSourceTinyRDM      = "tinyrdm"
+	ImportSourceRedisCli     = "redis-cli"
+)
+
+// ImportEntry is one connection read from another tool's export, together
+// with the profiles it needs. Ssh and Tls are nil when not used.
+type ImportEntry struct {
+	Conn     *model.Connection
+	Ssh      *model.Ssh
+	Tls      *model.Tls
+	Group    string
+	Warnings []string
+
+	// DuplicateOf names the connection, existing or earlier in the same
+	// import, that already covers this address and database.
+	DuplicateOf string
+}
+
+func (e *ImportEntry) warn(format string, args ...any) {
+	e.Warnings = append(e.Warnings, fmt.Sprintf(format, args...))
+}
+
+// ParseImport reads an export of source. An empty source is detected from
+// the data; the resolved source is returned.
+func ParseImport(source string, data []byte) (string, []*ImportEntry, error) {
+	if source == "" {
+		source = DetectImportSource(data)
+	}
+	var (
+		entries []*ImportEntry
+		err     error
+	)
+	switch source {
+	case ImportSourceARDM:
+		entries, err = parseARDM(data)
+	case ImportSourceRedisInsight:
+		entries, err = parseRedisInsight(data)
+	case ImportSourceTinyRDM:
+		entries, err = parseTinyRDM(data)
+	case ImportSourceRedisCli:
+		entries, err = parseRedisCli(data)
+	default:
+		return "", nil, fmt.Errorf("unknown import source %q", source)
+	}
+	if err != nil {
+		return source, nil, fmt.Errorf("%s import: %w", source, err)
+	}
+	for _, e := range entries {
+		fillImportDefaults(e)
+	}
+	return source, entries, nil
+}
+
+// DetectImportSource guesses the tool that wrote data: Tiny RDM exports a
+// zip or YAML, ARDM base64-wrapped JSON, RedisInsight JSON with
+// connectionType. Anything else is read as redis-cli lines.
+func DetectImportSource(data []byte) string {
+	trimmed := bytes.TrimSpace(data)
+	if bytes.HasPrefix(trimmed, []byte("PK\x03\x04")) {
+		return ImportSourceTinyRDM
+	}
+	if len(trimmed) > 0 && (trimmed[0] == '[' || trimmed[0] == '{') {
+		if bytes.Contains(trimmed, []byte(`"connectionType"`)) || bytes.Contains(trimmed, []byte(`"tlsServername"`)) {
+			return ImportSourceRedisInsight
+		}
+		return ImportSourceARDM
+	}
+	if dec, err := base64.StdEncoding.DecodeString(string(trimmed)); err == nil && json.Valid(dec) {
+		return ImportSourceARDM
+	}
+	for _, line := range strings.Split(string(trimmed), "\n") {
+		line = strings.TrimSpace(line)
+		if strings.HasPrefix(line, "- name:") || strings.HasPrefix(line, "addr:") || strings.HasPrefix(line, "connections:") {
+			return ImportSourceTinyRDM
+		}
+	}
+	return ImportSourceRedisCli
+}
+
+func fillImportDefaults(e *ImportEntry) {
+	c := e.Conn
+	if c.Mode == "" {
+		c.Mode = "standalone"
+	}
+	if c.Network == "" {
+		c.Network = "tcp"
+	}
+	if c.Network == "tcp" && c.Mode == "standalone" {
+		if c.Host == "" {
+			c.Host = "127.0.0.1"
+		}
+		if c.Port == 0 {
+			c.Port = defaultImportPort
+		}
+	}
+	if c.DialTimeout == 0 {
+		c.DialTimeout = 60
+	}
+	if c.ExecTimeout == 0 {
+		c.ExecTimeout = 60
+	}
+	if c.KeySize == 0 {
+		c.KeySize = 10000
+	}
+	if c.Name == "" {
+		c.Name = c.Addr()
+		if c.Network == "unix" {
+			c.Name = c.Sock
+		}
+	}
+	if e.Ssh != nil {
+		c.SshEnable = 1
+		if e.Ssh.Port == 0 {
+			e.Ssh.Port = 22
+		}
+		if e.Ssh.Timeout == 0 {
+			e.Ssh.Timeout = 30
+		}
+	}
+	if e.Tls != nil {
+		c.TlsEnable = 1
+		if e.Tls.Name == "" {
+			e.Tls.Name = c.Name
+		}
+	}
+}
+
+const defaultImportPort = 6379
+
+// importKey identifies what a connection points at: address(es) plus
+// database. Hosts compare case-insensitively and seed lists unordered.
+func importKey(c *model.Connection) string {
+	db := strconv.FormatInt(c.LastDb, 10)
+	if c.Network == "unix" {
+		return "unix:" + c.Sock + "/" + db
+	}
+	if c.Mode == "sentinel" || c.Mode == "cluster" {
+		addrs := strings.FieldsFunc(strings.ToLower(c.Addrs), func(r rune) bool {
+			return r == ',' || r == '\n' || r == '\r' || r == ' '
+		})
+		sort.Strings(addrs)
+		return c.Mode + ":" + strings.Join(addrs, ",") + "/" + c.SentinelMaster + "/" + db
+	}
+	return strings.ToLower(net.JoinHostPort(c.Host, strconv.FormatInt(c.Port, 10))) + "/" + db
+}
+
+// MarkDuplicates flags entries whose address and database match an existing
+// connection or an earlier entry of the same import.
+func MarkDuplicates(entries []*ImportEntry, existing []*model.Connection) {
+	seen := make(map[string]string, len(existing)+len(entries))
+	for _, c := range existing {
+		seen[importKey(c)] = c.Name
+	}
+	for _, e := range entries {
+		key := importKey(e.Conn)
+		if name, ok := seen[key]; ok {
+			e.DuplicateOf = name
+			continue
+		}
+		seen[key] = e.Conn.Name
+	}
+}
+
+// PlanImport parses data and marks duplicates against the stored
+// connections without writing anything.
+func (s *ServiceContext) PlanImport(ctx context.Context, source string, data []byte) (string, []*ImportEntry, error) {
+	source, entries, err := ParseImport(source, data)
+	if err != nil {
+		return source, nil, err
+	}
+	existing, err := s.ConnectionModel.FindAll(ctx)
+	if err != nil {
+		return source, nil, err
+	}
+	MarkDuplicates(entries, existing)
+	return source, entries, nil
+}
+
+// ApplyImport stores every entry not marked as a duplicate, creating its
+// group, SSH and TLS rows first. Groups are matched by name and SSH
+// profiles by user@host:port, so repeated imports do not pile them up.
+// Everything is stored in one transaction: a failed entry stores nothing.
+func (s *ServiceContext) ApplyImport(ctx context.Context, entries []*ImportEntry) (int, error) {
+	created := 0
+	err := s.WithTx(ctx, func(ctx context.Context) error {
+		var err error
+		created, err = s.applyImport(ctx, entries)
+		return err
+	})
+	if err != nil {
+		return 0, err
+	}
+	return created, nil
+}
+
+func (s *ServiceContext) applyImport(ctx context.Context, entries []*ImportEntry) (int, error) {
+	groupRows, err := s.GroupModel.FindAll(ctx)
+	if err != nil {
+		return 0, err
+	}
+	groups := make(map[string]string, len(groupRows))
+	for _, g := range groupRows {
+		groups[g.Name] = g.ID
+	}
+	sshRows, err := s.SshModel.FindAll(ctx)
+	if err != nil {
+		return 0, err
+	}
+	sshes := make(map[string]string, len(sshRows))
+	for _, h := range sshRows {
+		sshes[sshImportKey(h)] = h.ID
+	}
+
+	created := 0
+	for _, e := range entries {
+		if e.DuplicateOf != "" {
+			continue
+		}
+		c := e.Conn
+
+		if e.Group != "" {
+			id, ok := groups[e.Group]
+			if !ok {
+				g := &model.Group{Name: e.Group}
+				if _, err := s.GroupModel.Insert(ctx, g); err != nil {
+					return created, fmt.Errorf("create group %q: %w", e.Group, err)
+				}
+				id = g.ID
+				groups[e.Group] = id
+			}
+			c.GroupID = id
+		}
+
+		if e.Ssh != nil {
+			key := sshImportKey(e.Ssh)
+			id, ok := sshes[key]
+			if !ok {
+				if _, err := s.SshModel.Insert(ctx, e.Ssh); err != nil {
+					return created, fmt.Errorf("create ssh profile for %q: %w", c.Name, err)
+				}
+				id = e.Ssh.ID
+				sshes[key] = id
+			}
+			c.SshID = id
+		}
+
+		if e.Tls != nil {
+			if _, err := s.TlsModel.Insert(ctx, e.Tls); err != nil {
+				return created, fmt.Errorf("create tls profile for %q: %w", c.Name, err)
+			}
+			c.TlsID = e.Tls.ID
+		}
+
+		if _, err := s.ConnectionModel.Insert(ctx, c); err != nil {
+			return created, fmt.Errorf("create connection %q: %w", c.Name, err)
+		}
+		created++
+	}
+	return created, nil
+}
+
+func sshImportKey(h *model.Ssh) string {
+	return strings.ToLower(h.Username + "@" + h.Addr())
+}
+
+// readImportFile loads a certificate or key that the other tool referenced
+// by path. Unreadable files become a warning, not a failed import.
+func readImportFile(e *ImportEntry, what, path string) string {
+	if path == "" {
+		return ""
+	}
+	b, err := os.ReadFile(path)
+	if err != nil {
+		e.warn("%s %s not read: %v", what, path, err)
+		return ""
+	}
+	return string(b)
+}
@@ -0,0 +1,525 @@
+package svc
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/google/shlex"
+	"gopkg.in/yaml.v3"
+
+	"github.com/tradalab/rdms/internal/model"
+)
+
+// Another Redis Desktop Manager: "Export" writes the connection list as
+// base64 encoded JSON.
+
+type ardmConnection struct {
+	Name               string `json:"name"`
+	Host               string `json:"host"`
+	Port               any    `json:"port"`
+	Auth               string `json:"auth"`
+	Username           string `json:"username"`
+	Cluster            bool   `json:"cluster"`
+	ConnectionReadOnly bool   `json:"connectionReadOnly"`
+	SshOptions         *struct {
+		Host       string `json:"host"`
+		Port       any    `json:"port"`
+		Username   string `json:"username"`
+		Password   string `json:"password"`
+		PrivateKey string `json:"privatekey"`
+		Passphrase string `json:"passphrase"`
+		Timeout    any    `json:"timeout"`
+	} `json:"sshOptions"`
+	SslOptions *struct {
+		Key        string `json:"key"`
+		Cert       string `json:"cert"`
+		Ca         string `json:"ca"`
+		ServerName string `json:"servername"`
+	} `json:"sslOptions"`
+	SentinelOptions *struct {
+		MasterName   string `json:"masterName"`
+		NodePassword string `json:"nodePassword"`
+	} `json:"sentinelOptions"`
+}
+
+func parseARDM(data []byte) ([]*ImportEntry, error) {
+	data = bytes.TrimSpace(data)
+	if len(data) > 0 && data[0] != '[' && data[0] != '{' {
+		dec, err := base64.StdEncoding.DecodeString(string(data))
+		if err != nil {
+			return nil, fmt.Errorf("not base64 or json: %w", err)
+		}
+		data = dec
+	}
+
+	var list []ardmConnection
+	if err := json.Unmarshal(data, &list); err != nil {
+		// Older versions stored the connections keyed by their id.
+		var byKey map[string]ardmConnection
+		if err2 := json.Unmarshal(data, &byKey); err2 != nil {
+			return nil, err
+		}
+		for _, c := range byKey {
+			list = append(list, c)
+		}
+	}
+
+	entries := make([]*ImportEntry, 0, len(list))
+	for _, a := range list {
+		c := &model.Connection{
+			Name:     a.Name,
+			Host:     a.Host,
+			Port:     anyInt(a.Port),
+			Username: a.Username,
+			Password: a.Auth,
+			ReadOnly: boolToInt(a.ConnectionReadOnly),
+		}
+		e := &ImportEntry{Conn: c}
+		switch {
+		case a.SentinelOptions != nil && a.SentinelOptions.MasterName != "":
+			// ARDM dials the sentinel with auth and the nodes with
+			// nodePassword.
+			c.Mode = "sentinel"
+			c.Addrs = c.Addr()
+			c.SentinelMaster = a.SentinelOptions.MasterName
+			c.SentinelPassword = a.Auth
+			c.Password = a.SentinelOptions.NodePassword
+		case a.Cluster:
+			c.Mode = "cluster"
+			c.Addrs = c.Addr()
+		}
+		if o := a.SshOptions; o != nil && o.Host != "" {
+			e.Ssh = &model.Ssh{
+				Host:     o.Host,
+				Port:     anyInt(o.Port),
+				Username: o.Username,
+				Timeout:  anyInt(o.Timeout),
+			}
+			if o.PrivateKey != "" {
+				e.Ssh.Kind = "keypair"
+				e.Ssh.PrivateKey = readImportFile(e, "ssh private key", o.PrivateKey)
+				e.Ssh.Passphrase = o.Passphrase
+			} else {
+				e.Ssh.Kind = "password"
+				e.Ssh.Password = o.Password
+			}
+		}
+		if o := a.SslOptions; o != nil {
+			e.Tls = &model.Tls{
+				Verify:     1,
+				ServerName: o.ServerName,
+				UseSni:     boolToInt(o.ServerName != ""),
+				CaCert:     readImportFile(e, "ca certificate", o.Ca),
+				Cert:       readImportFile(e, "client certificate", o.Cert),
+				Key:        readImportFile(e, "client key", o.Key),
+			}
+			e.Tls.ClientAuth = boolToInt(e.Tls.Cert != "" && e.Tls.Key != "")
+		}
+		entries = append(entries, e)
+	}
+	return entries, nil
+}
+
+// RedisInsight: "Export" on the database list, with or without passwords.
+
+type redisInsightDatabase struct {
+	Name             string `json:"name"`
+	Host             string `json:"host"`
+	Port             int64  `json:"port"`
+	Db               int64  `json:"db"`
+	Username         string `json:"username"`
+	Password         string `json:"password"`
+	ConnectionType   string `json:"connectionType"`
+	Timeout          int64  `json:"timeout"`
+	Tls              bool   `json:"tls"`
+	TlsServername    string `json:"tlsServername"`
+	VerifyServerCert bool   `json:"verifyServerCert"`
+	CaCert           *struct {
+		Certificate string `json:"certificate"`
+	} `json:"caCert"`
+	ClientCert *struct {
+		Certificate string `json:"certificate"`
+		Key         string `json:"key"`
+	} `json:"clientCert"`
+	SentinelMaster *struct {
+		Name     string `json:"name"`
+		Username string `json:"username"`
+		Password string `json:"password"`
+	} `json:"sentinelMaster"`
+	Ssh        bool `json:"ssh"`
+	SshOptions *struct {
+		Host       string `json:"host"`
+		Port       int64  `json:"port"`
+		Username   string `json:"username"`
+		Password   string `json:"password"`
+		PrivateKey string `json:"privateKey"`
+		Passphrase string `json:"passphrase"`
+	} `json:"sshOptions"`
+}
+
+func parseRedisInsight(data []byte) ([]*ImportEntry, error) {
+	var list []redisInsightDatabase
+	if err := json.Unmarshal(data, &list); err != nil {
+		var one redisInsightDatabase
+		if err2 := json.Unmarshal(data, &one); err2 != nil {
+			return nil, err
+		}
+		list = []redisInsightDatabase{one}
+	}
+
+	entries := make([]*ImportEntry, 0, len(list))
+	for _, r := range list {
+		c := &model.Connection{
+			Name:     r.Name,
+			Host:     r.Host,
+			Port:     r.Port,
+			LastDb:   r.Db,
+			Username: r.Username,
+			Password: r.Password,
+		}
+		if r.Timeout > 0 {
+			c.DialTimeout = (r.Timeout + 999) / 1000
+		}
+		e := &ImportEntry{Conn: c}
+		switch strings.ToUpper(r.ConnectionType) {
+		case "SENTINEL":
+			// The database host is the sentinel; sentinelMaster holds the
+			// credentials of the monitored master.
+			c.Mode = "sentinel"
+			c.Addrs = c.Addr()
+			c.SentinelUsername, c.SentinelPassword = r.Username, r.Password
+			c.Username, c.Password = "", ""
+			if m := r.SentinelMaster; m != nil {
+				c.SentinelMaster = m.Name
+				c.Username, c.Password = m.Username, m.Password
+			}
+		case "CLUSTER":
+			c.Mode = "cluster"
+			c.Addrs = c.Addr()
+		}
+		if o := r.SshOptions; r.Ssh && o != nil {
+			e.Ssh = &model.Ssh{Host: o.Host, Port: o.Port, Username: o.Username}
+			if o.PrivateKey != "" {
+				e.Ssh.Kind = "keypair"
+				e.Ssh.PrivateKey = o.PrivateKey
+				e.Ssh.Passphrase = o.Passphrase
+			} else {
+				e.Ssh.Kind = "password"
+				e.Ssh.Password = o.Password
+			}
+		}
+		if r.Tls {
+			e.Tls = &model.Tls{
+				Verify:     boolToInt(r.VerifyServerCert),
+				ServerName: r.TlsServername,
+				UseSni:     boolToInt(r.TlsServername != ""),
+			}
+			if r.CaCert != nil {
+				e.Tls.CaCert = r.CaCert.Certificate
+			}
+			if cc := r.ClientCert; cc != nil && cc.Certificate != "" && cc.Key != "" {
+				e.Tls.ClientAuth = 1
+				e.Tls.Cert, e.Tls.Key = cc.Certificate, cc.Key
+			}
+		}
+		entries = append(entries, e)
+	}
+	return entries, nil
+}
+
+// Tiny RDM: "Export Connections" writes a zip holding connections.yaml, in
+// which groups nest their connections.
+
+type tinyRDMConnection struct {
+	Name        string `yaml:"name"`
+	Type        string `yaml:"type"`
+	LastDb      int64  `yaml:"last_db"`
+	Network     string `yaml:"network"`
+	Sock        string `yaml:"sock"`
+	Addr        string `yaml:"addr"`
+	Port        int64  `yaml:"port"`
+	Username    string `yaml:"username"`
+	Password    string `yaml:"password"`
+	ConnTimeout int64  `yaml:"conn_timeout"`
+	ExecTimeout int64  `yaml:"exec_timeout"`
+	LoadSize    int64  `yaml:"load_size"`
+	SSL         struct {
+		Enable        bool   `yaml:"enable"`
+		KeyFile       string `yaml:"key_file"`
+		CertFile      string `yaml:"cert_file"`
+		CAFile        string `yaml:"ca_file"`
+		AllowInsecure bool   `yaml:"allow_insecure"`
+		SNI           string `yaml:"sni"`
+	} `yaml:"ssl"`
+	SSH struct {
+		Enable     bool   `yaml:"enable"`
+		Addr       string `yaml:"addr"`
+		Port       int64  `yaml:"port"`
+		LoginType  string `yaml:"login_type"`
+		Username   string `yaml:"username"`
+		Password   string `yaml:"password"`
+		PKFile     string `yaml:"pkfile"`
+		Passphrase string `yaml:"passphrase"`
+	} `yaml:"ssh"`
+	Sentinel struct {
+		Enable   bool   `yaml:"enable"`
+		Master   string `yaml:"master"`
+		Username string `yaml:"username"`
+		Password string `yaml:"password"`
+	} `yaml:"sentinel"`
+	Cluster struct {
+		Enable bool `yaml:"enable"`
+	} `yaml:"cluster"`
+	Proxy struct {
+		Type int    `yaml:"type"`
+		Addr string `yaml:"addr"`
+	} `yaml:"proxy"`
+	Connections []tinyRDMConnection `yaml:"connections"`
+}
+
+func parseTinyRDM(data []byte) ([]*ImportEntry, error) {
+	if bytes.HasPrefix(data, []byte("PK\x03\x04")) {
+		yml, err := tinyRDMFromZip(data)
+		if err != nil {
+			return nil, err
+		}
+		data = yml
+	}
+
+	var list []tinyRDMConnection
+	if err := yaml.Unmarshal(data, &list); err != nil {
+		return nil, err
+	}
+
+	var entries []*ImportEntry
+	var walk func(items []tinyRDMConnection, group string)
+	walk = func(items []tinyRDMConnection, group string) {
+		for _, t := range items {
+			if t.Type == "group" {
+				walk(t.Connections, t.Name)
+				continue
+			}
+			entries = append(entries, tinyRDMEntry(t, group))
+		}
+	}
+	walk(list, "")
+	return entries, nil
+}
+
+func tinyRDMFromZip(data []byte) ([]byte, error) {
+	zr, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return nil, err
+	}
+	for _, f := range zr.File {
+		if !strings.HasSuffix(f.Name, "connections.yaml") {
+			continue
+		}
+		rc, err := f.Open()
+		if err != nil {
+			return nil, err
+		}
+		defer rc.Close()
+		return io.ReadAll(io.LimitReader(rc, 16<<20))
+	}
+	return nil, errors.New("connections.yaml not found in archive")
+}
+
+func tinyRDMEntry(t tinyRDMConnection, group string) *ImportEntry {
+	c := &model.Connection{
+		Name:        t.Name,
+		Network:     t.Network,
+		Sock:        t.Sock,
+		Host:        t.Addr,
+		Port:        t.Port,
+		LastDb:      t.LastDb,
+		Username:    t.Username,
+		Password:    t.Password,
+		DialTimeout: t.ConnTimeout,
+		ExecTimeout: t.ExecTimeout,
+		KeySize:     t.LoadSize,
+	}
+	e := &ImportEntry{Conn: c, Group: group}
+	switch {
+	case t.Sentinel.Enable:
+		c.Mode = "sentinel"
+		c.Addrs = c.Addr()
+		c.SentinelMaster = t.Sentinel.Master
+		c.SentinelUsername = t.Sentinel.Username
+		c.SentinelPassword = t.Sentinel.Password
+	case t.Cluster.Enable:
+		c.Mode = "cluster"
+		c.Addrs = c.Addr()
+	}
+	if t.SSH.Enable {
+		e.Ssh = &model.Ssh{Host: t.SSH.Addr, Port: t.SSH.Port, Username: t.SSH.Username}
+		if t.SSH.LoginType == "pkfile" {
+			e.Ssh.Kind = "keypair"
+			e.Ssh.PrivateKey = readImportFile(e, "ssh private key", t.SSH.PKFile)
+			e.Ssh.Passphrase = t.SSH.Passphrase
+		} else {
+			e.Ssh.Kind = "password"
+			e.Ssh.Password = t.SSH.Password
+		}
+	}
+	if t.SSL.Enable {
+		e.Tls = &model.Tls{
+			Verify:     boolToInt(!t.SSL.AllowInsecure),
+			ServerName: t.SSL.SNI,
+			UseSni:     boolToInt(t.SSL.SNI != ""),
+			CaCert:     readImportFile(e, "ca certificate", t.SSL.CAFile),
+			Cert:       readImportFile(e, "client certificate", t.SSL.CertFile),
+			Key:        readImportFile(e, "client key", t.SSL.KeyFile),
+		}
+		e.Tls.ClientAuth = boolToInt(e.Tls.Cert != "" && e.Tls.Key != "")
+	}
+	if t.Proxy.Type > 0 && t.Proxy.Addr != "" {
+		e.warn("proxy %s not imported; add it as a proxy profile", t.Proxy.Addr)
+	}
+	return e
+}
+
+// redis-cli: one invocation per line, e.g.
+//
+//	redis-cli -h cache.internal -p 6380 --user app -a secret -n 2 --tls
+
+func parseRedisCli(data []byte) ([]*ImportEntry, error) {
+	var entries []*ImportEntry
+	for n, line := range strings.Split(string(data), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		args, err := shlex.Split(line)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", n+1, err)
+		}
+		if len(args) > 0 && strings.HasSuffix(args[0], "redis-cli") {
+			args = args[1:]
+		}
+		e, err := redisCliEntry(args)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", n+1, err)
+		}
+		entries = append(entries, e)
+	}
+	return entries, nil
+}
+
+func redisCliEntry(args []string) (*ImportEntry, error) {
+	c := &model.Connection{}
+	e := &ImportEntry{Conn: c}
+	var tls *model.Tls
+	tlsOn := func() *model.Tls {
+		if tls == nil {
+			tls = &model.Tls{Verify: 1}
+		}
+		return tls
+	}
+
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		value := func() (string, error) {
+			if i+1 >= len(args) {
+				return "", fmt.Errorf("%s needs a value", arg)
+			}
+			i++
+			return args[i], nil
+		}
+		var (
+			v   string
+			err error
+		)
+		switch arg {
+		case "-h", "-p", "-a", "--pass", "--user", "-n", "-s", "-u", "--sni", "--cacert", "--cert", "--key":
+			if v, err = value(); err != nil {
+				return nil, err
+			}
+		}
+		switch arg {
+		case "-h":
+			c.Host = v
+		case "-p":
+			c.Port, err = strconv.ParseInt(v, 10, 64)
+		case "-a", "--pass":
+			c.Password = v
+		case "--user":
+			c.Username = v
+		case "-n":
+			c.LastDb, err = strconv.ParseInt(v, 10, 64)
+		case "-s":
+			c.Network, c.Sock = "unix", v
+		case "-u":
+			parsed, t, perr := model.ParseConnectionURL(v)
+			if perr != nil {
+				return nil, perr
+			}
+			*c, tls = *parsed, t
+		case "-c":
+			c.Mode = "cluster"
+		case "--tls":
+			tlsOn()
+		case "--insecure":
+			tlsOn().Verify = 0
+		case "--sni":
+			t := tlsOn()
+			t.ServerName, t.UseSni = v, 1
+		case "--cacert":
+			tlsOn().CaCert = readImportFile(e, "ca certificate", v)
+		case "--cert":
+			tlsOn().Cert = readImportFile(e, "client certificate", v)
+		case "--key":
+			tlsOn().Key = readImportFile(e, "client key", v)
+		case "--askpass":
+			e.warn("--askpass: enter the password after import")
+		default:
+			if !strings.HasPrefix(arg, "-") {
+				// The rest is a command to run, not connection settings.
+				i = len(args)
+			}
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%s: invalid value %q", arg, v)
+		}
+	}
+
+	if c.Mode == "cluster" && c.Addrs == "" {
+		if c.Port == 0 {
+			c.Port = defaultImportPort
+		}
+		if c.Host == "" {
+			c.Host = "127.0.0.1"
+		}
+		c.Addrs = c.Addr()
+	}
+	if tls != nil {
+		tls.ClientAuth = boolToInt(tls.Cert != "" && tls.Key != "")
+		e.Tls = tls
+	}
+	return e, nil
+}
+
+func anyInt(v any) int64 {
+	switch n := v.(type) {
+	case float64:
+		return int64(n)
+	case string:
+		i, _ := strconv.ParseInt(strings.TrimSpace(n), 10, 64)
+		return i
+	}
+	return 0
+}
+
+func boolToInt(b bool) int64 {
+	if b {
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,153 @@
+package svc
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/base64"
+	"testing"
+
+	"github.com/tradalab/rdms/internal/model"
+)
+
+func TestParseImportARDM(t *testing.T) {
+	raw := `[{"name":"prod","host":"10.0.0.1","port":6380,"auth":"pw","cluster":false,
+		"sshOptions":{"host":"bastion","port":"2222","username":"ops","password":"sp"}},
+		{"host":"10.0.0.2","port":7000,"cluster":true}]`
+	data := []byte(base64.StdEncoding.EncodeToString([]byte(raw)))
+
+	source, entries, err := ParseImport("", data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if source != ImportSourceARDM || len(entries) != 2 {
+		t.Fatalf("got %s with %d entries", source, len(entries))
+	}
+	prod := entries[0]
+	if prod.Conn.Host != "10.0.0.1" || prod.Conn.Port != 6380 || prod.Conn.Password != "pw" {
+		t.Errorf("connection parsed wrong: %+v", prod.Conn)
+	}
+	if prod.Ssh == nil || prod.Ssh.Port != 2222 || prod.Ssh.Kind != "password" || prod.Conn.SshEnable != 1 {
+		t.Errorf("ssh parsed wrong: %+v", prod.Ssh)
+	}
+	if c := entries[1].Conn; c.Mode != "cluster" || c.Addrs != "10.0.0.2:7000" || c.Name != "10.0.0.2:7000" {
+		t.Errorf("cluster parsed wrong: %+v", c)
+	}
+}
+
+func TestParseImportRedisInsight(t *testing.T) {
+	raw := `[{"name":"ha","host":"s1","port":26379,"password":"sentpw","connectionType":"SENTINEL",
+		"sentinelMaster":{"name":"mymaster","password":"nodepw"},
+		"tls":true,"tlsServername":"redis.example.com","verifyServerCert":true,"caCert":{"certificate":"CA"}}]`
+
+	source, entries, err := ParseImport("", []byte(raw))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if source != ImportSourceRedisInsight || len(entries) != 1 {
+		t.Fatalf("got %s with %d entries", source, len(entries))
+	}
+	e := entries[0]
+	if e.Conn.Mode != "sentinel" || e.Conn.SentinelMaster != "mymaster" || e.Conn.Addrs != "s1:26379" {
+		t.Errorf("sentinel parsed wrong: %+v", e.Conn)
+	}
+	if e.Conn.SentinelPassword != "sentpw" || e.Conn.Password != "nodepw" {
+		t.Errorf("sentinel credentials parsed wrong: %+v", e.Conn)
+	}
+	if e.Tls == nil || e.Tls.CaCert != "CA" || e.Tls.ServerName != "redis.example.com" || e.Tls.Verify != 1 {
+		t.Errorf("tls parsed wrong: %+v", e.Tls)
+	}
+}
+
+func TestParseImportTinyRDMZip(t *testing.T) {
+	yml := `- name: local
+  addr: 127.0.0.1
+  port: 6379
+  last_db: 1
+- name: Staging
+  type: group
+  connections:
+    - name: stage-cache
+      addr: stage.internal
+      port: 6380
+      ssl:
+        enable: true
+        allow_insecure: true
+      ssh:
+        enable: true
+        addr: jump
+        login_type: pwd
+        username: ops
+        password: x
+`
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	w, _ := zw.Create("connections.yaml")
+	w.Write([]byte(yml))
+	zw.Close()
+
+	source, entries, err := ParseImport("", buf.Bytes())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if source != ImportSourceTinyRDM || len(entries) != 2 {
+		t.Fatalf("got %s with %d entries", source, len(entries))
+	}
+	if entries[0].Group != "" || entries[0].Conn.LastDb != 1 {
+		t.Errorf("top level entry parsed wrong: %+v", entries[0])
+	}
+	stage := entries[1]
+	if stage.Group != "Staging" || stage.Conn.Host != "stage.internal" {
+		t.Errorf("grouped entry parsed wrong: %+v", stage)
+	}
+	if stage.Tls == nil || stage.Tls.Verify != 0 || stage.Ssh == nil || stage.Ssh.Port != 22 {
+		t.Errorf("tls/ssh parsed wrong: %+v %+v", stage.Tls, stage.Ssh)
+	}
+}
+
+func TestParseImportRedisCli(t *testing.T) {
+	data := []byte(`# team caches
+redis-cli -h cache.internal -p 6380 --user app -a 'se cret' -n 2 --tls --sni cache.example.com
+redis-cli -c -h 10.0.0.5 -p 7000 cluster info
+redis-cli -u rediss://u:p@h:6390/4
+`)
+	source, entries, err := ParseImport("", data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if source != ImportSourceRedisCli || len(entries) != 3 {
+		t.Fatalf("got %s with %d entries", source, len(entries))
+	}
+	c := entries[0].Conn
+	if c.Host != "cache.internal" || c.Port != 6380 || c.Username != "app" || c.Password != "se cret" || c.LastDb != 2 {
+		t.Errorf("line 1 parsed wrong: %+v", c)
+	}
+	if tls := entries[0].Tls; tls == nil || tls.ServerName != "cache.example.com" || tls.UseSni != 1 {
+		t.Errorf("line 1 tls parsed wrong: %+v", tls)
+	}
+	if c := entries[1].Conn; c.Mode != "cluster" || c.Addrs != "10.0.0.5:7000" {
+		t.Errorf("line 2 parsed wrong: %+v", c)
+	}
+	if c := entries[2].Conn; c.Host != "h" || c.Port != 6390 || c.LastDb != 4 || entries[2].Tls == nil {
+		t.Errorf("line 3 parsed wrong: %+v", c)
+	}
+
+	if _, _, err := ParseImport(ImportSourceRedisCli, []byte("redis-cli -p notaport")); err == nil {
+		t.Error("bad port must fail")
+	}
+}
+
+func TestMarkDuplicates(t *testing.T) {
+	_, entries, err := ParseImport(ImportSourceRedisCli, []byte("-h Cache -p 6379\n-h cache -p 6379 -n 1\n-h other\n-h other -p 6379"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	existing := []*model.Connection{{Name: "mine", Mode: "standalone", Network: "tcp", Host: "cache", Port: 6379}}
+	MarkDuplicates(entries, existing)
+
+	want := []string{"mine", "", "", "other:6379"}
+	for i, e := range entries {
+		if e.DuplicateOf != want[i] {
+			t.Errorf("entry %d duplicate of %q, want %q", i, e.DuplicateOf, want[i])
+		}
+	}
+}
@@ -0,0 +1,281 @@
+package svc
+
+import (
+	"bufio"
+	"encoding/base64"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"unicode/utf8"
+)
+
+const (
+	KeyExportJSONL = "jsonl"
+	KeyExportCSV   = "csv"
+	KeyExportRESP  = "resp"
+)
+
+// KeyElement is one piece of a key's value. Which fields are set depends on
+// the type:
+//
+//	string       Value
+//	list, set    Value
+//	hash         Field, Value
+//	zset         Field (member), Value (score)
+//	stream       Field (entry ID), Pairs (field, value, ...)
+type KeyElement struct {
+	Field string
+	Value string
+	Pairs []string
+}
+
+// KeyWriter writes exported keys in one format. Every key is a Begin, any
+// number of Elements calls with a page of its value each, and an End.
+type KeyWriter interface {
+	Begin(key, typ string, ttl int64) error // ttl in ms, -1 without expiry
+	Elements(elems []KeyElement) error
+	End() error
+	// Flush writes out anything buffered.
+	Flush() error
+}
+
+// NewKeyWriter returns the writer for format:
+//
+//   - jsonl: one JSON object per key, {"key", "type", "ttl", "value"}.
+//     Strings that are not valid UTF-8 are written as {"base64": "..."}.
+//   - csv: a header, then one row per element: key, type, ttl, field, value.
+//   - resp: RESP commands for redis-cli --pipe that delete and rebuild each
+//     key, then restore its TTL.
+func NewKeyWriter(format string, w io.Writer) (KeyWriter, error) {
+	switch format {
+	case KeyExportJSONL:
+		return &jsonlKeyWriter{w: bufio.NewWriter(w)}, nil
+	case KeyExportCSV:
+		cw := csv.NewWriter(w)
+		if err := cw.Write([]string{"key", "type", "ttl", "field", "value"}); err != nil {
+			return nil, err
+		}
+		return &csvKeyWriter{w: cw}, nil
+	case KeyExportRESP:
+		return &respKeyWriter{w: bufio.NewWriter(w)}, nil
+	}
+	return nil, fmt.Errorf("unknown export format %q", format)
+}
+
+// KeyExportString decodes a string written by the jsonl format.
+func KeyExportString(raw json.RawMessage) (string, error) {
+	var s string
+	if err := json.Unmarshal(raw, &s); err == nil {
+		return s, nil
+	}
+	var b struct {
+		Base64 *string `json:"base64"`
+	}
+	if err := json.Unmarshal(raw, &b); err != nil || b.Base64 == nil {
+		return "", fmt.Errorf("want a string or {\"base64\": ...}, got %s", raw)
+	}
+	v, err := base64.StdEncoding.DecodeString(*b.Base64)
+	return string(v), err
+}
+
+type jsonlKeyWriter struct {
+	w     *bufio.Writer
+	typ   string
+	first bool
+}
+
+func (j *jsonlKeyWriter) str(s string) {
+	var b []byte
+	if utf8.ValidString(s) {
+		b, _ = json.Marshal(s)
+	} else {
+		b, _ = json.Marshal(map[string]string{"base64": base64.StdEncoding.EncodeToString([]byte(s))})
+	}
+	_, _ = j.w.Write(b)
+}
+
+func (j *jsonlKeyWriter) Begin(key, typ string, ttl int64) error {
+	j.typ, j.first = typ, true
+	_, _ = j.w.WriteString(`{"key":`)
+	j.str(key)
+	fmt.Fprintf(j.w, `,"type":%q,"ttl":%d,"value":`, typ, ttl)
+	if typ != "string" {
+		_ = j.w.WriteByte('[')
+	}
+	return nil
+}
+
+func (j *jsonlKeyWriter) Elements(elems []KeyElement) error {
+	for _, e := range elems {
+		if j.typ == "string" {
+			j.str(e.Value)
+			continue
+		}
+		if !j.first {
+			_ = j.w.WriteByte(',')
+		}
+		j.first = false
+		switch j.typ {
+		case "hash", "zset":
+			_ = j.w.WriteByte('[')
+			j.str(e.Field)
+			_ = j.w.WriteByte(',')
+			j.str(e.Value)
+			_ = j.w.WriteByte(']')
+		case "stream":
+			fmt.Fprintf(j.w, `{"id":%q,"fields":[`, e.Field)
+			for i, p := range e.Pairs {
+				if i > 0 {
+					_ = j.w.WriteByte(',')
+				}
+				j.str(p)
+			}
+			_, _ = j.w.WriteString("]}")
+		default:
+			j.str(e.Value)
+		}
+	}
+	return nil
+}
+
+func (j *jsonlKeyWriter) End() error {
+	if j.typ != "string" {
+		_ = j.w.WriteByte(']')
+	}
+	_, err := j.w.WriteString("}\n")
+	return err
+}
+
+func (j *jsonlKeyWriter) Flush() error { return j.w.Flush() }
+
+type csvKeyWriter struct {
+	w   *csv.Writer
+	key string
+	typ string
+	ttl string
+	n   int64
+}
+
+func (c *csvKeyWriter) Begin(key, typ string, ttl int64) error {
+	c.key, c.typ, c.ttl, c.n = key, typ, strconv.FormatInt(ttl, 10), 0
+	return nil
+}
+
+func (c *csvKeyWriter) Elements(elems []KeyElement) error {
+	for _, e := range elems {
+		field, value := e.Field, e.Value
+		switch c.typ {
+		case "list":
+			field = strconv.FormatInt(c.n, 10)
+		case "stream":
+			b, _ := json.Marshal(e.Pairs)
+			value = string(b)
+		}
+		c.n++
+		if err := c.w.Write([]string{c.key, c.typ, c.ttl, field, value}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (c *csvKeyWriter) End() error { return c.w.Error() }
+
+func (c *csvKeyWriter) Flush() error {
+	c.w.Flush()
+	return c.w.Error()
+}
+
+type respKeyWriter struct {
+	w   *bufio.Writer
+	key string
+	typ string
+	ttl int64
+}
+
+func (r *respKeyWriter) cmd(args ...string) {
+	fmt.Fprintf(r.w, "*%d\r\n", len(args))
+	for _, a := range args {
+		fmt.Fprintf(r.w, "$%d\r\n%s\r\n", len(a), a)
+	}
+}
+
+func (r *respKeyWriter) Begin(key, typ string, ttl int64) error {
+	r.key, r.typ, r.ttl = key, typ, ttl
+	r.cmd("DEL", key)
+	return nil
+}
+
+// Elements turns each page into one command, so replaying the script costs
+// about one round trip per page.
+func (r *respKeyWriter) Elements(elems []KeyElement) error {
+	cmds, err := keyCommands(r.key, r.typ, elems)
+	if err != nil {
+		return err
+	}
+	for _, c := range cmds {
+		r.cmd(c...)
+	}
+	return nil
+}
+
+func (r *respKeyWriter) End() error {
+	if c := ttlCommand(r.key, r.ttl); c != nil {
+		r.cmd(c...)
+	}
+	return nil
+}
+
+// keyCommands returns the commands that add elems to key: one for the
+// whole page, or one per entry for streams.
+func keyCommands(key, typ string, elems []KeyElement) ([][]string, error) {
+	if len(elems) == 0 {
+		return nil, nil
+	}
+	if typ == "stream" {
+		cmds := make([][]string, len(elems))
+		for i, e := range elems {
+			cmds[i] = append([]string{"XADD", key, e.Field}, e.Pairs...)
+		}
+		return cmds, nil
+	}
+
+	var args []string
+	switch typ {
+	case "string":
+		return [][]string{{"SET", key, elems[0].Value}}, nil
+	case "list":
+		args = []string{"RPUSH", key}
+	case "set":
+		args = []string{"SADD", key}
+	case "hash":
+		args = []string{"HSET", key}
+	case "zset":
+		args = []string{"ZADD", key}
+	default:
+		return nil, fmt.Errorf("%s: cannot write type %s", key, typ)
+	}
+	for _, e := range elems {
+		switch typ {
+		case "list", "set":
+			args = append(args, e.Value)
+		case "hash":
+			args = append(args, e.Field, e.Value)
+		case "zset":
+			args = append(args, e.Value, e.Field)
+		}
+	}
+	return [][]string{args}, nil
+}
+
+// ttlCommand returns the PEXPIRE that restores ttl, or nil for -1.
+func ttlCommand(key string, ttl int64) []string {
+	if ttl < 0 {
+		return nil
+	}
+	return []string{"PEXPIRE", key, strconv.FormatInt(max(ttl, 1), 10)}
+}
+
+func (r *respKeyWriter) Flush() error { return r.w.Flush() }
@@ -0,0 +1,98 @@
+package svc
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+)
+
+func writeKeys(t *testing.T, format string) string {
+	t.Helper()
+	var buf bytes.Buffer
+	w, err := NewKeyWriter(format, &buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	steps := []struct {
+		key, typ string
+		ttl      int64
+		pages    [][]KeyElement
+	}{
+		{"s", "string", 1500, [][]KeyElement{{{Value: "v\xff"}}}},
+		{"l", "list", -1, [][]KeyElement{{{Value: "a"}, {Value: "b"}}, {{Value: "c"}}}},
+		{"z", "zset", -1, [][]KeyElement{{{Field: "m", Value: "1.5"}}}},
+		{"x", "stream", -1, [][]KeyElement{{{Field: "1-0", Pairs: []string{"f", "v"}}}}},
+	}
+	for _, s := range steps {
+		if err := w.Begin(s.key, s.typ, s.ttl); err != nil {
+			t.Fatal(err)
+		}
+		for _, p := range s.pages {
+			if err := w.Elements(p); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if err := w.End(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := w.Flush(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.String()
+}
+
+func TestKeyWriterJSONL(t *testing.T) {
+	want := `{"key":"s","type":"string","ttl":1500,"value":{"base64":"dv8="}}
+{"key":"l","type":"list","ttl":-1,"value":["a","b","c"]}
+{"key":"z","type":"zset","ttl":-1,"value":[["m","1.5"]]}
+{"key":"x","type":"stream","ttl":-1,"value":[{"id":"1-0","fields":["f","v"]}]}
+`
+	got := writeKeys(t, KeyExportJSONL)
+	if got != want {
+		t.Fatalf("jsonl =\n%s\nwant\n%s", got, want)
+	}
+
+	var rec struct {
+		Value json.RawMessage `json:"value"`
+	}
+	if err := json.Unmarshal([]byte(got[:bytes.IndexByte([]byte(got), '\n')]), &rec); err != nil {
+		t.Fatal(err)
+	}
+	if v, err := KeyExportString(rec.Value); err != nil || v != "v\xff" {
+		t.Errorf("decoded value = %q, %v", v, err)
+	}
+}
+
+func TestKeyWriterCSV(t *testing.T) {
+	want := "key,type,ttl,field,value\n" +
+		"s,string,1500,,v\xff\n" +
+		"l,list,-1,0,a\nl,list,-1,1,b\nl,list,-1,2,c\n" +
+		"z,zset,-1,m,1.5\n" +
+		"x,stream,-1,1-0,\"[\"\"f\"\",\"\"v\"\"]\"\n"
+	if got := writeKeys(t, KeyExportCSV); got != want {
+		t.Fatalf("csv =\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestKeyWriterRESP(t *testing.T) {
+	want := "*2\r\n$3\r\nDEL\r\n$1\r\ns\r\n" +
+		"*3\r\n$3\r\nSET\r\n$1\r\ns\r\n$2\r\nv\xff\r\n" +
+		"*3\r\n$7\r\nPEXPIRE\r\n$1\r\ns\r\n$4\r\n1500\r\n" +
+		"*2\r\n$3\r\nDEL\r\n$1\r\nl\r\n" +
+		"*4\r\n$5\r\nRPUSH\r\n$1\r\nl\r\n$1\r\na\r\n$1\r\nb\r\n" +
+		"*3\r\n$5\r\nRPUSH\r\n$1\r\nl\r\n$1\r\nc\r\n" +
+		"*2\r\n$3\r\nDEL\r\n$1\r\nz\r\n" +
+		"*4\r\n$4\r\nZADD\r\n$1\r\nz\r\n$3\r\n1.5\r\n$1\r\nm\r\n" +
+		"*2\r\n$3\r\nDEL\r\n$1\r\nx\r\n" +
+		"*5\r\n$4\r\nXADD\r\n$1\r\nx\r\n$3\r\n1-0\r\n$1\r\nf\r\n$1\r\nv\r\n"
+	if got := writeKeys(t, KeyExportRESP); got != want {
+		t.Fatalf("resp =\n%q\nwant\n%q", got, want)
+	}
+}
+
+func TestKeyWriterUnknownFormat(t *testing.T) {
+	if _, err := NewKeyWriter("xml", &bytes.Buffer{}); err == nil {
+		t.Fatal("want an error for an unknown format")
+	}
+}
@@ -0,0 +1,586 @@
+package svc
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+const (
+	// importChunk caps the elements behind one command, so a huge key
+	// becomes several RPUSH, SADD, ... calls.
+	importChunk = 1000
+
+	maxRESPArgs   = 1 << 20
+	maxRESPBulk   = 512 << 20
+	maxImportLine = 512 << 20
+)
+
+// ImportOptions configures the readers NewImportReader returns.
+type ImportOptions struct {
+	Delimiter rune // csv, default ','
+
+	// KeyTemplate turns each csv row into one key, its {column}
+	// placeholders filled from the row; empty reads the export layout.
+	KeyTemplate string
+	KeyType     string   // "hash" (default) or "string"
+	Fields      []string // hash: the columns stored as fields, default all
+	ValueColumn string   // string: the column holding the value
+
+	Commands CommandTable // resp: finds the key each command writes
+	DB       int          // resp: the database written to; SELECT of any other fails
+}
+
+// ImportOp is one unit of an import: the commands that write a key, or a
+// chunk of it, or a single command from a script.
+type ImportOp struct {
+	Key  string // the key the conflict policy looks at; "" for none
+	Cmds [][]string
+	Line int // where the record starts
+}
+
+// ImportError is a record that could not be read. Readers go on with the
+// next record after returning one.
+type ImportError struct {
+	Line int
+	Err  error
+}
+
+func (e *ImportError) Error() string { return fmt.Sprintf("line %d: %v", e.Line, e.Err) }
+func (e *ImportError) Unwrap() error { return e.Err }
+
+// ImportReader reads the ops of an import file in order.
+type ImportReader interface {
+	// Next returns the next op, io.EOF after the last, an *ImportError
+	// for a bad record, or any other error when reading cannot go on.
+	Next() (ImportOp, error)
+}
+
+// NewImportReader returns the reader for format:
+//
+//   - jsonl: what the jsonl export writes, one key per line.
+//   - csv: what the csv export writes, or with opts.KeyTemplate any csv
+//     with a header, one hash or string per row.
+//   - resp: RESP and inline commands, as redis-cli --pipe takes them. The
+//     conflict policy looks at the first key of each command. Commands
+//     that change the state of the pooled connection they run on fail,
+//     except SELECT of opts.DB, which is dropped.
+func NewImportReader(format string, r io.Reader, opts ImportOptions) (ImportReader, error) {
+	switch format {
+	case KeyExportJSONL:
+		return &jsonlImportReader{r: bufio.NewReader(r)}, nil
+	case KeyExportCSV:
+		cr := csv.NewReader(r)
+		if opts.Delimiter != 0 {
+			cr.Comma = opts.Delimiter
+		}
+		header, err := cr.Read()
+		if err != nil {
+			return nil, fmt.Errorf("read csv header: %w", err)
+		}
+		if opts.KeyTemplate != "" {
+			return newCSVRowReader(cr, header, opts)
+		}
+		if len(header) != 5 || strings.Join(header, ",") != "key,type,ttl,field,value" {
+			return nil, fmt.Errorf("csv header %q is not the export layout key,type,ttl,field,value; set a key template to map other files", header)
+		}
+		return &csvKeyReader{r: cr}, nil
+	case KeyExportRESP:
+		if opts.Commands == nil {
+			return nil, errors.New("resp import needs the server's command table")
+		}
+		return &scriptImportReader{r: bufio.NewReader(r), table: opts.Commands, db: opts.DB}, nil
+	}
+	return nil, fmt.Errorf("unknown import format %q", format)
+}
+
+// recordOp returns the op that writes a key read from an export, elems
+// chunked by importChunk.
+func recordOp(key, typ string, ttl int64, elems []KeyElement, line int) (ImportOp, error) {
+	op := ImportOp{Key: key, Line: line}
+	for len(elems) > 0 {
+		n := min(len(elems), importChunk)
+		cmds, err := keyCommands(key, typ, elems[:n])
+		if err != nil {
+			return ImportOp{}, err
+		}
+		op.Cmds = append(op.Cmds, cmds...)
+		elems = elems[n:]
+	}
+	if c := ttlCommand(key, ttl); c != nil {
+		op.Cmds = append(op.Cmds, c)
+	}
+	return op, nil
+}
+
+type jsonlImportReader struct {
+	r    *bufio.Reader
+	line int
+}
+
+func (j *jsonlImportReader) Next() (ImportOp, error) {
+	for {
+		b, err := j.r.ReadBytes('\n')
+		if len(b) == 0 && err != nil {
+			return ImportOp{}, err
+		}
+		j.line++
+		if len(bytes.TrimSpace(b)) == 0 {
+			continue
+		}
+		op, err := parseJSONLRecord(b, j.line)
+		if err != nil {
+			return ImportOp{}, &ImportError{Line: j.line, Err: err}
+		}
+		return op, nil
+	}
+}
+
+func parseJSONLRecord(b []byte, line int) (ImportOp, error) {
+	var rec struct {
+		Key   json.RawMessage `json:"key"`
+		Type  string          `json:"type"`
+		Ttl   *int64          `json:"ttl"`
+		Value json.RawMessage `json:"value"`
+	}
+	if err := json.Unmarshal(b, &rec); err != nil {
+		return ImportOp{}, err
+	}
+	if rec.Key == nil || rec.Value == nil {
+		return ImportOp{}, errors.New("record needs a key and a value")
+	}
+	key, err := KeyExportString(rec.Key)
+	if err != nil {
+		return ImportOp{}, fmt.Errorf("key: %w", err)
+	}
+	ttl := int64(-1)
+	if rec.Ttl != nil {
+		ttl = *rec.Ttl
+	}
+
+	var elems []KeyElement
+	switch rec.Type {
+	case "string":
+		v, err := KeyExportString(rec.Value)
+		if err != nil {
+			return ImportOp{}, err
+		}
+		elems = []KeyElement{{Value: v}}
+	case "list", "set":
+		var items []json.RawMessage
+		if err := json.Unmarshal(rec.Value, &items); err != nil {
+			return ImportOp{}, err
+		}
+		for _, it := range items {
+			v, err := KeyExportString(it)
+			if err != nil {
+				return ImportOp{}, err
+			}
+			elems = append(elems, KeyElement{Value: v})
+		}
+	case "hash", "zset":
+		var pairs [][]json.RawMessage
+		if err := json.Unmarshal(rec.Value, &pairs); err != nil {
+			return ImportOp{}, err
+		}
+		for _, p := range pairs {
+			if len(p) != 2 {
+				return ImportOp{}, fmt.Errorf("%s element needs two strings, got %d", rec.Type, len(p))
+			}
+			f, err := KeyExportString(p[0])
+			if err != nil {
+				return ImportOp{}, err
+			}
+			v, err := KeyExportString(p[1])
+			if err != nil {
+				return ImportOp{}, err
+			}
+			elems = append(elems, KeyElement{Field: f, Value: v})
+		}
+	case "stream":
+		var entries []struct {
+			Id     string            `json:"id"`
+			Fields []json.RawMessage `json:"fields"`
+		}
+		if err := json.Unmarshal(rec.Value, &entries); err != nil {
+			return ImportOp{}, err
+		}
+		for _, e := range entries {
+			pairs := make([]string, len(e.Fields))
+			for i, f := range e.Fields {
+				if pairs[i], err = KeyExportString(f); err != nil {
+					return ImportOp{}, err
+				}
+			}
+			elems = append(elems, KeyElement{Field: e.Id, Pairs: pairs})
+		}
+	default:
+		return ImportOp{}, fmt.Errorf("unsupported type %q", rec.Type)
+	}
+	if len(elems) == 0 {
+		return ImportOp{}, fmt.Errorf("%s %q has no elements", rec.Type, key)
+	}
+	return recordOp(key, rec.Type, ttl, elems, line)
+}
+
+// csvKeyReader reads the export layout, gathering the consecutive rows of
+// a key into one op, or one per importChunk rows.
+type csvKeyReader struct {
+	r       *csv.Reader
+	pending []string
+	err     error // met while gathering the rows of the last op
+}
+
+func (c *csvKeyReader) read() ([]string, int, error) {
+	if err := c.err; err != nil {
+		c.err = nil
+		return nil, 0, err
+	}
+	if row := c.pending; row != nil {
+		c.pending = nil
+		line, _ := c.r.FieldPos(0)
+		return row, line, nil
+	}
+	row, err := c.r.Read()
+	if err != nil {
+		var pe *csv.ParseError
+		if errors.As(err, &pe) {
+			return nil, 0, &ImportError{Line: pe.Line, Err: pe.Err}
+		}
+		return nil, 0, err
+	}
+	line, _ := c.r.FieldPos(0)
+	return row, line, nil
+}
+
+func (c *csvKeyReader) Next() (ImportOp, error) {
+	row, line, err := c.read()
+	if err != nil {
+		return ImportOp{}, err
+	}
+	key, typ := row[0], row[1]
+	ttl, err := strconv.ParseInt(row[2], 10, 64)
+	if err != nil {
+		return ImportOp{}, &ImportError{Line: line, Err: fmt.Errorf("ttl: %w", err)}
+	}
+
+	var elems []KeyElement
+	for {
+		e, err := csvElement(typ, row)
+		if err != nil {
+			return ImportOp{}, &ImportError{Line: line, Err: err}
+		}
+		elems = append(elems, e)
+		if len(elems) == importChunk {
+			break
+		}
+		next, _, err := c.read()
+		if err != nil {
+			// Apply the rows read so far; the error comes with the next call.
+			c.err = err
+			break
+		}
+		if next[0] != key || next[1] != typ {
+			c.pending = next
+			break
+		}
+		row = next
+	}
+	return recordOp(key, typ, ttl, elems, line)
+}
+
+func csvElement(typ string, row []string) (KeyElement, error) {
+	switch typ {
+	case "string", "list", "set":
+		return KeyElement{Value: row[4]}, nil
+	case "hash", "zset":
+		return KeyElement{Field: row[3], Value: row[4]}, nil
+	case "stream":
+		var pairs []string
+		if err := json.Unmarshal([]byte(row[4]), &pairs); err != nil {
+			return KeyElement{}, fmt.Errorf("stream fields: %w", err)
+		}
+		return KeyElement{Field: row[3], Pairs: pairs}, nil
+	}
+	return KeyElement{}, fmt.Errorf("unsupported type %q", typ)
+}
+
+var keyTemplateField = regexp.MustCompile(`\{([^{}]+)\}`)
+
+// csvRowReader maps every row of a csv file to one key named by a template.
+type csvRowReader struct {
+	r      *csv.Reader
+	header []string
+	column map[string]int
+	opts   ImportOptions
+	fields []int
+	value  int
+}
+
+func newCSVRowReader(r *csv.Reader, header []string, opts ImportOptions) (*csvRowReader, error) {
+	c := &csvRowReader{r: r, header: header, column: make(map[string]int, len(header)), opts: opts}
+	for i, h := range header {
+		c.column[h] = i
+	}
+	for _, m := range keyTemplateField.FindAllStringSubmatch(opts.KeyTemplate, -1) {
+		if _, ok := c.column[m[1]]; !ok {
+			return nil, fmt.Errorf("key template uses {%s}, which is not a column", m[1])
+		}
+	}
+
+	switch opts.KeyType {
+	case "", "hash":
+		c.opts.KeyType = "hash"
+		names := opts.Fields
+		if len(names) == 0 {
+			names = header
+		}
+		for _, n := range names {
+			i, ok := c.column[n]
+			if !ok {
+				return nil, fmt.Errorf("field %q is not a column", n)
+			}
+			c.fields = append(c.fields, i)
+		}
+	case "string":
+		i, ok := c.column[opts.ValueColumn]
+		if !ok {
+			return nil, fmt.Errorf("value column %q is not a column", opts.ValueColumn)
+		}
+		c.value = i
+	default:
+		return nil, fmt.Errorf("csv rows map to a hash or a string, not %q", opts.KeyType)
+	}
+	return c, nil
+}
+
+func (c *csvRowReader) Next() (ImportOp, error) {
+	row, err := c.r.Read()
+	if err != nil {
+		var pe *csv.ParseError
+		if errors.As(err, &pe) {
+			return ImportOp{}, &ImportError{Line: pe.Line, Err: pe.Err}
+		}
+		return ImportOp{}, err
+	}
+	line, _ := c.r.FieldPos(0)
+	key := keyTemplateField.ReplaceAllStringFunc(c.opts.KeyTemplate, func(m string) string {
+		return row[c.column[m[1:len(m)-1]]]
+	})
+
+	if c.opts.KeyType == "string" {
+		return ImportOp{Key: key, Cmds: [][]string{{"SET", key, row[c.value]}}, Line: line}, nil
+	}
+	args := []string{"HSET", key}
+	for _, i := range c.fields {
+		args = append(args, c.header[i], row[i])
+	}
+	return ImportOp{Key: key, Cmds: [][]string{args}, Line: line}, nil
+}
+
+// scriptImportReader reads RESP arrays and inline commands, one op each.
+// connStateCommands change the connection they run on: later commands of
+// the pipeline, or whoever takes the connection from the pool next, would
+// see another database, user or protocol, a transaction or a subscription.
+var connStateCommands = map[string]bool{
+	"auth": true, "hello": true, "client": true, "reset": true, "quit": true,
+	"multi": true, "exec": true, "discard": true, "watch": true, "unwatch": true,
+	"subscribe": true, "psubscribe": true, "ssubscribe": true,
+	"unsubscribe": true, "punsubscribe": true, "sunsubscribe": true,
+	"monitor": true, "sync": true, "psync": true,
+	"readonly": true, "readwrite": true, "asking": true,
+}
+
+type scriptImportReader struct {
+	r     *bufio.Reader
+	table CommandTable
+	db    int
+	line  int
+}
+
+func (s *scriptImportReader) Next() (ImportOp, error) {
+	for {
+		b, err := s.r.Peek(1)
+		if err != nil {
+			return ImportOp{}, err
+		}
+		start := s.line + 1
+
+		var args []string
+		if b[0] == '*' {
+			// A broken RESP frame leaves no way to find the next command.
+			if args, err = s.readRESP(); err != nil {
+				return ImportOp{}, fmt.Errorf("line %d: %w", start, err)
+			}
+		} else {
+			text, err := s.readLine()
+			if err != nil && err != io.EOF {
+				return ImportOp{}, err
+			}
+			if args, err = SplitCommandLine(text); err != nil {
+				return ImportOp{}, &ImportError{Line: start, Err: err}
+			}
+		}
+		if len(args) == 0 {
+			continue
+		}
+
+		switch name := strings.ToLower(args[0]); {
+		case name == "select":
+			if n, err := strconv.Atoi(args[len(args)-1]); len(args) == 2 && err == nil && n == s.db {
+				continue // the import already writes to this database
+			}
+			return ImportOp{}, &ImportError{Line: start, Err: fmt.Errorf("SELECT would leave database %d", s.db)}
+		case connStateCommands[name]:
+			return ImportOp{}, &ImportError{Line: start, Err: fmt.Errorf("%s changes the connection state and cannot be imported", strings.ToUpper(name))}
+		}
+
+		op := ImportOp{Cmds: [][]string{args}, Line: start}
+		if keys, _ := s.table.Keys(args); len(keys) > 0 {
+			op.Key = keys[0]
+		}
+		return op, nil
+	}
+}
+
+func (s *scriptImportReader) readLine() (string, error) {
+	var buf []byte
+	for {
+		chunk, err := s.r.ReadSlice('\n')
+		buf = append(buf, chunk...)
+		if len(buf) > maxImportLine {
+			return "", errors.New("line too long")
+		}
+		if err == bufio.ErrBufferFull {
+			continue
+		}
+		if len(buf) > 0 || err == nil {
+			s.line++
+		}
+		return strings.TrimRight(string(buf), "\r\n"), err
+	}
+}
+
+func (s *scriptImportReader) readRESP() ([]string, error) {
+	head, err := s.readLine()
+	if err != nil && err != io.EOF {
+		return nil, err
+	}
+	n, err := strconv.Atoi(head[1:])
+	if err != nil || n < 0 || n > maxRESPArgs {
+		return nil, fmt.Errorf("bad RESP array header %q", head)
+	}
+	args := make([]string, n)
+	for i := range args {
+		bulk, err := s.readLine()
+		if err != nil {
+			return nil, fmt.Errorf("RESP bulk header: %w", err)
+		}
+		size, err := strconv.Atoi(strings.TrimPrefix(bulk, "$"))
+		if !strings.HasPrefix(bulk, "$") || err != nil || size < 0 || size > maxRESPBulk {
+			return nil, fmt.Errorf("bad RESP bulk header %q", bulk)
+		}
+		buf := make([]byte, size+2)
+		if _, err := io.ReadFull(s.r, buf); err != nil {
+			return nil, fmt.Errorf("RESP bulk: %w", err)
+		}
+		if buf[size] != '\r' || buf[size+1] != '\n' {
+			return nil, errors.New("RESP bulk does not end in CRLF")
+		}
+		s.line += 1 + bytes.Count(buf[:size], []byte{'\n'})
+		args[i] = string(buf[:size])
+	}
+	return args, nil
+}
+
+var inlineEscapes = map[byte]byte{'n': '\n', 'r': '\r', 't': '\t', 'b': '\b', 'a': '\a'}
+
+// SplitCommandLine splits an inline command the way redis-cli does: on
+// spaces, with "double quotes" taking \n, \t, \xff style escapes and
+// 'single quotes' only \'.
+func SplitCommandLine(line string) ([]string, error) {
+	var (
+		args []string
+		i    int
+	)
+	for {
+		for i < len(line) && (line[i] == ' ' || line[i] == '\t') {
+			i++
+		}
+		if i == len(line) {
+			return args, nil
+		}
+
+		var (
+			cur    []byte
+			quote  byte
+			closed bool
+		)
+		if line[i] == '"' || line[i] == '\'' {
+			quote = line[i]
+			i++
+		}
+		for i < len(line) {
+			ch := line[i]
+			if quote == 0 {
+				if ch == ' ' || ch == '\t' {
+					break
+				}
+				cur = append(cur, ch)
+				i++
+				continue
+			}
+			if ch == quote {
+				i++
+				closed = true
+				if i < len(line) && line[i] != ' ' && line[i] != '\t' {
+					return nil, errors.New("closing quote must be followed by a space")
+				}
+				break
+			}
+			if ch == '\\' && i+1 < len(line) {
+				next := line[i+1]
+				if quote == '\'' {
+					if next == '\'' {
+						cur = append(cur, '\'')
+						i += 2
+						continue
+					}
+				} else if v, ok := hexEscape(line[i:]); ok {
+					cur = append(cur, v)
+					i += 4
+					continue
+				} else {
+					if e, ok := inlineEscapes[next]; ok {
+						next = e
+					}
+					cur = append(cur, next)
+					i += 2
+					continue
+				}
+			}
+			cur = append(cur, ch)
+			i++
+		}
+		if quote != 0 && !closed {
+			return nil, errors.New("unbalanced quotes")
+		}
+		args = append(args, string(cur))
+	}
+}
+
+// hexEscape decodes a \xff escape at the start of s.
+func hexEscape(s string) (byte, bool) {
+	if len(s) < 4 || s[1] != 'x' {
+		return 0, false
+	}
+	v, err := strconv.ParseUint(s[2:4], 16, 8)
+	return byte(v), err == nil
+}
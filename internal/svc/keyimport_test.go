@@ -0,0 +1,241 @@
+package svc
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+)
+
+func readOps(t *testing.T, r ImportReader) (ops []ImportOp, errs []error) {
+	t.Helper()
+	for {
+		op, err := r.Next()
+		if err == io.EOF {
+			return ops, errs
+		}
+		var ie *ImportError
+		if errors.As(err, &ie) {
+			errs = append(errs, err)
+			continue
+		}
+		if err != nil {
+			t.Fatalf("next: %v", err)
+		}
+		ops = append(ops, op)
+	}
+}
+
+func opString(op ImportOp) string {
+	cmds := make([]string, len(op.Cmds))
+	for i, c := range op.Cmds {
+		cmds[i] = strings.Join(c, " ")
+	}
+	return fmt.Sprintf("%s@%d: %s", op.Key, op.Line, strings.Join(cmds, "; "))
+}
+
+// exportedOps are the ops writeKeys' keys read back as.
+var exportedOps = []string{
+	"s@%d: SET s v\xff; PEXPIRE s 1500",
+	"l@%d: RPUSH l a b c",
+	"z@%d: ZADD z 1.5 m",
+	"x@%d: XADD x 1-0 f v",
+}
+
+func TestImportReaderJSONLReadsExport(t *testing.T) {
+	data := writeKeys(t, KeyExportJSONL)
+	lines := strings.SplitAfter(data, "\n")
+	data = lines[0] + "{not json\n\n" + strings.Join(lines[1:], "")
+
+	r, err := NewImportReader(KeyExportJSONL, strings.NewReader(data), ImportOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	ops, errs := readOps(t, r)
+	if len(errs) != 1 || !strings.HasPrefix(errs[0].Error(), "line 2:") {
+		t.Errorf("errors = %v", errs)
+	}
+	if len(ops) != len(exportedOps) {
+		t.Fatalf("ops = %d, want %d", len(ops), len(exportedOps))
+	}
+	for i, want := range exportedOps {
+		line := []int{1, 4, 5, 6}[i]
+		if got := opString(ops[i]); got != fmt.Sprintf(want, line) {
+			t.Errorf("op %d = %q, want %q", i, got, fmt.Sprintf(want, line))
+		}
+	}
+}
+
+func TestImportReaderCSVReadsExport(t *testing.T) {
+	r, err := NewImportReader(KeyExportCSV, strings.NewReader(writeKeys(t, KeyExportCSV)), ImportOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	ops, errs := readOps(t, r)
+	if len(errs) != 0 || len(ops) != len(exportedOps) {
+		t.Fatalf("ops = %d, errors = %v", len(ops), errs)
+	}
+	for i, want := range exportedOps {
+		line := []int{2, 3, 6, 7}[i]
+		if got := opString(ops[i]); got != fmt.Sprintf(want, line) {
+			t.Errorf("op %d = %q, want %q", i, got, fmt.Sprintf(want, line))
+		}
+	}
+
+	if _, err := NewImportReader(KeyExportCSV, strings.NewReader("id,name\n1,a\n"), ImportOptions{}); err == nil {
+		t.Error("want an error for a header that is not the export layout")
+	}
+}
+
+func TestImportReaderCSVChunksLargeKeys(t *testing.T) {
+	var b strings.Builder
+	b.WriteString("key,type,ttl,field,value\n")
+	for i := range importChunk + 5 {
+		fmt.Fprintf(&b, "big,set,-1,,m%d\n", i)
+	}
+	b.WriteString("next,string,-1,,v\n")
+
+	r, _ := NewImportReader(KeyExportCSV, strings.NewReader(b.String()), ImportOptions{})
+	ops, _ := readOps(t, r)
+	if len(ops) != 3 || ops[0].Key != "big" || ops[1].Key != "big" || ops[2].Key != "next" {
+		t.Fatalf("ops = %d", len(ops))
+	}
+	if n := len(ops[0].Cmds[0]) - 2; n != importChunk {
+		t.Errorf("first chunk holds %d members", n)
+	}
+	if n := len(ops[1].Cmds[0]) - 2; n != 5 || ops[1].Line != importChunk+2 {
+		t.Errorf("second chunk holds %d members from line %d", n, ops[1].Line)
+	}
+}
+
+func TestImportReaderCSVTemplate(t *testing.T) {
+	data := "id;name;age\n1;ann;30\n2;bob\n3;cy;52\n"
+	r, err := NewImportReader(KeyExportCSV, strings.NewReader(data), ImportOptions{
+		Delimiter:   ';',
+		KeyTemplate: "user:{id}",
+		Fields:      []string{"name", "age"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	ops, errs := readOps(t, r)
+	if len(errs) != 1 || !strings.HasPrefix(errs[0].Error(), "line 3:") {
+		t.Errorf("errors = %v", errs)
+	}
+	want := []string{"user:1@2: HSET user:1 name ann age 30", "user:3@4: HSET user:3 name cy age 52"}
+	if len(ops) != len(want) {
+		t.Fatalf("ops = %d", len(ops))
+	}
+	for i := range want {
+		if got := opString(ops[i]); got != want[i] {
+			t.Errorf("op %d = %q, want %q", i, got, want[i])
+		}
+	}
+
+	r, _ = NewImportReader(KeyExportCSV, strings.NewReader("sku,price\nA1,9.5\n"), ImportOptions{
+		KeyTemplate: "price:{sku}",
+		KeyType:     "string",
+		ValueColumn: "price",
+	})
+	if ops, _ := readOps(t, r); len(ops) != 1 || opString(ops[0]) != "price:A1@2: SET price:A1 9.5" {
+		t.Errorf("string ops = %v", ops)
+	}
+
+	if _, err := NewImportReader(KeyExportCSV, strings.NewReader("id\n1\n"), ImportOptions{KeyTemplate: "user:{uid}"}); err == nil {
+		t.Error("want an error for a template column that does not exist")
+	}
+}
+
+func TestImportReaderScript(t *testing.T) {
+	table := CommandTable{
+		"set":   {Name: "set", FirstKeyPos: 1, LastKeyPos: 1, StepCount: 1, Flags: []string{"write"}},
+		"rpush": {Name: "rpush", FirstKeyPos: 1, LastKeyPos: 1, StepCount: 1, Flags: []string{"write"}},
+		"ping":  {Name: "ping"},
+	}
+	data := "*3\r\n$3\r\nSET\r\n$3\r\na\nb\r\n$2\r\nv1\r\n" +
+		"RPUSH list \"x y\" 'it\\'s'\n" +
+		"\n" +
+		"PING\r\n" +
+		"SET k \"unterminated\n" +
+		"SET bin \"\\x00\\xff\\n\"\n"
+
+	r, err := NewImportReader(KeyExportRESP, strings.NewReader(data), ImportOptions{Commands: table})
+	if err != nil {
+		t.Fatal(err)
+	}
+	ops, errs := readOps(t, r)
+	if len(errs) != 1 || !strings.HasPrefix(errs[0].Error(), "line 12:") {
+		t.Errorf("errors = %v", errs)
+	}
+	want := []string{
+		"a\nb@1: SET a\nb v1",
+		"list@9: RPUSH list x y it's",
+		"@11: PING",
+		"bin@13: SET bin \x00\xff\n",
+	}
+	if len(ops) != len(want) {
+		t.Fatalf("ops = %v", ops)
+	}
+	for i := range want {
+		if got := opString(ops[i]); got != want[i] {
+			t.Errorf("op %d = %q, want %q", i, got, want[i])
+		}
+	}
+
+	r, _ = NewImportReader(KeyExportRESP, strings.NewReader("*2\r\n$3\r\nGET\r\n$9\r\nshort\r\n"), ImportOptions{Commands: table})
+	if _, err := r.Next(); err == nil || errors.As(err, new(*ImportError)) {
+		t.Errorf("broken RESP = %v, want a fatal error", err)
+	}
+}
+
+func TestImportReaderScriptConnectionState(t *testing.T) {
+	table := CommandTable{
+		"set": {Name: "set", FirstKeyPos: 1, LastKeyPos: 1, StepCount: 1, Flags: []string{"write"}},
+	}
+	data := "SELECT 3\n" +
+		"SET a 1\n" +
+		"*2\r\n$6\r\nselect\r\n$1\r\n0\r\n" +
+		"AUTH user pass\n" +
+		"MULTI\n" +
+		"SET b 2\n" +
+		"EXEC\n" +
+		"client setname x\n"
+
+	r, err := NewImportReader(KeyExportRESP, strings.NewReader(data), ImportOptions{Commands: table, DB: 3})
+	if err != nil {
+		t.Fatal(err)
+	}
+	ops, errs := readOps(t, r)
+	var lines []string
+	for _, err := range errs {
+		lines = append(lines, strings.SplitN(err.Error(), ":", 2)[0])
+	}
+	if got := strings.Join(lines, ","); got != "line 3,line 8,line 9,line 11,line 12" {
+		t.Errorf("errors = %v", errs)
+	}
+	if len(ops) != 2 || opString(ops[0]) != "a@2: SET a 1" || opString(ops[1]) != "b@10: SET b 2" {
+		t.Errorf("ops = %v", ops)
+	}
+}
+
+func TestSplitCommandLine(t *testing.T) {
+	for _, tc := range []struct {
+		in   string
+		want []string
+		err  bool
+	}{
+		{in: "  SET  a b ", want: []string{"SET", "a", "b"}},
+		{in: `SET "a b" "c\"d\te"`, want: []string{"SET", "a b", "c\"d\te"}},
+		{in: `SET 'a\'b' ''`, want: []string{"SET", "a'b", ""}},
+		{in: `SET "\x41\x4g"`, want: []string{"SET", "Ax4g"}},
+		{in: `SET "a"b`, err: true},
+		{in: `SET 'a`, err: true},
+		{in: "", want: nil},
+	} {
+		got, err := SplitCommandLine(tc.in)
+		if (err != nil) != tc.err || fmt.Sprintf("%q", got) != fmt.Sprintf("%q", tc.want) {
+			t.Errorf("SplitCommandLine(%q) = %q, %v", tc.in, got, err)
+		}
+	}
+}
@@ -0,0 +1,190 @@
+package svc
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/tradalab/rdms/internal/types"
+)
+
+const (
+	defaultKeyReportSeparator = ":"
+	defaultKeyReportDepth     = 1
+	defaultKeyReportTop       = 20
+	keyReportPrefixRows       = 200
+	maxKeyReportPrefixes      = 10000
+	keyReportOtherPrefix      = "*"
+	keyReportNoExpiryBand     = -1
+)
+
+var (
+	reportMemoryBuckets   = []int64{0, 64, 256, 1 << 10, 4 << 10, 16 << 10, 64 << 10, 256 << 10, 1 << 20, 4 << 20, 16 << 20, 64 << 20}
+	reportElementsBuckets = []int64{0, 1, 10, 100, 1000, 10000, 100000, 1000000, 10000000}
+	// Seconds; keyReportNoExpiryBand holds keys without a TTL.
+	reportTtlBuckets = []int64{keyReportNoExpiryBand, 0, 60, 3600, 86400, 7 * 86400, 30 * 86400}
+)
+
+type keyReportType struct {
+	stat     types.KeyReportType
+	memHist  []int64
+	elemHist []int64
+}
+
+// KeyReport aggregates sized keys into the big-key and memory report shared
+// by the live scan and the RDB analyzer: totals and rankings per type, size
+// and TTL histograms, and sums per key prefix.
+type KeyReport struct {
+	separator string
+	depth     int
+	top       int
+	keys      int64
+	memory    int64
+	ttlHist   []int64
+	types     map[string]*keyReportType
+	prefixes  map[string]*types.KeyReportPrefix
+}
+
+// NewKeyReport groups prefixes by the first depth segments split on
+// separator and ranks top keys per type. Zero values take the defaults.
+func NewKeyReport(separator string, depth, top int) *KeyReport {
+	if separator == "" {
+		separator = defaultKeyReportSeparator
+	}
+	if depth <= 0 {
+		depth = defaultKeyReportDepth
+	}
+	if top <= 0 {
+		top = defaultKeyReportTop
+	}
+	return &KeyReport{
+		separator: separator,
+		depth:     depth,
+		top:       top,
+		ttlHist:   make([]int64, len(reportTtlBuckets)),
+		types:     make(map[string]*keyReportType),
+		prefixes:  make(map[string]*types.KeyReportPrefix),
+	}
+}
+
+// Add counts one key; k.Ttl is in milliseconds, negative without expiry.
+func (r *KeyReport) Add(k types.KeyReportKey) {
+	r.keys++
+	r.memory += k.Memory
+
+	ttl := int64(keyReportNoExpiryBand)
+	if k.Ttl >= 0 {
+		ttl = k.Ttl / 1000
+	}
+	r.ttlHist[bucketOf(reportTtlBuckets, ttl)]++
+
+	t, ok := r.types[k.Type]
+	if !ok {
+		t = &keyReportType{
+			stat:     types.KeyReportType{Type: k.Type},
+			memHist:  make([]int64, len(reportMemoryBuckets)),
+			elemHist: make([]int64, len(reportElementsBuckets)),
+		}
+		r.types[k.Type] = t
+	}
+	t.stat.Keys++
+	t.stat.Memory += k.Memory
+	t.stat.Elements += k.Elements
+	t.memHist[bucketOf(reportMemoryBuckets, k.Memory)]++
+	t.elemHist[bucketOf(reportElementsBuckets, k.Elements)]++
+	t.stat.TopMemory = insertTop(t.stat.TopMemory, k, r.top, func(k types.KeyReportKey) int64 { return k.Memory })
+	t.stat.TopElements = insertTop(t.stat.TopElements, k, r.top, func(k types.KeyReportKey) int64 { return k.Elements })
+
+	prefix := KeyPrefix(k.Key, r.separator, r.depth)
+	p, ok := r.prefixes[prefix]
+	if !ok {
+		if len(r.prefixes) >= maxKeyReportPrefixes {
+			prefix = keyReportOtherPrefix
+			p = r.prefixes[prefix]
+		}
+		if p == nil {
+			p = &types.KeyReportPrefix{Prefix: prefix}
+			r.prefixes[prefix] = p
+		}
+	}
+	p.Keys++
+	p.Memory += k.Memory
+	p.Elements += k.Elements
+}
+
+// Snapshot returns the report so far, types and prefixes largest first.
+// It shares nothing with r, so r may keep growing.
+func (r *KeyReport) Snapshot() *types.ClientKeysReportEvent {
+	ev := &types.ClientKeysReportEvent{
+		Keys:    r.keys,
+		Memory:  r.memory,
+		TtlHist: histogram(reportTtlBuckets, r.ttlHist),
+	}
+	for _, t := range r.types {
+		s := t.stat
+		s.TopMemory = append([]types.KeyReportKey(nil), s.TopMemory...)
+		s.TopElements = append([]types.KeyReportKey(nil), s.TopElements...)
+		s.MemoryHist = histogram(reportMemoryBuckets, t.memHist)
+		s.ElementsHist = histogram(reportElementsBuckets, t.elemHist)
+		ev.Types = append(ev.Types, s)
+	}
+	sort.Slice(ev.Types, func(i, j int) bool { return ev.Types[i].Memory > ev.Types[j].Memory })
+
+	for _, p := range r.prefixes {
+		ev.Prefixes = append(ev.Prefixes, *p)
+	}
+	sort.Slice(ev.Prefixes, func(i, j int) bool {
+		if ev.Prefixes[i].Memory != ev.Prefixes[j].Memory {
+			return ev.Prefixes[i].Memory > ev.Prefixes[j].Memory
+		}
+		return ev.Prefixes[i].Prefix < ev.Prefixes[j].Prefix
+	})
+	if len(ev.Prefixes) > keyReportPrefixRows {
+		ev.Prefixes = ev.Prefixes[:keyReportPrefixRows]
+	}
+	return ev
+}
+
+// KeyPrefix is the first depth separator-delimited segments of key with
+// the trailing separator, or fewer when the key has fewer; "" when it has
+// none.
+func KeyPrefix(key, sep string, depth int) string {
+	end := 0
+	for i := 0; i < depth; i++ {
+		j := strings.Index(key[end:], sep)
+		if j < 0 {
+			break
+		}
+		end += j + len(sep)
+	}
+	return key[:end]
+}
+
+func bucketOf(bounds []int64, v int64) int {
+	if v < bounds[0] {
+		return 0
+	}
+	return sort.Search(len(bounds), func(i int) bool { return bounds[i] > v }) - 1
+}
+
+func histogram(bounds, counts []int64) []types.KeyReportBucket {
+	out := make([]types.KeyReportBucket, len(bounds))
+	for i, b := range bounds {
+		out[i] = types.KeyReportBucket{Min: b, Count: counts[i]}
+	}
+	return out
+}
+
+// insertTop keeps top sorted by size, largest first, and at most n long.
+func insertTop(top []types.KeyReportKey, k types.KeyReportKey, n int, size func(types.KeyReportKey) int64) []types.KeyReportKey {
+	v := size(k)
+	if len(top) >= n && v <= size(top[len(top)-1]) {
+		return top
+	}
+	i := sort.Search(len(top), func(i int) bool { return size(top[i]) < v })
+	if len(top) < n {
+		top = append(top, types.KeyReportKey{})
+	}
+	copy(top[i+1:], top[i:])
+	top[i] = k
+	return top
+}
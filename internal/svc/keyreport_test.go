@@ -0,0 +1,43 @@
+package svc
+
+import (
+	"testing"
+
+	"github.com/tradalab/rdms/internal/types"
+)
+
+func TestKeyPrefix(t *testing.T) {
+	cases := []struct {
+		key, sep string
+		depth    int
+		want     string
+	}{
+		{"user:1:profile", ":", 1, "user:"},
+		{"user:1:profile", ":", 2, "user:1:"},
+		{"user:1", ":", 3, "user:"},
+		{"plain", ":", 1, ""},
+		{"a::b", "::", 1, "a::"},
+	}
+	for _, c := range cases {
+		if got := KeyPrefix(c.key, c.sep, c.depth); got != c.want {
+			t.Errorf("KeyPrefix(%q, %q, %d) = %q, want %q", c.key, c.sep, c.depth, got, c.want)
+		}
+	}
+}
+
+func TestKeyReportTtlHistogram(t *testing.T) {
+	r := NewKeyReport("", 0, 0)
+	for _, ttl := range []int64{-1, -1, 500, 90 * 1000, 2 * 86400 * 1000, 90 * 86400 * 1000} {
+		r.Add(types.KeyReportKey{Key: "k", Type: "string", Ttl: ttl})
+	}
+	var got []int64
+	for _, b := range r.Snapshot().TtlHist {
+		got = append(got, b.Count)
+	}
+	want := []int64{2, 1, 1, 0, 1, 0, 1}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("ttl histogram = %v, want %v", got, want)
+		}
+	}
+}
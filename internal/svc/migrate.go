@@ -5,21 +5,44 @@ import (
 	"fmt"
 )
 
+// addedColumns are columns introduced after the first release. CREATE TABLE
+// IF NOT EXISTS leaves existing tables alone, so they are added here.
+var addedColumns = []struct {
+	table, column, ddl string
+}{
+	{"connection", "read_only", `ALTER TABLE "connection" ADD COLUMN read_only INTEGER NOT NULL DEFAULT 0`},
+	{"connection", "ssh_jump_ids", `ALTER TABLE "connection" ADD COLUMN ssh_jump_ids TEXT NOT NULL DEFAULT ''`},
+	{"connection", "read_from", `ALTER TABLE "connection" ADD COLUMN read_from TEXT NOT NULL DEFAULT ''`},
+	{"connection", "cred_provider", `ALTER TABLE "connection" ADD COLUMN cred_provider TEXT NOT NULL DEFAULT ''`},
+	{"connection", "cred_source", `ALTER TABLE "connection" ADD COLUMN cred_source TEXT NOT NULL DEFAULT ''`},
+	{"connection", "cred_ttl", `ALTER TABLE "connection" ADD COLUMN cred_ttl INTEGER NOT NULL DEFAULT 0`},
+	{"tls", "ca_file", `ALTER TABLE "tls" ADD COLUMN ca_file TEXT NOT NULL DEFAULT ''`},
+	{"tls", "cert_file", `ALTER TABLE "tls" ADD COLUMN cert_file TEXT NOT NULL DEFAULT ''`},
+	{"tls", "key_file", `ALTER TABLE "tls" ADD COLUMN key_file TEXT NOT NULL DEFAULT ''`},
+	{"tls", "key_passphrase", `ALTER TABLE "tls" ADD COLUMN key_passphrase TEXT NOT NULL DEFAULT ''`},
+	{"tls", "min_version", `ALTER TABLE "tls" ADD COLUMN min_version TEXT NOT NULL DEFAULT ''`},
+	{"tls", "max_version", `ALTER TABLE "tls" ADD COLUMN max_version TEXT NOT NULL DEFAULT ''`},
+	{"tls", "cipher_suites", `ALTER TABLE "tls" ADD COLUMN cipher_suites TEXT NOT NULL DEFAULT ''`},
+	{"tls", "pin_sha256", `ALTER TABLE "tls" ADD COLUMN pin_sha256 TEXT NOT NULL DEFAULT ''`},
+}
+
 func (s *ServiceContext) MigrateSchema(ctx context.Context) error {
 	db := s.sqlx.DB()
 	if db == nil {
 		return fmt.Errorf("sqlx db not ready")
 	}
 
-	var n int
-	if err := db.GetContext(ctx, &n,
-		`SELECT COUNT(*) FROM pragma_table_info('connection') WHERE name = 'read_only'`); err != nil {
-		return fmt.Errorf("inspect connection columns: %w", err)
-	}
-	if n == 0 {
-		if _, err := db.ExecContext(ctx,
-			`ALTER TABLE "connection" ADD COLUMN read_only INTEGER NOT NULL DEFAULT 0`); err != nil {
-			return fmt.Errorf("add connection.read_only: %w", err)
+	for _, c := range addedColumns {
+		var n int
+		if err := db.GetContext(ctx, &n,
+			`SELECT COUNT(*) FROM pragma_table_info(?) WHERE name = ?`, c.table, c.column); err != nil {
+			return fmt.Errorf("inspect %s columns: %w", c.table, err)
+		}
+		if n > 0 {
+			continue
+		}
+		if _, err := db.ExecContext(ctx, c.ddl); err != nil {
+			return fmt.Errorf("add %s.%s: %w", c.table, c.column, err)
 		}
 	}
 	return nil
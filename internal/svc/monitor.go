@@ -0,0 +1,252 @@
+package svc
+
+import (
+	"bufio"
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// DialMonitor opens a dedicated connection with opt's dialer, TLS and
+// credentials, asking the credentials provider if there is one, and
+// switches it to MONITOR. Every line read from the returned
+// reader after that is one command the server ran.
+func DialMonitor(ctx context.Context, opt *redis.Options) (net.Conn, *bufio.Reader, error) {
+	dialer := opt.Dialer
+	if dialer == nil {
+		d := &net.Dialer{}
+		dialer = func(ctx context.Context, network, addr string) (net.Conn, error) {
+			return d.DialContext(ctx, network, addr)
+		}
+	}
+	conn, err := dialer(ctx, opt.Network, opt.Addr)
+	if err != nil {
+		return nil, nil, err
+	}
+	if opt.TLSConfig != nil {
+		tc := tls.Client(conn, opt.TLSConfig)
+		if err := tc.HandshakeContext(ctx); err != nil {
+			_ = conn.Close()
+			return nil, nil, err
+		}
+		conn = tc
+	}
+
+	br := bufio.NewReader(conn)
+	readReply := func() (string, error) {
+		l, err := br.ReadString('\n')
+		return strings.TrimRight(l, "\r\n"), err
+	}
+	expectOK := func(what string) error {
+		l, err := readReply()
+		if err != nil {
+			return err
+		}
+		if strings.HasPrefix(l, "-") {
+			return fmt.Errorf("%s rejected: %s", what, strings.TrimPrefix(l, "-"))
+		}
+		return nil
+	}
+
+	user, pass := opt.Username, opt.Password
+	switch {
+	case opt.CredentialsProviderContext != nil:
+		if user, pass, err = opt.CredentialsProviderContext(ctx); err != nil {
+			_ = conn.Close()
+			return nil, nil, err
+		}
+	case opt.CredentialsProvider != nil:
+		user, pass = opt.CredentialsProvider()
+	}
+	if pass != "" {
+		args := []string{"AUTH", pass}
+		if user != "" {
+			args = []string{"AUTH", user, pass}
+		}
+		if err := writeRESP(conn, args...); err != nil {
+			_ = conn.Close()
+			return nil, nil, err
+		}
+		if err := expectOK("AUTH"); err != nil {
+			_ = conn.Close()
+			return nil, nil, err
+		}
+	}
+
+	if err := writeRESP(conn, "MONITOR"); err != nil {
+		_ = conn.Close()
+		return nil, nil, err
+	}
+	if err := expectOK("MONITOR"); err != nil {
+		_ = conn.Close()
+		return nil, nil, err
+	}
+	return conn, br, nil
+}
+
+func writeRESP(w io.Writer, args ...string) error {
+	var b strings.Builder
+	fmt.Fprintf(&b, "*%d\r\n", len(args))
+	for _, a := range args {
+		fmt.Fprintf(&b, "$%d\r\n%s\r\n", len(a), a)
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// MonitorEntry is one command from the MONITOR stream.
+type MonitorEntry struct {
+	DB   int
+	Addr string // client address, "lua" for scripts, "unix:<path>" for sockets
+	Args []string
+}
+
+// ParseMonitorLine parses a MONITOR line such as
+//
+//	1700000000.123456 [0 127.0.0.1:6379] "set" "k" "v\x00"
+//
+// undoing the escaping Redis applies to every argument.
+func ParseMonitorLine(line string) (MonitorEntry, error) {
+	var e MonitorEntry
+	line = strings.TrimPrefix(line, "+")
+	open := strings.IndexByte(line, '[')
+	end := strings.IndexByte(line, ']')
+	if open < 0 || end < open {
+		return e, fmt.Errorf("monitor line without client: %q", line)
+	}
+	db, addr, _ := strings.Cut(line[open+1:end], " ")
+	n, err := strconv.Atoi(db)
+	if err != nil {
+		return e, fmt.Errorf("monitor line with bad db: %q", line)
+	}
+	e.DB, e.Addr = n, addr
+
+	rest := line[end+1:]
+	for {
+		rest = strings.TrimLeft(rest, " ")
+		if rest == "" {
+			return e, nil
+		}
+		arg, tail, err := unquoteMonitorArg(rest)
+		if err != nil {
+			return e, fmt.Errorf("%w: %q", err, line)
+		}
+		e.Args = append(e.Args, arg)
+		rest = tail
+	}
+}
+
+var errMonitorQuote = errors.New("monitor line with bad quoting")
+
+func unquoteMonitorArg(s string) (string, string, error) {
+	if s[0] != '"' {
+		return "", "", errMonitorQuote
+	}
+	var b strings.Builder
+	for i := 1; i < len(s); i++ {
+		c := s[i]
+		if c == '"' {
+			return b.String(), s[i+1:], nil
+		}
+		if c != '\\' {
+			b.WriteByte(c)
+			continue
+		}
+		i++
+		if i >= len(s) {
+			break
+		}
+		switch s[i] {
+		case 'n':
+			b.WriteByte('\n')
+		case 'r':
+			b.WriteByte('\r')
+		case 't':
+			b.WriteByte('\t')
+		case 'a':
+			b.WriteByte('\a')
+		case 'b':
+			b.WriteByte('\b')
+		case 'x':
+			if i+2 >= len(s) {
+				return "", "", errMonitorQuote
+			}
+			v, err := strconv.ParseUint(s[i+1:i+3], 16, 8)
+			if err != nil {
+				return "", "", errMonitorQuote
+			}
+			b.WriteByte(byte(v))
+			i += 2
+		default: // \" and \\
+			b.WriteByte(s[i])
+		}
+	}
+	return "", "", errMonitorQuote
+}
+
+// CommandTable holds the key positions and flags from COMMAND, keyed by
+// lower-case command name.
+type CommandTable map[string]*redis.CommandInfo
+
+func LoadCommandTable(ctx context.Context, rdb redis.Cmdable) (CommandTable, error) {
+	infos, err := rdb.Command(ctx).Result()
+	if err != nil {
+		return nil, fmt.Errorf("COMMAND: %w", err)
+	}
+	t := make(CommandTable, len(infos))
+	for name, info := range infos {
+		if info != nil {
+			t[strings.ToLower(name)] = info
+		}
+	}
+	return t, nil
+}
+
+// Keys returns the keys args names and whether the command writes. Scripts
+// and functions take their keys from numkeys; other commands with movable
+// keys yield only the fixed positions COMMAND reports.
+func (t CommandTable) Keys(args []string) (keys []string, write bool) {
+	if len(args) == 0 {
+		return nil, false
+	}
+	name := strings.ToLower(args[0])
+	info := t[name]
+	if info == nil {
+		return nil, false
+	}
+	write = slices.Contains(info.Flags, "write")
+
+	switch name {
+	case "eval", "evalsha", "eval_ro", "evalsha_ro", "fcall", "fcall_ro":
+		if len(args) < 3 {
+			return nil, write
+		}
+		n, err := strconv.Atoi(args[2])
+		if err != nil || n < 0 || 3+n > len(args) {
+			return nil, write
+		}
+		return args[3 : 3+n], write
+	}
+
+	first, last, step := int(info.FirstKeyPos), int(info.LastKeyPos), int(info.StepCount)
+	if first <= 0 || first >= len(args) {
+		return nil, write
+	}
+	if last < 0 {
+		last += len(args)
+	}
+	last = min(last, len(args)-1)
+	step = max(step, 1)
+	for i := first; i <= last; i += step {
+		keys = append(keys, args[i])
+	}
+	return keys, write
+}
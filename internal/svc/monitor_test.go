@@ -0,0 +1,60 @@
+package svc
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseMonitorLine(t *testing.T) {
+	e, err := ParseMonitorLine(`+1700000000.123456 [3 10.0.0.7:51234] "SET" "a \"b\"" "\x00\xff\\n\r\n"`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := MonitorEntry{DB: 3, Addr: "10.0.0.7:51234", Args: []string{"SET", `a "b"`, "\x00\xff\\n\r\n"}}
+	if !reflect.DeepEqual(e, want) {
+		t.Errorf("got %+v, want %+v", e, want)
+	}
+
+	e, err = ParseMonitorLine(`1700000000.1 [0 lua] "get" "k"`)
+	if err != nil || e.Addr != "lua" || len(e.Args) != 2 {
+		t.Errorf("lua line = %+v, %v", e, err)
+	}
+
+	for _, bad := range []string{"OK", `1.0 [x 1.2.3.4:5] "get"`, `1.0 [0 a] "get`, `1.0 [0 a] "\xZZ"`, `1.0 [0 a] get`} {
+		if _, err := ParseMonitorLine(bad); err == nil {
+			t.Errorf("%q parsed", bad)
+		}
+	}
+}
+
+func TestCommandTableKeys(t *testing.T) {
+	table := CommandTable{
+		"get":   {FirstKeyPos: 1, LastKeyPos: 1, StepCount: 1, Flags: []string{"readonly"}},
+		"mset":  {FirstKeyPos: 1, LastKeyPos: -1, StepCount: 2, Flags: []string{"write"}},
+		"del":   {FirstKeyPos: 1, LastKeyPos: -1, StepCount: 1, Flags: []string{"write"}},
+		"eval":  {FirstKeyPos: 0, LastKeyPos: 0, StepCount: 0, Flags: []string{"noscript", "movablekeys"}},
+		"ping":  {FirstKeyPos: 0, LastKeyPos: 0, StepCount: 0},
+		"hset":  {FirstKeyPos: 1, LastKeyPos: 1, StepCount: 1, Flags: []string{"write"}},
+		"debug": {FirstKeyPos: 0, Flags: []string{"admin"}},
+	}
+	cases := []struct {
+		args  []string
+		keys  []string
+		write bool
+	}{
+		{[]string{"GET", "a"}, []string{"a"}, false},
+		{[]string{"mset", "a", "1", "b", "2"}, []string{"a", "b"}, true},
+		{[]string{"del", "a", "b", "c"}, []string{"a", "b", "c"}, true},
+		{[]string{"eval", "return 1", "2", "k1", "k2", "arg"}, []string{"k1", "k2"}, false},
+		{[]string{"eval", "return 1", "5", "k1"}, nil, false},
+		{[]string{"ping"}, nil, false},
+		{[]string{"hset"}, nil, true},
+		{[]string{"unknown", "a"}, nil, false},
+	}
+	for _, c := range cases {
+		keys, write := table.Keys(c.args)
+		if !reflect.DeepEqual(keys, c.keys) || write != c.write {
+			t.Errorf("%v: keys %v write %v, want %v %v", c.args, keys, write, c.keys, c.write)
+		}
+	}
+}
@@ -0,0 +1,300 @@
+package svc
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/tradalab/rdms/internal/model"
+	"github.com/tradalab/rdms/pkg/netx"
+	"golang.org/x/net/http/httpproxy"
+	"golang.org/x/net/proxy"
+)
+
+// getenv is os.Getenv, swapped in tests.
+var getenv = os.Getenv
+
+// proxyDialer returns a dialer that reaches targets through p. An unknown
+// protocol is an error: connections never fall back to dialing direct.
+func proxyDialer(p *model.Proxy, timeout time.Duration) (ContextDialer, error) {
+	switch p.Protocol {
+	case model.ProxyHttp, model.ProxyHttps:
+		d := &netx.HttpConnectDialer{
+			ProxyAddr: p.Addr(),
+			Username:  p.Username,
+			Password:  p.Password,
+			Timeout:   timeout,
+		}
+		if p.Protocol == model.ProxyHttps {
+			d.TLSConfig = &tls.Config{}
+		}
+		return d, nil
+	case model.ProxySocks5, model.ProxySocks5h:
+		var auth *proxy.Auth
+		if p.Username != "" {
+			auth = &proxy.Auth{
+				User:     p.Username,
+				Password: p.Password,
+			}
+		}
+		pd, err := proxy.SOCKS5("tcp", p.Addr(), auth, &net.Dialer{Timeout: timeout})
+		if err != nil {
+			return nil, fmt.Errorf("socks5 proxy setup failed: %w", err)
+		}
+		var cd ContextDialer
+		if d, ok := pd.(proxy.ContextDialer); ok {
+			cd = d
+		} else {
+			cd = netx.NewContextWrapper(pd)
+		}
+		// x/net passes names through, which is socks5h.
+		if p.Protocol == model.ProxySocks5 {
+			return &netx.ResolvingDialer{Next: cd}, nil
+		}
+		return cd, nil
+	case model.ProxySocks4, model.ProxySocks4a:
+		return &netx.Socks4Dialer{
+			ProxyAddr: p.Addr(),
+			UserID:    p.Username,
+			RemoteDNS: p.Protocol == model.ProxySocks4a,
+			Timeout:   timeout,
+		}, nil
+	case model.ProxySystem:
+		return newSystemProxyDialer(timeout)
+	}
+	return nil, fmt.Errorf("unknown proxy protocol %q", p.Protocol)
+}
+
+// systemProxy reads the proxy settings of the environment. Redis is not
+// HTTP, so HTTP_PROXY is ignored: HTTPS_PROXY wins, then ALL_PROXY.
+func systemProxy() (*url.URL, func(addr string) (*url.URL, error), error) {
+	raw := firstEnv("HTTPS_PROXY", "https_proxy", "ALL_PROXY", "all_proxy")
+	if raw == "" {
+		return nil, nil, fmt.Errorf("system proxy selected but neither HTTPS_PROXY nor ALL_PROXY is set")
+	}
+	u, err := parseProxyURL(raw)
+	if err != nil {
+		return nil, nil, err
+	}
+	match := (&httpproxy.Config{
+		HTTPSProxy: u.String(),
+		NoProxy:    firstEnv("NO_PROXY", "no_proxy"),
+	}).ProxyFunc()
+	return u, func(addr string) (*url.URL, error) {
+		return match(&url.URL{Scheme: "https", Host: addr})
+	}, nil
+}
+
+func firstEnv(names ...string) string {
+	for _, n := range names {
+		if v := strings.TrimSpace(getenv(n)); v != "" {
+			return v
+		}
+	}
+	return ""
+}
+
+func parseProxyURL(raw string) (*url.URL, error) {
+	if !strings.Contains(raw, "://") {
+		raw = "http://" + raw
+	}
+	u, err := url.Parse(raw)
+	if err != nil {
+		return nil, fmt.Errorf("invalid proxy url: %w", err)
+	}
+	if u.Hostname() == "" {
+		return nil, fmt.Errorf("invalid proxy url %q: no host", u.Redacted())
+	}
+	if !model.ValidProxyProtocol(u.Scheme) || u.Scheme == model.ProxySystem {
+		return nil, fmt.Errorf("unknown proxy protocol %q", u.Scheme)
+	}
+	return u, nil
+}
+
+// proxyFromURL turns an environment proxy URL into a profile.
+func proxyFromURL(u *url.URL) *model.Proxy {
+	p := &model.Proxy{
+		Protocol: u.Scheme,
+		Host:     u.Hostname(),
+		Username: u.User.Username(),
+	}
+	p.Password, _ = u.User.Password()
+	switch port := u.Port(); {
+	case port != "":
+		fmt.Sscan(port, &p.Port)
+	case p.Protocol == model.ProxyHttp:
+		p.Port = 80
+	case p.Protocol == model.ProxyHttps:
+		p.Port = 443
+	default:
+		p.Port = 1080
+	}
+	return p
+}
+
+type systemProxyDialer struct {
+	match  func(addr string) (*url.URL, error)
+	via    ContextDialer
+	direct *net.Dialer
+}
+
+func newSystemProxyDialer(timeout time.Duration) (ContextDialer, error) {
+	u, match, err := systemProxy()
+	if err != nil {
+		return nil, err
+	}
+	via, err := proxyDialer(proxyFromURL(u), timeout)
+	if err != nil {
+		return nil, err
+	}
+	return &systemProxyDialer{
+		match:  match,
+		via:    via,
+		direct: &net.Dialer{Timeout: timeout},
+	}, nil
+}
+
+func (d *systemProxyDialer) DialContext(ctx context.Context, network, addr string) (net.Conn, error) {
+	u, err := d.match(addr)
+	if err != nil {
+		return nil, err
+	}
+	if u == nil {
+		return d.direct.DialContext(ctx, network, addr)
+	}
+	return d.via.DialContext(ctx, network, addr)
+}
+
+// ProxyStep is one stage of a proxy test.
+type ProxyStep struct {
+	Name    string
+	Err     error
+	Detail  string
+	Latency time.Duration
+}
+
+// ProxyReport is what TestProxy found. Via is the proxy actually used,
+// which differs from the profile in system mode.
+type ProxyReport struct {
+	Via   string
+	Steps []*ProxyStep
+}
+
+func (r *ProxyReport) step(name string, start time.Time, detail string, err error) bool {
+	r.Steps = append(r.Steps, &ProxyStep{Name: name, Err: err, Detail: detail, Latency: time.Since(start)})
+	return err == nil
+}
+
+// TestProxy checks a proxy by itself: it reaches the proxy, completes TLS
+// for HTTPS proxies, and runs the protocol handshake to see whether the
+// credentials are accepted. A non-empty target is also tunnelled to.
+func TestProxy(ctx context.Context, p *model.Proxy, target string, timeout time.Duration) (*ProxyReport, error) {
+	if !model.ValidProxyProtocol(p.Protocol) {
+		return nil, fmt.Errorf("unknown proxy protocol %q", p.Protocol)
+	}
+	rep := &ProxyReport{}
+
+	if p.Protocol == model.ProxySystem {
+		start := time.Now()
+		u, match, err := systemProxy()
+		if !rep.step("environment", start, "", err) {
+			return rep, nil
+		}
+		if target != "" {
+			if m, err := match(target); err != nil {
+				rep.Steps[0].Err = err
+				return rep, nil
+			} else if m == nil {
+				rep.Steps[0].Detail = "NO_PROXY matches " + target + ", dialed direct"
+				start = time.Now()
+				conn, err := (&net.Dialer{Timeout: timeout}).DialContext(ctx, "tcp", target)
+				if err == nil {
+					conn.Close()
+				}
+				rep.step("tunnel", start, target, err)
+				return rep, nil
+			}
+		}
+		rep.Steps[0].Detail = u.Redacted()
+		p = proxyFromURL(u)
+	}
+	rep.Via = (&url.URL{Scheme: p.Protocol, Host: p.Addr()}).String()
+
+	start := time.Now()
+	conn, err := (&net.Dialer{Timeout: timeout}).DialContext(ctx, "tcp", p.Addr())
+	if !rep.step("connect", start, p.Addr(), err) {
+		return rep, nil
+	}
+	conn.Close()
+
+	probe := target
+	if probe == "" {
+		// Any address will do: only the proxy's answer to it matters.
+		probe = p.Addr()
+	}
+
+	start = time.Now()
+	switch p.Protocol {
+	case model.ProxyHttp, model.ProxyHttps:
+		d, _ := proxyDialer(p, timeout)
+		hd := d.(*netx.HttpConnectDialer)
+		if hd.TLSConfig != nil {
+			conn, err := hd.DialProxy(ctx)
+			detail := ""
+			if err == nil {
+				cs := conn.(*tls.Conn).ConnectionState()
+				detail = tls.VersionName(cs.Version) + " " + tls.CipherSuiteName(cs.CipherSuite)
+				conn.Close()
+			}
+			if !rep.step("tls", start, detail, err) {
+				return rep, nil
+			}
+			start = time.Now()
+		}
+		resp, err := hd.Probe(ctx, probe)
+		detail := ""
+		if err == nil {
+			detail = resp.Status
+			if resp.StatusCode == http.StatusProxyAuthRequired {
+				err = fmt.Errorf("proxy rejected the credentials: %s", resp.Status)
+			}
+		}
+		if !rep.step("handshake", start, detail, err) {
+			return rep, nil
+		}
+	case model.ProxySocks5, model.ProxySocks5h:
+		if !rep.step("handshake", start, "", netx.Socks5Probe(ctx, p.Addr(), p.Username, p.Password, timeout)) {
+			return rep, nil
+		}
+	case model.ProxySocks4, model.ProxySocks4a:
+		d, _ := proxyDialer(p, timeout)
+		code, err := d.(*netx.Socks4Dialer).Probe(ctx, probe)
+		detail := ""
+		if err == nil {
+			detail = fmt.Sprintf("reply %#x", code)
+		}
+		if !rep.step("handshake", start, detail, err) {
+			return rep, nil
+		}
+	}
+
+	if target != "" {
+		d, err := proxyDialer(p, timeout)
+		if err != nil {
+			return nil, err
+		}
+		start = time.Now()
+		conn, err := d.DialContext(ctx, "tcp", target)
+		if err == nil {
+			conn.Close()
+		}
+		rep.step("tunnel", start, target, err)
+	}
+	return rep, nil
+}
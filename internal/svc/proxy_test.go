@@ -0,0 +1,164 @@
+package svc
+
+import (
+	"bufio"
+	"context"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/tradalab/rdms/internal/model"
+)
+
+// serve accepts on a local listener and hands each connection to fn.
+func serve(t *testing.T, fn func(net.Conn)) *model.Proxy {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { ln.Close() })
+	go func() {
+		for {
+			c, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				defer c.Close()
+				fn(c)
+			}()
+		}
+	}()
+	host, port, _ := net.SplitHostPort(ln.Addr().String())
+	p, _ := strconv.Atoi(port)
+	return &model.Proxy{Host: host, Port: int64(p)}
+}
+
+func TestProxyDialerUnknownProtocol(t *testing.T) {
+	for _, proto := range []string{"", "socks6", "ftp"} {
+		if _, err := proxyDialer(&model.Proxy{Protocol: proto, Host: "h", Port: 1}, time.Second); err == nil {
+			t.Errorf("%q must fail instead of dialing direct", proto)
+		}
+	}
+}
+
+func TestSocks4aSendsHostname(t *testing.T) {
+	got := make(chan string, 1)
+	p := serve(t, func(c net.Conn) {
+		r := bufio.NewReader(c)
+		head := make([]byte, 8)
+		if _, err := io.ReadFull(r, head); err != nil {
+			return
+		}
+		user, _ := r.ReadString(0)
+		host := ""
+		if head[4] == 0 && head[5] == 0 && head[6] == 0 {
+			host, _ = r.ReadString(0)
+		}
+		select {
+		case got <- net.IP(head[4:8]).String() + "|" + strings.TrimSuffix(user, "\x00") + "|" + strings.TrimSuffix(host, "\x00"):
+		default:
+		}
+		_, _ = c.Write([]byte{0, 0x5a, 0, 0, 0, 0, 0, 0})
+	})
+	p.Protocol, p.Username = model.ProxySocks4a, "me"
+
+	d, err := proxyDialer(p, time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+	conn, err := d.DialContext(context.Background(), "tcp", "redis.internal:6379")
+	if err != nil {
+		t.Fatal(err)
+	}
+	conn.Close()
+	if s := <-got; s != "0.0.0.1|me|redis.internal" {
+		t.Errorf("request = %q", s)
+	}
+
+	rep, err := TestProxy(context.Background(), p, "", time.Second)
+	if err != nil || len(rep.Steps) != 2 || rep.Steps[1].Err != nil {
+		t.Fatalf("report = %+v, %v", rep, err)
+	}
+}
+
+func TestTestProxyHttpCredentials(t *testing.T) {
+	p := serve(t, func(c net.Conn) {
+		req, err := http.ReadRequest(bufio.NewReader(c))
+		if err != nil {
+			return
+		}
+		status := "407 Proxy Authentication Required"
+		if req.Header.Get("Proxy-Authorization") == "Basic dTpw" {
+			status = "200 Connection established"
+		}
+		_, _ = io.WriteString(c, "HTTP/1.1 "+status+"\r\n\r\n")
+	})
+	p.Protocol = model.ProxyHttp
+	ctx := context.Background()
+
+	rep, _ := TestProxy(ctx, p, "", time.Second)
+	if last := rep.Steps[len(rep.Steps)-1]; last.Name != "handshake" || last.Err == nil {
+		t.Errorf("missing credentials must fail the handshake: %+v", last)
+	}
+
+	p.Username, p.Password = "u", "p"
+	rep, _ = TestProxy(ctx, p, "redis:6379", time.Second)
+	for _, s := range rep.Steps {
+		if s.Err != nil {
+			t.Errorf("%s: %v", s.Name, s.Err)
+		}
+	}
+	if len(rep.Steps) != 3 || rep.Via != "http://"+p.Addr() {
+		t.Errorf("report = %+v", rep)
+	}
+}
+
+func TestSystemProxy(t *testing.T) {
+	env := map[string]string{}
+	getenv = func(k string) string { return env[k] }
+	t.Cleanup(func() { getenv = os.Getenv })
+
+	if _, err := proxyDialer(&model.Proxy{Protocol: model.ProxySystem}, time.Second); err == nil {
+		t.Error("system mode without a proxy set must fail")
+	}
+
+	env["all_proxy"] = "socks5h://u:p@10.0.0.1"
+	env["HTTPS_PROXY"] = "proxy.corp:3128"
+	env["NO_PROXY"] = "localhost,.internal,10.1.0.0/16"
+	u, match, err := systemProxy()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if u.String() != "http://proxy.corp:3128" || proxyFromURL(u).Port != 3128 {
+		t.Errorf("HTTPS_PROXY must win: %s", u)
+	}
+	for addr, direct := range map[string]bool{
+		"redis.internal:6379": true,
+		"10.1.2.3:6379":       true,
+		"10.2.0.1:6379":       false,
+		"redis.example:6379":  false,
+	} {
+		m, err := match(addr)
+		if err != nil || (m == nil) != direct {
+			t.Errorf("%s: proxy %v, err %v", addr, m, err)
+		}
+	}
+
+	delete(env, "HTTPS_PROXY")
+	u, _, _ = systemProxy()
+	if p := proxyFromURL(u); p.Protocol != model.ProxySocks5h || p.Port != 1080 || p.Password != "p" {
+		t.Errorf("ALL_PROXY = %+v", p)
+	}
+
+	env["ALL_PROXY"] = "gopher://x"
+	if _, _, err := systemProxy(); err == nil {
+		t.Error("unknown scheme must fail")
+	}
+}
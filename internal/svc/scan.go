@@ -0,0 +1,231 @@
+package svc
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// ScanNode is one keyspace a SCAN has to walk: the client itself for
+// standalone/sentinel, every shard for cluster.
+type ScanNode struct {
+	Addr string
+	Rdb  redis.Cmdable
+}
+
+// ScanStep is a node still to be scanned and the SCAN cursor it stopped at.
+type ScanStep struct {
+	Node   ScanNode
+	Cursor uint64
+}
+
+// ScanPlan tracks per-node progress of a keyspace scan. Its cursor form is a
+// plain number for single-node scans (wire-compatible with a raw SCAN cursor)
+// and "addr=cursor;addr=cursor" for cluster scans, listing only the nodes
+// that are not finished yet. "0" means there is nothing left to scan.
+type ScanPlan struct {
+	Steps   []ScanStep
+	cluster bool
+}
+
+// ScanTarget says which cluster nodes a scan may walk.
+type ScanTarget int
+
+const (
+	// ScanMasters walks every master. Scans that feed writes, such as a
+	// delete by prefix, must use it: replicas refuse the writes.
+	ScanMasters ScanTarget = iota
+	// ScanReadNodes walks one replica per shard when the cluster client
+	// routes reads to replicas, and the masters otherwise. For browsing and
+	// reports on the Reader client.
+	ScanReadNodes
+)
+
+func ScanNodes(ctx context.Context, rdb redis.UniversalClient, target ScanTarget) ([]ScanNode, error) {
+	cc, ok := rdb.(*redis.ClusterClient)
+	if !ok {
+		addr := ""
+		if c, ok := rdb.(*redis.Client); ok {
+			addr = c.Options().Addr
+		}
+		return []ScanNode{{Addr: addr, Rdb: rdb}}, nil
+	}
+
+	if o := cc.Options(); target == ScanReadNodes && (o.ReadOnly || o.RouteByLatency || o.RouteRandomly) {
+		return replicaScanNodes(ctx, cc)
+	}
+
+	var (
+		mu    sync.Mutex
+		nodes []ScanNode
+	)
+	err := cc.ForEachMaster(ctx, func(ctx context.Context, c *redis.Client) error {
+		mu.Lock()
+		nodes = append(nodes, ScanNode{Addr: c.Options().Addr, Rdb: c})
+		mu.Unlock()
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("list cluster masters: %w", err)
+	}
+	if len(nodes) == 0 {
+		return nil, fmt.Errorf("cluster has no reachable masters")
+	}
+	sort.Slice(nodes, func(i, j int) bool { return nodes[i].Addr < nodes[j].Addr })
+	return nodes, nil
+}
+
+// replicaScanNodes picks one node per shard for a cluster that reads from
+// replicas: the first replica the client knows, else the master.
+func replicaScanNodes(ctx context.Context, cc *redis.ClusterClient) ([]ScanNode, error) {
+	slots, err := cc.ClusterSlots(ctx).Result()
+	if err != nil {
+		return nil, fmt.Errorf("list cluster slots: %w", err)
+	}
+	var mu sync.Mutex
+	clients := make(map[string]*redis.Client)
+	err = cc.ForEachShard(ctx, func(ctx context.Context, c *redis.Client) error {
+		mu.Lock()
+		clients[c.Options().Addr] = c
+		mu.Unlock()
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("list cluster nodes: %w", err)
+	}
+
+	var nodes []ScanNode
+	seen := make(map[string]bool)
+	for _, s := range slots {
+		if len(s.Nodes) == 0 || seen[s.Nodes[0].Addr] {
+			continue
+		}
+		seen[s.Nodes[0].Addr] = true
+		pick := s.Nodes[0].Addr
+		for _, n := range s.Nodes[1:] {
+			if _, ok := clients[n.Addr]; ok {
+				pick = n.Addr
+				break
+			}
+		}
+		if c, ok := clients[pick]; ok {
+			nodes = append(nodes, ScanNode{Addr: pick, Rdb: c})
+		}
+	}
+	if len(nodes) == 0 {
+		return nil, fmt.Errorf("cluster has no reachable nodes")
+	}
+	sort.Slice(nodes, func(i, j int) bool { return nodes[i].Addr < nodes[j].Addr })
+	return nodes, nil
+}
+
+// PlanScan resolves cursor against the current topology of the target
+// nodes. An empty or "0" cursor starts every node from the beginning.
+func PlanScan(ctx context.Context, rdb redis.UniversalClient, target ScanTarget, cursor string) (*ScanPlan, error) {
+	nodes, err := ScanNodes(ctx, rdb, target)
+	if err != nil {
+		return nil, err
+	}
+	_, cluster := rdb.(*redis.ClusterClient)
+	plan := &ScanPlan{cluster: cluster}
+
+	if cursor == "" || cursor == "0" {
+		for _, n := range nodes {
+			plan.Steps = append(plan.Steps, ScanStep{Node: n})
+		}
+		return plan, nil
+	}
+
+	if !cluster {
+		c, err := strconv.ParseUint(cursor, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid scan cursor %q", cursor)
+		}
+		plan.Steps = []ScanStep{{Node: nodes[0], Cursor: c}}
+		return plan, nil
+	}
+
+	byAddr := make(map[string]ScanNode, len(nodes))
+	for _, n := range nodes {
+		byAddr[n.Addr] = n
+	}
+	for _, part := range strings.Split(cursor, ";") {
+		addr, num, ok := strings.Cut(part, "=")
+		if !ok {
+			return nil, fmt.Errorf("invalid cluster scan cursor %q", cursor)
+		}
+		c, err := strconv.ParseUint(num, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid cluster scan cursor %q", cursor)
+		}
+		n, ok := byAddr[addr]
+		if !ok {
+			return nil, fmt.Errorf("node %s is no longer scanned, restart the scan", addr)
+		}
+		plan.Steps = append(plan.Steps, ScanStep{Node: n, Cursor: c})
+	}
+	return plan, nil
+}
+
+func (p *ScanPlan) Done() bool {
+	return len(p.Steps) == 0
+}
+
+// Advance records the cursor SCAN returned for the current (first) node and
+// drops the node once it wraps back to 0.
+func (p *ScanPlan) Advance(next uint64) {
+	if len(p.Steps) == 0 {
+		return
+	}
+	if next == 0 {
+		p.Steps = p.Steps[1:]
+		return
+	}
+	p.Steps[0].Cursor = next
+}
+
+func (p *ScanPlan) Cursor() string {
+	if len(p.Steps) == 0 {
+		return "0"
+	}
+	if !p.cluster {
+		return strconv.FormatUint(p.Steps[0].Cursor, 10)
+	}
+	parts := make([]string, 0, len(p.Steps))
+	for _, s := range p.Steps {
+		parts = append(parts, s.Node.Addr+"="+strconv.FormatUint(s.Cursor, 10))
+	}
+	return strings.Join(parts, ";")
+}
+
+// Page runs one SCAN round on the current node and advances the plan.
+func (p *ScanPlan) Page(ctx context.Context, match, keyType string, count int64) ([]string, error) {
+	if len(p.Steps) == 0 {
+		return nil, nil
+	}
+	step := p.Steps[0]
+
+	var (
+		keys []string
+		next uint64
+		err  error
+	)
+	if keyType != "" {
+		keys, next, err = step.Node.Rdb.ScanType(ctx, step.Cursor, match, count, keyType).Result()
+	} else {
+		keys, next, err = step.Node.Rdb.Scan(ctx, step.Cursor, match, count).Result()
+	}
+	if err != nil {
+		if p.cluster {
+			return nil, fmt.Errorf("scan %s: %w", step.Node.Addr, err)
+		}
+		return nil, err
+	}
+	p.Advance(next)
+	return keys, nil
+}
@@ -0,0 +1,277 @@
+package svc
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// SentinelEventChannels are the sentinel pub/sub channels worth watching
+// during a failover.
+var SentinelEventChannels = []string{
+	"+switch-master",
+	"+sdown", "-sdown",
+	"+odown", "-odown",
+	"+try-failover",
+	"+elected-leader",
+	"+failover-end",
+	"-failover-abort-no-good-slave",
+}
+
+var ErrNotSentinel = errors.New("connection is not in sentinel mode")
+
+// SentinelNode is one configured sentinel address and whether it answered.
+type SentinelNode struct {
+	Addr    string
+	Err     error
+	Latency time.Duration
+}
+
+// SentinelMaster is a monitored master as reported by the first sentinel
+// that knows it. ReportedBy lists every sentinel that monitors it.
+type SentinelMaster struct {
+	Info       map[string]string
+	Replicas   []map[string]string
+	Sentinels  []map[string]string
+	ReportedBy []string
+}
+
+func (m *SentinelMaster) Name() string { return m.Info["name"] }
+
+func (m *SentinelMaster) Addr() string {
+	return net.JoinHostPort(m.Info["ip"], m.Info["port"])
+}
+
+type SentinelTopology struct {
+	Nodes   []*SentinelNode
+	Masters []*SentinelMaster
+}
+
+// SentinelEvent is one message from a sentinel's event channels.
+type SentinelEvent struct {
+	Sentinel string
+	Event    string
+	Master   string
+	Message  string
+	Time     time.Time
+}
+
+type sentinelConn struct {
+	addr string
+	*redis.SentinelClient
+}
+
+// sentinelClients opens a client per configured sentinel address over the
+// connection's transport. The caller closes them.
+func (c *Client) sentinelClients() ([]sentinelConn, error) {
+	if c.Cfg.Mode != "sentinel" {
+		return nil, ErrNotSentinel
+	}
+	opts, err := redisOptions(c.Cfg, c.Ssh, c.Proxy, c.Tls, 0)
+	if err != nil {
+		return nil, err
+	}
+	clients := make([]sentinelConn, 0, len(opts.Addrs))
+	for _, addr := range opts.Addrs {
+		o := c.nodeOptions(opts, addr)
+		o.Username, o.Password = c.Cfg.SentinelUsername, c.Cfg.SentinelPassword
+		o.CredentialsProviderContext = nil
+		clients = append(clients, sentinelConn{addr, redis.NewSentinelClient(o)})
+	}
+	return clients, nil
+}
+
+// nodeOptions are options for a single-node client to addr that shares the
+// connection's transport and credentials, for commands that must reach one
+// specific server.
+func (c *Client) nodeOptions(opts *redis.UniversalOptions, addr string) *redis.Options {
+	o := &redis.Options{
+		Addr:                       addr,
+		Username:                   opts.Username,
+		Password:                   opts.Password,
+		CredentialsProviderContext: opts.CredentialsProviderContext,
+		DialTimeout:                opts.DialTimeout,
+		ReadTimeout:                opts.ReadTimeout,
+		WriteTimeout:               opts.WriteTimeout,
+		Protocol:                   opts.Protocol,
+		PoolSize:                   2,
+	}
+	if c.transport != nil {
+		o.Dialer = c.transport.dial
+	}
+	return o
+}
+
+func closeSentinels(clients []sentinelConn) {
+	for _, sc := range clients {
+		_ = sc.Close()
+	}
+}
+
+// SentinelTopology asks every configured sentinel for the masters it
+// monitors, and the first sentinel that knows a master for its replicas and
+// peer sentinels. Unreachable sentinels are reported, not fatal.
+func (c *Client) SentinelTopology(ctx context.Context) (*SentinelTopology, error) {
+	clients, err := c.sentinelClients()
+	if err != nil {
+		return nil, err
+	}
+	defer closeSentinels(clients)
+
+	topo := &SentinelTopology{Nodes: make([]*SentinelNode, len(clients))}
+	reports := make([][]map[string]string, len(clients))
+	var wg sync.WaitGroup
+	for i, sc := range clients {
+		wg.Add(1)
+		go func(i int, sc sentinelConn) {
+			defer wg.Done()
+			start := time.Now()
+			cmd := redis.NewMapStringStringSliceCmd(ctx, "sentinel", "masters")
+			_ = sc.Process(ctx, cmd)
+			topo.Nodes[i] = &SentinelNode{Addr: sc.addr, Err: cmd.Err(), Latency: time.Since(start)}
+			reports[i] = cmd.Val()
+		}(i, sc)
+	}
+	wg.Wait()
+
+	byName := make(map[string]*SentinelMaster)
+	owner := make(map[string]sentinelConn)
+	for i, masters := range reports {
+		for _, info := range masters {
+			name := info["name"]
+			m, ok := byName[name]
+			if !ok {
+				m = &SentinelMaster{Info: info}
+				byName[name] = m
+				owner[name] = clients[i]
+				topo.Masters = append(topo.Masters, m)
+			}
+			m.ReportedBy = append(m.ReportedBy, topo.Nodes[i].Addr)
+		}
+	}
+	if len(topo.Masters) == 0 {
+		for _, n := range topo.Nodes {
+			if n.Err != nil {
+				return topo, fmt.Errorf("no sentinel answered: %s: %w", n.Addr, n.Err)
+			}
+		}
+	}
+
+	for _, m := range topo.Masters {
+		sc := owner[m.Name()]
+		if m.Replicas, err = sc.Replicas(ctx, m.Name()).Result(); err != nil {
+			return topo, fmt.Errorf("sentinel replicas %s: %w", m.Name(), err)
+		}
+		if m.Sentinels, err = sc.Sentinels(ctx, m.Name()).Result(); err != nil {
+			return topo, fmt.Errorf("sentinel sentinels %s: %w", m.Name(), err)
+		}
+	}
+	return topo, nil
+}
+
+// SentinelFailover forces a failover of master, defaulting to the
+// connection's own. The first sentinel that accepts the command wins.
+func (c *Client) SentinelFailover(ctx context.Context, master string) error {
+	if c.ReadOnly.Load() {
+		return ErrReadOnly
+	}
+	if master == "" {
+		master = c.Cfg.SentinelMaster
+	}
+	clients, err := c.sentinelClients()
+	if err != nil {
+		return err
+	}
+	defer closeSentinels(clients)
+
+	var errs []error
+	for _, sc := range clients {
+		err := sc.Failover(ctx, master).Err()
+		if err == nil {
+			return nil
+		}
+		errs = append(errs, fmt.Errorf("%s: %w", sc.addr, err))
+	}
+	return fmt.Errorf("sentinel failover %s: %w", master, errors.Join(errs...))
+}
+
+// WatchSentinels subscribes to SentinelEventChannels on every configured
+// sentinel and calls fn for each event until ctx ends or fn fails. Each
+// sentinel reports its own view, so the same transition may arrive once per
+// sentinel.
+func (c *Client) WatchSentinels(ctx context.Context, fn func(*SentinelEvent) error) error {
+	clients, err := c.sentinelClients()
+	if err != nil {
+		return err
+	}
+	defer closeSentinels(clients)
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	events := make(chan *SentinelEvent)
+	for _, sc := range clients {
+		ps := sc.Subscribe(ctx, SentinelEventChannels...)
+		defer ps.Close()
+		go func(addr string, ch <-chan *redis.Message) {
+			for {
+				select {
+				case <-ctx.Done():
+					return
+				case msg, ok := <-ch:
+					if !ok {
+						return
+					}
+					ev := ParseSentinelEvent(msg.Channel, msg.Payload)
+					ev.Sentinel = addr
+					select {
+					case events <- ev:
+					case <-ctx.Done():
+						return
+					}
+				}
+			}
+		}(sc.addr, ps.Channel())
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case ev := <-events:
+			if err := fn(ev); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// ParseSentinelEvent extracts the master name from a sentinel event:
+//
+//	+switch-master <master> <old-ip> <old-port> <new-ip> <new-port>
+//	+sdown master <master> <ip> <port>
+//	+sdown slave <ip>:<port> <ip> <port> @ <master> <ip> <port>
+func ParseSentinelEvent(channel, payload string) *SentinelEvent {
+	ev := &SentinelEvent{Event: channel, Message: payload, Time: time.Now()}
+	fields := strings.Fields(payload)
+	switch {
+	case channel == "+switch-master" && len(fields) > 0:
+		ev.Master = fields[0]
+	case len(fields) > 1 && fields[0] == "master":
+		ev.Master = fields[1]
+	default:
+		for i, f := range fields {
+			if f == "@" && i+1 < len(fields) {
+				ev.Master = fields[i+1]
+				break
+			}
+		}
+	}
+	return ev
+}
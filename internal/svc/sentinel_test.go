@@ -0,0 +1,37 @@
+package svc
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/tradalab/rdms/internal/model"
+)
+
+func TestParseSentinelEvent(t *testing.T) {
+	cases := []struct{ channel, payload, master string }{
+		{"+switch-master", "mymaster 10.0.0.1 6379 10.0.0.2 6379", "mymaster"},
+		{"+odown", "master mymaster 10.0.0.1 6379 #quorum 2/2", "mymaster"},
+		{"+sdown", "slave 10.0.0.3:6379 10.0.0.3 6379 @ cache 10.0.0.1 6379", "cache"},
+		{"+sdown", "sentinel 5f1c 10.0.0.9 26379 @ cache 10.0.0.1 6379", "cache"},
+		{"+sdown", "", ""},
+	}
+	for _, tc := range cases {
+		ev := ParseSentinelEvent(tc.channel, tc.payload)
+		if ev.Master != tc.master || ev.Event != tc.channel || ev.Message != tc.payload {
+			t.Errorf("%s %q: got %+v", tc.channel, tc.payload, ev)
+		}
+	}
+}
+
+func TestSentinelFailoverGuards(t *testing.T) {
+	c := &Client{Cfg: &model.Connection{Mode: "standalone"}}
+	if err := c.SentinelFailover(context.Background(), "m"); !errors.Is(err, ErrNotSentinel) {
+		t.Errorf("standalone: got %v", err)
+	}
+	c.Cfg.Mode = "sentinel"
+	c.ReadOnly.Store(true)
+	if err := c.SentinelFailover(context.Background(), "m"); !errors.Is(err, ErrReadOnly) {
+		t.Errorf("read-only: got %v", err)
+	}
+}
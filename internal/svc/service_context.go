@@ -28,6 +28,7 @@ type ServiceContext struct {
 	GroupModel        model.GroupModel
 	SearchPresetModel model.SearchPresetModel
 	SettingModel      model.SettingModel
+	KnownHostModel    model.KnownHostModel
 	// scorix:model:fields:end
 
 	emit   func(name string, data any)
@@ -42,7 +43,7 @@ func NewServiceContext(a *app.App) *ServiceContext {
 	sqlxMod.RegisterDriver("sqlite", func(dsn string) (*sqlx.DB, error) { return sqlx.Connect("sqlite", dsn) })
 	a.Module(sqlxMod)
 	// scorix:model:init:end
-	return &ServiceContext{
+	sc := &ServiceContext{
 		Cfg:          &config.Config{},
 		RedisManager: NewManager(),
 		sqlx:         sqlxMod,
@@ -54,11 +55,15 @@ func NewServiceContext(a *app.App) *ServiceContext {
 		GroupModel:        model.NewGroupModel(sqlxMod.Conn),
 		SearchPresetModel: model.NewSearchPresetModel(sqlxMod.Conn),
 		SettingModel:      model.NewSettingModel(sqlxMod.Conn),
+		KnownHostModel:    model.NewKnownHostModel(sqlxMod.Conn),
 		// scorix:model:assigns:end
 		emit:   a.Emit,
 		emitTo: a.EmitTo,
 		on:     func(name string, fn func(context.Context, json.RawMessage)) { a.Event(name, fn) },
 	}
+	sc.RedisManager.SetHostKeys(NewHostKeyStore(sc.KnownHostModel))
+	sc.RedisManager.SetEmitter(sc.Emit)
+	return sc
 }
 
 func (s *ServiceContext) Emit(name string, data any) {
@@ -79,3 +84,12 @@ func (s *ServiceContext) On(name string, fn func(context.Context, json.RawMessag
 		s.on(name, fn)
 	}
 }
+
+// WithTx runs fn in one database transaction; model calls made with the ctx
+// it passes join the transaction.
+func (s *ServiceContext) WithTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	if s.sqlx == nil {
+		return fn(ctx)
+	}
+	return s.sqlx.WithTx(ctx, fn)
+}
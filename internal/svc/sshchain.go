@@ -0,0 +1,136 @@
+package svc
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"time"
+
+	"golang.org/x/crypto/ssh"
+
+	"github.com/tradalab/rdms/internal/model"
+)
+
+type ContextDialer interface {
+	DialContext(ctx context.Context, network, addr string) (net.Conn, error)
+}
+
+// SshHopError names the hop of a jump chain that failed. Hop is 1-based.
+type SshHopError struct {
+	Hop   int
+	Total int
+	Addr  string
+	Err   error
+}
+
+func (e *SshHopError) Error() string {
+	return fmt.Sprintf("ssh hop %d/%d (%s): %v", e.Hop, e.Total, e.Addr, e.Err)
+}
+
+func (e *SshHopError) Unwrap() error { return e.Err }
+
+// SshChain is a ProxyJump-style tunnel: the first hop is dialled with the base
+// dialer (so a proxy applies there) and every later hop through the one before.
+type SshChain struct {
+	clients []*ssh.Client
+}
+
+// DialSshChain connects every hop in order. hostKey returns the host key
+// callback for the hop at the given 0-based index.
+func DialSshChain(ctx context.Context, base ContextDialer, hops []*model.Ssh, hostKey func(hop int) ssh.HostKeyCallback) (*SshChain, error) {
+	chain := &SshChain{}
+	for i, hop := range hops {
+		addr := hop.Addr()
+		fail := func(err error) (*SshChain, error) {
+			chain.Close()
+			return nil, &SshHopError{Hop: i + 1, Total: len(hops), Addr: addr, Err: err}
+		}
+
+		sshConfig, err := hop.BuildClientCfg(hostKey(i))
+		if err != nil {
+			return fail(err)
+		}
+
+		var conn net.Conn
+		if i == 0 {
+			conn, err = base.DialContext(ctx, "tcp", addr)
+		} else {
+			conn, err = chain.Dial(ctx, "tcp", addr)
+		}
+		if err != nil {
+			return fail(fmt.Errorf("dial failed: %w", err))
+		}
+
+		if d, ok := ctx.Deadline(); ok {
+			_ = conn.SetDeadline(d)
+		}
+		c, chans, reqs, err := ssh.NewClientConn(conn, addr, sshConfig)
+		if err != nil {
+			conn.Close()
+			return fail(fmt.Errorf("handshake failed: %w", err))
+		}
+		_ = conn.SetDeadline(time.Time{})
+		chain.clients = append(chain.clients, ssh.NewClient(c, chans, reqs))
+	}
+	return chain, nil
+}
+
+// Dial opens a connection to addr from the last hop of the chain.
+func (c *SshChain) Dial(ctx context.Context, network, addr string) (net.Conn, error) {
+	last := c.clients[len(c.clients)-1]
+
+	type dialResult struct {
+		conn net.Conn
+		err  error
+	}
+	ch := make(chan dialResult, 1)
+	go func() {
+		conn, err := last.Dial(network, addr)
+		ch <- dialResult{conn, err}
+	}()
+
+	select {
+	case <-ctx.Done():
+		go func() {
+			if res := <-ch; res.conn != nil {
+				res.conn.Close()
+			}
+		}()
+		return nil, ctx.Err()
+	case res := <-ch:
+		return res.conn, res.err
+	}
+}
+
+// Keepalive sends an OpenSSH keepalive request on every hop. Any reply, even
+// a refusal, proves the hop is alive.
+func (c *SshChain) Keepalive(timeout time.Duration) error {
+	for i, cl := range c.clients {
+		done := make(chan error, 1)
+		go func() {
+			_, _, err := cl.SendRequest("keepalive@openssh.com", true, nil)
+			done <- err
+		}()
+		select {
+		case err := <-done:
+			if err != nil {
+				return fmt.Errorf("ssh hop %d/%d keepalive: %w", i+1, len(c.clients), err)
+			}
+		case <-time.After(timeout):
+			return fmt.Errorf("ssh hop %d/%d keepalive: no reply after %s", i+1, len(c.clients), timeout)
+		}
+	}
+	return nil
+}
+
+// Close tears the chain down from the innermost hop outwards.
+func (c *SshChain) Close() error {
+	var first error
+	for i := len(c.clients) - 1; i >= 0; i-- {
+		if err := c.clients[i].Close(); err != nil && first == nil {
+			first = err
+		}
+	}
+	c.clients = nil
+	return first
+}
@@ -0,0 +1,140 @@
+package svc
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"errors"
+	"io"
+	"net"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+	"golang.org/x/crypto/ssh"
+
+	"github.com/tradalab/rdms/internal/model"
+)
+
+// startSshServer runs a password-auth SSH server that only forwards
+// direct-tcpip channels, which is all a jump host needs to do.
+func startSshServer(t *testing.T) *model.Ssh {
+	t.Helper()
+	_, priv, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	signer, err := ssh.NewSignerFromKey(priv)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfg := &ssh.ServerConfig{
+		PasswordCallback: func(c ssh.ConnMetadata, pass []byte) (*ssh.Permissions, error) {
+			if c.User() == "u" && string(pass) == "p" {
+				return nil, nil
+			}
+			return nil, errors.New("denied")
+		},
+	}
+	cfg.AddHostKey(signer)
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { ln.Close() })
+
+	go func() {
+		for {
+			nc, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				_, chans, reqs, err := ssh.NewServerConn(nc, cfg)
+				if err != nil {
+					nc.Close()
+					return
+				}
+				go ssh.DiscardRequests(reqs)
+				for nch := range chans {
+					if nch.ChannelType() != "direct-tcpip" {
+						_ = nch.Reject(ssh.UnknownChannelType, "only direct-tcpip")
+						continue
+					}
+					var target struct {
+						Host     string
+						Port     uint32
+						OrigHost string
+						OrigPort uint32
+					}
+					if err := ssh.Unmarshal(nch.ExtraData(), &target); err != nil {
+						_ = nch.Reject(ssh.ConnectionFailed, err.Error())
+						continue
+					}
+					up, err := net.Dial("tcp", net.JoinHostPort(target.Host, strconv.Itoa(int(target.Port))))
+					if err != nil {
+						_ = nch.Reject(ssh.ConnectionFailed, err.Error())
+						continue
+					}
+					ch, creqs, err := nch.Accept()
+					if err != nil {
+						up.Close()
+						continue
+					}
+					go ssh.DiscardRequests(creqs)
+					go func() { _, _ = io.Copy(ch, up); ch.Close() }()
+					go func() { _, _ = io.Copy(up, ch); up.Close() }()
+				}
+			}()
+		}
+	}()
+
+	addr := ln.Addr().(*net.TCPAddr)
+	return &model.Ssh{ID: addr.String(), Host: "127.0.0.1", Port: int64(addr.Port), Username: "u", Kind: "password", Password: "p", Timeout: 5}
+}
+
+func TestDialSshChainTwoHops(t *testing.T) {
+	mr := miniredis.RunT(t)
+	first, second := startSshServer(t), startSshServer(t)
+
+	m := NewManager()
+	m.SetHostKeys(NewHostKeyStore(&memKnownHosts{rows: map[string]*model.KnownHost{}}, ""))
+
+	host, port, _ := net.SplitHostPort(mr.Addr())
+	p, _ := strconv.Atoi(port)
+	cfg := &model.Connection{Network: "tcp", Host: host, Port: int64(p), SshEnable: 1, DialTimeout: 5}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	opts, _, err := m.buildOptions(ctx, cfg, []*model.Ssh{first, second}, nil, nil, 0)
+	if err != nil {
+		t.Fatalf("build options through two hops: %v", err)
+	}
+	rdb := redis.NewUniversalClient(opts)
+	defer rdb.Close()
+	if err := rdb.Ping(ctx).Err(); err != nil {
+		t.Fatalf("ping through two hops: %v", err)
+	}
+}
+
+func TestDialSshChainReportsFailingHop(t *testing.T) {
+	first := startSshServer(t)
+	bad := *startSshServer(t)
+	bad.Password = "wrong"
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	_, err := DialSshChain(ctx, &net.Dialer{}, []*model.Ssh{first, &bad}, func(int) ssh.HostKeyCallback {
+		return ssh.InsecureIgnoreHostKey()
+	})
+	var hopErr *SshHopError
+	if !errors.As(err, &hopErr) {
+		t.Fatalf("expected SshHopError, got %v", err)
+	}
+	if hopErr.Hop != 2 || hopErr.Total != 2 || hopErr.Addr != bad.Addr() {
+		t.Fatalf("wrong hop reported: %+v", hopErr)
+	}
+}
@@ -0,0 +1,212 @@
+package svc
+
+import (
+	"context"
+	"errors"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+
+	"github.com/tradalab/rdms/internal/types"
+	"github.com/tradalab/rdms/pkg/netx"
+)
+
+const (
+	ClientStateConnected    = "connected"
+	ClientStateReconnecting = "reconnecting"
+	ClientStateFailed       = "failed"
+
+	// EventClientState carries a types.ClientStateEvent.
+	EventClientState = "client:state"
+)
+
+var (
+	superviseInterval    = 15 * time.Second
+	keepaliveTimeout     = 10 * time.Second
+	reconnectMinBackoff  = time.Second
+	reconnectMaxBackoff  = 30 * time.Second
+	reconnectMaxAttempts = 8
+)
+
+var errTunnelDown = errors.New("ssh tunnel is down")
+
+// sshTunnel owns the SSH jump chain of a client and re-dials it on demand
+// after the supervisor (or a failed keepalive) has torn it down.
+type sshTunnel struct {
+	mu       sync.Mutex
+	chain    *SshChain
+	dial     func(ctx context.Context) (*SshChain, error)
+	probed   *SshChain // chain of the last good keepalive, at probedAt
+	probedAt time.Time
+}
+
+func (t *sshTunnel) current(ctx context.Context) (*SshChain, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.chain == nil {
+		chain, err := t.dial(ctx)
+		if err != nil {
+			return nil, err
+		}
+		t.chain = chain
+	}
+	return t.chain, nil
+}
+
+func (t *sshTunnel) Dial(ctx context.Context, network, addr string) (net.Conn, error) {
+	chain, err := t.current(ctx)
+	if err != nil {
+		return nil, err
+	}
+	conn, err := chain.Dial(ctx, network, addr)
+	if err != nil {
+		return nil, err
+	}
+	return &netx.IgnoreDeadlineConn{Conn: conn}, nil
+}
+
+// Keepalive probes every hop of the live chain.
+func (t *sshTunnel) Keepalive(timeout time.Duration) error {
+	t.mu.Lock()
+	chain := t.chain
+	t.mu.Unlock()
+	if chain == nil {
+		return errTunnelDown
+	}
+	return chain.Keepalive(timeout)
+}
+
+// check is Keepalive for the supervisors of all DB clients on the tunnel:
+// one probe per half interval answers for every one of them. It returns the
+// chain it looked at so that a failure resets that chain and no newer one.
+func (t *sshTunnel) check(ctx context.Context, timeout time.Duration) (*SshChain, error) {
+	chain, err := t.current(ctx)
+	if err != nil {
+		return nil, err
+	}
+	t.mu.Lock()
+	fresh := t.probed == chain && time.Since(t.probedAt) < superviseInterval/2
+	t.mu.Unlock()
+	if fresh {
+		return chain, nil
+	}
+	if err := chain.Keepalive(timeout); err != nil {
+		return chain, err
+	}
+	t.mu.Lock()
+	t.probed, t.probedAt = chain, time.Now()
+	t.mu.Unlock()
+	return chain, nil
+}
+
+// resetIf drops chain if it is still the live one. Supervisors of other DB
+// clients that saw the same failure find a fresh chain and leave it be.
+func (t *sshTunnel) resetIf(chain *SshChain) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if chain != nil && t.chain == chain {
+		_ = t.chain.Close()
+		t.chain = nil
+	}
+}
+
+// Reset drops the chain; the next dial builds a fresh one. Pooled Redis
+// connections riding the old chain fail and are replaced by go-redis.
+func (t *sshTunnel) Reset() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.chain != nil {
+		_ = t.chain.Close()
+		t.chain = nil
+	}
+}
+
+func (t *sshTunnel) Close() {
+	t.Reset()
+}
+
+// supervise health-checks c until ctx is cancelled: SSH keepalives first, then
+// a PING through the pool. Network failures re-dial the tunnel with
+// exponential backoff; an error reply from Redis leaves the tunnel, which is
+// shared with the other DBs of the connection, alone. Every state change is
+// emitted as EventClientState.
+func (m *ClientManager) supervise(ctx context.Context, c *Client) {
+	state := ClientStateConnected
+	attempt := 0
+	wait := superviseInterval
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(wait):
+		}
+
+		chain, err := m.healthCheck(ctx, c)
+		if ctx.Err() != nil {
+			return
+		}
+		if err == nil {
+			if state != ClientStateConnected {
+				state = ClientStateConnected
+				m.emitState(c, state, attempt, nil)
+			}
+			attempt = 0
+			wait = superviseInterval
+			continue
+		}
+
+		attempt++
+		var replyErr redis.Error
+		if tunnel := c.tunnel(); tunnel != nil && !errors.As(err, &replyErr) {
+			tunnel.resetIf(chain)
+		}
+		if attempt > reconnectMaxAttempts {
+			if state != ClientStateFailed {
+				state = ClientStateFailed
+				m.emitState(c, state, attempt, err)
+			}
+			wait = reconnectMaxBackoff
+			continue
+		}
+		state = ClientStateReconnecting
+		m.emitState(c, state, attempt, err)
+		wait = min(reconnectMinBackoff<<(attempt-1), reconnectMaxBackoff)
+	}
+}
+
+func (m *ClientManager) healthCheck(ctx context.Context, c *Client) (*SshChain, error) {
+	timeout := time.Duration(c.Cfg.DialTimeout) * time.Second
+	if timeout <= 0 {
+		timeout = keepaliveTimeout
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	var chain *SshChain
+	if tunnel := c.tunnel(); tunnel != nil {
+		var err error
+		if chain, err = tunnel.check(ctx, keepaliveTimeout); err != nil {
+			return chain, err
+		}
+	}
+	return chain, c.Rdb.Ping(ctx).Err()
+}
+
+func (m *ClientManager) emitState(c *Client, state string, attempt int, err error) {
+	if m.emit == nil {
+		return
+	}
+	ev := &types.ClientStateEvent{
+		ConnectionId:  c.Cfg.ID,
+		DatabaseIndex: int32(c.DbIdx),
+		State:         state,
+		Attempt:       int32(attempt),
+	}
+	if err != nil {
+		ev.Error = err.Error()
+	}
+	m.emit(EventClientState, ev)
+}
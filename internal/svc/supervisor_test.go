@@ -0,0 +1,138 @@
+package svc
+
+import (
+	"context"
+	"net"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"golang.org/x/crypto/ssh"
+
+	"github.com/tradalab/rdms/internal/model"
+	"github.com/tradalab/rdms/internal/types"
+)
+
+type stateRecorder struct {
+	states chan string
+}
+
+func (r *stateRecorder) emit(name string, data any) {
+	if name != EventClientState {
+		return
+	}
+	r.states <- data.(*types.ClientStateEvent).State
+}
+
+func (r *stateRecorder) wait(t *testing.T, want string) {
+	t.Helper()
+	deadline := time.After(5 * time.Second)
+	for {
+		select {
+		case got := <-r.states:
+			if got == want {
+				return
+			}
+		case <-deadline:
+			t.Fatalf("no %q state event", want)
+		}
+	}
+}
+
+func fastSupervisor(t *testing.T) {
+	t.Helper()
+	interval, minBackoff := superviseInterval, reconnectMinBackoff
+	superviseInterval, reconnectMinBackoff = 20*time.Millisecond, 10*time.Millisecond
+	t.Cleanup(func() { superviseInterval, reconnectMinBackoff = interval, minBackoff })
+}
+
+func TestSuperviseReportsDropAndRecovery(t *testing.T) {
+	fastSupervisor(t)
+	mr := miniredis.RunT(t)
+	rec := &stateRecorder{states: make(chan string, 64)}
+	m := NewManager()
+	m.SetEmitter(rec.emit)
+	defer m.CloseAll()
+
+	host, port, _ := net.SplitHostPort(mr.Addr())
+	p, _ := strconv.Atoi(port)
+	cfg := &model.Connection{ID: "sup", Network: "tcp", Host: host, Port: int64(p), DialTimeout: 1, ExecTimeout: 1}
+	if _, err := m.Add(cfg, nil, nil, nil, 0); err != nil {
+		t.Fatal(err)
+	}
+
+	mr.Close()
+	rec.wait(t, ClientStateReconnecting)
+
+	if err := mr.Restart(); err != nil {
+		t.Fatal(err)
+	}
+	rec.wait(t, ClientStateConnected)
+}
+
+func TestSshTunnelRedialsAfterReset(t *testing.T) {
+	mr := miniredis.RunT(t)
+	hop := startSshServer(t)
+	tunnel := &sshTunnel{dial: func(ctx context.Context) (*SshChain, error) {
+		return DialSshChain(ctx, &net.Dialer{}, []*model.Ssh{hop}, func(int) ssh.HostKeyCallback {
+			return ssh.InsecureIgnoreHostKey()
+		})
+	}}
+	defer tunnel.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	for i := 0; i < 2; i++ {
+		conn, err := tunnel.Dial(ctx, "tcp", mr.Addr())
+		if err != nil {
+			t.Fatalf("dial %d: %v", i, err)
+		}
+		conn.Close()
+		if err := tunnel.Keepalive(time.Second); err != nil {
+			t.Fatalf("keepalive %d: %v", i, err)
+		}
+		tunnel.Reset()
+		if err := tunnel.Keepalive(time.Second); err != errTunnelDown {
+			t.Fatalf("keepalive after reset = %v, want errTunnelDown", err)
+		}
+	}
+}
+
+func TestSshTunnelResetsOnlyTheFailedChain(t *testing.T) {
+	hop := startSshServer(t)
+	dials := 0
+	tunnel := &sshTunnel{dial: func(ctx context.Context) (*SshChain, error) {
+		dials++
+		return DialSshChain(ctx, &net.Dialer{}, []*model.Ssh{hop}, func(int) ssh.HostKeyCallback {
+			return ssh.InsecureIgnoreHostKey()
+		})
+	}}
+	defer tunnel.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	stale, err := tunnel.check(ctx, time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// A second DB's supervisor in the same interval shares the probe.
+	if again, err := tunnel.check(ctx, time.Second); err != nil || again != stale {
+		t.Fatalf("second check = %p, %v; want the same chain", again, err)
+	}
+
+	tunnel.resetIf(stale)
+	fresh, err := tunnel.check(ctx, time.Second)
+	if err != nil || fresh == stale {
+		t.Fatalf("check after reset = %p, %v; want a new chain", fresh, err)
+	}
+
+	// Another supervisor reporting the old chain must not drop the new one.
+	tunnel.resetIf(stale)
+	if err := tunnel.Keepalive(time.Second); err != nil {
+		t.Fatalf("keepalive after a stale reset: %v", err)
+	}
+	if dials != 2 {
+		t.Errorf("dialed %d chains, want 2", dials)
+	}
+}
@@ -0,0 +1,114 @@
+package svc
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net"
+	"time"
+
+	"github.com/tradalab/rdms/internal/model"
+)
+
+// TlsCert is one certificate of the chain a server presented.
+type TlsCert struct {
+	Subject     string
+	Issuer      string
+	SANs        []string
+	NotBefore   time.Time
+	NotAfter    time.Time
+	Fingerprint string
+	IsCA        bool
+}
+
+// TlsReport is the result of a handshake with a profile. The handshake
+// itself never verifies, so the chain is returned even when verification
+// or the pin would fail; those results are in VerifyErr and PinErr.
+type TlsReport struct {
+	Version     string
+	CipherSuite string
+	Chain       []*TlsCert
+	VerifyErr   error
+	Pinned      bool
+	PinErr      error
+}
+
+// InspectTls connects to addr with the profile and reports the server
+// chain and whether the profile would accept it.
+func InspectTls(ctx context.Context, d ContextDialer, t *model.Tls, addr string) (*TlsReport, error) {
+	cfg, err := t.BuildTlsConfig()
+	if err != nil {
+		return nil, err
+	}
+	host, _, err := net.SplitHostPort(addr)
+	if err != nil {
+		return nil, err
+	}
+	serverName := cfg.ServerName
+	if serverName == "" {
+		serverName = host
+	}
+
+	probe := cfg.Clone()
+	probe.InsecureSkipVerify = true
+	probe.VerifyConnection = nil
+	probe.ServerName = serverName
+
+	raw, err := d.DialContext(ctx, "tcp", addr)
+	if err != nil {
+		return nil, err
+	}
+	conn := tls.Client(raw, probe)
+	defer conn.Close()
+	if err := conn.HandshakeContext(ctx); err != nil {
+		return nil, fmt.Errorf("tls handshake: %w", err)
+	}
+	cs := conn.ConnectionState()
+
+	rep := &TlsReport{
+		Version:     tls.VersionName(cs.Version),
+		CipherSuite: tls.CipherSuiteName(cs.CipherSuite),
+	}
+	for _, c := range cs.PeerCertificates {
+		tc := &TlsCert{
+			Subject:     c.Subject.String(),
+			Issuer:      c.Issuer.String(),
+			NotBefore:   c.NotBefore,
+			NotAfter:    c.NotAfter,
+			Fingerprint: model.CertFingerprint(c),
+			IsCA:        c.IsCA,
+		}
+		tc.SANs = append(tc.SANs, c.DNSNames...)
+		for _, ip := range c.IPAddresses {
+			tc.SANs = append(tc.SANs, ip.String())
+		}
+		tc.SANs = append(tc.SANs, c.EmailAddresses...)
+		for _, u := range c.URIs {
+			tc.SANs = append(tc.SANs, u.String())
+		}
+		rep.Chain = append(rep.Chain, tc)
+	}
+	if len(cs.PeerCertificates) == 0 {
+		rep.VerifyErr = fmt.Errorf("server sent no certificate")
+		return rep, nil
+	}
+
+	// Verified as crypto/tls would, so the result holds whatever the
+	// profile's verify switch says.
+	opts := x509.VerifyOptions{
+		Roots:         cfg.RootCAs,
+		DNSName:       serverName,
+		Intermediates: x509.NewCertPool(),
+	}
+	for _, c := range cs.PeerCertificates[1:] {
+		opts.Intermediates.AddCert(c)
+	}
+	_, rep.VerifyErr = cs.PeerCertificates[0].Verify(opts)
+
+	if cfg.VerifyConnection != nil {
+		rep.Pinned = true
+		rep.PinErr = cfg.VerifyConnection(cs)
+	}
+	return rep, nil
+}
@@ -0,0 +1,102 @@
+package svc
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/tradalab/rdms/internal/model"
+)
+
+// selfSignedCert is a fresh self-signed cert for "redis.test" and
+// 127.0.0.1, with its PEM.
+func selfSignedCert(t *testing.T) (tls.Certificate, string) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tmpl := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "redis.test"},
+		DNSNames:              []string{"redis.test"},
+		IPAddresses:           []net.IP{net.ParseIP("127.0.0.1")},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageCertSign | x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cert := tls.Certificate{Certificate: [][]byte{der}, PrivateKey: key}
+	return cert, string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}))
+}
+
+// selfSignedServer serves TLS on localhost with a selfSignedCert and
+// returns its address and PEM.
+func selfSignedServer(t *testing.T) (string, string) {
+	t.Helper()
+	cert, certPEM := selfSignedCert(t)
+	ln, err := tls.Listen("tcp", "127.0.0.1:0", &tls.Config{Certificates: []tls.Certificate{cert}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { ln.Close() })
+	go func() {
+		for {
+			c, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				defer c.Close()
+				_ = c.(*tls.Conn).Handshake()
+			}()
+		}
+	}()
+	return ln.Addr().String(), certPEM
+}
+
+func TestInspectTls(t *testing.T) {
+	addr, caPEM := selfSignedServer(t)
+	ctx := context.Background()
+
+	rep, err := InspectTls(ctx, &net.Dialer{}, &model.Tls{Verify: 1}, addr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rep.Chain) != 1 || rep.Chain[0].Subject != "CN=redis.test" || rep.VerifyErr == nil {
+		t.Fatalf("unknown CA must be reported, not fatal: %+v", rep)
+	}
+	if got := strings.Join(rep.Chain[0].SANs, ","); got != "redis.test,127.0.0.1" {
+		t.Errorf("sans = %s", got)
+	}
+
+	rep, err = InspectTls(ctx, &net.Dialer{}, &model.Tls{Verify: 1, CaCert: caPEM}, addr)
+	if err != nil || rep.VerifyErr != nil {
+		t.Fatalf("trusted CA: %v / %v", err, rep.VerifyErr)
+	}
+
+	rep, _ = InspectTls(ctx, &net.Dialer{}, &model.Tls{PinSha256: rep.Chain[0].Fingerprint}, addr)
+	if !rep.Pinned || rep.PinErr != nil {
+		t.Errorf("matching pin: %+v", rep)
+	}
+	rep, _ = InspectTls(ctx, &net.Dialer{}, &model.Tls{PinSha256: strings.Repeat("00", 32)}, addr)
+	if rep.PinErr == nil {
+		t.Error("wrong pin must be reported")
+	}
+}
@@ -0,0 +1,147 @@
+package svc
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"net"
+	"strings"
+
+	"github.com/redis/go-redis/v9"
+
+	"github.com/tradalab/rdms/internal/model"
+)
+
+// transport is the network path of a connection: proxy dialer, SSH chain,
+// address mapping and TLS. All DB clients of a connection share one, so
+// switching databases never opens another SSH session; it is closed when the
+// last of them is released.
+type transport struct {
+	// rawDial reaches addr through the proxy, tunnel and address mapping;
+	// dial adds the TLS handshake on top when the connection uses TLS.
+	rawDial func(ctx context.Context, network, addr string) (net.Conn, error)
+	tls     *tls.Config
+	tunnel  *sshTunnel
+	addrMap AddrMapper
+
+	key     string
+	version string
+	refs    int
+	owner   *ClientManager
+}
+
+// apply routes o through the transport. go-redis skips its own TLS when a
+// Dialer is set, so dial does the handshake and o.TLSConfig stays nil.
+func (t *transport) apply(o *redis.UniversalOptions) {
+	o.Dialer = t.dial
+	o.TLSConfig = nil
+}
+
+func (t *transport) dial(ctx context.Context, network, addr string) (net.Conn, error) {
+	conn, err := t.rawDial(ctx, network, addr)
+	if err != nil || t.tls == nil {
+		return conn, err
+	}
+	tc := tls.Client(conn, t.tlsFor(addr))
+	if err := tc.HandshakeContext(ctx); err != nil {
+		_ = conn.Close()
+		return nil, err
+	}
+	return tc, nil
+}
+
+// tlsFor is the TLS config for a connection to addr. Without an explicit
+// server name the certificate is checked against the host of addr as the
+// cluster or sentinel announced it, not the address it is mapped to.
+func (t *transport) tlsFor(addr string) *tls.Config {
+	if t.tls.ServerName != "" {
+		return t.tls
+	}
+	c := t.tls.Clone()
+	c.ServerName, _, _ = net.SplitHostPort(addr)
+	if c.ServerName == "" {
+		c.ServerName = addr
+	}
+	return c
+}
+
+// mapAddr returns the address dial actually connects to for addr.
+func (t *transport) mapAddr(addr string) string {
+	mapped, _ := t.addrMap.Map(addr)
+	return mapped
+}
+
+func (t *transport) close() {
+	if t.tunnel != nil {
+		t.tunnel.Close()
+	}
+}
+
+// release drops one client's reference. Private transports close at once.
+func (t *transport) release() {
+	if t.owner == nil {
+		t.close()
+		return
+	}
+	t.owner.releaseTransport(t)
+}
+
+// acquireTransport returns the shared transport of cfg, building it on first
+// use. A transport whose settings changed is detached from the map and left
+// to the clients still holding it.
+func (m *ClientManager) acquireTransport(ctx context.Context, cfg *model.Connection, sshCfgs []*model.Ssh, proxyCfg *model.Proxy, tlsCfg *model.Tls) (*transport, error) {
+	version := transportVersion(cfg, sshCfgs, proxyCfg, tlsCfg)
+
+	m.trMu.Lock()
+	defer m.trMu.Unlock()
+
+	if t, ok := m.transports[cfg.ID]; ok {
+		if t.version == version {
+			t.refs++
+			return t, nil
+		}
+		delete(m.transports, cfg.ID)
+	}
+
+	t, err := m.buildTransport(ctx, cfg, sshCfgs, proxyCfg, tlsCfg)
+	if err != nil {
+		return nil, err
+	}
+	t.key, t.version, t.owner, t.refs = cfg.ID, version, m, 1
+	m.transports[cfg.ID] = t
+	return t, nil
+}
+
+func (m *ClientManager) releaseTransport(t *transport) {
+	m.trMu.Lock()
+	defer m.trMu.Unlock()
+
+	t.refs--
+	if t.refs > 0 {
+		return
+	}
+	if m.transports[t.key] == t {
+		delete(m.transports, t.key)
+	}
+	t.close()
+}
+
+// transportVersion covers only what shapes the network path. Connection
+// UpdatedAt is left out on purpose: it moves whenever last_db is saved.
+func transportVersion(cfg *model.Connection, sshCfgs []*model.Ssh, proxyCfg *model.Proxy, tlsCfg *model.Tls) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s|%s|%s|%d|%s|%s|%s|%d|%d",
+		cfg.Mode, cfg.Network, cfg.Host, cfg.Port, cfg.Addrs, cfg.Sock, cfg.AddrMapping, cfg.DialTimeout, cfg.ExecTimeout)
+	if cfg.SshEnable > 0 {
+		for _, s := range sshCfgs {
+			fmt.Fprintf(&b, "|ssh:%s@%d", s.ID, s.UpdatedAt.UnixNano())
+		}
+	}
+	if cfg.ProxyEnable > 0 && proxyCfg != nil {
+		fmt.Fprintf(&b, "|proxy:%s@%d", proxyCfg.ID, proxyCfg.UpdatedAt.UnixNano())
+	}
+	if cfg.TlsEnable > 0 && tlsCfg != nil {
+		fmt.Fprintf(&b, "|tls:%s@%d", tlsCfg.ID, tlsCfg.UpdatedAt.UnixNano())
+	}
+	return b.String()
+}
@@ -0,0 +1,138 @@
+package svc
+
+import (
+	"sort"
+
+	"github.com/tradalab/rdms/internal/types"
+)
+
+const (
+	defaultTtlSamples     = 5
+	defaultTtlLeakRatio   = 0.8
+	defaultTtlLeakMinKeys = 10
+	maxTtlLeakRows        = 50
+)
+
+type ttlPrefix struct {
+	stat types.TtlPrefix
+	hist []int64
+}
+
+// TtlReport buckets keys by TTL overall and per key prefix, and flags the
+// prefixes whose keys mostly never expire, the usual sign of a leak.
+type TtlReport struct {
+	separator string
+	depth     int
+	samples   int
+	leakRatio float64
+	leakMin   int64
+	keys      int64
+	noExpiry  int64
+	hist      []int64
+	prefixes  map[string]*ttlPrefix
+}
+
+// NewTtlReport groups prefixes like NewKeyReport and keeps samples keys
+// without expiry per prefix. A prefix with at least leakMin keys is flagged
+// once leakRatio of them have no TTL. Zero values take the defaults.
+func NewTtlReport(separator string, depth, samples int, leakRatio float64, leakMin int64) *TtlReport {
+	if separator == "" {
+		separator = defaultKeyReportSeparator
+	}
+	if depth <= 0 {
+		depth = defaultKeyReportDepth
+	}
+	if samples <= 0 {
+		samples = defaultTtlSamples
+	}
+	if leakRatio <= 0 || leakRatio > 1 {
+		leakRatio = defaultTtlLeakRatio
+	}
+	if leakMin <= 0 {
+		leakMin = defaultTtlLeakMinKeys
+	}
+	return &TtlReport{
+		separator: separator,
+		depth:     depth,
+		samples:   samples,
+		leakRatio: leakRatio,
+		leakMin:   leakMin,
+		hist:      make([]int64, len(reportTtlBuckets)),
+		prefixes:  make(map[string]*ttlPrefix),
+	}
+}
+
+// Add counts one key; ttl is in milliseconds, negative without expiry.
+func (r *TtlReport) Add(key string, ttl, memory int64) {
+	band := int64(keyReportNoExpiryBand)
+	if ttl >= 0 {
+		band = ttl / 1000
+	}
+	b := bucketOf(reportTtlBuckets, band)
+	r.keys++
+	r.hist[b]++
+
+	prefix := KeyPrefix(key, r.separator, r.depth)
+	p, ok := r.prefixes[prefix]
+	if !ok {
+		if len(r.prefixes) >= maxKeyReportPrefixes {
+			prefix = keyReportOtherPrefix
+			p = r.prefixes[prefix]
+		}
+		if p == nil {
+			p = &ttlPrefix{stat: types.TtlPrefix{Prefix: prefix}, hist: make([]int64, len(reportTtlBuckets))}
+			r.prefixes[prefix] = p
+		}
+	}
+	p.stat.Keys++
+	p.stat.Memory += memory
+	p.hist[b]++
+	if ttl < 0 {
+		r.noExpiry++
+		p.stat.NoExpiry++
+		p.stat.NoExpiryMemory += memory
+		if len(p.stat.Samples) < r.samples {
+			p.stat.Samples = append(p.stat.Samples, key)
+		}
+	}
+}
+
+// Snapshot returns the report so far. It shares nothing with r, so r may
+// keep growing.
+func (r *TtlReport) Snapshot() *types.ClientKeysTtlEvent {
+	ev := &types.ClientKeysTtlEvent{
+		Keys:     r.keys,
+		NoExpiry: r.noExpiry,
+		TtlHist:  histogram(reportTtlBuckets, r.hist),
+	}
+	for _, p := range r.prefixes {
+		s := p.stat
+		s.Samples = append([]string(nil), s.Samples...)
+		s.TtlHist = histogram(reportTtlBuckets, p.hist)
+		ev.Prefixes = append(ev.Prefixes, s)
+		if s.Keys >= r.leakMin && float64(s.NoExpiry) >= r.leakRatio*float64(s.Keys) {
+			ev.Leaks = append(ev.Leaks, s)
+		}
+	}
+
+	sort.Slice(ev.Prefixes, func(i, j int) bool {
+		if ev.Prefixes[i].Keys != ev.Prefixes[j].Keys {
+			return ev.Prefixes[i].Keys > ev.Prefixes[j].Keys
+		}
+		return ev.Prefixes[i].Prefix < ev.Prefixes[j].Prefix
+	})
+	if len(ev.Prefixes) > keyReportPrefixRows {
+		ev.Prefixes = ev.Prefixes[:keyReportPrefixRows]
+	}
+
+	sort.Slice(ev.Leaks, func(i, j int) bool {
+		if ev.Leaks[i].NoExpiryMemory != ev.Leaks[j].NoExpiryMemory {
+			return ev.Leaks[i].NoExpiryMemory > ev.Leaks[j].NoExpiryMemory
+		}
+		return ev.Leaks[i].Prefix < ev.Leaks[j].Prefix
+	})
+	if len(ev.Leaks) > maxTtlLeakRows {
+		ev.Leaks = ev.Leaks[:maxTtlLeakRows]
+	}
+	return ev
+}
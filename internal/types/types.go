@@ -1,6 +1,31 @@
 // Code generated by scorix. DO NOT EDIT.
 package types
 
+type ActiveClient struct {
+	ConnectionId   string `json:"connection_id"`
+	ConnectionName string `json:"connection_name"`
+	DatabaseIndex  int32  `json:"database_index"`
+	LastUsed       int64  `json:"last_used"`
+	IdleSeconds    int64  `json:"idle_seconds"`
+	MonitorActive  bool   `json:"monitor_active"`
+	PubsubActive   bool   `json:"pubsub_active"`
+	ReadOnly       bool   `json:"read_only"`
+	SshHops        int32  `json:"ssh_hops"`
+}
+
+type AddrMapPreviewRes struct {
+	Rows   []AddrMapRow `json:"rows"`
+	Errors []string     `json:"errors"`
+}
+
+type AddrMapRow struct {
+	Addr     string             `json:"addr"`
+	DialAddr string             `json:"dial_addr"`
+	Rule     string             `json:"rule"`
+	Master   bool               `json:"master"`
+	Slots    []ClusterSlotRange `json:"slots"`
+}
+
 type ClientConnectReq struct {
 	ConnectionId  string `json:"connection_id"`
 	DatabaseIndex int32  `json:"database_index"`
@@ -79,6 +104,7 @@ type ClientKeysDeleteByPrefixReq struct {
 	DatabaseIndex int32    `json:"database_index"`
 	Prefix        string   `json:"prefix"`
 	Keys          []string `json:"keys"`
+	DryRun        bool     `json:"dry_run"`
 }
 
 type ClientKeysDeleteByPrefixRes struct {
@@ -86,11 +112,103 @@ type ClientKeysDeleteByPrefixRes struct {
 }
 
 type ClientKeysDeleteProgressEvent struct {
-	ConnectionId string `json:"connection_id"`
-	Prefix       string `json:"prefix"`
-	Deleted      int64  `json:"deleted"`
-	Total        int64  `json:"total"`
-	Status       string `json:"status"`
+	ConnectionId string           `json:"connection_id"`
+	Prefix       string           `json:"prefix"`
+	Deleted      int64            `json:"deleted"`
+	Total        int64            `json:"total"`
+	Status       string           `json:"status"`
+	Matched      int64            `json:"matched"`
+	DryRun       bool             `json:"dry_run"`
+	Node         string           `json:"node"`
+	Nodes        []DeleteNodeStat `json:"nodes"`
+}
+
+type ClientKeysExportProgressEvent struct {
+	ConnectionId string         `json:"connection_id"`
+	Path         string         `json:"path"`
+	Exported     int64          `json:"exported"`
+	Total        int64          `json:"total"`
+	Status       string         `json:"status"`
+	Matched      int64          `json:"matched"`
+	Skipped      int64          `json:"skipped"`
+	Elements     int64          `json:"elements"`
+	Bytes        int64          `json:"bytes"`
+	Key          string         `json:"key"`
+	Truncated    bool           `json:"truncated"`
+	Nodes        []ScanNodeStat `json:"nodes"`
+}
+
+type ClientKeysExportReq struct {
+	ConnectionId  string      `json:"connection_id"`
+	DatabaseIndex int32       `json:"database_index"`
+	Filters       []KeyFilter `json:"filters"`
+	MatchAll      bool        `json:"match_all"`
+	KeyType       string      `json:"key_type"`
+	Keys          []string    `json:"keys"`
+	Path          string      `json:"path"`
+	Format        string      `json:"format"`
+	PageSize      int64       `json:"page_size"`
+	ScanCount     int64       `json:"scan_count"`
+	Rate          int64       `json:"rate"`
+	BudgetMs      int64       `json:"budget_ms"`
+}
+
+type ClientKeysHotEvent struct {
+	Mode      string         `json:"mode"`
+	Policy    string         `json:"policy"`
+	Warning   string         `json:"warning"`
+	Keys      []HotKey       `json:"keys"`
+	Prefixes  []HotKeyPrefix `json:"prefixes"`
+	Clients   []HotKeyClient `json:"clients"`
+	Scanned   uint64         `json:"scanned"`
+	Matched   uint64         `json:"matched"`
+	ElapsedMs int64          `json:"elapsed_ms"`
+	WindowMs  int64          `json:"window_ms"`
+	Done      bool           `json:"done"`
+	Truncated bool           `json:"truncated"`
+	NodeStats []ScanNodeStat `json:"node_stats"`
+}
+
+type ClientKeysHotReq struct {
+	ConnectionId  string      `json:"connection_id"`
+	DatabaseIndex int32       `json:"database_index"`
+	Filters       []KeyFilter `json:"filters"`
+	MatchAll      bool        `json:"match_all"`
+	Mode          string      `json:"mode"`
+	Separator     string      `json:"separator"`
+	PrefixDepth   int32       `json:"prefix_depth"`
+	Top           int32       `json:"top"`
+	WindowMs      int64       `json:"window_ms"`
+	ScanCount     int64       `json:"scan_count"`
+	Rate          int64       `json:"rate"`
+}
+
+type ClientKeysImportProgressEvent struct {
+	ConnectionId string   `json:"connection_id"`
+	Path         string   `json:"path"`
+	Read         int64    `json:"read"`
+	Size         int64    `json:"size"`
+	Imported     int64    `json:"imported"`
+	Skipped      int64    `json:"skipped"`
+	Overwritten  int64    `json:"overwritten"`
+	Commands     int64    `json:"commands"`
+	Errors       int64    `json:"errors"`
+	ErrorSamples []string `json:"error_samples"`
+	Status       string   `json:"status"`
+}
+
+type ClientKeysImportReq struct {
+	ConnectionId  string   `json:"connection_id"`
+	DatabaseIndex int32    `json:"database_index"`
+	Path          string   `json:"path"`
+	Format        string   `json:"format"`
+	Conflict      string   `json:"conflict"`
+	BatchSize     int64    `json:"batch_size"`
+	CsvDelimiter  string   `json:"csv_delimiter"`
+	KeyTemplate   string   `json:"key_template"`
+	KeyType       string   `json:"key_type"`
+	Fields        []string `json:"fields"`
+	ValueColumn   string   `json:"value_column"`
 }
 
 type ClientKeysMetadataReq struct {
@@ -103,18 +221,48 @@ type ClientKeysMetadataRes struct {
 	Items []KeyMetadata `json:"items"`
 }
 
+type ClientKeysReportEvent struct {
+	Scanned   uint64            `json:"scanned"`
+	Matched   uint64            `json:"matched"`
+	Keys      int64             `json:"keys"`
+	Memory    int64             `json:"memory"`
+	Types     []KeyReportType   `json:"types"`
+	Prefixes  []KeyReportPrefix `json:"prefixes"`
+	ElapsedMs int64             `json:"elapsed_ms"`
+	Done      bool              `json:"done"`
+	Truncated bool              `json:"truncated"`
+	NodeStats []ScanNodeStat    `json:"node_stats"`
+	TtlHist   []KeyReportBucket `json:"ttl_hist"`
+}
+
+type ClientKeysReportReq struct {
+	ConnectionId  string      `json:"connection_id"`
+	DatabaseIndex int32       `json:"database_index"`
+	Filters       []KeyFilter `json:"filters"`
+	MatchAll      bool        `json:"match_all"`
+	KeyType       string      `json:"key_type"`
+	Separator     string      `json:"separator"`
+	PrefixDepth   int32       `json:"prefix_depth"`
+	Top           int32       `json:"top"`
+	MemorySamples int32       `json:"memory_samples"`
+	ScanCount     int64       `json:"scan_count"`
+	Rate          int64       `json:"rate"`
+	BudgetMs      int64       `json:"budget_ms"`
+}
+
 type ClientKeysScanByPrefixRes struct {
 	Keys       []string `json:"keys"`
 	NextCursor string   `json:"next_cursor"`
 }
 
 type ClientKeysSearchEvent struct {
-	Keys      []string `json:"keys"`
-	Scanned   uint64   `json:"scanned"`
-	Matched   uint64   `json:"matched"`
-	Cursor    string   `json:"cursor"`
-	Done      bool     `json:"done"`
-	Truncated bool     `json:"truncated"`
+	Keys      []string       `json:"keys"`
+	Scanned   uint64         `json:"scanned"`
+	Matched   uint64         `json:"matched"`
+	Cursor    string         `json:"cursor"`
+	Done      bool           `json:"done"`
+	Truncated bool           `json:"truncated"`
+	Nodes     []ScanNodeStat `json:"nodes"`
 }
 
 type ClientKeysSearchReq struct {
@@ -129,6 +277,66 @@ type ClientKeysSearchReq struct {
 	BudgetMs      int64       `json:"budget_ms"`
 }
 
+type ClientKeysTreeEvent struct {
+	Prefix    string         `json:"prefix"`
+	Nodes     []KeyTreeNode  `json:"nodes"`
+	Leaves    []string       `json:"leaves"`
+	LeafCount int64          `json:"leaf_count"`
+	Scanned   uint64         `json:"scanned"`
+	Matched   uint64         `json:"matched"`
+	Done      bool           `json:"done"`
+	Truncated bool           `json:"truncated"`
+	NodeStats []ScanNodeStat `json:"node_stats"`
+}
+
+type ClientKeysTreeReq struct {
+	ConnectionId  string      `json:"connection_id"`
+	DatabaseIndex int32       `json:"database_index"`
+	Prefix        string      `json:"prefix"`
+	Separator     string      `json:"separator"`
+	Filters       []KeyFilter `json:"filters"`
+	MatchAll      bool        `json:"match_all"`
+	KeyType       string      `json:"key_type"`
+	ScanCount     int64       `json:"scan_count"`
+	BudgetMs      int64       `json:"budget_ms"`
+	MemorySamples int32       `json:"memory_samples"`
+	LeafLimit     int64       `json:"leaf_limit"`
+}
+
+type ClientKeysTtlEvent struct {
+	Scanned   uint64            `json:"scanned"`
+	Matched   uint64            `json:"matched"`
+	Keys      int64             `json:"keys"`
+	NoExpiry  int64             `json:"no_expiry"`
+	TtlHist   []KeyReportBucket `json:"ttl_hist"`
+	Prefixes  []TtlPrefix       `json:"prefixes"`
+	Leaks     []TtlPrefix       `json:"leaks"`
+	ElapsedMs int64             `json:"elapsed_ms"`
+	Done      bool              `json:"done"`
+	Truncated bool              `json:"truncated"`
+	NodeStats []ScanNodeStat    `json:"node_stats"`
+}
+
+type ClientKeysTtlReq struct {
+	ConnectionId  string      `json:"connection_id"`
+	DatabaseIndex int32       `json:"database_index"`
+	Filters       []KeyFilter `json:"filters"`
+	MatchAll      bool        `json:"match_all"`
+	KeyType       string      `json:"key_type"`
+	Separator     string      `json:"separator"`
+	PrefixDepth   int32       `json:"prefix_depth"`
+	Samples       int32       `json:"samples"`
+	LeakRatio     float64     `json:"leak_ratio"`
+	LeakMinKeys   int64       `json:"leak_min_keys"`
+	ScanCount     int64       `json:"scan_count"`
+	Rate          int64       `json:"rate"`
+	BudgetMs      int64       `json:"budget_ms"`
+}
+
+type ClientListActiveRes struct {
+	Items []ActiveClient `json:"items"`
+}
+
 type ClientLoadAllKeysReq struct {
 	ConnectionId  string `json:"connection_id"`
 	DatabaseIndex int32  `json:"database_index"`
@@ -191,10 +399,157 @@ type ClientSetReadOnlyReq struct {
 	ReadOnly      bool   `json:"read_only"`
 }
 
+type ClientStateEvent struct {
+	ConnectionId  string `json:"connection_id"`
+	DatabaseIndex int32  `json:"database_index"`
+	State         string `json:"state"`
+	Attempt       int32  `json:"attempt"`
+	Error         string `json:"error"`
+}
+
+type ClusterNode struct {
+	Id          string             `json:"id"`
+	Addr        string             `json:"addr"`
+	DialAddr    string             `json:"dial_addr"`
+	Hostname    string             `json:"hostname"`
+	Role        string             `json:"role"`
+	MasterId    string             `json:"master_id"`
+	Flags       []string           `json:"flags"`
+	LinkState   string             `json:"link_state"`
+	Health      string             `json:"health"`
+	ConfigEpoch int64              `json:"config_epoch"`
+	ReplOffset  int64              `json:"repl_offset"`
+	Slots       []ClusterSlotRange `json:"slots"`
+	Stats       ClusterNodeStats   `json:"stats"`
+	Error       string             `json:"error"`
+}
+
+type ClusterNodeStats struct {
+	UsedMemory     int64   `json:"used_memory"`
+	MaxMemory      int64   `json:"max_memory"`
+	FragRatio      float64 `json:"frag_ratio"`
+	OpsPerSec      int64   `json:"ops_per_sec"`
+	KeyspaceHits   int64   `json:"keyspace_hits"`
+	KeyspaceMisses int64   `json:"keyspace_misses"`
+	EvictedKeys    int64   `json:"evicted_keys"`
+	ExpiredKeys    int64   `json:"expired_keys"`
+	Keys           int64   `json:"keys"`
+	Expires        int64   `json:"expires"`
+}
+
+type ClusterReq struct {
+	ConnectionId  string `json:"connection_id"`
+	DatabaseIndex int32  `json:"database_index"`
+}
+
+type ClusterShard struct {
+	Master     ClusterNode        `json:"master"`
+	Replicas   []ClusterNode      `json:"replicas"`
+	Slots      []ClusterSlotRange `json:"slots"`
+	SlotCount  int32              `json:"slot_count"`
+	Keys       int64              `json:"keys"`
+	UsedMemory int64              `json:"used_memory"`
+}
+
+type ClusterSlotRange struct {
+	Start int32 `json:"start"`
+	End   int32 `json:"end"`
+}
+
+type ClusterTopologyRes struct {
+	Source string         `json:"source"`
+	Shards []ClusterShard `json:"shards"`
+}
+
+type ConnTestNode struct {
+	Addr      string `json:"addr"`
+	DialAddr  string `json:"dial_addr"`
+	Role      string `json:"role"`
+	Ok        bool   `json:"ok"`
+	Error     string `json:"error"`
+	LatencyMs int64  `json:"latency_ms"`
+}
+
+type ConnTestRes struct {
+	Ok            bool            `json:"ok"`
+	Stages        []ConnTestStage `json:"stages"`
+	ServerVersion string          `json:"server_version"`
+	ServerMode    string          `json:"server_mode"`
+	Nodes         []ConnTestNode  `json:"nodes"`
+}
+
+type ConnTestStage struct {
+	Name      string `json:"name"`
+	Ok        bool   `json:"ok"`
+	Detail    string `json:"detail"`
+	Error     string `json:"error"`
+	LatencyMs int64  `json:"latency_ms"`
+}
+
+type ConnectionExportReq struct {
+	Ids        []string `json:"ids"`
+	Passphrase string   `json:"passphrase"`
+}
+
+type ConnectionExportRes struct {
+	Data        []byte `json:"data"`
+	Connections int32  `json:"connections"`
+}
+
+type ConnectionExportUrlReq struct {
+	Id             string `json:"id"`
+	IncludeSecrets bool   `json:"include_secrets"`
+}
+
+type ConnectionExportUrlRes struct {
+	Url string `json:"url"`
+}
+
+type ConnectionImportExternalItem struct {
+	Name        string   `json:"name"`
+	Mode        string   `json:"mode"`
+	Addr        string   `json:"addr"`
+	Db          int32    `json:"db"`
+	Group       string   `json:"group"`
+	Ssh         bool     `json:"ssh"`
+	Tls         bool     `json:"tls"`
+	DuplicateOf string   `json:"duplicate_of"`
+	Warnings    []string `json:"warnings"`
+}
+
+type ConnectionImportExternalReq struct {
+	Source string `json:"source"`
+	Data   []byte `json:"data"`
+}
+
+type ConnectionImportExternalRes struct {
+	Source  string                         `json:"source"`
+	Created int32                          `json:"created"`
+	Skipped int32                          `json:"skipped"`
+	Items   []ConnectionImportExternalItem `json:"items"`
+}
+
+type ConnectionImportReq struct {
+	Data       []byte `json:"data"`
+	Passphrase string `json:"passphrase"`
+}
+
+type ConnectionImportRes struct {
+	Connections int32 `json:"connections"`
+	Groups      int32 `json:"groups"`
+	Ssh         int32 `json:"ssh"`
+	Tls         int32 `json:"tls"`
+	Proxies     int32 `json:"proxies"`
+}
+
 type ConnectionListRes struct {
 	Items []ConnectionReq `json:"items"`
 }
 
+type ConnectionParseUrlReq struct {
+	Url string `json:"url"`
+}
+
 type ConnectionReq struct {
 	Id               string   `json:"id"`
 	Mode             string   `json:"mode"`
@@ -225,6 +580,12 @@ type ConnectionReq struct {
 	GroupId          string   `json:"group_id"`
 	LastDb           int32    `json:"last_db"`
 	ReadOnly         bool     `json:"read_only"`
+	SshJumpIds       []string `json:"ssh_jump_ids"`
+	Url              string   `json:"url"`
+	ReadFrom         string   `json:"read_from"`
+	CredProvider     string   `json:"cred_provider"`
+	CredSource       string   `json:"cred_source"`
+	CredTtl          int64    `json:"cred_ttl"`
 }
 
 type ConsoleInputEvent struct {
@@ -249,6 +610,13 @@ type DbInfo struct {
 	AvgTtl  int64  `json:"avg_ttl"`
 }
 
+type DeleteNodeStat struct {
+	Addr    string `json:"addr"`
+	Matched int64  `json:"matched"`
+	Deleted int64  `json:"deleted"`
+	Done    bool   `json:"done"`
+}
+
 type Empty struct {
 }
 
@@ -268,6 +636,28 @@ type GroupUpsertReq struct {
 	Name string `json:"name"`
 }
 
+type HotKey struct {
+	Key    string `json:"key"`
+	Hits   int64  `json:"hits"`
+	Reads  int64  `json:"reads"`
+	Writes int64  `json:"writes"`
+}
+
+type HotKeyClient struct {
+	Addr     string `json:"addr"`
+	Commands int64  `json:"commands"`
+	Reads    int64  `json:"reads"`
+	Writes   int64  `json:"writes"`
+}
+
+type HotKeyPrefix struct {
+	Prefix string `json:"prefix"`
+	Keys   int64  `json:"keys"`
+	Hits   int64  `json:"hits"`
+	Reads  int64  `json:"reads"`
+	Writes int64  `json:"writes"`
+}
+
 type IdReq struct {
 	Id string `json:"id"`
 }
@@ -332,6 +722,37 @@ type KeyMetadata struct {
 	Size int64  `json:"size"`
 }
 
+type KeyReportBucket struct {
+	Min   int64 `json:"min"`
+	Count int64 `json:"count"`
+}
+
+type KeyReportKey struct {
+	Key      string `json:"key"`
+	Type     string `json:"type"`
+	Memory   int64  `json:"memory"`
+	Elements int64  `json:"elements"`
+	Ttl      int64  `json:"ttl"`
+}
+
+type KeyReportPrefix struct {
+	Prefix   string `json:"prefix"`
+	Keys     int64  `json:"keys"`
+	Memory   int64  `json:"memory"`
+	Elements int64  `json:"elements"`
+}
+
+type KeyReportType struct {
+	Type         string            `json:"type"`
+	Keys         int64             `json:"keys"`
+	Memory       int64             `json:"memory"`
+	Elements     int64             `json:"elements"`
+	TopMemory    []KeyReportKey    `json:"top_memory"`
+	TopElements  []KeyReportKey    `json:"top_elements"`
+	MemoryHist   []KeyReportBucket `json:"memory_hist"`
+	ElementsHist []KeyReportBucket `json:"elements_hist"`
+}
+
 type KeySetMemberDelReq struct {
 	ConnectionId  string `json:"connection_id"`
 	DatabaseIndex int32  `json:"database_index"`
@@ -354,6 +775,21 @@ type KeyStreamEntryDelReq struct {
 	EntryId       string `json:"entry_id"`
 }
 
+type KeyTreeNode struct {
+	Prefix        string             `json:"prefix"`
+	Name          string             `json:"name"`
+	Keys          int64              `json:"keys"`
+	Types         []KeyTreeTypeCount `json:"types"`
+	NoTtl         int64              `json:"no_ttl"`
+	Memory        int64              `json:"memory"`
+	MemorySampled int64              `json:"memory_sampled"`
+}
+
+type KeyTreeTypeCount struct {
+	Type  string `json:"type"`
+	Count int64  `json:"count"`
+}
+
 type KeyValue struct {
 	Key   string `json:"key"`
 	Value string `json:"value"`
@@ -423,6 +859,24 @@ type ProxyReq struct {
 	Password string `json:"password"`
 }
 
+type ProxyTestReq struct {
+	Proxy  ProxyReq `json:"proxy"`
+	Target string   `json:"target"`
+}
+
+type ProxyTestRes struct {
+	Via   string          `json:"via"`
+	Steps []ProxyTestStep `json:"steps"`
+}
+
+type ProxyTestStep struct {
+	Name      string `json:"name"`
+	Ok        bool   `json:"ok"`
+	Detail    string `json:"detail"`
+	Error     string `json:"error"`
+	LatencyMs int64  `json:"latency_ms"`
+}
+
 type PubSubPublishReq struct {
 	ConnectionId  string `json:"connection_id"`
 	DatabaseIndex int32  `json:"database_index"`
@@ -448,6 +902,33 @@ type PubsubMessageEvent struct {
 	Pattern      string `json:"pattern"`
 }
 
+type RdbAnalyzeEvent struct {
+	Version      int32                 `json:"version"`
+	RedisVersion string                `json:"redis_version"`
+	CreatedAt    int64                 `json:"created_at"`
+	Read         int64                 `json:"read"`
+	Size         int64                 `json:"size"`
+	Report       ClientKeysReportEvent `json:"report"`
+}
+
+type RdbAnalyzeReq struct {
+	Path          string      `json:"path"`
+	DatabaseIndex int32       `json:"database_index"`
+	Filters       []KeyFilter `json:"filters"`
+	MatchAll      bool        `json:"match_all"`
+	KeyType       string      `json:"key_type"`
+	Separator     string      `json:"separator"`
+	PrefixDepth   int32       `json:"prefix_depth"`
+	Top           int32       `json:"top"`
+}
+
+type ScanNodeStat struct {
+	Addr    string `json:"addr"`
+	Scanned uint64 `json:"scanned"`
+	Matched uint64 `json:"matched"`
+	Done    bool   `json:"done"`
+}
+
 type SearchPresetItem struct {
 	Id        string      `json:"id"`
 	Name      string      `json:"name"`
@@ -470,6 +951,60 @@ type SearchPresetUpsertReq struct {
 	KeyType  string      `json:"key_type"`
 }
 
+type SentinelEvent struct {
+	ConnectionId string `json:"connection_id"`
+	Sentinel     string `json:"sentinel"`
+	Event        string `json:"event"`
+	Master       string `json:"master"`
+	Message      string `json:"message"`
+	Time         int64  `json:"time"`
+}
+
+type SentinelFailoverReq struct {
+	ConnectionId  string `json:"connection_id"`
+	DatabaseIndex int32  `json:"database_index"`
+	Master        string `json:"master"`
+}
+
+type SentinelMaster struct {
+	Name              string         `json:"name"`
+	Addr              string         `json:"addr"`
+	Flags             string         `json:"flags"`
+	Quorum            int32          `json:"quorum"`
+	NumReplicas       int32          `json:"num_replicas"`
+	NumOtherSentinels int32          `json:"num_other_sentinels"`
+	ConfigEpoch       int64          `json:"config_epoch"`
+	ReportedBy        []string       `json:"reported_by"`
+	Replicas          []SentinelPeer `json:"replicas"`
+	Sentinels         []SentinelPeer `json:"sentinels"`
+}
+
+type SentinelNode struct {
+	Addr      string `json:"addr"`
+	Ok        bool   `json:"ok"`
+	Error     string `json:"error"`
+	LatencyMs int64  `json:"latency_ms"`
+}
+
+type SentinelPeer struct {
+	Addr         string `json:"addr"`
+	RunId        string `json:"run_id"`
+	Flags        string `json:"flags"`
+	LinkStatus   string `json:"link_status"`
+	ReplOffset   int64  `json:"repl_offset"`
+	LastOkPingMs int64  `json:"last_ok_ping_ms"`
+}
+
+type SentinelReq struct {
+	ConnectionId  string `json:"connection_id"`
+	DatabaseIndex int32  `json:"database_index"`
+}
+
+type SentinelTopologyRes struct {
+	Nodes   []SentinelNode   `json:"nodes"`
+	Masters []SentinelMaster `json:"masters"`
+}
+
 type SettingGetReq struct {
 	Key string `json:"key"`
 }
@@ -506,15 +1041,27 @@ type SshListRes struct {
 }
 
 type SshReq struct {
-	Id         string `json:"id"`
-	Host       string `json:"host"`
-	Port       int32  `json:"port"`
-	Username   string `json:"username"`
-	Kind       string `json:"kind"`
-	Password   string `json:"password"`
-	PrivateKey string `json:"private_key"`
-	Passphrase string `json:"passphrase"`
-	Timeout    int64  `json:"timeout"`
+	Id            string   `json:"id"`
+	Host          string   `json:"host"`
+	Port          int32    `json:"port"`
+	Username      string   `json:"username"`
+	Kind          string   `json:"kind"`
+	Password      string   `json:"password"`
+	PrivateKey    string   `json:"private_key"`
+	Passphrase    string   `json:"passphrase"`
+	Timeout       int64    `json:"timeout"`
+	AcceptHostKey string   `json:"accept_host_key"`
+	JumpIds       []string `json:"jump_ids"`
+}
+
+type SshTestRes struct {
+	Host             string `json:"host"`
+	HostKeyStatus    string `json:"host_key_status"`
+	KeyType          string `json:"key_type"`
+	Fingerprint      string `json:"fingerprint"`
+	KnownFingerprint string `json:"known_fingerprint"`
+	Hop              int32  `json:"hop"`
+	HopAddr          string `json:"hop_addr"`
 }
 
 type StreamValue struct {
@@ -529,20 +1076,65 @@ type SystemInfoRes struct {
 	Runtime string `json:"runtime"`
 }
 
+type TlsCertInfo struct {
+	Subject     string   `json:"subject"`
+	Issuer      string   `json:"issuer"`
+	Sans        []string `json:"sans"`
+	NotBefore   int64    `json:"not_before"`
+	NotAfter    int64    `json:"not_after"`
+	Fingerprint string   `json:"fingerprint"`
+	IsCa        bool     `json:"is_ca"`
+}
+
 type TlsListRes struct {
 	Items []TlsReq `json:"items"`
 }
 
 type TlsReq struct {
-	Id         string `json:"id"`
-	Name       string `json:"name"`
-	UseSni     bool   `json:"use_sni"`
-	ServerName string `json:"server_name"`
-	Verify     bool   `json:"verify"`
-	ClientAuth bool   `json:"client_auth"`
-	CaCert     string `json:"ca_cert"`
-	Cert       string `json:"cert"`
-	Key        string `json:"key"`
+	Id            string `json:"id"`
+	Name          string `json:"name"`
+	UseSni        bool   `json:"use_sni"`
+	ServerName    string `json:"server_name"`
+	Verify        bool   `json:"verify"`
+	ClientAuth    bool   `json:"client_auth"`
+	CaCert        string `json:"ca_cert"`
+	Cert          string `json:"cert"`
+	Key           string `json:"key"`
+	CaFile        string `json:"ca_file"`
+	CertFile      string `json:"cert_file"`
+	KeyFile       string `json:"key_file"`
+	KeyPassphrase string `json:"key_passphrase"`
+	MinVersion    string `json:"min_version"`
+	MaxVersion    string `json:"max_version"`
+	CipherSuites  string `json:"cipher_suites"`
+	PinSha256     string `json:"pin_sha256"`
+}
+
+type TlsTestReq struct {
+	Tls  TlsReq `json:"tls"`
+	Host string `json:"host"`
+	Port int32  `json:"port"`
+}
+
+type TlsTestRes struct {
+	Version     string        `json:"version"`
+	CipherSuite string        `json:"cipher_suite"`
+	Chain       []TlsCertInfo `json:"chain"`
+	Verified    bool          `json:"verified"`
+	VerifyError string        `json:"verify_error"`
+	Pinned      bool          `json:"pinned"`
+	PinOk       bool          `json:"pin_ok"`
+	PinError    string        `json:"pin_error"`
+}
+
+type TtlPrefix struct {
+	Prefix         string            `json:"prefix"`
+	Keys           int64             `json:"keys"`
+	Memory         int64             `json:"memory"`
+	NoExpiry       int64             `json:"no_expiry"`
+	NoExpiryMemory int64             `json:"no_expiry_memory"`
+	TtlHist        []KeyReportBucket `json:"ttl_hist"`
+	Samples        []string          `json:"samples"`
 }
 
 type UpsertRes struct {
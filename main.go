@@ -60,6 +60,7 @@ func main() {
 		if err := sc.MigrateSecrets(ctx); err != nil {
 			log.Printf("secrets migration failed (will retry next start): %v", err)
 		}
+		sc.ApplyClientLimits(ctx)
 	})
 
 	if *mode == "web" {
@@ -3,6 +3,7 @@ package netx
 import (
 	"bufio"
 	"context"
+	"crypto/tls"
 	"encoding/base64"
 	"fmt"
 	"net"
@@ -15,6 +16,9 @@ type HttpConnectDialer struct {
 	Username  string
 	Password  string
 	Timeout   time.Duration
+	// TLSConfig makes this an HTTPS proxy: the connection to the proxy is
+	// wrapped in TLS before the CONNECT is sent.
+	TLSConfig *tls.Config
 }
 
 func (d *HttpConnectDialer) Dial(network, addr string) (net.Conn, error) {
@@ -22,19 +26,64 @@ func (d *HttpConnectDialer) Dial(network, addr string) (net.Conn, error) {
 }
 
 func (d *HttpConnectDialer) DialContext(ctx context.Context, network, addr string) (net.Conn, error) {
+	conn, resp, err := d.connect(ctx, addr)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		_ = conn.Close()
+		return nil, fmt.Errorf("proxy connection failed: %d %s", resp.StatusCode, resp.Status)
+	}
+
+	return conn, nil
+}
+
+// Probe sends a CONNECT for addr and returns the proxy's answer whatever
+// its status, so a caller can tell refused credentials (407) from a refused
+// target.
+func (d *HttpConnectDialer) Probe(ctx context.Context, addr string) (*http.Response, error) {
+	conn, resp, err := d.connect(ctx, addr)
+	if err != nil {
+		return nil, err
+	}
+	_ = conn.Close()
+	return resp, nil
+}
+
+// DialProxy opens the connection to the proxy itself, with TLS for an
+// HTTPS proxy.
+func (d *HttpConnectDialer) DialProxy(ctx context.Context) (net.Conn, error) {
 	dialer := &net.Dialer{
 		Timeout: d.Timeout,
 	}
 
 	conn, err := dialer.DialContext(ctx, "tcp", d.ProxyAddr)
+	if err != nil || d.TLSConfig == nil {
+		return conn, err
+	}
+
+	cfg := d.TLSConfig.Clone()
+	if cfg.ServerName == "" {
+		cfg.ServerName, _, _ = net.SplitHostPort(d.ProxyAddr)
+	}
+	tc := tls.Client(conn, cfg)
+	if err := tc.HandshakeContext(ctx); err != nil {
+		_ = conn.Close()
+		return nil, fmt.Errorf("proxy tls handshake: %w", err)
+	}
+	return tc, nil
+}
+
+func (d *HttpConnectDialer) connect(ctx context.Context, addr string) (net.Conn, *http.Response, error) {
+	conn, err := d.DialProxy(ctx)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 
 	req, err := http.NewRequestWithContext(ctx, http.MethodConnect, "http://"+addr, nil)
 	if err != nil {
 		_ = conn.Close()
-		return nil, err
+		return nil, nil, err
 	}
 
 	if d.Username != "" {
@@ -44,22 +93,17 @@ func (d *HttpConnectDialer) DialContext(ctx context.Context, network, addr strin
 
 	if err := req.Write(conn); err != nil {
 		_ = conn.Close()
-		return nil, err
+		return nil, nil, err
 	}
 
 	resp, err := http.ReadResponse(bufio.NewReader(conn), req)
 	if err != nil {
 		_ = conn.Close()
-		return nil, err
-	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode != http.StatusOK {
-		_ = conn.Close()
-		return nil, fmt.Errorf("proxy connection failed: %d %s", resp.StatusCode, resp.Status)
+		return nil, nil, err
 	}
+	_ = resp.Body.Close()
 
-	return conn, nil
+	return conn, resp, nil
 }
 
 type ContextDialer interface {
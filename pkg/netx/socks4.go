@@ -0,0 +1,128 @@
+package netx
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"time"
+)
+
+// Socks4Dialer connects through a SOCKS4 proxy. With RemoteDNS it speaks
+// SOCKS4a and hands host names to the proxy instead of resolving them.
+type Socks4Dialer struct {
+	ProxyAddr string
+	UserID    string
+	RemoteDNS bool
+	Timeout   time.Duration
+}
+
+func (d *Socks4Dialer) Dial(network, addr string) (net.Conn, error) {
+	return d.DialContext(context.Background(), network, addr)
+}
+
+func (d *Socks4Dialer) DialContext(ctx context.Context, network, addr string) (net.Conn, error) {
+	conn, code, err := d.connect(ctx, addr)
+	if err != nil {
+		return nil, err
+	}
+	if code != socks4Granted {
+		_ = conn.Close()
+		return nil, socks4Error(code)
+	}
+	return conn, nil
+}
+
+// Probe sends a CONNECT for addr and returns the proxy's reply code; any
+// well-formed reply shows the proxy speaks SOCKS4.
+func (d *Socks4Dialer) Probe(ctx context.Context, addr string) (byte, error) {
+	conn, code, err := d.connect(ctx, addr)
+	if err != nil {
+		return 0, err
+	}
+	_ = conn.Close()
+	return code, nil
+}
+
+const socks4Granted = 0x5a
+
+func socks4Error(code byte) error {
+	switch code {
+	case 0x5b:
+		return errors.New("socks4: request rejected or failed")
+	case 0x5c:
+		return errors.New("socks4: proxy cannot reach identd on the client")
+	case 0x5d:
+		return errors.New("socks4: identd user id mismatch")
+	}
+	return fmt.Errorf("socks4: unknown reply code %#x", code)
+}
+
+func (d *Socks4Dialer) connect(ctx context.Context, addr string) (net.Conn, byte, error) {
+	host, portStr, err := net.SplitHostPort(addr)
+	if err != nil {
+		return nil, 0, err
+	}
+	port, err := strconv.ParseUint(portStr, 10, 16)
+	if err != nil {
+		return nil, 0, fmt.Errorf("socks4: bad port %q", portStr)
+	}
+
+	var domain string
+	ip := net.ParseIP(host)
+	switch {
+	case ip != nil:
+		ip = ip.To4()
+	case d.RemoteDNS:
+		// SOCKS4a: an address of 0.0.0.x tells the proxy a name follows.
+		ip, domain = net.IPv4(0, 0, 0, 1).To4(), host
+	default:
+		ips, err := net.DefaultResolver.LookupIP(ctx, "ip4", host)
+		if err != nil {
+			return nil, 0, err
+		}
+		ip = ips[0].To4()
+	}
+	if ip == nil {
+		return nil, 0, fmt.Errorf("socks4: %s is not an IPv4 address", host)
+	}
+
+	req := []byte{4, 1, byte(port >> 8), byte(port)}
+	req = append(req, ip...)
+	req = append(req, d.UserID...)
+	req = append(req, 0)
+	if domain != "" {
+		req = append(req, domain...)
+		req = append(req, 0)
+	}
+
+	dialer := &net.Dialer{
+		Timeout: d.Timeout,
+	}
+	conn, err := dialer.DialContext(ctx, "tcp", d.ProxyAddr)
+	if err != nil {
+		return nil, 0, err
+	}
+	if deadline, ok := ctx.Deadline(); ok {
+		_ = conn.SetDeadline(deadline)
+	} else if d.Timeout > 0 {
+		_ = conn.SetDeadline(time.Now().Add(d.Timeout))
+	}
+
+	reply := make([]byte, 8)
+	if _, err = conn.Write(req); err == nil {
+		_, err = io.ReadFull(conn, reply)
+	}
+	if err != nil {
+		_ = conn.Close()
+		return nil, 0, fmt.Errorf("socks4: %w", err)
+	}
+	if reply[0] != 0 {
+		_ = conn.Close()
+		return nil, 0, errors.New("socks4: invalid reply, not a SOCKS4 proxy")
+	}
+	_ = conn.SetDeadline(time.Time{})
+	return conn, reply[1], nil
+}
@@ -0,0 +1,91 @@
+package netx
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"time"
+)
+
+// ResolvingDialer resolves host names locally and hands Next only the IP,
+// for proxies that must not see the name (socks5 as opposed to socks5h).
+type ResolvingDialer struct {
+	Next ContextDialer
+}
+
+func (d *ResolvingDialer) DialContext(ctx context.Context, network, addr string) (net.Conn, error) {
+	host, port, err := net.SplitHostPort(addr)
+	if err != nil {
+		return nil, err
+	}
+	if net.ParseIP(host) == nil {
+		ips, err := net.DefaultResolver.LookupIPAddr(ctx, host)
+		if err != nil {
+			return nil, err
+		}
+		addr = net.JoinHostPort(ips[0].IP.String(), port)
+	}
+	return d.Next.DialContext(ctx, network, addr)
+}
+
+// Socks5Probe greets a SOCKS5 proxy and authenticates when a username is
+// set, without asking it to connect anywhere.
+func Socks5Probe(ctx context.Context, proxyAddr, username, password string, timeout time.Duration) error {
+	dialer := &net.Dialer{
+		Timeout: timeout,
+	}
+	conn, err := dialer.DialContext(ctx, "tcp", proxyAddr)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	if deadline, ok := ctx.Deadline(); ok {
+		_ = conn.SetDeadline(deadline)
+	} else if timeout > 0 {
+		_ = conn.SetDeadline(time.Now().Add(timeout))
+	}
+
+	method := byte(0x00)
+	if username != "" {
+		method = 0x02
+	}
+	if _, err := conn.Write([]byte{5, 1, method}); err != nil {
+		return fmt.Errorf("socks5: %w", err)
+	}
+	reply := make([]byte, 2)
+	if _, err := io.ReadFull(conn, reply); err != nil {
+		return fmt.Errorf("socks5: %w", err)
+	}
+	if reply[0] != 5 {
+		return errors.New("socks5: invalid reply, not a SOCKS5 proxy")
+	}
+	if reply[1] != method {
+		if username == "" {
+			return errors.New("socks5: proxy requires authentication")
+		}
+		return errors.New("socks5: proxy does not accept username/password authentication")
+	}
+	if method == 0x00 {
+		return nil
+	}
+
+	if len(username) > 255 || len(password) > 255 {
+		return errors.New("socks5: username or password too long")
+	}
+	req := []byte{1, byte(len(username))}
+	req = append(req, username...)
+	req = append(req, byte(len(password)))
+	req = append(req, password...)
+	if _, err := conn.Write(req); err != nil {
+		return fmt.Errorf("socks5: %w", err)
+	}
+	if _, err := io.ReadFull(conn, reply); err != nil {
+		return fmt.Errorf("socks5: %w", err)
+	}
+	if reply[1] != 0 {
+		return errors.New("socks5: username/password rejected")
+	}
+	return nil
+}
@@ -0,0 +1,705 @@
+// Package rdb reads Redis RDB snapshots without a server. It walks every key
+// and reports its type, encoding, element count, expiry and the bytes it
+// takes in the file, skipping over values rather than building them.
+package rdb
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+)
+
+// MaxVersion is the newest RDB format the decoder understands (Redis 7.4
+// and 8.x).
+const MaxVersion = 12
+
+const (
+	opSlotInfo      = 0xF4
+	opFunction2     = 0xF5
+	opFunctionPreGA = 0xF6
+	opModuleAux     = 0xF7
+	opIdle          = 0xF8
+	opFreq          = 0xF9
+	opAux           = 0xFA
+	opResizeDB      = 0xFB
+	opExpireTimeMs  = 0xFC
+	opExpireTime    = 0xFD
+	opSelectDB      = 0xFE
+	opEOF           = 0xFF
+)
+
+const (
+	typeString              = 0
+	typeList                = 1
+	typeSet                 = 2
+	typeZset                = 3
+	typeHash                = 4
+	typeZset2               = 5
+	typeModulePreGA         = 6
+	typeModule2             = 7
+	typeHashZipmap          = 9
+	typeListZiplist         = 10
+	typeSetIntset           = 11
+	typeZsetZiplist         = 12
+	typeHashZiplist         = 13
+	typeListQuicklist       = 14
+	typeStreamListpacks     = 15
+	typeHashListpack        = 16
+	typeZsetListpack        = 17
+	typeListQuicklist2      = 18
+	typeStreamListpacks2    = 19
+	typeSetListpack         = 20
+	typeStreamListpacks3    = 21
+	typeHashMetadataPreGA   = 22
+	typeHashListpackExPreGA = 23
+	typeHashMetadata        = 24
+	typeHashListpackEx      = 25
+)
+
+const (
+	moduleOpEOF    = 0
+	moduleOpSint   = 1
+	moduleOpUint   = 2
+	moduleOpFloat  = 3
+	moduleOpDouble = 4
+	moduleOpString = 5
+)
+
+const (
+	encInt8  = 0
+	encInt16 = 1
+	encInt32 = 2
+	encLZF   = 3
+)
+
+const quicklistNodePlain = 1
+
+// maxBlob bounds the strings read into memory: keys and compact encodings.
+// Plain values are skipped whatever their size.
+const maxBlob = 1 << 30
+
+// Entry is one key of the snapshot.
+type Entry struct {
+	DB       int
+	Key      string
+	Type     string // as TYPE reports it, or the module type's name
+	Encoding string // as OBJECT ENCODING reports it
+	Elements int64  // fields, members or entries; the length for strings
+	Size     int64  // bytes the key and its value take in the file
+	Expiry   int64  // unix ms, 0 without
+}
+
+// Decoder reads entries from an RDB stream one by one.
+type Decoder struct {
+	r       *bufio.Reader
+	off     int64
+	db      int
+	done    bool
+	Version int
+	// Aux holds the AUX fields read so far, such as redis-ver and ctime.
+	// They come before the first key.
+	Aux map[string]string
+}
+
+// NewDecoder reads and checks the RDB header.
+func NewDecoder(r io.Reader) (*Decoder, error) {
+	d := &Decoder{r: bufio.NewReaderSize(r, 1<<16), Aux: make(map[string]string)}
+	magic, err := d.bytes(9)
+	if err != nil {
+		return nil, fmt.Errorf("not an RDB file: %w", err)
+	}
+	if string(magic[:5]) != "REDIS" {
+		return nil, errors.New("not an RDB file: bad magic")
+	}
+	d.Version, err = strconv.Atoi(string(magic[5:]))
+	if err != nil || d.Version < 1 {
+		return nil, fmt.Errorf("not an RDB file: bad version %q", magic[5:])
+	}
+	if d.Version > MaxVersion {
+		return nil, fmt.Errorf("unsupported RDB version %d, newest supported is %d", d.Version, MaxVersion)
+	}
+	return d, nil
+}
+
+// Offset is how many bytes of the stream have been consumed.
+func (d *Decoder) Offset() int64 { return d.off }
+
+// Next returns the next key, or io.EOF after the last one.
+func (d *Decoder) Next() (*Entry, error) {
+	if d.done {
+		return nil, io.EOF
+	}
+	var expiry int64
+	for {
+		start := d.off
+		op, err := d.byte()
+		if err != nil {
+			return nil, err
+		}
+		switch op {
+		case opEOF:
+			d.done = true
+			if d.Version >= 5 {
+				// CRC64 of the file; a file cut right here is still complete.
+				_, _ = d.bytes(8)
+			}
+			return nil, io.EOF
+		case opSelectDB:
+			n, err := d.length()
+			if err != nil {
+				return nil, err
+			}
+			d.db = int(n)
+		case opResizeDB:
+			if err := d.skipLengths(2); err != nil {
+				return nil, err
+			}
+		case opSlotInfo:
+			if err := d.skipLengths(3); err != nil {
+				return nil, err
+			}
+		case opAux:
+			k, err := d.string()
+			if err != nil {
+				return nil, err
+			}
+			v, err := d.string()
+			if err != nil {
+				return nil, err
+			}
+			d.Aux[string(k)] = string(v)
+		case opExpireTime:
+			b, err := d.bytes(4)
+			if err != nil {
+				return nil, err
+			}
+			expiry = int64(binary.LittleEndian.Uint32(b)) * 1000
+		case opExpireTimeMs:
+			if expiry, err = d.millis(); err != nil {
+				return nil, err
+			}
+		case opFreq:
+			if _, err := d.byte(); err != nil {
+				return nil, err
+			}
+		case opIdle:
+			if _, err := d.length(); err != nil {
+				return nil, err
+			}
+		case opModuleAux:
+			// Module id, then the "when" marker as an opcode and a value.
+			if err := d.skipLengths(3); err != nil {
+				return nil, err
+			}
+			if err := d.skipModuleValue(); err != nil {
+				return nil, err
+			}
+		case opFunction2:
+			if _, err := d.skipString(); err != nil {
+				return nil, err
+			}
+		case opFunctionPreGA:
+			return nil, errors.New("pre-GA function records (Redis 7.0 release candidates) are not supported")
+		default:
+			key, err := d.string()
+			if err != nil {
+				return nil, err
+			}
+			e := &Entry{DB: d.db, Key: string(key), Expiry: expiry}
+			if err := d.value(op, e); err != nil {
+				return nil, fmt.Errorf("key %q: %w", key, err)
+			}
+			e.Size = d.off - start
+			return e, nil
+		}
+	}
+}
+
+func (d *Decoder) value(t byte, e *Entry) error {
+	var err error
+	switch t {
+	case typeString:
+		e.Type, e.Encoding = "string", "raw"
+		e.Elements, err = d.skipString()
+	case typeList:
+		e.Type, e.Encoding = "list", "linkedlist"
+		e.Elements, err = d.skipStrings(1)
+	case typeSet:
+		e.Type, e.Encoding = "set", "hashtable"
+		e.Elements, err = d.skipStrings(1)
+	case typeHash:
+		e.Type, e.Encoding = "hash", "hashtable"
+		e.Elements, err = d.skipStrings(2)
+	case typeZset, typeZset2:
+		e.Type, e.Encoding = "zset", "skiplist"
+		e.Elements, err = d.skipZset(t == typeZset2)
+	case typeHashZipmap:
+		e.Type, e.Encoding = "hash", "zipmap"
+		e.Elements, err = d.blobCount(zipmapLen, 2)
+	case typeListZiplist:
+		e.Type, e.Encoding = "list", "ziplist"
+		e.Elements, err = d.blobCount(ziplistLen, 1)
+	case typeSetIntset:
+		e.Type, e.Encoding = "set", "intset"
+		e.Elements, err = d.blobCount(intsetLen, 1)
+	case typeZsetZiplist:
+		e.Type, e.Encoding = "zset", "ziplist"
+		e.Elements, err = d.blobCount(ziplistLen, 2)
+	case typeHashZiplist:
+		e.Type, e.Encoding = "hash", "ziplist"
+		e.Elements, err = d.blobCount(ziplistLen, 2)
+	case typeHashListpack:
+		e.Type, e.Encoding = "hash", "listpack"
+		e.Elements, err = d.blobCount(listpackLen, 2)
+	case typeZsetListpack:
+		e.Type, e.Encoding = "zset", "listpack"
+		e.Elements, err = d.blobCount(listpackLen, 2)
+	case typeSetListpack:
+		e.Type, e.Encoding = "set", "listpack"
+		e.Elements, err = d.blobCount(listpackLen, 1)
+	case typeListQuicklist, typeListQuicklist2:
+		e.Type, e.Encoding = "list", "quicklist"
+		e.Elements, err = d.skipQuicklist(t == typeListQuicklist2)
+	case typeStreamListpacks, typeStreamListpacks2, typeStreamListpacks3:
+		e.Type, e.Encoding = "stream", "stream"
+		e.Elements, err = d.skipStream(t)
+	case typeHashMetadata, typeHashMetadataPreGA:
+		e.Type, e.Encoding = "hash", "hashtable"
+		e.Elements, err = d.skipHashMetadata(t == typeHashMetadata)
+	case typeHashListpackEx, typeHashListpackExPreGA:
+		e.Type, e.Encoding = "hash", "listpackex"
+		if t == typeHashListpackEx {
+			if _, err := d.millis(); err != nil { // smallest field expiry
+				return err
+			}
+		}
+		e.Elements, err = d.blobCount(listpackLen, 3)
+	case typeModule2:
+		var id uint64
+		if id, err = d.length(); err != nil {
+			return err
+		}
+		e.Type, e.Encoding = moduleTypeName(id), "module"
+		err = d.skipModuleValue()
+	case typeModulePreGA:
+		return errors.New("pre-GA module values are not supported")
+	default:
+		return fmt.Errorf("unknown value type %d", t)
+	}
+	return err
+}
+
+func (d *Decoder) skipZset(binaryScores bool) (int64, error) {
+	n, err := d.length()
+	if err != nil {
+		return 0, err
+	}
+	for i := uint64(0); i < n; i++ {
+		if _, err := d.skipString(); err != nil {
+			return 0, err
+		}
+		if binaryScores {
+			_, err = d.bytes(8)
+		} else {
+			err = d.skipDouble()
+		}
+		if err != nil {
+			return 0, err
+		}
+	}
+	return int64(n), nil
+}
+
+// skipDouble skips a score of the old ZSET type: a length byte and the
+// number as text, with 253-255 standing for NaN and the infinities.
+func (d *Decoder) skipDouble() error {
+	n, err := d.byte()
+	if err != nil || n >= 253 {
+		return err
+	}
+	_, err = d.bytes(int(n))
+	return err
+}
+
+func (d *Decoder) skipQuicklist(v2 bool) (int64, error) {
+	nodes, err := d.length()
+	if err != nil {
+		return 0, err
+	}
+	var total int64
+	for i := uint64(0); i < nodes; i++ {
+		if v2 {
+			container, err := d.length()
+			if err != nil {
+				return 0, err
+			}
+			if container == quicklistNodePlain {
+				if _, err := d.skipString(); err != nil {
+					return 0, err
+				}
+				total++
+				continue
+			}
+		}
+		count := ziplistLen
+		if v2 {
+			count = listpackLen
+		}
+		n, err := d.blobCount(count, 1)
+		if err != nil {
+			return 0, err
+		}
+		total += n
+	}
+	return total, nil
+}
+
+func (d *Decoder) skipStream(t byte) (int64, error) {
+	nodes, err := d.length()
+	if err != nil {
+		return 0, err
+	}
+	for i := uint64(0); i < nodes; i++ {
+		// Master entry ID, then the listpack of entries.
+		if _, err := d.skipString(); err != nil {
+			return 0, err
+		}
+		if _, err := d.skipString(); err != nil {
+			return 0, err
+		}
+	}
+	length, err := d.length()
+	if err != nil {
+		return 0, err
+	}
+	// Last ID; from v2 also the first and max-deleted IDs and entries-added.
+	skip := 2
+	if t >= typeStreamListpacks2 {
+		skip += 5
+	}
+	if err := d.skipLengths(skip); err != nil {
+		return 0, err
+	}
+
+	groups, err := d.length()
+	if err != nil {
+		return 0, err
+	}
+	for i := uint64(0); i < groups; i++ {
+		if _, err := d.skipString(); err != nil {
+			return 0, err
+		}
+		skip := 2 // last delivered ID
+		if t >= typeStreamListpacks2 {
+			skip++ // entries read
+		}
+		if err := d.skipLengths(skip); err != nil {
+			return 0, err
+		}
+		pel, err := d.length()
+		if err != nil {
+			return 0, err
+		}
+		for j := uint64(0); j < pel; j++ {
+			// Raw ID and delivery time, then the delivery count.
+			if _, err := d.bytes(16 + 8); err != nil {
+				return 0, err
+			}
+			if _, err := d.length(); err != nil {
+				return 0, err
+			}
+		}
+		consumers, err := d.length()
+		if err != nil {
+			return 0, err
+		}
+		for j := uint64(0); j < consumers; j++ {
+			if _, err := d.skipString(); err != nil {
+				return 0, err
+			}
+			times := 8 // seen time
+			if t >= typeStreamListpacks3 {
+				times += 8 // active time
+			}
+			if _, err := d.bytes(times); err != nil {
+				return 0, err
+			}
+			pel, err := d.length()
+			if err != nil {
+				return 0, err
+			}
+			if _, err := d.bytes(int(pel) * 16); err != nil {
+				return 0, err
+			}
+		}
+	}
+	return int64(length), nil
+}
+
+func (d *Decoder) skipHashMetadata(ga bool) (int64, error) {
+	if ga {
+		if _, err := d.millis(); err != nil { // smallest field expiry
+			return 0, err
+		}
+	}
+	n, err := d.length()
+	if err != nil {
+		return 0, err
+	}
+	for i := uint64(0); i < n; i++ {
+		// Field TTL, field, value.
+		if _, err := d.length(); err != nil {
+			return 0, err
+		}
+		if _, err := d.skipString(); err != nil {
+			return 0, err
+		}
+		if _, err := d.skipString(); err != nil {
+			return 0, err
+		}
+	}
+	return int64(n), nil
+}
+
+func (d *Decoder) skipModuleValue() error {
+	for {
+		op, err := d.length()
+		if err != nil {
+			return err
+		}
+		switch op {
+		case moduleOpEOF:
+			return nil
+		case moduleOpSint, moduleOpUint:
+			_, err = d.length()
+		case moduleOpFloat:
+			_, err = d.bytes(4)
+		case moduleOpDouble:
+			_, err = d.bytes(8)
+		case moduleOpString:
+			_, err = d.skipString()
+		default:
+			return fmt.Errorf("unknown module opcode %d", op)
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// moduleTypeName decodes the 9-character type name packed into the upper
+// 54 bits of a module type id.
+func moduleTypeName(id uint64) string {
+	const charset = "ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz0123456789-_"
+	name := make([]byte, 9)
+	id >>= 10
+	for i := 8; i >= 0; i-- {
+		name[i] = charset[id&63]
+		id >>= 6
+	}
+	return string(name)
+}
+
+// skipStrings reads a count n and skips n*per strings, returning n.
+func (d *Decoder) skipStrings(per int) (int64, error) {
+	n, err := d.length()
+	if err != nil {
+		return 0, err
+	}
+	for i := uint64(0); i < n*uint64(per); i++ {
+		if _, err := d.skipString(); err != nil {
+			return 0, err
+		}
+	}
+	return int64(n), nil
+}
+
+// blobCount reads a string holding a compact encoding and counts its
+// entries, per entries to an element.
+func (d *Decoder) blobCount(count func([]byte) (int64, error), per int64) (int64, error) {
+	b, err := d.string()
+	if err != nil {
+		return 0, err
+	}
+	n, err := count(b)
+	if err != nil {
+		return 0, err
+	}
+	return n / per, nil
+}
+
+func (d *Decoder) skipLengths(n int) error {
+	for i := 0; i < n; i++ {
+		if _, err := d.length(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// length reads a length-encoded integer. Special string encodings are an
+// error here.
+func (d *Decoder) length() (uint64, error) {
+	n, special, err := d.lengthOrSpecial()
+	if err == nil && special {
+		err = errors.New("unexpected string encoding where a length belongs")
+	}
+	return n, err
+}
+
+func (d *Decoder) lengthOrSpecial() (uint64, bool, error) {
+	b, err := d.byte()
+	if err != nil {
+		return 0, false, err
+	}
+	switch b >> 6 {
+	case 0:
+		return uint64(b & 0x3f), false, nil
+	case 1:
+		next, err := d.byte()
+		return uint64(b&0x3f)<<8 | uint64(next), false, err
+	case 2:
+		switch b {
+		case 0x80:
+			v, err := d.bytes(4)
+			if err != nil {
+				return 0, false, err
+			}
+			return uint64(binary.BigEndian.Uint32(v)), false, nil
+		case 0x81:
+			v, err := d.bytes(8)
+			if err != nil {
+				return 0, false, err
+			}
+			return binary.BigEndian.Uint64(v), false, nil
+		}
+		return 0, false, fmt.Errorf("bad length encoding 0x%02x", b)
+	default:
+		return uint64(b & 0x3f), true, nil
+	}
+}
+
+// string reads a string, expanding integer and LZF encodings.
+func (d *Decoder) string() ([]byte, error) {
+	n, special, err := d.lengthOrSpecial()
+	if err != nil {
+		return nil, err
+	}
+	if !special {
+		return d.bytes(int(n))
+	}
+	switch n {
+	case encInt8, encInt16, encInt32:
+		return d.intString(n)
+	case encLZF:
+		clen, err := d.length()
+		if err != nil {
+			return nil, err
+		}
+		ulen, err := d.length()
+		if err != nil {
+			return nil, err
+		}
+		in, err := d.bytes(int(clen))
+		if err != nil {
+			return nil, err
+		}
+		return lzfDecompress(in, int(ulen))
+	}
+	return nil, fmt.Errorf("unknown string encoding %d", n)
+}
+
+// skipString skips a string without holding it and returns its length.
+func (d *Decoder) skipString() (int64, error) {
+	n, special, err := d.lengthOrSpecial()
+	if err != nil {
+		return 0, err
+	}
+	if !special {
+		return int64(n), d.discard(n)
+	}
+	switch n {
+	case encInt8, encInt16, encInt32:
+		b, err := d.intString(n)
+		return int64(len(b)), err
+	case encLZF:
+		clen, err := d.length()
+		if err != nil {
+			return 0, err
+		}
+		ulen, err := d.length()
+		if err != nil {
+			return 0, err
+		}
+		return int64(ulen), d.discard(clen)
+	}
+	return 0, fmt.Errorf("unknown string encoding %d", n)
+}
+
+// intString reads a string stored as a little-endian integer and returns
+// its decimal text.
+func (d *Decoder) intString(enc uint64) ([]byte, error) {
+	b, err := d.bytes(1 << enc)
+	if err != nil {
+		return nil, err
+	}
+	var v int64
+	switch enc {
+	case encInt8:
+		v = int64(int8(b[0]))
+	case encInt16:
+		v = int64(int16(binary.LittleEndian.Uint16(b)))
+	default:
+		v = int64(int32(binary.LittleEndian.Uint32(b)))
+	}
+	return strconv.AppendInt(nil, v, 10), nil
+}
+
+func (d *Decoder) millis() (int64, error) {
+	b, err := d.bytes(8)
+	if err != nil {
+		return 0, err
+	}
+	return int64(binary.LittleEndian.Uint64(b)), nil
+}
+
+func (d *Decoder) byte() (byte, error) {
+	b, err := d.r.ReadByte()
+	if err != nil {
+		return 0, unexpected(err)
+	}
+	d.off++
+	return b, nil
+}
+
+func (d *Decoder) bytes(n int) ([]byte, error) {
+	if n < 0 || n > maxBlob {
+		return nil, fmt.Errorf("bad length %d", n)
+	}
+	b := make([]byte, n)
+	m, err := io.ReadFull(d.r, b)
+	d.off += int64(m)
+	return b, unexpected(err)
+}
+
+func (d *Decoder) discard(n uint64) error {
+	if n > math.MaxInt64 {
+		return fmt.Errorf("bad length %d", n)
+	}
+	m, err := io.CopyN(io.Discard, d.r, int64(n))
+	d.off += m
+	return unexpected(err)
+}
+
+// unexpected turns EOF inside a record into io.ErrUnexpectedEOF, so only
+// Next's own io.EOF means the file ended where it should.
+func unexpected(err error) error {
+	if err == io.EOF {
+		return io.ErrUnexpectedEOF
+	}
+	return err
+}
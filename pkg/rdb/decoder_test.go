@@ -0,0 +1,254 @@
+package rdb
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+)
+
+// rdbWriter builds RDB bytes by hand, one encoding at a time.
+type rdbWriter struct{ bytes.Buffer }
+
+func (w *rdbWriter) op(b ...byte) *rdbWriter { w.Write(b); return w }
+
+func (w *rdbWriter) len(n uint64) *rdbWriter {
+	switch {
+	case n < 1<<6:
+		w.WriteByte(byte(n))
+	case n < 1<<14:
+		w.WriteByte(0x40 | byte(n>>8))
+		w.WriteByte(byte(n))
+	case n < 1<<32:
+		w.WriteByte(0x80)
+		_ = binary.Write(w, binary.BigEndian, uint32(n))
+	default:
+		w.WriteByte(0x81)
+		_ = binary.Write(w, binary.BigEndian, n)
+	}
+	return w
+}
+
+func (w *rdbWriter) str(s string) *rdbWriter {
+	w.len(uint64(len(s)))
+	w.WriteString(s)
+	return w
+}
+
+func (w *rdbWriter) le(v any) *rdbWriter { _ = binary.Write(w, binary.LittleEndian, v); return w }
+
+func listpack(entries ...string) string {
+	var body bytes.Buffer
+	for _, e := range entries {
+		if len(e) == 1 && e[0] >= '0' && e[0] <= '9' {
+			body.WriteByte(e[0] - '0') // 7-bit uint
+			body.WriteByte(1)
+			continue
+		}
+		body.WriteByte(0x80 | byte(len(e)))
+		body.WriteString(e)
+		body.WriteByte(byte(1 + len(e)))
+	}
+	var b bytes.Buffer
+	_ = binary.Write(&b, binary.LittleEndian, uint32(6+body.Len()+1))
+	_ = binary.Write(&b, binary.LittleEndian, uint16(len(entries)))
+	b.Write(body.Bytes())
+	b.WriteByte(0xff)
+	return b.String()
+}
+
+func ziplist(entries ...string) string {
+	var body bytes.Buffer
+	prev := 0
+	for _, e := range entries {
+		body.WriteByte(byte(prev))
+		body.WriteByte(byte(len(e)))
+		body.WriteString(e)
+		prev = 2 + len(e)
+	}
+	var b bytes.Buffer
+	_ = binary.Write(&b, binary.LittleEndian, uint32(10+body.Len()+1))
+	_ = binary.Write(&b, binary.LittleEndian, uint32(0))
+	_ = binary.Write(&b, binary.LittleEndian, uint16(len(entries)))
+	b.Write(body.Bytes())
+	b.WriteByte(0xff)
+	return b.String()
+}
+
+// saturate sets a listpack's or ziplist's header count to 65535, as Redis
+// does for long ones.
+func saturate(blob string, at int) string {
+	b := []byte(blob)
+	binary.LittleEndian.PutUint16(b[at:], 0xffff)
+	return string(b)
+}
+
+func moduleID(name string, encver uint64) uint64 {
+	const charset = "ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz0123456789-_"
+	var id uint64
+	for _, c := range name {
+		id = id<<6 | uint64(strings.IndexRune(charset, c))
+	}
+	return id<<10 | encver
+}
+
+func sampleRDB() []byte {
+	w := &rdbWriter{}
+	w.WriteString("REDIS0012")
+	w.op(opAux).str("redis-ver").str("7.4.0")
+	w.op(opAux).str("ctime").str("1700000000")
+	w.op(opModuleAux).len(moduleID("ReJSON-RL", 3)).len(moduleOpUint).len(2).len(moduleOpUint).len(5).len(moduleOpEOF)
+	w.op(opFunction2).str("#!lua name=lib\nredis.register_function('f', function() end)")
+	w.op(opSelectDB).len(0).op(opResizeDB).len(16).len(3)
+
+	w.op(typeString).str("s:raw").str("hello")
+	w.op(opExpireTimeMs).le(uint64(1700000060000))
+	w.op(typeString).str("s:int").op(0xc0, 42)
+	// "abc", then a 6-byte back reference 3 bytes back.
+	w.op(typeString).str("s:lzf").op(0xc3).len(6).len(9).op(2, 'a', 'b', 'c', 4<<5, 2)
+	w.op(opFreq, 7)
+	w.op(typeListQuicklist2).str("l:ql").len(2).len(2).str(listpack("a", "b", "c")).len(quicklistNodePlain).str("big")
+	w.op(opIdle).len(100)
+	w.op(typeSetIntset).str("set:int").str(string(binary.LittleEndian.AppendUint32(binary.LittleEndian.AppendUint32(nil, 4), 3)) + strings.Repeat("\x00", 12))
+	w.op(typeSetListpack).str("set:lp").str(listpack("x", "y"))
+	w.op(typeZsetListpack).str("z:lp").str(listpack("m1", "1", "m2", "2"))
+	w.op(typeZset2).str("z:sk").len(2).str("a").le(1.5).str("b").le(2.5)
+	w.op(typeHashListpack).str("h:lp").str(saturate(listpack("f1", "v1", "f2", "v2", "f3", "v3"), 4))
+	w.op(typeHash).str("h:ht").len(2).str("f1").str("v1").str("f2").str("v2")
+	w.op(typeHashListpackEx).str("h:ex").le(uint64(1700000100000)).str(listpack("f", "v", "9"))
+	w.op(typeHashMetadata).str("h:md").le(uint64(1700000100000)).len(2).len(0).str("f1").str("v1").len(5).str("f2").str("v2")
+	w.op(typeListZiplist).str("l:zl").str(saturate(ziplist("a", "b"), 8))
+	w.op(typeListQuicklist).str("l:q1").len(1).str(ziplist("a", "b", "c"))
+	w.op(typeStreamListpacks3).str("st").
+		len(1).str(strings.Repeat("\x00", 16)).str(listpack("x")).
+		len(5).len(1).len(5).                             // length, last ID
+		len(1).len(0).len(0).len(0).len(5).               // first ID, max deleted ID, entries added
+		len(1).str("g").len(1).len(3).len(3).             // group, last ID, entries read
+		len(1).op(bytes.Repeat([]byte{0}, 24)...).len(1). // PEL
+		len(1).str("c").le(uint64(1)).le(uint64(2)).      // consumer, seen and active time
+		len(1).op(bytes.Repeat([]byte{0}, 16)...)
+	w.op(typeModule2).str("json").len(moduleID("ReJSON-RL", 3)).len(moduleOpString).str("{}").len(moduleOpDouble).le(1.0).len(moduleOpEOF)
+
+	w.op(opSelectDB).len(1).op(opSlotInfo).len(1).len(2).len(0)
+	w.op(opExpireTime).le(uint32(1700000000))
+	w.op(typeZset).str("z:old").len(2).str("m").op(3).op([]byte("1.5")...).str("n").op(254)
+
+	w.op(opEOF).le(uint64(0))
+	return w.Bytes()
+}
+
+func TestDecoderReadsEveryEncoding(t *testing.T) {
+	data := sampleRDB()
+	d, err := NewDecoder(bytes.NewReader(data))
+	if err != nil {
+		t.Fatalf("NewDecoder: %v", err)
+	}
+	if d.Version != 12 {
+		t.Errorf("version = %d", d.Version)
+	}
+
+	want := []struct {
+		key, typ, enc string
+		elements      int64
+		db            int
+	}{
+		{"s:raw", "string", "raw", 5, 0},
+		{"s:int", "string", "raw", 2, 0},
+		{"s:lzf", "string", "raw", 9, 0},
+		{"l:ql", "list", "quicklist", 4, 0},
+		{"set:int", "set", "intset", 3, 0},
+		{"set:lp", "set", "listpack", 2, 0},
+		{"z:lp", "zset", "listpack", 2, 0},
+		{"z:sk", "zset", "skiplist", 2, 0},
+		{"h:lp", "hash", "listpack", 3, 0},
+		{"h:ht", "hash", "hashtable", 2, 0},
+		{"h:ex", "hash", "listpackex", 1, 0},
+		{"h:md", "hash", "hashtable", 2, 0},
+		{"l:zl", "list", "ziplist", 2, 0},
+		{"l:q1", "list", "quicklist", 3, 0},
+		{"st", "stream", "stream", 5, 0},
+		{"json", "ReJSON-RL", "module", 0, 0},
+		{"z:old", "zset", "skiplist", 2, 1},
+	}
+
+	var size int64
+	for i, w := range want {
+		e, err := d.Next()
+		if err != nil {
+			t.Fatalf("entry %d (%s): %v", i, w.key, err)
+		}
+		if e.Key != w.key || e.Type != w.typ || e.Encoding != w.enc || e.Elements != w.elements || e.DB != w.db {
+			t.Errorf("entry %d = %+v, want %+v", i, e, w)
+		}
+		if e.Size <= int64(len(e.Key)) {
+			t.Errorf("%s: size %d does not cover the value", e.Key, e.Size)
+		}
+		size += e.Size
+		switch e.Key {
+		case "s:int":
+			if e.Expiry != 1700000060000 {
+				t.Errorf("s:int expiry = %d", e.Expiry)
+			}
+		case "z:old":
+			if e.Expiry != 1700000000000 {
+				t.Errorf("z:old expiry = %d", e.Expiry)
+			}
+		default:
+			if e.Expiry != 0 {
+				t.Errorf("%s has expiry %d, the previous key's must not carry over", e.Key, e.Expiry)
+			}
+		}
+	}
+	if _, err := d.Next(); err != io.EOF {
+		t.Fatalf("after the last key: %v, want io.EOF", err)
+	}
+	if d.Offset() != int64(len(data)) {
+		t.Errorf("offset = %d, want the whole file of %d", d.Offset(), len(data))
+	}
+	if d.Aux["redis-ver"] != "7.4.0" || d.Aux["ctime"] != "1700000000" {
+		t.Errorf("aux = %v", d.Aux)
+	}
+	if size >= int64(len(data)) {
+		t.Errorf("key sizes sum to %d, more than the file", size)
+	}
+}
+
+func TestDecoderRejectsBadInput(t *testing.T) {
+	data := sampleRDB()
+	cases := map[string][]byte{
+		"magic":     []byte("RIDES0011"),
+		"version":   []byte("REDIS0013"),
+		"truncated": data[:len(data)/2],
+	}
+	for name, in := range cases {
+		t.Run(name, func(t *testing.T) {
+			d, err := NewDecoder(bytes.NewReader(in))
+			for err == nil {
+				_, err = d.Next()
+			}
+			if err == io.EOF {
+				t.Fatal("bad input read as a complete file")
+			}
+			if name == "truncated" && !errors.Is(err, io.ErrUnexpectedEOF) {
+				t.Errorf("err = %v, want io.ErrUnexpectedEOF", err)
+			}
+		})
+	}
+}
+
+func TestLzfDecompress(t *testing.T) {
+	// Literal "ab", then back references with the long-run length byte.
+	in := []byte{1, 'a', 'b', 7 << 5, 3, 1}
+	out, err := lzfDecompress(in, 2+7+3+2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(out) != strings.Repeat("ab", 7) {
+		t.Errorf("out = %q", out)
+	}
+	if _, err := lzfDecompress([]byte{0, 'a', 1<<5 | 1, 5}, 3); err == nil {
+		t.Error("a reference before the start must fail")
+	}
+}
@@ -0,0 +1,246 @@
+package rdb
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+)
+
+var errCorrupt = errors.New("corrupt compact encoding")
+
+// lzfDecompress expands liblzf output to exactly n bytes.
+func lzfDecompress(in []byte, n int) ([]byte, error) {
+	if n < 0 || n > maxBlob {
+		return nil, fmt.Errorf("bad length %d", n)
+	}
+	out := make([]byte, 0, n)
+	for i := 0; i < len(in); {
+		ctrl := int(in[i])
+		i++
+		if ctrl < 1<<5 {
+			// Literal run of ctrl+1 bytes.
+			run := ctrl + 1
+			if i+run > len(in) {
+				return nil, errors.New("lzf: literal past the input")
+			}
+			out = append(out, in[i:i+run]...)
+			i += run
+			continue
+		}
+		// Back reference of (ctrl>>5)+2 bytes, longer ones with an extra
+		// length byte.
+		run := ctrl >> 5
+		if run == 7 {
+			if i >= len(in) {
+				return nil, errors.New("lzf: truncated back reference")
+			}
+			run += int(in[i])
+			i++
+		}
+		if i >= len(in) {
+			return nil, errors.New("lzf: truncated back reference")
+		}
+		ref := len(out) - (ctrl&0x1f)<<8 - int(in[i]) - 1
+		i++
+		if ref < 0 {
+			return nil, errors.New("lzf: back reference before the start")
+		}
+		for j := 0; j < run+2; j++ {
+			out = append(out, out[ref+j])
+		}
+	}
+	if len(out) != n {
+		return nil, fmt.Errorf("lzf: expanded to %d bytes, want %d", len(out), n)
+	}
+	return out, nil
+}
+
+// ziplistLen counts the entries of a ziplist. The header count saturates
+// at 65535, past which the entries are walked.
+func ziplistLen(b []byte) (int64, error) {
+	if len(b) < 11 {
+		return 0, errCorrupt
+	}
+	if n := binary.LittleEndian.Uint16(b[8:10]); n < 0xffff {
+		return int64(n), nil
+	}
+	var n int64
+	for i := 10; ; n++ {
+		if i >= len(b) {
+			return 0, errCorrupt
+		}
+		if b[i] == 0xff {
+			return n, nil
+		}
+		// Previous entry length: one byte, or 0xfe and four more.
+		if b[i] < 0xfe {
+			i++
+		} else {
+			i += 5
+		}
+		if i >= len(b) {
+			return 0, errCorrupt
+		}
+		size, err := ziplistEntrySize(b[i:])
+		if err != nil {
+			return 0, err
+		}
+		i += size
+	}
+}
+
+// ziplistEntrySize is the length of an entry's encoding and content.
+func ziplistEntrySize(b []byte) (int, error) {
+	e := b[0]
+	switch e >> 6 {
+	case 0:
+		return 1 + int(e&0x3f), nil
+	case 1:
+		if len(b) < 2 {
+			return 0, errCorrupt
+		}
+		return 2 + int(e&0x3f)<<8 + int(b[1]), nil
+	case 2:
+		if len(b) < 5 {
+			return 0, errCorrupt
+		}
+		return 5 + int(binary.BigEndian.Uint32(b[1:5])), nil
+	}
+	switch e {
+	case 0xc0:
+		return 1 + 2, nil
+	case 0xd0:
+		return 1 + 4, nil
+	case 0xe0:
+		return 1 + 8, nil
+	case 0xf0:
+		return 1 + 3, nil
+	case 0xfe:
+		return 1 + 1, nil
+	}
+	if e > 0xf0 && e < 0xfe {
+		return 1, nil // 4-bit immediate
+	}
+	return 0, errCorrupt
+}
+
+// listpackLen counts the entries of a listpack. Like the ziplist, its
+// header count saturates at 65535.
+func listpackLen(b []byte) (int64, error) {
+	if len(b) < 7 {
+		return 0, errCorrupt
+	}
+	if n := binary.LittleEndian.Uint16(b[4:6]); n < 0xffff {
+		return int64(n), nil
+	}
+	var n int64
+	for i := 6; ; n++ {
+		if i >= len(b) {
+			return 0, errCorrupt
+		}
+		if b[i] == 0xff {
+			return n, nil
+		}
+		size, err := listpackEntrySize(b[i:])
+		if err != nil {
+			return 0, err
+		}
+		i += size + listpackBacklenSize(size)
+	}
+}
+
+// listpackEntrySize is the length of an entry's encoding and content,
+// without the trailing back-length.
+func listpackEntrySize(b []byte) (int, error) {
+	e := b[0]
+	switch {
+	case e&0x80 == 0: // 7-bit uint
+		return 1, nil
+	case e&0xc0 == 0x80: // 6-bit string length
+		return 1 + int(e&0x3f), nil
+	case e&0xe0 == 0xc0: // 13-bit int
+		return 2, nil
+	case e&0xf0 == 0xe0: // 12-bit string length
+		if len(b) < 2 {
+			return 0, errCorrupt
+		}
+		return 2 + int(e&0x0f)<<8 + int(b[1]), nil
+	}
+	switch e {
+	case 0xf0: // 32-bit string length
+		if len(b) < 5 {
+			return 0, errCorrupt
+		}
+		return 5 + int(binary.LittleEndian.Uint32(b[1:5])), nil
+	case 0xf1:
+		return 1 + 2, nil
+	case 0xf2:
+		return 1 + 3, nil
+	case 0xf3:
+		return 1 + 4, nil
+	case 0xf4:
+		return 1 + 8, nil
+	}
+	return 0, errCorrupt
+}
+
+// listpackBacklenSize is how many 7-bit groups encode size.
+func listpackBacklenSize(size int) int {
+	switch {
+	case size < 1<<7:
+		return 1
+	case size < 1<<14:
+		return 2
+	case size < 1<<21:
+		return 3
+	case size < 1<<28:
+		return 4
+	}
+	return 5
+}
+
+// intsetLen reads the member count from an intset header.
+func intsetLen(b []byte) (int64, error) {
+	if len(b) < 8 {
+		return 0, errCorrupt
+	}
+	return int64(binary.LittleEndian.Uint32(b[4:8])), nil
+}
+
+// zipmapLen counts the key/value strings of a zipmap, the hash encoding
+// of RDB files older than Redis 2.6.
+func zipmapLen(b []byte) (int64, error) {
+	if len(b) < 2 {
+		return 0, errCorrupt
+	}
+	if b[0] < 254 {
+		return 2 * int64(b[0]), nil
+	}
+	var n int64
+	for i := 1; ; {
+		if i >= len(b) {
+			return 0, errCorrupt
+		}
+		if b[i] == 0xff {
+			return n, nil
+		}
+		size := int(b[i])
+		i++
+		if size == 254 {
+			if i+4 > len(b) {
+				return 0, errCorrupt
+			}
+			size = int(binary.LittleEndian.Uint32(b[i : i+4]))
+			i += 4
+		}
+		if n%2 == 1 {
+			// Values carry a free-space byte and that much padding.
+			if i >= len(b) {
+				return 0, errCorrupt
+			}
+			size += 1 + int(b[i])
+		}
+		i += size
+		n++
+	}
+}
@@ -0,0 +1,27 @@
+package util
+
+import "testing"
+
+func TestHashSlot(t *testing.T) {
+	cases := map[string]int{
+		"foo":                  12182,
+		"bar":                  5061,
+		"hello":                866,
+		"user1000":             3443,
+		"{user1000}.following": 3443,
+		"{user1000}.followers": 3443,
+	}
+	for key, want := range cases {
+		if got := HashSlot(key); got != want {
+			t.Errorf("HashSlot(%q) = %d, want %d", key, got, want)
+		}
+	}
+
+	// An empty tag hashes the whole key, not the empty string.
+	if HashSlot("{}foo") == HashSlot("") {
+		t.Error("empty hash tag must hash the whole key")
+	}
+	if HashSlot("foo{}{bar}") == HashSlot("bar") {
+		t.Error("only the first {...} counts, and an empty one disables tagging")
+	}
+}
@@ -1,6 +1,8 @@
 package util
 
-import "unicode"
+import (
+	"unicode"
+)
 
 func EncodeRedisKey(key string) any {
 	if ContainsBinary(key) {
@@ -21,13 +21,21 @@ export const client = {
   keysMetadata: (params: T.ClientKeysMetadataReq) => scorix.invoke<T.ClientKeysMetadataRes>("client:keys-metadata", params),
   keysDeleteByPrefix: (params: T.ClientKeysDeleteByPrefixReq) => scorix.serverStream<T.ClientKeysDeleteProgressEvent>("client:keys-delete-by-prefix", params),
   keysScanByPrefix: (params: T.ClientKeysDeleteByPrefixReq) => scorix.invoke<T.ClientKeysScanByPrefixRes>("client:keys-scan-by-prefix", params),
+  keysTree: (params: T.ClientKeysTreeReq) => scorix.serverStream<T.ClientKeysTreeEvent>("client:keys-tree", params),
+  keysReport: (params: T.ClientKeysReportReq) => scorix.serverStream<T.ClientKeysReportEvent>("client:keys-report", params),
+  keysHot: (params: T.ClientKeysHotReq) => scorix.serverStream<T.ClientKeysHotEvent>("client:keys-hot", params),
+  keysTtl: (params: T.ClientKeysTtlReq) => scorix.serverStream<T.ClientKeysTtlEvent>("client:keys-ttl", params),
+  keysExport: (params: T.ClientKeysExportReq) => scorix.serverStream<T.ClientKeysExportProgressEvent>("client:keys-export", params),
+  keysImport: (params: T.ClientKeysImportReq) => scorix.serverStream<T.ClientKeysImportProgressEvent>("client:keys-import", params),
   keysSearch: (params: T.ClientKeysSearchReq) => scorix.serverStream<T.ClientKeysSearchEvent>("client:keys-search", params),
   searchKeys: (params: T.ClientSearchKeysReq) => scorix.invoke<T.ClientSearchKeysRes>("client:search-keys", params),
   setReadOnly: (params: T.ClientSetReadOnlyReq) => scorix.invoke<T.Empty>("client:set-read-only", params),
+  listActive: (params: T.Empty) => scorix.invoke<T.ClientListActiveRes>("client:list-active", params),
 };
 
 export const conn = {
-  test: (params: T.ConnectionReq) => scorix.invoke<T.Empty>("conn:test", params),
+  test: (params: T.ConnectionReq) => scorix.invoke<T.ConnTestRes>("conn:test", params),
+  mapPreview: (params: T.ConnectionReq) => scorix.invoke<T.AddrMapPreviewRes>("conn:map-preview", params),
 };
 
 export const preset = {
@@ -68,19 +76,21 @@ export const console = {
 
 export const proxy = {
   list: (params: T.Empty) => scorix.invoke<T.ProxyListRes>("proxy:list", params),
+  test: (params: T.ProxyTestReq) => scorix.invoke<T.ProxyTestRes>("proxy:test", params),
   upsert: (params: T.ProxyReq) => scorix.invoke<T.UpsertRes>("proxy:upsert", params),
   delete: (params: T.IdReq) => scorix.invoke<T.Empty>("proxy:delete", params),
 };
 
 export const ssh = {
   list: (params: T.Empty) => scorix.invoke<T.SshListRes>("ssh:list", params),
-  test: (params: T.SshReq) => scorix.invoke<T.Empty>("ssh:test", params),
+  test: (params: T.SshReq) => scorix.invoke<T.SshTestRes>("ssh:test", params),
   upsert: (params: T.SshReq) => scorix.invoke<T.UpsertRes>("ssh:upsert", params),
   delete: (params: T.IdReq) => scorix.invoke<T.Empty>("ssh:delete", params),
 };
 
 export const tls = {
   list: (params: T.Empty) => scorix.invoke<T.TlsListRes>("tls:list", params),
+  test: (params: T.TlsTestReq) => scorix.invoke<T.TlsTestRes>("tls:test", params),
   upsert: (params: T.TlsReq) => scorix.invoke<T.UpsertRes>("tls:upsert", params),
   delete: (params: T.IdReq) => scorix.invoke<T.Empty>("tls:delete", params),
 };
@@ -89,6 +99,12 @@ export const connection = {
   list: (params: T.Empty) => scorix.invoke<T.ConnectionListRes>("connection:list", params),
   upsert: (params: T.ConnectionReq) => scorix.invoke<T.UpsertRes>("connection:upsert", params),
   delete: (params: T.IdReq) => scorix.invoke<T.Empty>("connection:delete", params),
+  parseUrl: (params: T.ConnectionParseUrlReq) => scorix.invoke<T.ConnectionReq>("connection:parse-url", params),
+  exportUrl: (params: T.ConnectionExportUrlReq) => scorix.invoke<T.ConnectionExportUrlRes>("connection:export-url", params),
+  importExternalPreview: (params: T.ConnectionImportExternalReq) => scorix.invoke<T.ConnectionImportExternalRes>("connection:import-external-preview", params),
+  importExternal: (params: T.ConnectionImportExternalReq) => scorix.invoke<T.ConnectionImportExternalRes>("connection:import-external", params),
+  export: (params: T.ConnectionExportReq) => scorix.invoke<T.ConnectionExportRes>("connection:export", params),
+  import: (params: T.ConnectionImportReq) => scorix.invoke<T.ConnectionImportRes>("connection:import", params),
 };
 
 export const system = {
@@ -107,3 +123,16 @@ export const setting = {
   get: (params: T.SettingGetReq) => scorix.invoke<T.SettingGetRes>("setting:get", params),
 };
 
+export const cluster = {
+  topology: (params: T.ClusterReq) => scorix.invoke<T.ClusterTopologyRes>("cluster:topology", params),
+};
+
+export const sentinel = {
+  topology: (params: T.SentinelReq) => scorix.invoke<T.SentinelTopologyRes>("sentinel:topology", params),
+  failover: (params: T.SentinelFailoverReq) => scorix.invoke<T.Empty>("sentinel:failover", params),
+  events: (params: T.SentinelReq) => scorix.serverStream<T.SentinelEvent>("sentinel:events", params),
+};
+
+export const rdb = {
+  analyze: (params: T.RdbAnalyzeReq) => scorix.serverStream<T.RdbAnalyzeEvent>("rdb:analyze", params),
+};
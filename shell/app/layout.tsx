@@ -10,6 +10,7 @@ import { Loading } from "@/components/app/loading"
 import { ThemeProvider } from "next-themes"
 import { ConfirmProvider } from "@tradalab/lyra/blocks"
 import { ConnectionStatus } from "@/components/app/connection-status"
+import { ClientStateToaster } from "@/components/app/client-state-toaster"
 import { TooltipProvider } from "@tradalab/lyra/ui"
 
 export const metadata: Metadata = {
@@ -40,6 +41,7 @@ export default function RootLayout({ children }: Readonly<{ children: React.Reac
                     <Toaster />
                     <Loading />
                     <ConnectionStatus />
+                    <ClientStateToaster />
                   </SidebarInset>
                 </SidebarProvider>
               </TooltipProvider>
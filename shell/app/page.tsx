@@ -10,6 +10,12 @@ import { ConnectionDetailTabSlowQuery } from "@/components/app/connection-detail
 import { ConnectionDetailTabPubSub } from "@/components/app/connection-detail/connection-detail-tab-pubsub"
 import { ConnectionDetailTabKeyList } from "@/components/app/connection-detail/connection-detail-tab-key-list"
 import { ConnectionDetailTabMonitor } from "@/components/app/connection-detail/connection-detail-tab-monitor"
+import { ConnectionDetailTabCluster } from "@/components/app/connection-detail/connection-detail-tab-cluster"
+import { ConnectionDetailTabSentinel } from "@/components/app/connection-detail/connection-detail-tab-sentinel"
+import { ConnectionDetailTabMemoryReport } from "@/components/app/connection-detail/connection-detail-tab-memory-report"
+import { ConnectionDetailTabHotKeys } from "@/components/app/connection-detail/connection-detail-tab-hot-keys"
+import { ConnectionDetailTabTtlReport } from "@/components/app/connection-detail/connection-detail-tab-ttl-report"
+import { ConnectionDetailTabRdbAnalyzer } from "@/components/app/connection-detail/connection-detail-tab-rdb-analyzer"
 
 export default function Page() {
   const { selectedDb } = useAppContext()
@@ -34,6 +40,12 @@ export default function Page() {
                 {tab.type === "slow-query" && <ConnectionDetailTabSlowQuery connectionId={tab.connectionId} databaseIdx={tab.databaseIdx} />}
                 {tab.type === "pubsub" && <ConnectionDetailTabPubSub connectionId={tab.connectionId} databaseIdx={tab.databaseIdx} />}
                 {tab.type === "monitor" && <ConnectionDetailTabMonitor connectionId={tab.connectionId} databaseIdx={tab.databaseIdx} />}
+                {tab.type === "sentinel" && <ConnectionDetailTabSentinel connectionId={tab.connectionId} databaseIdx={tab.databaseIdx} />}
+                {tab.type === "cluster" && <ConnectionDetailTabCluster connectionId={tab.connectionId} databaseIdx={tab.databaseIdx} />}
+                {tab.type === "memory-report" && <ConnectionDetailTabMemoryReport connectionId={tab.connectionId} databaseIdx={tab.databaseIdx} />}
+                {tab.type === "hot-keys" && <ConnectionDetailTabHotKeys connectionId={tab.connectionId} databaseIdx={tab.databaseIdx} />}
+                {tab.type === "ttl-report" && <ConnectionDetailTabTtlReport connectionId={tab.connectionId} databaseIdx={tab.databaseIdx} />}
+                {tab.type === "rdb-analyzer" && <ConnectionDetailTabRdbAnalyzer />}
                 {tab.type === "key-list" && <ConnectionDetailTabKeyList connectionId={tab.connectionId} databaseIdx={tab.databaseIdx} />}
               </div>
             )
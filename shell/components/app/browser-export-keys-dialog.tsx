@@ -0,0 +1,139 @@
+"use client"
+
+import { useEffect, useState } from "react"
+import { useTranslation } from "react-i18next"
+import { CheckIcon, DownloadIcon, SquareIcon } from "lucide-react"
+
+import { Badge, Button, Dialog, DialogContent, DialogFooter, DialogHeader, DialogTitle, Input, Label } from "@tradalab/lyra/ui"
+import { Select, SelectContent, SelectGroup, SelectItem, SelectTrigger, SelectValue } from "@tradalab/lyra/ui"
+
+import type { KeyFilter } from "@/types"
+import { formatFileSize } from "@/lib/utils"
+import { useKeysExport } from "@/hooks/api/keys-export"
+
+const EXTENSIONS: Record<string, string> = { jsonl: "jsonl", csv: "csv", resp: "resp" }
+
+interface BrowserExportKeysDialogProps {
+  open: boolean
+  onOpenChange: (open: boolean) => void
+  connectionId: string
+  databaseIdx: number
+  /** Exported as they are when set; otherwise the filters pick the keys. */
+  keys: string[]
+  filters: KeyFilter[]
+  matchAll: boolean
+  keyType: string
+}
+
+export function BrowserExportKeysDialog({ open, onOpenChange, connectionId, databaseIdx, keys, filters, matchAll, keyType }: BrowserExportKeysDialogProps) {
+  const { t } = useTranslation()
+  const { progress, isRunning, error, start, cancel } = useKeysExport(connectionId, databaseIdx)
+  const [format, setFormat] = useState("jsonl")
+  const [path, setPath] = useState("")
+
+  useEffect(() => {
+    if (!open) cancel()
+  }, [open, cancel])
+
+  const changeFormat = (f: string) => {
+    setFormat(f)
+    setPath(p => p.replace(/\.(jsonl|csv|resp)$/, "." + EXTENSIONS[f]))
+  }
+
+  const done = progress?.status === "done"
+  const total = progress?.total ?? 0
+  const handled = (progress?.exported ?? 0) + (progress?.skipped ?? 0)
+
+  return (
+    <Dialog open={open} onOpenChange={onOpenChange}>
+      <DialogContent className="sm:max-w-[520px]" onPointerDownOutside={e => e.preventDefault()}>
+        <DialogHeader>
+          <DialogTitle className="flex items-center gap-2">
+            <DownloadIcon className="h-5 w-5" />
+            {t("export_keys")}
+          </DialogTitle>
+        </DialogHeader>
+
+        <div className="grid gap-3 py-2">
+          <p className="text-sm text-muted-foreground">{keys.length > 0 ? t("export_selected", { count: keys.length }) : t("export_filtered")}</p>
+          <div className="grid gap-1">
+            <Label className="text-xs">{t("export_format")}</Label>
+            <Select value={format} onValueChange={changeFormat} disabled={isRunning}>
+              <SelectTrigger className="h-8">
+                <SelectValue />
+              </SelectTrigger>
+              <SelectContent>
+                <SelectGroup>
+                  <SelectItem value="jsonl">JSON Lines</SelectItem>
+                  <SelectItem value="csv">CSV</SelectItem>
+                  <SelectItem value="resp">RESP (redis-cli --pipe)</SelectItem>
+                </SelectGroup>
+              </SelectContent>
+            </Select>
+            <p className="text-[11px] text-muted-foreground">{t(`export_format_${format}`)}</p>
+          </div>
+          <div className="grid gap-1">
+            <Label className="text-xs">{t("export_path")}</Label>
+            <Input
+              className="h-8 font-mono text-xs"
+              placeholder={`/tmp/keys.${EXTENSIONS[format]}`}
+              value={path}
+              disabled={isRunning}
+              onChange={e => setPath(e.target.value)}
+            />
+          </div>
+
+          {progress && (
+            <div className="grid gap-1.5 text-xs text-muted-foreground font-mono">
+              <div className="h-1.5 bg-muted/30 rounded-sm">
+                <div className="h-1.5 bg-primary/60 rounded-sm" style={{ width: `${done ? 100 : total > 0 ? (handled / total) * 100 : 0}%` }} />
+              </div>
+              <div className="flex items-center gap-3">
+                <span>{t("export_progress", { exported: progress.exported, total })}</span>
+                <span>{formatFileSize(progress.bytes)}</span>
+                {progress.skipped > 0 && <span>{t("export_skipped", { count: progress.skipped })}</span>}
+                {progress.truncated && <Badge variant="destructive">{t("report_truncated")}</Badge>}
+              </div>
+              {!done && progress.key && <div className="truncate">{progress.key}</div>}
+              {done && (
+                <div className="flex items-center gap-1 text-foreground">
+                  <CheckIcon className="h-3 w-3 text-primary" />
+                  <span className="truncate">{progress.path}</span>
+                </div>
+              )}
+            </div>
+          )}
+          {error && <div className="text-xs text-destructive">{error}</div>}
+        </div>
+
+        <DialogFooter>
+          <Button variant="outline" onClick={() => onOpenChange(false)}>
+            {done ? t("close") : t("cancel")}
+          </Button>
+          {isRunning ? (
+            <Button variant="outline" onClick={cancel}>
+              <SquareIcon className="h-4 w-4" />
+              {t("export_stop")}
+            </Button>
+          ) : (
+            <Button
+              disabled={!path || !connectionId}
+              onClick={() =>
+                start(path, {
+                  format,
+                  keys,
+                  filters: keys.length > 0 ? [] : filters,
+                  match_all: matchAll,
+                  key_type: keys.length > 0 ? "" : keyType,
+                })
+              }
+            >
+              <DownloadIcon className="h-4 w-4" />
+              {t("export")}
+            </Button>
+          )}
+        </DialogFooter>
+      </DialogContent>
+    </Dialog>
+  )
+}
@@ -0,0 +1,230 @@
+"use client"
+
+import { useEffect, useRef, useState } from "react"
+import { useTranslation } from "react-i18next"
+import { CheckIcon, SquareIcon, UploadIcon } from "lucide-react"
+
+import { Badge, Button, Dialog, DialogContent, DialogFooter, DialogHeader, DialogTitle, Input, Label } from "@tradalab/lyra/ui"
+import { Select, SelectContent, SelectGroup, SelectItem, SelectTrigger, SelectValue } from "@tradalab/lyra/ui"
+
+import { formatFileSize } from "@/lib/utils"
+import { useKeysImport } from "@/hooks/api/keys-import"
+
+interface BrowserImportKeysDialogProps {
+  open: boolean
+  onOpenChange: (open: boolean) => void
+  connectionId: string
+  databaseIdx: number
+  /** Called once an import finishes so the key list can be reloaded. */
+  onDone?: () => void
+}
+
+export function BrowserImportKeysDialog({ open, onOpenChange, connectionId, databaseIdx, onDone }: BrowserImportKeysDialogProps) {
+  const { t } = useTranslation()
+  const { progress, isRunning, error, start, cancel } = useKeysImport(connectionId, databaseIdx)
+  const [format, setFormat] = useState("jsonl")
+  const [conflict, setConflict] = useState("skip")
+  const [path, setPath] = useState("")
+  const [delimiter, setDelimiter] = useState("")
+  const [keyTemplate, setKeyTemplate] = useState("")
+  const [keyType, setKeyType] = useState("hash")
+  const [fields, setFields] = useState("")
+  const [valueColumn, setValueColumn] = useState("")
+
+  useEffect(() => {
+    if (!open) cancel()
+  }, [open, cancel])
+
+  const done = progress?.status === "done"
+  const onDoneRef = useRef(onDone)
+  onDoneRef.current = onDone
+  useEffect(() => {
+    if (done) onDoneRef.current?.()
+  }, [done])
+
+  const size = progress?.size ?? 0
+  const mapRows = format === "csv" && keyTemplate !== ""
+
+  return (
+    <Dialog open={open} onOpenChange={onOpenChange}>
+      <DialogContent className="sm:max-w-[520px]" onPointerDownOutside={e => e.preventDefault()}>
+        <DialogHeader>
+          <DialogTitle className="flex items-center gap-2">
+            <UploadIcon className="h-5 w-5" />
+            {t("import_keys")}
+          </DialogTitle>
+        </DialogHeader>
+
+        <div className="grid gap-3 py-2">
+          <div className="grid gap-1">
+            <Label className="text-xs">{t("import_path")}</Label>
+            <Input
+              className="h-8 font-mono text-xs"
+              placeholder={`/tmp/keys.${format}`}
+              value={path}
+              disabled={isRunning}
+              onChange={e => setPath(e.target.value)}
+            />
+          </div>
+          <div className="grid grid-cols-2 gap-2">
+            <div className="grid gap-1">
+              <Label className="text-xs">{t("export_format")}</Label>
+              <Select value={format} onValueChange={setFormat} disabled={isRunning}>
+                <SelectTrigger className="h-8">
+                  <SelectValue />
+                </SelectTrigger>
+                <SelectContent>
+                  <SelectGroup>
+                    <SelectItem value="jsonl">JSON Lines</SelectItem>
+                    <SelectItem value="csv">CSV</SelectItem>
+                    <SelectItem value="resp">RESP / redis-cli</SelectItem>
+                  </SelectGroup>
+                </SelectContent>
+              </Select>
+            </div>
+            <div className="grid gap-1">
+              <Label className="text-xs">{t("import_conflict")}</Label>
+              <Select value={conflict} onValueChange={setConflict} disabled={isRunning}>
+                <SelectTrigger className="h-8">
+                  <SelectValue />
+                </SelectTrigger>
+                <SelectContent>
+                  <SelectGroup>
+                    <SelectItem value="skip">{t("import_conflict_skip")}</SelectItem>
+                    <SelectItem value="overwrite">{t("import_conflict_overwrite")}</SelectItem>
+                    <SelectItem value="fail">{t("import_conflict_fail")}</SelectItem>
+                  </SelectGroup>
+                </SelectContent>
+              </Select>
+            </div>
+          </div>
+          <p className="text-[11px] text-muted-foreground">{t(`import_format_${format}`)}</p>
+
+          {format === "csv" && (
+            <div className="grid gap-2 rounded-md border p-2">
+              <div className="grid grid-cols-[1fr_80px] gap-2">
+                <div className="grid gap-1">
+                  <Label className="text-xs">{t("import_key_template")}</Label>
+                  <Input
+                    className="h-8 font-mono text-xs"
+                    placeholder="user:{id}"
+                    value={keyTemplate}
+                    disabled={isRunning}
+                    onChange={e => setKeyTemplate(e.target.value)}
+                  />
+                </div>
+                <div className="grid gap-1">
+                  <Label className="text-xs">{t("import_delimiter")}</Label>
+                  <Input
+                    className="h-8 font-mono text-xs"
+                    placeholder=","
+                    maxLength={1}
+                    value={delimiter}
+                    disabled={isRunning}
+                    onChange={e => setDelimiter(e.target.value)}
+                  />
+                </div>
+              </div>
+              {mapRows && (
+                <div className="grid grid-cols-[100px_1fr] gap-2">
+                  <Select value={keyType} onValueChange={setKeyType} disabled={isRunning}>
+                    <SelectTrigger className="h-8">
+                      <SelectValue />
+                    </SelectTrigger>
+                    <SelectContent>
+                      <SelectGroup>
+                        <SelectItem value="hash">hash</SelectItem>
+                        <SelectItem value="string">string</SelectItem>
+                      </SelectGroup>
+                    </SelectContent>
+                  </Select>
+                  {keyType === "hash" ? (
+                    <Input
+                      className="h-8 font-mono text-xs"
+                      placeholder={t("import_fields")}
+                      value={fields}
+                      disabled={isRunning}
+                      onChange={e => setFields(e.target.value)}
+                    />
+                  ) : (
+                    <Input
+                      className="h-8 font-mono text-xs"
+                      placeholder={t("import_value_column")}
+                      value={valueColumn}
+                      disabled={isRunning}
+                      onChange={e => setValueColumn(e.target.value)}
+                    />
+                  )}
+                </div>
+              )}
+              <p className="text-[11px] text-muted-foreground">{mapRows ? t("import_template_hint") : t("import_csv_layout")}</p>
+            </div>
+          )}
+
+          {progress && (
+            <div className="grid gap-1.5 text-xs text-muted-foreground font-mono">
+              <div className="h-1.5 bg-muted/30 rounded-sm">
+                <div className="h-1.5 bg-primary/60 rounded-sm" style={{ width: `${done ? 100 : size > 0 ? (progress.read / size) * 100 : 0}%` }} />
+              </div>
+              <div className="flex flex-wrap items-center gap-3">
+                <span>{t("import_progress", { imported: progress.imported, commands: progress.commands })}</span>
+                <span>
+                  {formatFileSize(progress.read)} / {formatFileSize(size)}
+                </span>
+                {progress.skipped > 0 && <span>{t("export_skipped", { count: progress.skipped })}</span>}
+                {progress.overwritten > 0 && <span>{t("import_overwritten", { count: progress.overwritten })}</span>}
+                {progress.errors > 0 && <Badge variant="destructive">{t("import_errors", { count: progress.errors })}</Badge>}
+              </div>
+              {(progress.error_samples?.length ?? 0) > 0 && (
+                <div className="max-h-24 overflow-auto rounded-sm bg-muted/30 p-1.5 text-[11px] text-destructive">
+                  {progress.error_samples?.map((e, i) => (
+                    <div key={i} className="truncate" title={e}>
+                      {e}
+                    </div>
+                  ))}
+                </div>
+              )}
+              {done && (
+                <div className="flex items-center gap-1 text-foreground">
+                  <CheckIcon className="h-3 w-3 text-primary" />
+                  <span className="truncate">{progress.path}</span>
+                </div>
+              )}
+            </div>
+          )}
+          {error && <div className="text-xs text-destructive">{error}</div>}
+        </div>
+
+        <DialogFooter>
+          <Button variant="outline" onClick={() => onOpenChange(false)}>
+            {done ? t("close") : t("cancel")}
+          </Button>
+          {isRunning ? (
+            <Button variant="outline" onClick={cancel}>
+              <SquareIcon className="h-4 w-4" />
+              {t("export_stop")}
+            </Button>
+          ) : (
+            <Button
+              disabled={!path || !connectionId}
+              onClick={() =>
+                start(path, {
+                  format,
+                  conflict,
+                  csv_delimiter: format === "csv" ? delimiter : "",
+                  key_template: mapRows ? keyTemplate : "",
+                  key_type: mapRows ? keyType : "",
+                  fields: mapRows && keyType === "hash" ? fields.split(",").map(f => f.trim()).filter(Boolean) : [],
+                  value_column: mapRows && keyType === "string" ? valueColumn : "",
+                })
+              }
+            >
+              <UploadIcon className="h-4 w-4" />
+              {t("import")}
+            </Button>
+          )}
+        </DialogFooter>
+      </DialogContent>
+    </Dialog>
+  )
+}
@@ -0,0 +1,30 @@
+"use client"
+
+import * as React from "react"
+import { useTranslation } from "react-i18next"
+import { toast } from "@tradalab/lyra/ui"
+import scorix from "@/lib/scorix"
+import type { ClientStateEvent } from "@/types"
+
+export function ClientStateToaster() {
+  const { t } = useTranslation()
+
+  React.useEffect(() => {
+    return scorix.on("client:state", (ev: ClientStateEvent) => {
+      const target = `${ev.connection_id} / db${ev.database_index}`
+      switch (ev.state) {
+        case "reconnecting":
+          toast.add({ title: t("client_reconnecting", { attempt: ev.attempt }), description: `${target}: ${ev.error}` })
+          break
+        case "failed":
+          toast.add({ title: t("client_reconnect_failed"), description: `${target}: ${ev.error}`, type: "error" })
+          break
+        case "connected":
+          toast.add({ title: t("client_reconnected"), description: target, type: "success" })
+          break
+      }
+    })
+  }, [t])
+
+  return null
+}
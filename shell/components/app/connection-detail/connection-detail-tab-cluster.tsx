@@ -0,0 +1,123 @@
+"use client"
+
+import { useTranslation } from "react-i18next"
+import { Boxes, RefreshCcwIcon } from "lucide-react"
+
+import { Badge, Button, Card, CardContent, Separator } from "@tradalab/lyra/ui"
+import { Table, TableBody, TableCell, TableHead, TableHeader, TableRow } from "@tradalab/lyra/ui"
+
+import { ClusterNode, ClusterSlotRange } from "@/types"
+import { formatFileSize } from "@/lib/utils"
+import { useClusterTopology } from "@/hooks/api/cluster.api"
+
+// A shard whose slot or key share is this far off the even split is flagged.
+const IMBALANCE = 0.2
+
+function slotText(slots?: ClusterSlotRange[]) {
+  return (slots ?? []).map(r => (r.start === r.end ? `${r.start}` : `${r.start}-${r.end}`)).join(", ")
+}
+
+function NodeRow({ node, role }: { node: ClusterNode; role: string }) {
+  const { t } = useTranslation()
+  const failed = node.flags?.some(f => f === "fail" || f === "fail?") || node.link_state === "disconnected" || (node.health && node.health !== "online")
+  return (
+    <TableRow className={failed ? "bg-destructive/10" : undefined}>
+      <TableCell>{role}</TableCell>
+      <TableCell className="font-mono text-xs">
+        {node.addr}
+        {node.dial_addr && node.dial_addr !== node.addr && <span className="text-muted-foreground"> → {node.dial_addr}</span>}
+        {node.hostname && <div className="text-muted-foreground">{node.hostname}</div>}
+      </TableCell>
+      <TableCell className="space-x-1">
+        {node.flags?.filter(f => f !== "myself").map(f => (
+          <Badge key={f} variant={f.startsWith("fail") ? "destructive" : "outline"}>
+            {f}
+          </Badge>
+        ))}
+      </TableCell>
+      <TableCell>
+        {node.link_state}
+        {node.health && <span className="text-muted-foreground"> / {node.health}</span>}
+      </TableCell>
+      <TableCell className="text-right font-mono text-xs">{node.config_epoch}</TableCell>
+      <TableCell className="text-right font-mono text-xs">{node.stats.keys}</TableCell>
+      <TableCell className="text-right font-mono text-xs">{formatFileSize(node.stats.used_memory)}</TableCell>
+      <TableCell className="text-right font-mono text-xs">{node.stats.ops_per_sec}</TableCell>
+      <TableCell className="text-xs text-destructive">{node.error && t("cluster_info_failed", { error: node.error })}</TableCell>
+    </TableRow>
+  )
+}
+
+export function ConnectionDetailTabCluster({ connectionId, databaseIdx }: { connectionId: string; databaseIdx: number }) {
+  const { t } = useTranslation()
+  const { data, isFetching, refetch, error } = useClusterTopology(connectionId, databaseIdx)
+
+  const shards = data?.shards ?? []
+  const masters = shards.filter(s => s.master?.id)
+  const totalKeys = masters.reduce((n, s) => n + s.keys, 0)
+  const evenSlots = masters.length ? 16384 / masters.length : 0
+  const evenKeys = masters.length ? totalKeys / masters.length : 0
+  const skewed = (v: number, even: number) => even > 0 && Math.abs(v - even) / even > IMBALANCE
+
+  return (
+    <Card className="w-full h-full border bg-background flex flex-col rounded-none border-none shadow-none p-0">
+      <CardContent className="p-0 flex flex-col w-full h-full min-h-0">
+        <div className="flex items-center justify-between px-4 h-11 border-b bg-muted/10 shrink-0">
+          <div className="text-xs text-muted-foreground font-mono flex items-center gap-2">
+            <Boxes className="h-4 w-4 text-primary" />
+            <span className="font-semibold text-foreground uppercase">{t("cluster")}</span>
+            <Separator orientation="vertical" className="h-4 mx-1" />
+            <span>{t("cluster_summary", { shards: masters.length, keys: totalKeys })}</span>
+            {data?.source && <Badge variant="outline">CLUSTER {data.source.toUpperCase()}</Badge>}
+          </div>
+          <Button variant="outline" size="sm" className="h-7 text-xs px-2" onClick={() => refetch()} disabled={isFetching}>
+            <RefreshCcwIcon className={isFetching ? "h-3 w-3 animate-spin" : "h-3 w-3"} />
+            <span className="hidden lg:inline ml-1">{t("refresh")}</span>
+          </Button>
+        </div>
+
+        <div className="flex-1 min-h-0 overflow-auto p-4 space-y-4">
+          {error && <div className="text-xs text-destructive">{String(error)}</div>}
+          {shards.map((s, i) => (
+            <div key={s.master?.id || `orphans-${i}`} className="border rounded">
+              <div className="flex items-center gap-2 px-3 py-2 border-b bg-muted/10 text-xs">
+                <span className="font-semibold text-sm">{s.master?.id ? t("cluster_shard", { index: i + 1 }) : t("cluster_orphans")}</span>
+                <span className="font-mono text-muted-foreground truncate" title={slotText(s.slots)}>
+                  {slotText(s.slots)}
+                </span>
+                {s.master?.id && (
+                  <>
+                    <Badge variant={skewed(s.slot_count, evenSlots) ? "destructive" : "outline"}>{t("cluster_slots", { count: s.slot_count })}</Badge>
+                    <Badge variant={skewed(s.keys, evenKeys) ? "destructive" : "outline"}>{t("cluster_keys", { count: s.keys })}</Badge>
+                    <Badge variant="outline">{formatFileSize(s.used_memory)}</Badge>
+                  </>
+                )}
+              </div>
+              <Table>
+                <TableHeader>
+                  <TableRow>
+                    <TableHead>{t("sentinel_role")}</TableHead>
+                    <TableHead>{t("address")}</TableHead>
+                    <TableHead>{t("sentinel_flags")}</TableHead>
+                    <TableHead>{t("sentinel_link")}</TableHead>
+                    <TableHead className="text-right">{t("sentinel_epoch")}</TableHead>
+                    <TableHead className="text-right">{t("keys")}</TableHead>
+                    <TableHead className="text-right">{t("memory")}</TableHead>
+                    <TableHead className="text-right">ops/s</TableHead>
+                    <TableHead />
+                  </TableRow>
+                </TableHeader>
+                <TableBody>
+                  {s.master?.id && <NodeRow node={s.master} role={t("cluster_master")} />}
+                  {(s.replicas ?? []).map(r => (
+                    <NodeRow key={r.id} node={r} role={t("sentinel_replica")} />
+                  ))}
+                </TableBody>
+              </Table>
+            </div>
+          ))}
+        </div>
+      </CardContent>
+    </Card>
+  )
+}
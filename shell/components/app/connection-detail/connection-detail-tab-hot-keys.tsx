@@ -0,0 +1,207 @@
+"use client"
+
+import { useState } from "react"
+import { useTranslation } from "react-i18next"
+import { AlertTriangleIcon, DownloadIcon, FlameIcon, PlayIcon, SquareIcon } from "lucide-react"
+
+import { Badge, Button, Card, CardContent, Input, Label, Separator } from "@tradalab/lyra/ui"
+import { Select, SelectContent, SelectGroup, SelectItem, SelectTrigger, SelectValue } from "@tradalab/lyra/ui"
+import { Table, TableBody, TableCell, TableHead, TableHeader, TableRow } from "@tradalab/lyra/ui"
+
+import { useKeysHot } from "@/hooks/api/keys-hot"
+import { download } from "@/components/app/memory-report/memory-report-view"
+
+export function ConnectionDetailTabHotKeys({ connectionId, databaseIdx }: { connectionId: string; databaseIdx: number }) {
+  const { t } = useTranslation()
+  const { report, isRunning, error, start, cancel } = useKeysHot(connectionId, databaseIdx)
+  const [mode, setMode] = useState("auto")
+  const [windowSec, setWindowSec] = useState(10)
+  const [separator, setSeparator] = useState(":")
+  const [depth, setDepth] = useState(1)
+  const [top, setTop] = useState(20)
+
+  const monitor = report ? report.mode === "monitor" : mode === "monitor"
+  const stamp = () => new Date().toISOString().slice(0, 19).replace(/[:T]/g, "-")
+
+  return (
+    <Card className="w-full h-full border bg-background flex flex-col rounded-none border-none shadow-none p-0">
+      <CardContent className="p-0 flex flex-col w-full h-full min-h-0">
+        <div className="flex items-center justify-between px-4 h-11 border-b bg-muted/10 shrink-0">
+          <div className="text-xs text-muted-foreground font-mono flex items-center gap-2">
+            <FlameIcon className="h-4 w-4 text-primary" />
+            <span className="font-semibold text-foreground uppercase">{t("hot_keys")}</span>
+            {report && (
+              <>
+                <Separator orientation="vertical" className="h-4 mx-1" />
+                <Badge variant="outline">{report.mode === "lfu" ? "OBJECT FREQ" : "MONITOR"}</Badge>
+                {report.policy && <Badge variant="outline">{report.policy}</Badge>}
+                <span>{t(monitor ? "hot_summary_monitor" : "hot_summary_lfu", { scanned: report.scanned, matched: report.matched })}</span>
+                {report.truncated && <Badge variant="destructive">{t("report_truncated")}</Badge>}
+              </>
+            )}
+          </div>
+          <Button
+            variant="outline"
+            size="sm"
+            className="h-7 text-xs px-2"
+            disabled={!report}
+            onClick={() => download(JSON.stringify(report, null, 2), `hot-keys-${stamp()}.json`, "application/json")}
+          >
+            <DownloadIcon className="h-3 w-3" />
+            JSON
+          </Button>
+        </div>
+
+        <div className="flex items-end gap-3 px-4 py-2 border-b shrink-0">
+          <div className="grid gap-1">
+            <Label className="text-xs">{t("hot_mode")}</Label>
+            <Select value={mode} onValueChange={setMode}>
+              <SelectTrigger className="h-7 w-40 text-xs">
+                <SelectValue />
+              </SelectTrigger>
+              <SelectContent>
+                <SelectGroup>
+                  <SelectItem value="auto">{t("hot_mode_auto")}</SelectItem>
+                  <SelectItem value="lfu">OBJECT FREQ (LFU)</SelectItem>
+                  <SelectItem value="monitor">MONITOR</SelectItem>
+                </SelectGroup>
+              </SelectContent>
+            </Select>
+          </div>
+          <div className="grid gap-1">
+            <Label className="text-xs">{t("hot_window")}</Label>
+            <Input className="h-7 w-20" type="number" min={1} max={60} value={windowSec} onChange={e => setWindowSec(Number(e.target.value))} />
+          </div>
+          <div className="grid gap-1">
+            <Label className="text-xs">{t("report_separator")}</Label>
+            <Input className="h-7 w-16" value={separator} onChange={e => setSeparator(e.target.value)} />
+          </div>
+          <div className="grid gap-1">
+            <Label className="text-xs">{t("report_depth")}</Label>
+            <Input className="h-7 w-16" type="number" min={1} value={depth} onChange={e => setDepth(Number(e.target.value))} />
+          </div>
+          <div className="grid gap-1">
+            <Label className="text-xs">{t("report_top")}</Label>
+            <Input className="h-7 w-20" type="number" min={1} value={top} onChange={e => setTop(Number(e.target.value))} />
+          </div>
+          {isRunning ? (
+            <Button size="sm" variant="outline" className="h-7" onClick={cancel}>
+              <SquareIcon className="h-3 w-3" />
+              {t("cancel")}
+            </Button>
+          ) : (
+            <Button
+              size="sm"
+              className="h-7"
+              onClick={() => start({ mode: mode === "auto" ? "" : mode, window_ms: windowSec * 1000, separator, prefix_depth: depth, top })}
+            >
+              <PlayIcon className="h-3 w-3" />
+              {t("report_run")}
+            </Button>
+          )}
+          {report && (
+            <span className="text-xs text-muted-foreground ml-auto">
+              {(report.elapsed_ms / 1000).toFixed(1)}s{report.window_ms > 0 && ` / ${report.window_ms / 1000}s`}
+            </span>
+          )}
+        </div>
+
+        {monitor && (
+          <div className="flex items-start gap-2 px-4 py-2 border-b shrink-0 text-xs text-destructive bg-destructive/5">
+            <AlertTriangleIcon className="h-4 w-4 shrink-0" />
+            <span>{t("hot_monitor_warning")}</span>
+          </div>
+        )}
+
+        <div className="flex-1 min-h-0 overflow-auto p-4 space-y-4">
+          {error && <div className="text-xs text-destructive">{error}</div>}
+          {!report && !isRunning && !error && <div className="text-xs text-muted-foreground">{t("hot_hint")}</div>}
+
+          {report && (
+            <div className="border rounded">
+              <div className="px-3 py-2 border-b bg-muted/10 text-xs font-semibold text-sm">{t("hot_keys")}</div>
+              <Table>
+                <TableHeader>
+                  <TableRow>
+                    <TableHead>{t("report_key")}</TableHead>
+                    <TableHead className="text-right">{monitor ? t("hot_hits") : t("hot_freq")}</TableHead>
+                    {monitor && <TableHead className="text-right">{t("hot_reads")}</TableHead>}
+                    {monitor && <TableHead className="text-right">{t("hot_writes")}</TableHead>}
+                  </TableRow>
+                </TableHeader>
+                <TableBody>
+                  {(report.keys ?? []).map(k => (
+                    <TableRow key={k.key}>
+                      <TableCell className="font-mono text-xs max-w-[420px] truncate" title={k.key}>
+                        {k.key}
+                      </TableCell>
+                      <TableCell className="text-right font-mono text-xs">{k.hits}</TableCell>
+                      {monitor && <TableCell className="text-right font-mono text-xs">{k.reads}</TableCell>}
+                      {monitor && <TableCell className="text-right font-mono text-xs">{k.writes}</TableCell>}
+                    </TableRow>
+                  ))}
+                </TableBody>
+              </Table>
+            </div>
+          )}
+
+          {(report?.prefixes ?? []).length > 0 && (
+            <div className="border rounded">
+              <div className="px-3 py-2 border-b bg-muted/10 text-xs font-semibold text-sm">{t("report_prefixes")}</div>
+              <Table>
+                <TableHeader>
+                  <TableRow>
+                    <TableHead>{t("report_prefix")}</TableHead>
+                    <TableHead className="text-right">{t("keys")}</TableHead>
+                    <TableHead className="text-right">{monitor ? t("hot_hits") : t("hot_freq")}</TableHead>
+                    {monitor && <TableHead className="text-right">{t("hot_reads")}</TableHead>}
+                    {monitor && <TableHead className="text-right">{t("hot_writes")}</TableHead>}
+                  </TableRow>
+                </TableHeader>
+                <TableBody>
+                  {report!.prefixes!.map(p => (
+                    <TableRow key={p.prefix}>
+                      <TableCell className="font-mono text-xs">
+                        {p.prefix === "" ? t("report_no_prefix") : p.prefix === "*" ? t("report_other_prefixes") : p.prefix}
+                      </TableCell>
+                      <TableCell className="text-right font-mono text-xs">{p.keys}</TableCell>
+                      <TableCell className="text-right font-mono text-xs">{p.hits}</TableCell>
+                      {monitor && <TableCell className="text-right font-mono text-xs">{p.reads}</TableCell>}
+                      {monitor && <TableCell className="text-right font-mono text-xs">{p.writes}</TableCell>}
+                    </TableRow>
+                  ))}
+                </TableBody>
+              </Table>
+            </div>
+          )}
+
+          {(report?.clients ?? []).length > 0 && (
+            <div className="border rounded">
+              <div className="px-3 py-2 border-b bg-muted/10 text-xs font-semibold text-sm">{t("hot_clients")}</div>
+              <Table>
+                <TableHeader>
+                  <TableRow>
+                    <TableHead>{t("hot_client")}</TableHead>
+                    <TableHead className="text-right">{t("hot_commands")}</TableHead>
+                    <TableHead className="text-right">{t("hot_reads")}</TableHead>
+                    <TableHead className="text-right">{t("hot_writes")}</TableHead>
+                  </TableRow>
+                </TableHeader>
+                <TableBody>
+                  {report!.clients!.map(c => (
+                    <TableRow key={c.addr}>
+                      <TableCell className="font-mono text-xs">{c.addr}</TableCell>
+                      <TableCell className="text-right font-mono text-xs">{c.commands}</TableCell>
+                      <TableCell className="text-right font-mono text-xs">{c.reads}</TableCell>
+                      <TableCell className="text-right font-mono text-xs">{c.writes}</TableCell>
+                    </TableRow>
+                  ))}
+                </TableBody>
+              </Table>
+            </div>
+          )}
+        </div>
+      </CardContent>
+    </Card>
+  )
+}
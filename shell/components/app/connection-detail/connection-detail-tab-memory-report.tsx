@@ -0,0 +1,102 @@
+"use client"
+
+import { useState } from "react"
+import { useTranslation } from "react-i18next"
+import { DownloadIcon, HardDriveIcon, PlayIcon, SquareIcon } from "lucide-react"
+
+import { Badge, Button, Card, CardContent, Input, Label, Separator } from "@tradalab/lyra/ui"
+
+import { formatFileSize } from "@/lib/utils"
+import { useKeysReport } from "@/hooks/api/keys-report"
+import { MemoryReportView, download, toCsv } from "@/components/app/memory-report/memory-report-view"
+
+export function ConnectionDetailTabMemoryReport({ connectionId, databaseIdx }: { connectionId: string; databaseIdx: number }) {
+  const { t } = useTranslation()
+  const { report, isRunning, error, start, cancel } = useKeysReport(connectionId, databaseIdx)
+  const [separator, setSeparator] = useState(":")
+  const [depth, setDepth] = useState(1)
+  const [top, setTop] = useState(20)
+  const [rate, setRate] = useState(5000)
+
+  const stamp = () => new Date().toISOString().slice(0, 19).replace(/[:T]/g, "-")
+
+  return (
+    <Card className="w-full h-full border bg-background flex flex-col rounded-none border-none shadow-none p-0">
+      <CardContent className="p-0 flex flex-col w-full h-full min-h-0">
+        <div className="flex items-center justify-between px-4 h-11 border-b bg-muted/10 shrink-0">
+          <div className="text-xs text-muted-foreground font-mono flex items-center gap-2">
+            <HardDriveIcon className="h-4 w-4 text-primary" />
+            <span className="font-semibold text-foreground uppercase">{t("memory_report")}</span>
+            {report && (
+              <>
+                <Separator orientation="vertical" className="h-4 mx-1" />
+                <span>{t("report_summary", { keys: report.keys, scanned: report.scanned, memory: formatFileSize(report.memory) })}</span>
+                {report.truncated && <Badge variant="destructive">{t("report_truncated")}</Badge>}
+              </>
+            )}
+          </div>
+          <div className="flex items-center gap-2">
+            <Button
+              variant="outline"
+              size="sm"
+              className="h-7 text-xs px-2"
+              disabled={!report}
+              onClick={() => download(JSON.stringify(report, null, 2), `memory-report-${stamp()}.json`, "application/json")}
+            >
+              <DownloadIcon className="h-3 w-3" />
+              JSON
+            </Button>
+            <Button
+              variant="outline"
+              size="sm"
+              className="h-7 text-xs px-2"
+              disabled={!report}
+              onClick={() => download(toCsv(report!), `memory-report-${stamp()}.csv`, "text/csv")}
+            >
+              <DownloadIcon className="h-3 w-3" />
+              CSV
+            </Button>
+          </div>
+        </div>
+
+        <div className="flex items-end gap-3 px-4 py-2 border-b shrink-0">
+          <div className="grid gap-1">
+            <Label className="text-xs">{t("report_separator")}</Label>
+            <Input className="h-7 w-16" value={separator} onChange={e => setSeparator(e.target.value)} />
+          </div>
+          <div className="grid gap-1">
+            <Label className="text-xs">{t("report_depth")}</Label>
+            <Input className="h-7 w-16" type="number" min={1} value={depth} onChange={e => setDepth(Number(e.target.value))} />
+          </div>
+          <div className="grid gap-1">
+            <Label className="text-xs">{t("report_top")}</Label>
+            <Input className="h-7 w-20" type="number" min={1} value={top} onChange={e => setTop(Number(e.target.value))} />
+          </div>
+          <div className="grid gap-1">
+            <Label className="text-xs">{t("report_rate")}</Label>
+            <Input className="h-7 w-24" type="number" min={0} value={rate} onChange={e => setRate(Number(e.target.value))} />
+          </div>
+          {isRunning ? (
+            <Button size="sm" variant="outline" className="h-7" onClick={cancel}>
+              <SquareIcon className="h-3 w-3" />
+              {t("cancel")}
+            </Button>
+          ) : (
+            <Button size="sm" className="h-7" onClick={() => start({ separator, prefix_depth: depth, top, rate: rate > 0 ? rate : -1 })}>
+              <PlayIcon className="h-3 w-3" />
+              {t("report_run")}
+            </Button>
+          )}
+          {report && <span className="text-xs text-muted-foreground ml-auto">{(report.elapsed_ms / 1000).toFixed(1)}s</span>}
+        </div>
+
+        <div className="flex-1 min-h-0 overflow-auto p-4 space-y-4">
+          {error && <div className="text-xs text-destructive">{error}</div>}
+          {!report && !isRunning && !error && <div className="text-xs text-muted-foreground">{t("report_hint")}</div>}
+
+          {report && <MemoryReportView report={report} />}
+        </div>
+      </CardContent>
+    </Card>
+  )
+}
@@ -0,0 +1,122 @@
+"use client"
+
+import { useState } from "react"
+import { useTranslation } from "react-i18next"
+import { DownloadIcon, FileSearchIcon, PlayIcon, SquareIcon } from "lucide-react"
+
+import { Badge, Button, Card, CardContent, Input, Label, Separator } from "@tradalab/lyra/ui"
+
+import { formatFileSize } from "@/lib/utils"
+import { useRdbAnalyze } from "@/hooks/api/rdb"
+import { MemoryReportView, download, toCsv } from "@/components/app/memory-report/memory-report-view"
+
+export function ConnectionDetailTabRdbAnalyzer() {
+  const { t } = useTranslation()
+  const { result, isRunning, error, start, cancel } = useRdbAnalyze()
+  const [path, setPath] = useState("")
+  const [db, setDb] = useState(-1)
+  const [separator, setSeparator] = useState(":")
+  const [depth, setDepth] = useState(1)
+  const [top, setTop] = useState(20)
+
+  const report = result?.report
+  const stamp = () => new Date().toISOString().slice(0, 19).replace(/[:T]/g, "-")
+
+  return (
+    <Card className="w-full h-full border bg-background flex flex-col rounded-none border-none shadow-none p-0">
+      <CardContent className="p-0 flex flex-col w-full h-full min-h-0">
+        <div className="flex items-center justify-between px-4 h-11 border-b bg-muted/10 shrink-0">
+          <div className="text-xs text-muted-foreground font-mono flex items-center gap-2">
+            <FileSearchIcon className="h-4 w-4 text-primary" />
+            <span className="font-semibold text-foreground uppercase">{t("rdb_analyzer")}</span>
+            {result && (
+              <>
+                <Separator orientation="vertical" className="h-4 mx-1" />
+                <Badge variant="outline">RDB v{result.version}</Badge>
+                {result.redis_version && <Badge variant="outline">Redis {result.redis_version}</Badge>}
+                <span>{new Date(result.created_at * 1000).toLocaleString()}</span>
+                <Separator orientation="vertical" className="h-4 mx-1" />
+                <span>{t("report_summary", { keys: report!.keys, scanned: report!.scanned, memory: formatFileSize(report!.memory) })}</span>
+              </>
+            )}
+          </div>
+          <div className="flex items-center gap-2">
+            <Button
+              variant="outline"
+              size="sm"
+              className="h-7 text-xs px-2"
+              disabled={!result}
+              onClick={() => download(JSON.stringify(result, null, 2), `rdb-report-${stamp()}.json`, "application/json")}
+            >
+              <DownloadIcon className="h-3 w-3" />
+              JSON
+            </Button>
+            <Button
+              variant="outline"
+              size="sm"
+              className="h-7 text-xs px-2"
+              disabled={!report}
+              onClick={() => download(toCsv(report!), `rdb-report-${stamp()}.csv`, "text/csv")}
+            >
+              <DownloadIcon className="h-3 w-3" />
+              CSV
+            </Button>
+          </div>
+        </div>
+
+        <div className="flex items-end gap-3 px-4 py-2 border-b shrink-0">
+          <div className="grid gap-1 flex-1">
+            <Label className="text-xs">{t("rdb_path")}</Label>
+            <Input className="h-7 font-mono text-xs" placeholder="/var/lib/redis/dump.rdb" value={path} onChange={e => setPath(e.target.value)} />
+          </div>
+          <div className="grid gap-1">
+            <Label className="text-xs">{t("rdb_database")}</Label>
+            <Input className="h-7 w-16" type="number" min={-1} value={db} onChange={e => setDb(Number(e.target.value))} />
+          </div>
+          <div className="grid gap-1">
+            <Label className="text-xs">{t("report_separator")}</Label>
+            <Input className="h-7 w-16" value={separator} onChange={e => setSeparator(e.target.value)} />
+          </div>
+          <div className="grid gap-1">
+            <Label className="text-xs">{t("report_depth")}</Label>
+            <Input className="h-7 w-16" type="number" min={1} value={depth} onChange={e => setDepth(Number(e.target.value))} />
+          </div>
+          <div className="grid gap-1">
+            <Label className="text-xs">{t("report_top")}</Label>
+            <Input className="h-7 w-20" type="number" min={1} value={top} onChange={e => setTop(Number(e.target.value))} />
+          </div>
+          {isRunning ? (
+            <Button size="sm" variant="outline" className="h-7" onClick={cancel}>
+              <SquareIcon className="h-3 w-3" />
+              {t("cancel")}
+            </Button>
+          ) : (
+            <Button size="sm" className="h-7" disabled={!path} onClick={() => start(path, { database_index: db, separator, prefix_depth: depth, top })}>
+              <PlayIcon className="h-3 w-3" />
+              {t("report_run")}
+            </Button>
+          )}
+        </div>
+
+        {result && (
+          <div className="flex items-center gap-3 px-4 py-1.5 border-b shrink-0 text-xs text-muted-foreground font-mono">
+            <div className="flex-1 h-1.5 bg-muted/30 rounded-sm">
+              <div className="h-1.5 bg-primary/60 rounded-sm" style={{ width: `${result.size > 0 ? (result.read / result.size) * 100 : 0}%` }} />
+            </div>
+            <span>
+              {formatFileSize(result.read)} / {formatFileSize(result.size)}
+            </span>
+            <span>{(report!.elapsed_ms / 1000).toFixed(1)}s</span>
+          </div>
+        )}
+
+        <div className="flex-1 min-h-0 overflow-auto p-4 space-y-4">
+          {error && <div className="text-xs text-destructive">{error}</div>}
+          {!result && !isRunning && !error && <div className="text-xs text-muted-foreground">{t("rdb_hint")}</div>}
+
+          {report && <MemoryReportView report={report} />}
+        </div>
+      </CardContent>
+    </Card>
+  )
+}
@@ -0,0 +1,171 @@
+"use client"
+
+import { useCallback, useEffect, useState } from "react"
+import { useTranslation } from "react-i18next"
+import { Network, Pause, Play, RefreshCcwIcon, ShuffleIcon, Trash2 } from "lucide-react"
+
+import { Badge, Button, Card, CardContent, Separator, toast } from "@tradalab/lyra/ui"
+import { Table, TableBody, TableCell, TableHead, TableHeader, TableRow } from "@tradalab/lyra/ui"
+import { useConfirm } from "@tradalab/lyra/blocks"
+
+import { SentinelEvent, SentinelMaster } from "@/types"
+import { useReadOnly } from "@/hooks/api/connection.api"
+import { useSentinelEvents, useSentinelFailover, useSentinelTopology } from "@/hooks/api/sentinel.api"
+
+const MAX_EVENTS = 500
+
+export function ConnectionDetailTabSentinel({ connectionId, databaseIdx }: { connectionId: string; databaseIdx: number }) {
+  const { t } = useTranslation()
+  const confirm = useConfirm()
+  const readOnly = useReadOnly(connectionId)
+  const [events, setEvents] = useState<SentinelEvent[]>([])
+
+  const { data, isFetching, refetch, error } = useSentinelTopology(connectionId, databaseIdx)
+  const failover = useSentinelFailover(connectionId, databaseIdx)
+
+  const onEvent = useCallback(
+    (ev: SentinelEvent) => {
+      setEvents(prev => [ev, ...prev].slice(0, MAX_EVENTS))
+      if (ev.event === "+switch-master") refetch()
+    },
+    [refetch],
+  )
+  const { active, start, stop } = useSentinelEvents(connectionId, databaseIdx, onEvent)
+
+  useEffect(() => {
+    start()
+    return () => stop()
+    // eslint-disable-next-line react-hooks/exhaustive-deps
+  }, [connectionId])
+
+  const onFailover = async (m: SentinelMaster) => {
+    const ok = await confirm({
+      title: t("sentinel_failover"),
+      description: t("sentinel_failover_desc", { name: m.name, addr: m.addr }),
+      confirmText: t("sentinel_failover"),
+      danger: true,
+    })
+    if (!ok) return
+    try {
+      await failover.mutateAsync(m.name)
+      toast.add({ title: t("sentinel_failover_started", { name: m.name }), type: "success" })
+    } catch (e: any) {
+      const msg = e instanceof Error ? e.message : typeof e === "string" ? e : t("unknown_error")
+      toast.add({ title: t("sentinel_failover_failed"), description: msg, type: "error" })
+    }
+  }
+
+  return (
+    <Card className="w-full h-full border bg-background flex flex-col rounded-none border-none shadow-none p-0">
+      <CardContent className="p-0 flex flex-col w-full h-full min-h-0">
+        <div className="flex items-center justify-between px-4 h-11 border-b bg-muted/10 shrink-0">
+          <div className="text-xs text-muted-foreground font-mono flex items-center gap-2">
+            <Network className="h-4 w-4 text-primary" />
+            <span className="font-semibold text-foreground uppercase">{t("sentinel")}</span>
+            <Separator orientation="vertical" className="h-4 mx-1" />
+            {(data?.nodes ?? []).map(n => (
+              <Badge key={n.addr} variant={n.ok ? "outline" : "destructive"} title={n.error || `${n.latency_ms} ms`}>
+                {n.addr}
+              </Badge>
+            ))}
+          </div>
+          <Button variant="outline" size="sm" className="h-7 text-xs px-2" onClick={() => refetch()} disabled={isFetching}>
+            <RefreshCcwIcon className={isFetching ? "h-3 w-3 animate-spin" : "h-3 w-3"} />
+            <span className="hidden lg:inline ml-1">{t("refresh")}</span>
+          </Button>
+        </div>
+
+        <div className="flex-1 min-h-0 overflow-auto p-4 space-y-4">
+          {error && <div className="text-xs text-destructive">{String(error)}</div>}
+          {(data?.masters ?? []).map(m => (
+            <div key={m.name} className="border rounded">
+              <div className="flex items-center justify-between px-3 py-2 border-b bg-muted/10">
+                <div className="text-sm flex items-center gap-2">
+                  <span className="font-semibold">{m.name}</span>
+                  <span className="font-mono text-xs">{m.addr}</span>
+                  <Badge variant={m.flags === "master" ? "outline" : "destructive"}>{m.flags}</Badge>
+                  <span className="text-xs text-muted-foreground">
+                    {t("sentinel_quorum")} {m.quorum} · {t("sentinel_epoch")} {m.config_epoch} · {t("sentinel_reported_by", { count: m.reported_by?.length ?? 0 })}
+                  </span>
+                </div>
+                <Button
+                  size="sm"
+                  variant="destructive"
+                  className="h-7 text-xs"
+                  disabled={readOnly || failover.isPending}
+                  title={readOnly ? t("read_only") : undefined}
+                  onClick={() => onFailover(m)}
+                >
+                  <ShuffleIcon className="h-3 w-3" />
+                  {t("sentinel_failover")}
+                </Button>
+              </div>
+              <Table>
+                <TableHeader>
+                  <TableRow>
+                    <TableHead>{t("sentinel_role")}</TableHead>
+                    <TableHead>{t("address")}</TableHead>
+                    <TableHead>{t("sentinel_flags")}</TableHead>
+                    <TableHead>{t("sentinel_link")}</TableHead>
+                    <TableHead className="text-right">{t("sentinel_offset")}</TableHead>
+                  </TableRow>
+                </TableHeader>
+                <TableBody>
+                  {(m.replicas ?? []).map(r => (
+                    <TableRow key={"r" + r.addr}>
+                      <TableCell>{t("sentinel_replica")}</TableCell>
+                      <TableCell className="font-mono text-xs">{r.addr}</TableCell>
+                      <TableCell>{r.flags}</TableCell>
+                      <TableCell>{r.link_status}</TableCell>
+                      <TableCell className="text-right font-mono text-xs">{r.repl_offset}</TableCell>
+                    </TableRow>
+                  ))}
+                  {(m.sentinels ?? []).map(s => (
+                    <TableRow key={"s" + s.addr}>
+                      <TableCell>{t("sentinel")}</TableCell>
+                      <TableCell className="font-mono text-xs">{s.addr}</TableCell>
+                      <TableCell>{s.flags}</TableCell>
+                      <TableCell>{s.last_ok_ping_ms} ms</TableCell>
+                      <TableCell />
+                    </TableRow>
+                  ))}
+                </TableBody>
+              </Table>
+            </div>
+          ))}
+        </div>
+
+        <div className="h-56 shrink-0 border-t flex flex-col min-h-0">
+          <div className="flex items-center justify-between px-4 h-9 border-b bg-muted/10">
+            <div className="text-xs font-semibold flex items-center gap-2">
+              {t("sentinel_events")}
+              <span className={active ? "flex h-2 w-2 rounded-full bg-green-500 animate-pulse" : "flex h-2 w-2 rounded-full bg-red-500"} />
+            </div>
+            <div className="flex gap-2">
+              <Button variant="outline" size="sm" className="h-7 text-xs px-2" onClick={active ? stop : start}>
+                {active ? <Pause className="h-3 w-3 text-red-500" /> : <Play className="h-3 w-3 text-green-500" />}
+              </Button>
+              <Button variant="destructive" size="sm" className="h-7 text-xs px-2" onClick={() => setEvents([])} title={t("pubsub_clear")}>
+                <Trash2 className="h-3 w-3" />
+              </Button>
+            </div>
+          </div>
+          <div className="flex-1 overflow-y-auto p-2 font-mono text-xs space-y-0.5">
+            {events.length === 0 ? (
+              <div className="text-muted-foreground italic p-2">{t("pubsub_waiting_events")}</div>
+            ) : (
+              events.map((ev, i) => (
+                <div key={i} className="flex gap-3 break-all">
+                  <span className="text-muted-foreground shrink-0">[{new Date(ev.time).toLocaleTimeString()}]</span>
+                  <span className="shrink-0 text-primary">{ev.event}</span>
+                  <span>{ev.message}</span>
+                  <span className="text-muted-foreground shrink-0 ml-auto">{ev.sentinel}</span>
+                </div>
+              ))
+            )}
+          </div>
+        </div>
+      </CardContent>
+    </Card>
+  )
+}
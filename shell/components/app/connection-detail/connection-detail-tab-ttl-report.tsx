@@ -0,0 +1,196 @@
+"use client"
+
+import { useState } from "react"
+import { useTranslation } from "react-i18next"
+import { DownloadIcon, HourglassIcon, PlayIcon, SquareIcon } from "lucide-react"
+
+import { Badge, Button, Card, CardContent, Input, Label, Separator } from "@tradalab/lyra/ui"
+import { Table, TableBody, TableCell, TableHead, TableHeader, TableRow } from "@tradalab/lyra/ui"
+
+import { TtlPrefix } from "@/types"
+import { formatFileSize } from "@/lib/utils"
+import { useKeysTtl } from "@/hooks/api/keys-ttl"
+import { Histogram, download, ttlLabel } from "@/components/app/memory-report/memory-report-view"
+
+// TtlBar draws a prefix's TTL buckets as one stacked bar, keys without expiry in red.
+function TtlBar({ prefix }: { prefix: TtlPrefix }) {
+  const { t } = useTranslation()
+  const hist = prefix.ttl_hist ?? []
+  return (
+    <div className="flex h-3 w-48 rounded-sm overflow-hidden bg-muted/30">
+      {hist.map((b, i) =>
+        b.count > 0 ? (
+          <div
+            key={b.min}
+            className={b.min < 0 ? "bg-destructive/70" : "bg-primary"}
+            style={{ width: `${(b.count / prefix.keys) * 100}%`, opacity: b.min < 0 ? 1 : 0.35 + (0.65 * i) / hist.length }}
+            title={`${ttlLabel(b, t("report_no_expiry"))}: ${b.count}`}
+          />
+        ) : null
+      )}
+    </div>
+  )
+}
+
+function prefixLabel(p: string, t: (k: string) => string) {
+  return p === "" ? t("report_no_prefix") : p === "*" ? t("report_other_prefixes") : p
+}
+
+export function ConnectionDetailTabTtlReport({ connectionId, databaseIdx }: { connectionId: string; databaseIdx: number }) {
+  const { t } = useTranslation()
+  const { report, isRunning, error, start, cancel } = useKeysTtl(connectionId, databaseIdx)
+  const [separator, setSeparator] = useState(":")
+  const [depth, setDepth] = useState(1)
+  const [ratio, setRatio] = useState(80)
+  const [minKeys, setMinKeys] = useState(10)
+  const [rate, setRate] = useState(5000)
+
+  const stamp = () => new Date().toISOString().slice(0, 19).replace(/[:T]/g, "-")
+
+  return (
+    <Card className="w-full h-full border bg-background flex flex-col rounded-none border-none shadow-none p-0">
+      <CardContent className="p-0 flex flex-col w-full h-full min-h-0">
+        <div className="flex items-center justify-between px-4 h-11 border-b bg-muted/10 shrink-0">
+          <div className="text-xs text-muted-foreground font-mono flex items-center gap-2">
+            <HourglassIcon className="h-4 w-4 text-primary" />
+            <span className="font-semibold text-foreground uppercase">{t("ttl_report")}</span>
+            {report && (
+              <>
+                <Separator orientation="vertical" className="h-4 mx-1" />
+                <span>{t("ttl_summary", { keys: report.keys, scanned: report.scanned, none: report.no_expiry })}</span>
+                {report.truncated && <Badge variant="destructive">{t("report_truncated")}</Badge>}
+              </>
+            )}
+          </div>
+          <Button
+            variant="outline"
+            size="sm"
+            className="h-7 text-xs px-2"
+            disabled={!report}
+            onClick={() => download(JSON.stringify(report, null, 2), `ttl-report-${stamp()}.json`, "application/json")}
+          >
+            <DownloadIcon className="h-3 w-3" />
+            JSON
+          </Button>
+        </div>
+
+        <div className="flex items-end gap-3 px-4 py-2 border-b shrink-0">
+          <div className="grid gap-1">
+            <Label className="text-xs">{t("report_separator")}</Label>
+            <Input className="h-7 w-16" value={separator} onChange={e => setSeparator(e.target.value)} />
+          </div>
+          <div className="grid gap-1">
+            <Label className="text-xs">{t("report_depth")}</Label>
+            <Input className="h-7 w-16" type="number" min={1} value={depth} onChange={e => setDepth(Number(e.target.value))} />
+          </div>
+          <div className="grid gap-1">
+            <Label className="text-xs">{t("ttl_leak_ratio")}</Label>
+            <Input className="h-7 w-20" type="number" min={1} max={100} value={ratio} onChange={e => setRatio(Number(e.target.value))} />
+          </div>
+          <div className="grid gap-1">
+            <Label className="text-xs">{t("ttl_leak_min_keys")}</Label>
+            <Input className="h-7 w-20" type="number" min={1} value={minKeys} onChange={e => setMinKeys(Number(e.target.value))} />
+          </div>
+          <div className="grid gap-1">
+            <Label className="text-xs">{t("report_rate")}</Label>
+            <Input className="h-7 w-24" type="number" min={0} value={rate} onChange={e => setRate(Number(e.target.value))} />
+          </div>
+          {isRunning ? (
+            <Button size="sm" variant="outline" className="h-7" onClick={cancel}>
+              <SquareIcon className="h-3 w-3" />
+              {t("cancel")}
+            </Button>
+          ) : (
+            <Button
+              size="sm"
+              className="h-7"
+              onClick={() => start({ separator, prefix_depth: depth, leak_ratio: ratio / 100, leak_min_keys: minKeys, rate: rate > 0 ? rate : -1 })}
+            >
+              <PlayIcon className="h-3 w-3" />
+              {t("report_run")}
+            </Button>
+          )}
+          {report && <span className="text-xs text-muted-foreground ml-auto">{(report.elapsed_ms / 1000).toFixed(1)}s</span>}
+        </div>
+
+        <div className="flex-1 min-h-0 overflow-auto p-4 space-y-4">
+          {error && <div className="text-xs text-destructive">{error}</div>}
+          {!report && !isRunning && !error && <div className="text-xs text-muted-foreground">{t("ttl_hint")}</div>}
+
+          {report && (
+            <div className="border rounded">
+              <div className="px-3 py-2 border-b bg-muted/10 text-xs font-semibold text-sm">{t("report_ttl")}</div>
+              <div className="p-3">
+                <Histogram buckets={report.ttl_hist} label={b => ttlLabel(b, t("report_no_expiry"))} />
+              </div>
+            </div>
+          )}
+
+          {report && (
+            <div className="border rounded">
+              <div className="px-3 py-2 border-b bg-muted/10 text-xs font-semibold text-sm">{t("ttl_leaks")}</div>
+              {(report.leaks ?? []).length === 0 ? (
+                <div className="px-3 py-2 text-xs text-muted-foreground">{t("ttl_no_leaks")}</div>
+              ) : (
+                <Table>
+                  <TableHeader>
+                    <TableRow>
+                      <TableHead>{t("report_prefix")}</TableHead>
+                      <TableHead className="text-right">{t("report_no_expiry")}</TableHead>
+                      <TableHead className="text-right">{t("memory")}</TableHead>
+                      <TableHead>{t("ttl_samples")}</TableHead>
+                    </TableRow>
+                  </TableHeader>
+                  <TableBody>
+                    {report.leaks!.map(p => (
+                      <TableRow key={p.prefix}>
+                        <TableCell className="font-mono text-xs">{prefixLabel(p.prefix, t)}</TableCell>
+                        <TableCell className="text-right font-mono text-xs">
+                          {p.no_expiry} / {p.keys}
+                        </TableCell>
+                        <TableCell className="text-right font-mono text-xs">{formatFileSize(p.no_expiry_memory)}</TableCell>
+                        <TableCell className="font-mono text-xs max-w-[420px] truncate" title={(p.samples ?? []).join("\n")}>
+                          {(p.samples ?? []).join(", ")}
+                        </TableCell>
+                      </TableRow>
+                    ))}
+                  </TableBody>
+                </Table>
+              )}
+            </div>
+          )}
+
+          {(report?.prefixes ?? []).length > 0 && (
+            <div className="border rounded">
+              <div className="px-3 py-2 border-b bg-muted/10 text-xs font-semibold text-sm">{t("report_prefixes")}</div>
+              <Table>
+                <TableHeader>
+                  <TableRow>
+                    <TableHead>{t("report_prefix")}</TableHead>
+                    <TableHead className="text-right">{t("keys")}</TableHead>
+                    <TableHead className="text-right">{t("report_no_expiry")}</TableHead>
+                    <TableHead className="text-right">{t("memory")}</TableHead>
+                    <TableHead>{t("report_ttl")}</TableHead>
+                  </TableRow>
+                </TableHeader>
+                <TableBody>
+                  {report!.prefixes!.map(p => (
+                    <TableRow key={p.prefix}>
+                      <TableCell className="font-mono text-xs">{prefixLabel(p.prefix, t)}</TableCell>
+                      <TableCell className="text-right font-mono text-xs">{p.keys}</TableCell>
+                      <TableCell className="text-right font-mono text-xs">{p.no_expiry}</TableCell>
+                      <TableCell className="text-right font-mono text-xs">{formatFileSize(p.memory)}</TableCell>
+                      <TableCell>
+                        <TtlBar prefix={p} />
+                      </TableCell>
+                    </TableRow>
+                  ))}
+                </TableBody>
+              </Table>
+            </div>
+          )}
+        </div>
+      </CardContent>
+    </Card>
+  )
+}
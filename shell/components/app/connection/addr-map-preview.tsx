@@ -0,0 +1,59 @@
+"use client"
+
+import { useState } from "react"
+import { UseFormReturn } from "react-hook-form"
+import { useTranslation } from "react-i18next"
+import { ArrowRightIcon, EyeIcon } from "lucide-react"
+import { Badge, Button, Spinner, toast } from "@tradalab/lyra/ui"
+import { AddrMapPreviewRes } from "@/types"
+import { useAddrMapPreview } from "@/hooks/api/connection.api"
+
+export function AddrMapPreview({ form }: { form: UseFormReturn<any> }) {
+  const { t } = useTranslation()
+  const preview = useAddrMapPreview()
+  const [result, setResult] = useState<AddrMapPreviewRes | null>(null)
+
+  const run = () =>
+    form.handleSubmit(async values => {
+      setResult(null)
+      try {
+        setResult(await preview.mutateAsync(values))
+      } catch (e) {
+        const msg = e instanceof Error ? e.message : typeof e === "string" ? e : ""
+        toast.add({ title: t("conn_failed"), description: msg, type: "error" })
+      }
+    })()
+
+  return (
+    <div className="space-y-2">
+      <Button type="button" size="sm" variant="outline" disabled={preview.isPending} onClick={run}>
+        {preview.isPending ? <Spinner /> : <EyeIcon />}
+        {t("addr_mapping_preview")}
+      </Button>
+
+      {result && (
+        <div className="border rounded-md p-2 space-y-1 text-xs">
+          {(result.errors ?? []).map(e => (
+            <div key={e} className="text-destructive break-all">
+              {e}
+            </div>
+          ))}
+          {(result.rows ?? []).map(r => (
+            <div key={r.addr} className="flex items-center gap-2">
+              <Badge variant="outline">{r.master ? t("cluster_master") : t("sentinel_replica")}</Badge>
+              <span className="font-mono">{r.addr}</span>
+              <ArrowRightIcon className="w-3 h-3 text-muted-foreground shrink-0" />
+              <span className={`font-mono ${r.rule ? "" : "text-muted-foreground"}`}>{r.dial_addr}</span>
+              {r.master && (
+                <span className="text-muted-foreground">{(r.slots ?? []).map(s => (s.start === s.end ? `${s.start}` : `${s.start}-${s.end}`)).join(", ")}</span>
+              )}
+              <span className="ml-auto text-muted-foreground truncate" title={r.rule}>
+                {r.rule || t("addr_mapping_unmapped")}
+              </span>
+            </div>
+          ))}
+        </div>
+      )}
+    </div>
+  )
+}
@@ -0,0 +1,57 @@
+"use client"
+
+import { useTranslation } from "react-i18next"
+import { CheckCircle2Icon, XCircleIcon, XIcon } from "lucide-react"
+import { Badge, Button } from "@tradalab/lyra/ui"
+import { ConnTestRes } from "@/types"
+
+function Mark({ ok }: { ok: boolean }) {
+  return ok ? <CheckCircle2Icon className="w-4 h-4 text-green-600 shrink-0" /> : <XCircleIcon className="w-4 h-4 text-destructive shrink-0" />
+}
+
+export function ConnTestTrace({ result, onClose }: { result: ConnTestRes; onClose: () => void }) {
+  const { t } = useTranslation()
+
+  return (
+    <div className="border-t p-3 space-y-2 max-h-64 overflow-y-auto text-xs">
+      <div className="flex items-center gap-2">
+        <span className="font-semibold text-muted-foreground uppercase tracking-wider">{t("conn_trace")}</span>
+        {result.server_version && <Badge variant="outline">Redis {result.server_version}</Badge>}
+        {result.server_mode && <Badge variant="outline">{result.server_mode}</Badge>}
+        <Button type="button" size="icon" variant="ghost" className="ml-auto h-6 w-6" onClick={onClose}>
+          <XIcon />
+        </Button>
+      </div>
+
+      {(result.stages ?? []).map(s => (
+        <div key={s.name} className="flex items-start gap-2">
+          <Mark ok={s.ok} />
+          <div className="min-w-0">
+            <div className="font-medium">
+              {t(`conn_stage_${s.name}`)} <span className="text-muted-foreground font-normal">{s.latency_ms} ms</span>
+            </div>
+            {s.detail && <div className="text-muted-foreground break-all">{s.detail}</div>}
+            {s.error && <div className="text-destructive break-all">{s.error}</div>}
+          </div>
+        </div>
+      ))}
+
+      {(result.nodes ?? []).length > 0 && (
+        <div className="space-y-1 pt-1">
+          <div className="font-medium">{t("conn_nodes")}</div>
+          {result.nodes!.map(n => (
+            <div key={n.addr} className="flex items-center gap-2">
+              <Mark ok={n.ok} />
+              <Badge variant="outline">{n.role === "master" ? t("cluster_master") : t("sentinel_replica")}</Badge>
+              <span className="font-mono">{n.addr}</span>
+              {n.dial_addr && n.dial_addr !== n.addr && <span className="font-mono text-muted-foreground">→ {n.dial_addr}</span>}
+              <span className={`ml-auto truncate ${n.ok ? "text-muted-foreground" : "text-destructive"}`} title={n.error}>
+                {n.ok ? `${n.latency_ms} ms` : n.error}
+              </span>
+            </div>
+          ))}
+        </div>
+      )}
+    </div>
+  )
+}
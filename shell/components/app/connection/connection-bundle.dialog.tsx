@@ -0,0 +1,123 @@
+"use client"
+
+import { useState } from "react"
+import { useTranslation } from "react-i18next"
+import { PackageIcon, PackageOpenIcon } from "lucide-react"
+
+import { Button, Input, Label, Spinner, toast } from "@tradalab/lyra/ui"
+import { Dialog, DialogClose, DialogContent, DialogFooter, DialogHeader, DialogTitle } from "@tradalab/lyra/ui"
+
+import { useExportConnections, useImportConnections } from "@/hooks/api/connection.api"
+
+export type Props = {
+  mode: "export" | "import"
+  ids?: string[]
+  open: boolean
+  onOpenChange: (v: boolean) => void
+}
+
+function toBase64(bytes: Uint8Array): string {
+  let bin = ""
+  for (let i = 0; i < bytes.length; i += 0x8000) {
+    bin += String.fromCharCode(...bytes.subarray(i, i + 0x8000))
+  }
+  return btoa(bin)
+}
+
+function download(base64: string, name: string) {
+  const bin = atob(base64)
+  const bytes = new Uint8Array(bin.length)
+  for (let i = 0; i < bin.length; i++) bytes[i] = bin.charCodeAt(i)
+  const url = URL.createObjectURL(new Blob([bytes], { type: "application/json" }))
+  const a = document.createElement("a")
+  a.href = url
+  a.download = name
+  a.click()
+  URL.revokeObjectURL(url)
+}
+
+export function ConnectionBundleDialog({ mode, ids, open, onOpenChange }: Props) {
+  const { t } = useTranslation()
+  const [passphrase, setPassphrase] = useState("")
+  const [confirm, setConfirm] = useState("")
+  const [data, setData] = useState("")
+
+  const exportBundle = useExportConnections()
+  const importBundle = useImportConnections()
+  const pending = exportBundle.isPending || importBundle.isPending
+
+  const reset = () => {
+    setPassphrase("")
+    setConfirm("")
+    setData("")
+  }
+
+  const onFile = async (file?: File) => {
+    setData(file ? toBase64(new Uint8Array(await file.arrayBuffer())) : "")
+  }
+
+  const onSubmit = async () => {
+    try {
+      if (mode === "export") {
+        const res = await exportBundle.mutateAsync({ ids: ids ?? [], passphrase })
+        download(res.data, `redishub-${new Date().toISOString().slice(0, 10)}.rhb`)
+        toast.add({ title: t("bundle_exported", { count: res.connections }), type: "success" })
+      } else {
+        const res = await importBundle.mutateAsync({ data, passphrase })
+        toast.add({ title: t("bundle_imported", { count: res.connections }), type: "success" })
+      }
+      reset()
+      onOpenChange(false)
+    } catch (e: any) {
+      const msg = e instanceof Error ? e.message : typeof e === "string" ? e : t("unknown_error")
+      toast.add({ title: t(mode === "export" ? "bundle_export_failed" : "import_failed"), description: msg, type: "error" })
+    }
+  }
+
+  const ready = passphrase !== "" && (mode === "export" ? passphrase === confirm : data !== "")
+
+  return (
+    <Dialog
+      open={open}
+      onOpenChange={v => {
+        if (!v) reset()
+        onOpenChange(v)
+      }}
+    >
+      <DialogContent className="sm:max-w-[420px] p-0">
+        <DialogHeader className="px-4 pt-4">
+          <DialogTitle className="text-sm">{t(mode === "export" ? "bundle_export" : "bundle_import")}</DialogTitle>
+        </DialogHeader>
+
+        <div className="flex flex-col gap-3 px-4">
+          <div className="text-xs text-muted-foreground">
+            {mode === "export" ? t("bundle_export_hint", { count: ids?.length || 0 }) : t("bundle_import_hint")}
+          </div>
+          {mode === "import" && <Input type="file" accept=".rhb,.json" onChange={e => onFile(e.target.files?.[0])} />}
+          <div className="grid gap-1">
+            <Label>{t("passphrase")}</Label>
+            <Input type="password" value={passphrase} onChange={e => setPassphrase(e.target.value)} />
+          </div>
+          {mode === "export" && (
+            <div className="grid gap-1">
+              <Label>{t("bundle_passphrase_confirm")}</Label>
+              <Input type="password" value={confirm} onChange={e => setConfirm(e.target.value)} />
+            </div>
+          )}
+        </div>
+
+        <DialogFooter className="p-2 border-t flex gap-2">
+          <DialogClose asChild>
+            <Button size="sm" variant="outline">
+              {t("cancel")}
+            </Button>
+          </DialogClose>
+          <Button size="sm" disabled={!ready || pending} onClick={onSubmit}>
+            {pending ? <Spinner /> : mode === "export" ? <PackageIcon /> : <PackageOpenIcon />}
+            {t(mode === "export" ? "export" : "import")}
+          </Button>
+        </DialogFooter>
+      </DialogContent>
+    </Dialog>
+  )
+}
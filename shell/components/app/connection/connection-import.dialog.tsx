@@ -0,0 +1,177 @@
+"use client"
+
+import { useState } from "react"
+import { useTranslation } from "react-i18next"
+import { ImportIcon } from "lucide-react"
+
+import { Badge, Button, Input, Spinner, toast } from "@tradalab/lyra/ui"
+import { Dialog, DialogClose, DialogContent, DialogFooter, DialogHeader, DialogTitle } from "@tradalab/lyra/ui"
+import { Select, SelectContent, SelectItem, SelectTrigger, SelectValue } from "@tradalab/lyra/ui"
+import { Table, TableBody, TableCell, TableHead, TableHeader, TableRow } from "@tradalab/lyra/ui"
+import { Textarea } from "@tradalab/lyra/ui"
+
+import { ConnectionImportExternalRes } from "@/types"
+import { useImportExternal, useImportExternalPreview } from "@/hooks/api/connection.api"
+
+const SOURCES = ["auto", "ardm", "redisinsight", "tinyrdm", "redis-cli"]
+
+export type Props = {
+  open: boolean
+  onOpenChange: (v: boolean) => void
+}
+
+function toBase64(bytes: Uint8Array): string {
+  let bin = ""
+  for (let i = 0; i < bytes.length; i += 0x8000) {
+    bin += String.fromCharCode(...bytes.subarray(i, i + 0x8000))
+  }
+  return btoa(bin)
+}
+
+export function ConnectionImportDialog({ open, onOpenChange }: Props) {
+  const { t } = useTranslation()
+  const [source, setSource] = useState("auto")
+  const [data, setData] = useState("")
+  const [text, setText] = useState("")
+  const [preview, setPreview] = useState<ConnectionImportExternalRes | null>(null)
+
+  const previewImport = useImportExternalPreview()
+  const runImport = useImportExternal()
+
+  const req = () => ({
+    source: source === "auto" ? "" : source,
+    data: data || toBase64(new TextEncoder().encode(text)),
+  })
+
+  const reset = () => {
+    setData("")
+    setText("")
+    setPreview(null)
+  }
+
+  const onFile = async (file?: File) => {
+    if (!file) return
+    setData(toBase64(new Uint8Array(await file.arrayBuffer())))
+    setPreview(null)
+  }
+
+  const onPreview = async () => {
+    try {
+      setPreview(await previewImport.mutateAsync(req()))
+    } catch (e: any) {
+      const msg = e instanceof Error ? e.message : typeof e === "string" ? e : t("unknown_error")
+      toast.add({ title: t("import_failed"), description: msg, type: "error" })
+    }
+  }
+
+  const onImport = async () => {
+    try {
+      const res = await runImport.mutateAsync(req())
+      toast.add({ title: t("import_done", { created: res.created, skipped: res.skipped }), type: "success" })
+      reset()
+      onOpenChange(false)
+    } catch (e: any) {
+      const msg = e instanceof Error ? e.message : typeof e === "string" ? e : t("unknown_error")
+      toast.add({ title: t("import_failed"), description: msg, type: "error" })
+    }
+  }
+
+  const items = preview?.items ?? []
+  const creatable = items.filter(i => !i.duplicate_of).length
+
+  return (
+    <Dialog
+      open={open}
+      onOpenChange={v => {
+        if (!v) reset()
+        onOpenChange(v)
+      }}
+    >
+      <DialogContent className="sm:max-w-[760px] p-0 flex flex-col max-h-[85vh]">
+        <DialogHeader className="px-4 pt-4">
+          <DialogTitle className="text-sm">{t("import_connections")}</DialogTitle>
+        </DialogHeader>
+
+        <div className="flex flex-col gap-3 px-4 min-h-0 overflow-auto">
+          <div className="flex gap-2">
+            <Select value={source} onValueChange={setSource}>
+              <SelectTrigger className="w-48">
+                <SelectValue />
+              </SelectTrigger>
+              <SelectContent>
+                {SOURCES.map(s => (
+                  <SelectItem key={s} value={s}>
+                    {t(`import_source_${s.replace("-", "_")}`)}
+                  </SelectItem>
+                ))}
+              </SelectContent>
+            </Select>
+            <Input type="file" className="flex-1" onChange={e => onFile(e.target.files?.[0])} />
+          </div>
+          {!data && (
+            <Textarea
+              rows={4}
+              className="font-mono text-xs"
+              value={text}
+              onChange={e => {
+                setText(e.target.value)
+                setPreview(null)
+              }}
+              placeholder="redis-cli -h 127.0.0.1 -p 6379 -n 0"
+            />
+          )}
+
+          {preview && (
+            <Table>
+              <TableHeader>
+                <TableRow>
+                  <TableHead>{t("name")}</TableHead>
+                  <TableHead>{t("address")}</TableHead>
+                  <TableHead>{t("group")}</TableHead>
+                  <TableHead />
+                </TableRow>
+              </TableHeader>
+              <TableBody>
+                {items.map((item, i) => (
+                  <TableRow key={i} className={item.duplicate_of ? "opacity-50" : undefined}>
+                    <TableCell>{item.name}</TableCell>
+                    <TableCell className="font-mono text-xs">
+                      {item.addr}/{item.db}
+                    </TableCell>
+                    <TableCell>{item.group}</TableCell>
+                    <TableCell className="space-x-1">
+                      {item.ssh && <Badge variant="outline">SSH</Badge>}
+                      {item.tls && <Badge variant="outline">TLS</Badge>}
+                      {item.duplicate_of && <Badge variant="secondary">{t("import_duplicate_of", { name: item.duplicate_of })}</Badge>}
+                      {item.warnings?.map(w => (
+                        <div key={w} className="text-xs text-amber-600 dark:text-amber-400">
+                          {w}
+                        </div>
+                      ))}
+                    </TableCell>
+                  </TableRow>
+                ))}
+              </TableBody>
+            </Table>
+          )}
+        </div>
+
+        <DialogFooter className="p-2 border-t flex gap-2">
+          <DialogClose asChild>
+            <Button size="sm" variant="outline">
+              {t("cancel")}
+            </Button>
+          </DialogClose>
+          <Button size="sm" variant="outline" disabled={(!data && !text.trim()) || previewImport.isPending} onClick={onPreview}>
+            {previewImport.isPending && <Spinner />}
+            {t("preview")}
+          </Button>
+          <Button size="sm" disabled={!preview || creatable === 0 || runImport.isPending} onClick={onImport}>
+            {runImport.isPending ? <Spinner /> : <ImportIcon />}
+            {t("import_count", { count: creatable })}
+          </Button>
+        </DialogFooter>
+      </DialogContent>
+    </Dialog>
+  )
+}
@@ -4,10 +4,15 @@ import { UseFormReturn } from "react-hook-form"
 import { FormControl, FormField, FormItem, FormLabel, FormMessage } from "@tradalab/lyra/blocks"
 import { Input } from "@tradalab/lyra/ui"
 import { Switch } from "@tradalab/lyra/ui"
+import { Select, SelectContent, SelectItem, SelectTrigger, SelectValue } from "@tradalab/lyra/ui"
 import { useTranslation } from "react-i18next"
+import { RedisModeEnum } from "@/types/redis-mode.enum"
+import { AddrMapPreview } from "./addr-map-preview"
 
 export function ConnectionOptionalForm({ form }: { form: UseFormReturn<any> }) {
   const { t } = useTranslation()
+  const mode: RedisModeEnum = form.watch("mode") || RedisModeEnum.STANDALONE
+  const credProvider: string = form.watch("cred_provider") || ""
 
   return (
     <>
@@ -27,6 +32,33 @@ export function ConnectionOptionalForm({ form }: { form: UseFormReturn<any> }) {
         )}
       />
 
+      {(mode === RedisModeEnum.CLUSTER || mode === RedisModeEnum.SENTINEL) && (
+        <FormField
+          control={form.control}
+          name="read_from"
+          render={({ field }) => (
+            <FormItem>
+              <FormLabel>{t("read_from")}</FormLabel>
+              <Select value={field.value || "master"} onValueChange={v => field.onChange(v === "master" ? "" : v)}>
+                <FormControl>
+                  <SelectTrigger className="w-full">
+                    <SelectValue />
+                  </SelectTrigger>
+                </FormControl>
+                <SelectContent>
+                  <SelectItem value="master">{t("read_from_master")}</SelectItem>
+                  <SelectItem value="replica">{t("read_from_replica")}</SelectItem>
+                  {mode === RedisModeEnum.CLUSTER && <SelectItem value="latency">{t("read_from_latency")}</SelectItem>}
+                  {mode === RedisModeEnum.CLUSTER && <SelectItem value="random">{t("read_from_random")}</SelectItem>}
+                </SelectContent>
+              </Select>
+              <p className="text-[11px] text-muted-foreground leading-tight">{t("read_from_help")}</p>
+              <FormMessage />
+            </FormItem>
+          )}
+        />
+      )}
+
       <FormField
         control={form.control}
         name="exec_timeout"
@@ -69,6 +101,59 @@ export function ConnectionOptionalForm({ form }: { form: UseFormReturn<any> }) {
 
       <div className="pt-4 space-y-4">
         <div className="font-semibold text-xs text-muted-foreground uppercase tracking-wider">{t("advanced")}</div>
+        <FormField
+          control={form.control}
+          name="cred_provider"
+          render={({ field }) => (
+            <FormItem>
+              <FormLabel>{t("cred_provider")}</FormLabel>
+              <Select value={field.value || "none"} onValueChange={v => field.onChange(v === "none" ? "" : v)}>
+                <FormControl>
+                  <SelectTrigger className="w-full">
+                    <SelectValue />
+                  </SelectTrigger>
+                </FormControl>
+                <SelectContent>
+                  <SelectItem value="none">{t("cred_provider_none")}</SelectItem>
+                  <SelectItem value="command">{t("cred_provider_command")}</SelectItem>
+                  <SelectItem value="file">{t("cred_provider_file")}</SelectItem>
+                </SelectContent>
+              </Select>
+              <p className="text-[11px] text-muted-foreground leading-tight">{t("cred_provider_help")}</p>
+              <FormMessage />
+            </FormItem>
+          )}
+        />
+        {credProvider !== "" && (
+          <>
+            <FormField
+              control={form.control}
+              name="cred_source"
+              render={({ field }) => (
+                <FormItem>
+                  <FormLabel>{credProvider === "file" ? t("cred_source_file") : t("cred_source_command")}</FormLabel>
+                  <FormControl>
+                    <Input {...field} value={field.value ?? ""} placeholder={credProvider === "file" ? "/var/run/secrets/redis-token" : "vault read -field=password database/creds/redis"} />
+                  </FormControl>
+                  <FormMessage />
+                </FormItem>
+              )}
+            />
+            <FormField
+              control={form.control}
+              name="cred_ttl"
+              render={({ field }) => (
+                <FormItem>
+                  <FormLabel>{t("cred_ttl")}</FormLabel>
+                  <FormControl>
+                    <Input type="number" {...field} onChange={e => field.onChange(e.target.value === "" ? undefined : Number(e.target.value))} />
+                  </FormControl>
+                  <FormMessage />
+                </FormItem>
+              )}
+            />
+          </>
+        )}
         <FormField
           control={form.control}
           name="addr_mapping"
@@ -79,7 +164,7 @@ export function ConnectionOptionalForm({ form }: { form: UseFormReturn<any> }) {
                 <textarea
                   {...field}
                   className="flex min-h-[80px] w-full rounded-md border border-input bg-background px-3 py-2 text-sm ring-offset-background placeholder:text-muted-foreground focus-visible:outline-none focus-visible:ring-2 focus-visible:ring-ring focus-visible:ring-offset-2 disabled:cursor-not-allowed disabled:opacity-50"
-                  placeholder="172.18.0.2:6379=127.0.0.1:8001"
+                  placeholder="10.0.0.0/16:* => 127.0.0.1:{port+10000}"
                 />
               </FormControl>
               <p className="text-[11px] text-muted-foreground leading-tight">{t("addr_mapping_help")}</p>
@@ -87,6 +172,7 @@ export function ConnectionOptionalForm({ form }: { form: UseFormReturn<any> }) {
             </FormItem>
           )}
         />
+        {mode === RedisModeEnum.CLUSTER && <AddrMapPreview form={form} />}
       </div>
     </>
   )
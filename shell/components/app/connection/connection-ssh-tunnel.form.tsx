@@ -2,7 +2,7 @@
 
 import { UseFormReturn } from "react-hook-form"
 import { useTranslation } from "react-i18next"
-import { MoreHorizontal } from "lucide-react"
+import { MoreHorizontal, Plus, X } from "lucide-react"
 
 import { Button } from "@tradalab/lyra/ui"
 import { FormControl, FormField, FormItem, FormLabel, FormMessage } from "@tradalab/lyra/blocks"
@@ -18,6 +18,8 @@ export function ConnectionSshTunnelForm({ form }: { form: UseFormReturn<any> })
   const { data: sshList = [] } = useSshList()
 
   const enabled = form.watch("ssh_enable")
+  const jumpIds: string[] = form.watch("ssh_jump_ids") ?? []
+  const setJumpIds = (ids: string[]) => form.setValue("ssh_jump_ids", ids, { shouldDirty: true })
 
   return (
     <div className="space-y-6">
@@ -37,6 +39,36 @@ export function ConnectionSshTunnelForm({ form }: { form: UseFormReturn<any> })
         )}
       />
 
+      {!!enabled && (
+        <FormItem>
+          <FormLabel>{t("ssh_jump_hosts")}</FormLabel>
+          <p className="text-xs text-muted-foreground">{t("ssh_jump_hosts_desc")}</p>
+          {jumpIds.map((id, i) => (
+            <div key={i} className="flex gap-1">
+              <Select value={id || undefined} onValueChange={val => setJumpIds(jumpIds.map((v, j) => (j === i ? val : v)))}>
+                <SelectTrigger className="flex-1">
+                  <SelectValue placeholder={t("ssh_configuration")} />
+                </SelectTrigger>
+                <SelectContent>
+                  {sshList?.map((ssh: SshDO) => (
+                    <SelectItem key={ssh.id} value={ssh.id}>
+                      {ssh.username}@{ssh.host}:{ssh.port}
+                    </SelectItem>
+                  ))}
+                </SelectContent>
+              </Select>
+              <Button type="button" size="icon" variant="outline" onClick={() => setJumpIds(jumpIds.filter((_, j) => j !== i))}>
+                <X className="size-4" />
+              </Button>
+            </div>
+          ))}
+          <Button type="button" variant="outline" size="sm" onClick={() => setJumpIds([...jumpIds, ""])}>
+            <Plus className="size-4" />
+            {t("ssh_add_jump_host")}
+          </Button>
+        </FormItem>
+      )}
+
       {!!enabled && (
         <FormField
           control={form.control}
@@ -1,6 +1,6 @@
 "use client"
 
-import { useEffect, forwardRef, useImperativeHandle, useCallback } from "react"
+import { useEffect, forwardRef, useImperativeHandle, useCallback, useState } from "react"
 import { useForm } from "react-hook-form"
 import { zodResolver } from "@hookform/resolvers/zod"
 import * as z from "zod"
@@ -14,7 +14,8 @@ import { ConnectionSshTunnelForm } from "@/components/app/connection/connection-
 import { ConnectionTlsForm } from "@/components/app/connection/connection-tls.form"
 import { ConnectionOptionalForm } from "@/components/app/connection/connection-optional.form"
 import { ConnectionProxyForm } from "@/components/app/connection/connection-proxy.form"
-import { ConnectionReq as ConnectionDO } from "@/types"
+import { ConnTestTrace } from "@/components/app/connection/conn-test.trace"
+import { ConnTestRes, ConnectionReq as ConnectionDO } from "@/types"
 import { RedisModeEnum } from "@/types/redis-mode.enum"
 import { useTestConnection, useUpsertConnection } from "@/hooks/api/connection.api"
 
@@ -41,11 +42,17 @@ const connectionSchema = z
     key_size: z.preprocess(val => (val === "" ? undefined : val), z.coerce.number().min(0).optional()),
     ssh_enable: z.boolean().default(false),
     ssh_id: z.string().nullish(),
+    ssh_jump_ids: z.array(z.string().min(1)).default([]),
     proxy_enable: z.boolean().default(false),
     proxy_id: z.string().nullish(),
     tls_enable: z.boolean().default(false),
     tls_id: z.string().nullish(),
+    tls: z.any().optional(),
     read_only: z.boolean().default(false),
+    read_from: z.string().default(""),
+    cred_provider: z.string().default(""),
+    cred_source: z.string().optional().nullable(),
+    cred_ttl: z.preprocess(val => (val === "" ? undefined : val), z.coerce.number().min(0).optional()),
   })
   .refine(
     data => {
@@ -131,7 +138,8 @@ const connectionSchema = z
   .refine(
     data => {
       if (data.tls_enable) {
-        return !!data.tls_id
+        // A profile parsed from a rediss:// URL is created on save.
+        return !!data.tls_id || !!data.tls?.name
       }
       return true
     },
@@ -140,6 +148,18 @@ const connectionSchema = z
       path: ["tls_id"],
     }
   )
+  .refine(
+    data => {
+      if (data.cred_provider) {
+        return !!data.cred_source
+      }
+      return true
+    },
+    {
+      message: "Command or file is required",
+      path: ["cred_source"],
+    }
+  )
 
 type FormValues = z.input<typeof connectionSchema>
 type FormOutput = z.output<typeof connectionSchema>
@@ -182,6 +202,7 @@ export const ConnectionForm = forwardRef<ConnectionFormRef, Props>(({ connection
 
   const upsertConnection = useUpsertConnection()
   const testConnection = useTestConnection()
+  const [trace, setTrace] = useState<ConnTestRes | null>(null)
 
   useEffect(() => {
     const { mode, ...rest } = connection || {}
@@ -227,8 +248,15 @@ export const ConnectionForm = forwardRef<ConnectionFormRef, Props>(({ connection
       form.handleSubmit(async values => {
         const data = values as FormOutput
         try {
-          await testConnection.mutateAsync(data as any)
-          toast.add({ title: t("conn_success"), type: "success" })
+          setTrace(null)
+          const res = await testConnection.mutateAsync(data as any)
+          setTrace(res)
+          if (res.ok) {
+            toast.add({ title: t("conn_success"), description: res.server_version ? `Redis ${res.server_version}` : undefined, type: "success" })
+          } else {
+            const failed = (res.stages ?? []).find(s => !s.ok)
+            toast.add({ title: t("conn_failed"), description: failed ? `${t(`conn_stage_${failed.name}`)}: ${failed.error}` : undefined, type: "error" })
+          }
         } catch (e: any) {
           const msg = e instanceof Error ? e.message : typeof e === "string" ? e : ""
           toast.add({ title: t("conn_failed"), description: msg, type: "error" })
@@ -310,6 +338,7 @@ export const ConnectionForm = forwardRef<ConnectionFormRef, Props>(({ connection
             ]}
           />
         </div>
+        {trace && <ConnTestTrace result={trace} onClose={() => setTrace(null)} />}
       </form>
     </Form>
   )
@@ -26,17 +26,22 @@ export function ConnectionProvider({ children }: { children: ReactNode }) {
       proxy_id: "",
       proxy_enable: false,
       ssh_id: "",
+      ssh_jump_ids: [],
       ssh_enable: false,
       tls_id: "",
       tls_enable: false,
       read_only: false,
+      read_from: "",
+      cred_provider: "",
+      cred_source: "",
+      cred_ttl: 600,
       mode: RedisModeEnum.STANDALONE,
     })
     setOpen(true)
   }
 
   const handleEdit = (conn: ConnectionDO) => {
-    setConnection({ ...conn, proxy_enable: Boolean(conn.proxy_enable), ssh_enable: Boolean(conn.ssh_enable), tls_enable: Boolean(conn.tls_enable) })
+    setConnection({ ...conn, proxy_enable: Boolean(conn.proxy_enable), ssh_enable: Boolean(conn.ssh_enable), tls_enable: Boolean(conn.tls_enable), ssh_jump_ids: conn.ssh_jump_ids ?? [] })
     setOpen(true)
   }
 
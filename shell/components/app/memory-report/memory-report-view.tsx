@@ -0,0 +1,162 @@
+"use client"
+
+import { useTranslation } from "react-i18next"
+
+import { Badge } from "@tradalab/lyra/ui"
+import { Table, TableBody, TableCell, TableHead, TableHeader, TableRow } from "@tradalab/lyra/ui"
+
+import { ClientKeysReportEvent, KeyReportBucket, KeyReportKey } from "@/types"
+import { formatFileSize } from "@/lib/utils"
+
+export function download(text: string, name: string, type: string) {
+  const url = URL.createObjectURL(new Blob([text], { type }))
+  const a = document.createElement("a")
+  a.href = url
+  a.download = name
+  a.click()
+  URL.revokeObjectURL(url)
+}
+
+function csvCell(v: string | number) {
+  const s = String(v)
+  return /[",\n]/.test(s) ? `"${s.replace(/"/g, '""')}"` : s
+}
+
+// One row per ranked key and per prefix, so a spreadsheet can pivot on the first column.
+export function toCsv(report: ClientKeysReportEvent) {
+  const rows: (string | number)[][] = [["section", "type", "key_or_prefix", "keys", "memory", "elements", "ttl_ms"]]
+  for (const t of report.types ?? []) {
+    for (const k of t.top_memory ?? []) rows.push(["top_memory", t.type, k.key, 1, k.memory, k.elements, k.ttl])
+    for (const k of t.top_elements ?? []) rows.push(["top_elements", t.type, k.key, 1, k.memory, k.elements, k.ttl])
+  }
+  for (const p of report.prefixes ?? []) rows.push(["prefix", "", p.prefix, p.keys, p.memory, p.elements, ""])
+  for (const b of report.ttl_hist ?? []) rows.push(["ttl", "", b.min < 0 ? "no_expiry" : `${b.min}s+`, b.count, "", "", ""])
+  return rows.map(r => r.map(csvCell).join(",")).join("\n")
+}
+
+export function Histogram({ buckets, bytes, label }: { buckets?: KeyReportBucket[]; bytes?: boolean; label?: (b: KeyReportBucket) => string }) {
+  const list = buckets ?? []
+  const max = Math.max(1, ...list.map(b => b.count))
+  return (
+    <div className="space-y-0.5">
+      {list.map((b, i) => (
+        <div key={b.min} className="flex items-center gap-2 text-xs">
+          <span className="w-24 shrink-0 text-right font-mono text-muted-foreground">
+            {label ? label(b) : bytes ? formatFileSize(b.min, 0) : b.min}
+            {i === list.length - 1 && "+"}
+          </span>
+          <div className="flex-1 h-3 bg-muted/30 rounded-sm">
+            <div className="h-3 bg-primary/60 rounded-sm" style={{ width: `${(b.count / max) * 100}%` }} />
+          </div>
+          <span className="w-16 shrink-0 font-mono">{b.count}</span>
+        </div>
+      ))}
+    </div>
+  )
+}
+
+function TopKeys({ keys }: { keys?: KeyReportKey[] }) {
+  const { t } = useTranslation()
+  return (
+    <Table>
+      <TableHeader>
+        <TableRow>
+          <TableHead>{t("report_key")}</TableHead>
+          <TableHead className="text-right">{t("memory")}</TableHead>
+          <TableHead className="text-right">{t("report_elements")}</TableHead>
+          <TableHead className="text-right">TTL</TableHead>
+        </TableRow>
+      </TableHeader>
+      <TableBody>
+        {(keys ?? []).map(k => (
+          <TableRow key={k.key}>
+            <TableCell className="font-mono text-xs max-w-[320px] truncate" title={k.key}>
+              {k.key}
+            </TableCell>
+            <TableCell className="text-right font-mono text-xs">{formatFileSize(k.memory)}</TableCell>
+            <TableCell className="text-right font-mono text-xs">{k.elements}</TableCell>
+            <TableCell className="text-right font-mono text-xs">{k.ttl < 0 ? "-" : `${Math.round(k.ttl / 1000)}s`}</TableCell>
+          </TableRow>
+        ))}
+      </TableBody>
+    </Table>
+  )
+}
+
+export function ttlLabel(b: KeyReportBucket, noExpiry: string) {
+  if (b.min < 0) return noExpiry
+  if (b.min >= 86400) return `${b.min / 86400}d`
+  if (b.min >= 3600) return `${b.min / 3600}h`
+  if (b.min >= 60) return `${b.min / 60}m`
+  return `${b.min}s`
+}
+
+// MemoryReportView renders a report from a live scan or an RDB file.
+export function MemoryReportView({ report }: { report: ClientKeysReportEvent }) {
+  const { t } = useTranslation()
+  return (
+    <>
+      {(report.types ?? []).map(s => (
+        <div key={s.type} className="border rounded">
+          <div className="flex items-center gap-2 px-3 py-2 border-b bg-muted/10 text-xs">
+            <span className="font-semibold text-sm">{s.type}</span>
+            <Badge variant="outline">{t("cluster_keys", { count: s.keys })}</Badge>
+            <Badge variant="outline">{formatFileSize(s.memory)}</Badge>
+            <Badge variant="outline">
+              {s.elements} {t("report_elements")}
+            </Badge>
+          </div>
+          <div className="grid grid-cols-2 gap-4 p-3">
+            <div className="space-y-2">
+              <div className="text-xs font-medium">{t("report_top_memory")}</div>
+              <TopKeys keys={s.top_memory} />
+              <Histogram buckets={s.memory_hist} bytes />
+            </div>
+            <div className="space-y-2">
+              <div className="text-xs font-medium">{t("report_top_elements")}</div>
+              <TopKeys keys={s.top_elements} />
+              <Histogram buckets={s.elements_hist} />
+            </div>
+          </div>
+        </div>
+      ))}
+
+      {(report.prefixes ?? []).length > 0 && (
+        <div className="border rounded">
+          <div className="px-3 py-2 border-b bg-muted/10 text-xs font-semibold text-sm">{t("report_prefixes")}</div>
+          <Table>
+            <TableHeader>
+              <TableRow>
+                <TableHead>{t("report_prefix")}</TableHead>
+                <TableHead className="text-right">{t("keys")}</TableHead>
+                <TableHead className="text-right">{t("memory")}</TableHead>
+                <TableHead className="text-right">{t("report_elements")}</TableHead>
+              </TableRow>
+            </TableHeader>
+            <TableBody>
+              {report.prefixes!.map(p => (
+                <TableRow key={p.prefix}>
+                  <TableCell className="font-mono text-xs">
+                    {p.prefix === "" ? t("report_no_prefix") : p.prefix === "*" ? t("report_other_prefixes") : p.prefix}
+                  </TableCell>
+                  <TableCell className="text-right font-mono text-xs">{p.keys}</TableCell>
+                  <TableCell className="text-right font-mono text-xs">{formatFileSize(p.memory)}</TableCell>
+                  <TableCell className="text-right font-mono text-xs">{p.elements}</TableCell>
+                </TableRow>
+              ))}
+            </TableBody>
+          </Table>
+        </div>
+      )}
+
+      {(report.ttl_hist ?? []).length > 0 && (
+        <div className="border rounded">
+          <div className="px-3 py-2 border-b bg-muted/10 text-xs font-semibold text-sm">{t("report_ttl")}</div>
+          <div className="p-3">
+            <Histogram buckets={report.ttl_hist} label={b => ttlLabel(b, t("report_no_expiry"))} />
+          </div>
+        </div>
+      )}
+    </>
+  )
+}
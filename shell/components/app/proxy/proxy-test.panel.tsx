@@ -0,0 +1,61 @@
+"use client"
+
+import { useState } from "react"
+import { useTranslation } from "react-i18next"
+import { CheckCircle2Icon, PlugIcon, XCircleIcon } from "lucide-react"
+import { Button, Input, Spinner, toast } from "@tradalab/lyra/ui"
+import { ProxyReq as ProxyDO, ProxyTestRes } from "@/types"
+import { useTestProxy } from "@/hooks/api/proxy.api"
+
+export function ProxyTestPanel({ values }: { values: () => Partial<ProxyDO> }) {
+  const { t } = useTranslation()
+  const testProxy = useTestProxy()
+  const [target, setTarget] = useState("")
+  const [result, setResult] = useState<ProxyTestRes | null>(null)
+
+  const run = async () => {
+    setResult(null)
+    try {
+      setResult(await testProxy.mutateAsync({ proxy: values() as ProxyDO, target: target.trim() }))
+    } catch (e) {
+      const msg = e instanceof Error ? e.message : typeof e === "string" ? e : ""
+      toast.add({ title: t("conn_failed"), description: msg, type: "error" })
+    }
+  }
+
+  return (
+    <div className="border rounded-lg p-4 space-y-4">
+      <div className="font-semibold text-xs text-muted-foreground uppercase tracking-wider">{t("proxy_test")}</div>
+      <div className="flex gap-2">
+        <Input value={target} onChange={e => setTarget(e.target.value)} placeholder={t("proxy_test_target")} />
+        <Button type="button" size="sm" variant="outline" disabled={testProxy.isPending} onClick={run}>
+          {testProxy.isPending ? <Spinner /> : <PlugIcon />}
+          {t("test_conn")}
+        </Button>
+      </div>
+      <p className="text-xs text-muted-foreground">{t("proxy_test_target_help")}</p>
+
+      {result && (
+        <div className="space-y-2">
+          {result.via && (
+            <div className="text-xs">
+              <span className="text-muted-foreground">{t("proxy_via")}: </span>
+              <span className="font-mono">{result.via}</span>
+            </div>
+          )}
+          {(result.steps ?? []).map(s => (
+            <div key={s.name} className="flex items-start gap-2 text-xs">
+              {s.ok ? <CheckCircle2Icon className="w-4 h-4 text-green-600 shrink-0" /> : <XCircleIcon className="w-4 h-4 text-destructive shrink-0" />}
+              <div>
+                <div className="font-medium">
+                  {t(`proxy_step_${s.name}`)} <span className="text-muted-foreground font-normal">{s.latency_ms} ms</span>
+                </div>
+                {(s.error || s.detail) && <div className={`break-all ${s.error ? "text-destructive" : "text-muted-foreground"}`}>{s.error || s.detail}</div>}
+              </div>
+            </div>
+          ))}
+        </div>
+      )}
+    </div>
+  )
+}
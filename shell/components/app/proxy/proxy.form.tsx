@@ -10,15 +10,21 @@ import { Input, toast } from "@tradalab/lyra/ui"
 import { Select, SelectContent, SelectItem, SelectTrigger, SelectValue } from "@tradalab/lyra/ui"
 import { useUpsertProxy, useDeleteProxy } from "@/hooks/api/proxy.api"
 import { ProxyReq as ProxyDO } from "@/types"
-
-const formSchema = z.object({
-  id: z.string().optional(),
-  protocol: z.string().min(1),
-  host: z.string().min(1),
-  port: z.number().int().min(1).max(65535),
-  username: z.string().optional(),
-  password: z.string().optional(),
-})
+import { ProxyTestPanel } from "./proxy-test.panel"
+
+const formSchema = z
+  .object({
+    id: z.string().optional(),
+    protocol: z.string().min(1),
+    host: z.string(),
+    port: z.number().int().min(0).max(65535),
+    username: z.string().optional(),
+    password: z.string().optional(),
+  })
+  .refine(data => data.protocol === "system" || (!!data.host && data.port >= 1), {
+    message: "Host and port are required",
+    path: ["host"],
+  })
 
 export type ProxyFormValues = z.infer<typeof formSchema>
 
@@ -66,6 +72,8 @@ export const ProxyForm = forwardRef<ProxyFormRef, ProxyFormProps>(({ proxy, onPe
     form.reset(defaultValues)
   }, [defaultValues, form])
 
+  const isSystem = form.watch("protocol") === "system"
+
   const pending: PendingState = {
     save: upsert.isPending,
     delete: remove.isPending,
@@ -130,78 +138,90 @@ export const ProxyForm = forwardRef<ProxyFormRef, ProxyFormProps>(({ proxy, onPe
                 </FormControl>
                 <SelectContent>
                   <SelectItem value="http">HTTP</SelectItem>
-                  <SelectItem value="socks5">SOCKS5</SelectItem>
+                  <SelectItem value="https">HTTPS</SelectItem>
+                  <SelectItem value="socks5">SOCKS5 ({t("proxy_dns_local")})</SelectItem>
+                  <SelectItem value="socks5h">SOCKS5h ({t("proxy_dns_remote")})</SelectItem>
+                  <SelectItem value="socks4">SOCKS4 ({t("proxy_dns_local")})</SelectItem>
+                  <SelectItem value="socks4a">SOCKS4a ({t("proxy_dns_remote")})</SelectItem>
+                  <SelectItem value="system">{t("proxy_system")}</SelectItem>
                 </SelectContent>
               </Select>
+              {field.value === "system" && <p className="text-xs text-muted-foreground">{t("proxy_system_help")}</p>}
               <FormMessage />
             </FormItem>
           )}
         />
 
-        <div className="grid grid-cols-4 gap-4">
-          <div className="col-span-3">
+        {!isSystem && (
+          <>
+            <div className="grid grid-cols-4 gap-4">
+              <div className="col-span-3">
+                <FormField
+                  control={form.control}
+                  name="host"
+                  render={({ field }) => (
+                    <FormItem>
+                      <FormLabel>{t("host")}</FormLabel>
+                      <FormControl>
+                        <Input placeholder="127.0.0.1" {...field} />
+                      </FormControl>
+                      <FormMessage />
+                    </FormItem>
+                  )}
+                />
+              </div>
+              <div className="col-span-1">
+                <FormField
+                  control={form.control}
+                  name="port"
+                  render={({ field }) => (
+                    <FormItem>
+                      <FormLabel>{t("port")}</FormLabel>
+                      <FormControl>
+                        <Input type="number" {...field} onChange={e => field.onChange(parseInt(e.target.value) || 0)} />
+                      </FormControl>
+                      <FormMessage />
+                    </FormItem>
+                  )}
+                />
+              </div>
+            </div>
+
             <FormField
               control={form.control}
-              name="host"
+              name="username"
               render={({ field }) => (
                 <FormItem>
-                  <FormLabel>{t("host")}</FormLabel>
+                  <FormLabel>
+                    {t("username")} ({t("optional")})
+                  </FormLabel>
                   <FormControl>
-                    <Input placeholder="127.0.0.1" {...field} />
+                    <Input {...field} />
                   </FormControl>
                   <FormMessage />
                 </FormItem>
               )}
             />
-          </div>
-          <div className="col-span-1">
+
             <FormField
               control={form.control}
-              name="port"
+              name="password"
               render={({ field }) => (
                 <FormItem>
-                  <FormLabel>{t("port")}</FormLabel>
+                  <FormLabel>
+                    {t("password")} ({t("optional")})
+                  </FormLabel>
                   <FormControl>
-                    <Input type="number" {...field} onChange={e => field.onChange(parseInt(e.target.value) || 0)} />
+                    <Input type="password" {...field} value={field.value ?? ""} placeholder={secretPlaceholder} />
                   </FormControl>
                   <FormMessage />
                 </FormItem>
               )}
             />
-          </div>
-        </div>
-
-        <FormField
-          control={form.control}
-          name="username"
-          render={({ field }) => (
-            <FormItem>
-              <FormLabel>
-                {t("username")} ({t("optional")})
-              </FormLabel>
-              <FormControl>
-                <Input {...field} />
-              </FormControl>
-              <FormMessage />
-            </FormItem>
-          )}
-        />
+          </>
+        )}
 
-        <FormField
-          control={form.control}
-          name="password"
-          render={({ field }) => (
-            <FormItem>
-              <FormLabel>
-                {t("password")} ({t("optional")})
-              </FormLabel>
-              <FormControl>
-                <Input type="password" {...field} value={field.value ?? ""} placeholder={secretPlaceholder} />
-              </FormControl>
-              <FormMessage />
-            </FormItem>
-          )}
-        />
+        <ProxyTestPanel values={() => ({ ...form.getValues(), id: proxy.id }) as Partial<ProxyDO>} />
       </form>
     </Form>
   )
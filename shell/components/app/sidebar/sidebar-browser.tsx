@@ -15,8 +15,16 @@ import {
   RadioIcon,
   LayoutGridIcon,
   MonitorIcon,
+  NetworkIcon,
+  BoxesIcon,
+  HardDriveIcon,
+  FileSearchIcon,
+  FlameIcon,
+  HourglassIcon,
   LockIcon,
   PanelsTopLeftIcon,
+  DownloadIcon,
+  UploadIcon,
 } from "lucide-react"
 import { flattenTree, sortTree, TreeItem, FlattenedTreeItem } from "@/components/app/tree"
 import { useEffect, useMemo, useRef, useState } from "react"
@@ -40,6 +48,8 @@ import { useTranslation } from "react-i18next"
 import { useConfirm } from "@tradalab/lyra/blocks"
 import { useTabStore } from "@/stores/tab.store"
 import { BrowserBulkDeleteDialog } from "@/components/app/browser-bulk-delete-dialog"
+import { BrowserExportKeysDialog } from "@/components/app/browser-export-keys-dialog"
+import { BrowserImportKeysDialog } from "@/components/app/browser-import-keys-dialog"
 
 export function SidebarBrowser() {
   const { t } = useTranslation()
@@ -52,6 +62,8 @@ export function SidebarBrowser() {
   const [dbs, setDbs] = useState<DbInfo[]>([])
   const confirm = useConfirm()
   const [deleteDialogOpen, setDeleteDialogOpen] = useState(false)
+  const [exportDialogOpen, setExportDialogOpen] = useState(false)
+  const [importDialogOpen, setImportDialogOpen] = useState(false)
   const [deletePrefix, setDeletePrefix] = useState("")
 
   const { connect, selectedDb, setSelectedDbIdx, selectedDbIdx } = useAppContext()
@@ -345,6 +357,64 @@ export function SidebarBrowser() {
                   <ActivityIcon className="h-4 w-4" />
                   {t("slow_query")}
                 </DropdownMenuItem>
+                <DropdownMenuItem
+                  className="gap-2 cursor-pointer"
+                  onClick={() =>
+                    addTab({ type: "memory-report", title: "Memory Report", connectionId: selectedDb!, connectionName: currentConnection?.name, databaseIdx: selectedDbIdx })
+                  }
+                >
+                  <HardDriveIcon className="h-4 w-4" />
+                  {t("memory_report")}
+                </DropdownMenuItem>
+                <DropdownMenuItem
+                  className="gap-2 cursor-pointer"
+                  onClick={() =>
+                    addTab({ type: "hot-keys", title: "Hot Keys", connectionId: selectedDb!, connectionName: currentConnection?.name, databaseIdx: selectedDbIdx })
+                  }
+                >
+                  <FlameIcon className="h-4 w-4" />
+                  {t("hot_keys")}
+                </DropdownMenuItem>
+                <DropdownMenuItem
+                  className="gap-2 cursor-pointer"
+                  onClick={() =>
+                    addTab({ type: "ttl-report", title: "TTL Report", connectionId: selectedDb!, connectionName: currentConnection?.name, databaseIdx: selectedDbIdx })
+                  }
+                >
+                  <HourglassIcon className="h-4 w-4" />
+                  {t("ttl_report")}
+                </DropdownMenuItem>
+                <DropdownMenuItem
+                  className="gap-2 cursor-pointer"
+                  onClick={() =>
+                    addTab({ type: "rdb-analyzer", title: "RDB Analyzer", connectionId: selectedDb!, connectionName: currentConnection?.name, databaseIdx: selectedDbIdx })
+                  }
+                >
+                  <FileSearchIcon className="h-4 w-4" />
+                  {t("rdb_analyzer")}
+                </DropdownMenuItem>
+                {currentConnection?.mode === "sentinel" && (
+                  <DropdownMenuItem
+                    className="gap-2 cursor-pointer"
+                    onClick={() =>
+                      addTab({ type: "sentinel", title: "Sentinel", connectionId: selectedDb!, connectionName: currentConnection?.name, databaseIdx: selectedDbIdx })
+                    }
+                  >
+                    <NetworkIcon className="h-4 w-4" />
+                    {t("sentinel")}
+                  </DropdownMenuItem>
+                )}
+                {currentConnection?.mode === "cluster" && (
+                  <DropdownMenuItem
+                    className="gap-2 cursor-pointer"
+                    onClick={() =>
+                      addTab({ type: "cluster", title: "Cluster", connectionId: selectedDb!, connectionName: currentConnection?.name, databaseIdx: selectedDbIdx })
+                    }
+                  >
+                    <BoxesIcon className="h-4 w-4" />
+                    {t("cluster")}
+                  </DropdownMenuItem>
+                )}
                 <DropdownMenuSeparator />
                 <DropdownMenuCheckboxItem checked={readOnly} onCheckedChange={() => toggleReadOnly()} className="gap-2 cursor-pointer">
                   <LockIcon className="h-4 w-4" />
@@ -394,6 +464,18 @@ export function SidebarBrowser() {
           <Button size="icon-sm" variant="outline" title={"load_all"} disabled={isLoading || !hasMore} onClick={loadAll}>
             {isLoading ? <Spinner /> : <ListEndIcon />}
           </Button>
+          <Button size="icon-sm" variant="outline" title={t("export_keys")} disabled={keys.length === 0} onClick={() => setExportDialogOpen(true)}>
+            <DownloadIcon />
+          </Button>
+          <Button
+            size="icon-sm"
+            variant="outline"
+            title={readOnly ? t("read_only_blocked") : t("import_keys")}
+            disabled={readOnly || !selectedDb}
+            onClick={() => setImportDialogOpen(true)}
+          >
+            <UploadIcon />
+          </Button>
         </div>
         <KeyFilterBar
           filters={filters}
@@ -459,6 +541,23 @@ export function SidebarBrowser() {
         onScan={scanByPrefix}
         onConfirm={keys => deleteByPrefix(deletePrefix, keys)}
       />
+      <BrowserExportKeysDialog
+        open={exportDialogOpen}
+        onOpenChange={setExportDialogOpen}
+        connectionId={selectedDb || ""}
+        databaseIdx={selectedDbIdx}
+        keys={selectedIds.filter(id => !id.startsWith("group__"))}
+        filters={filters}
+        matchAll={matchAll}
+        keyType={keyType}
+      />
+      <BrowserImportKeysDialog
+        open={importDialogOpen}
+        onOpenChange={setImportDialogOpen}
+        connectionId={selectedDb || ""}
+        databaseIdx={selectedDbIdx}
+        onDone={reload}
+      />
     </SidebarPanel>
   )
 }
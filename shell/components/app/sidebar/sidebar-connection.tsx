@@ -5,7 +5,7 @@ import { useTranslation } from "react-i18next"
 
 import { SidebarContent, SidebarGroup, SidebarGroupContent, SidebarHeader, SidebarInput, SidebarMenu, toast } from "@tradalab/lyra/ui"
 import { SidebarPanel } from "@tradalab/lyra/shell"
-import { EditIcon, FolderPlusIcon, LockIcon, MoreHorizontal, PlugIcon, PlusIcon, RefreshCcwIcon, Trash2Icon, UnplugIcon } from "lucide-react"
+import { EditIcon, FolderPlusIcon, ImportIcon, LinkIcon, LockIcon, MoreHorizontal, PackageIcon, PackageOpenIcon, PlugIcon, PlusIcon, RefreshCcwIcon, Trash2Icon, UnplugIcon } from "lucide-react"
 import { TreeExpander, TreeIcon, TreeLabel, TreeNode, TreeNodeContent, TreeNodeTrigger, TreeProvider, TreeView } from "@tradalab/lyra/blocks"
 import { filterTree, sortTree, TreeItem } from "@/components/app/tree"
 import { buildDbTree, cn } from "@/lib/utils"
@@ -15,13 +15,17 @@ import { useConfirm } from "@tradalab/lyra/blocks"
 import { useConnection } from "@/components/app/connection/connection.context"
 import { ConnectionReq as ConnectionDO, GroupItem as GroupDO } from "@/types"
 import { useDeleteGroup, useGroupList } from "@/hooks/api/group.api"
-import { useConnectionList, useDeleteConnection } from "@/hooks/api/connection.api"
+import { useConnectionList, useDeleteConnection, useExportConnectionUrl } from "@/hooks/api/connection.api"
 import { useGroup } from "@/components/app/group/group.context"
+import { ConnectionImportDialog } from "@/components/app/connection/connection-import.dialog"
+import { ConnectionBundleDialog } from "@/components/app/connection/connection-bundle.dialog"
 
 export function SidebarConnection() {
   const { t } = useTranslation()
   const [keyword, setKeyword] = useState("")
   const [selectedIds, setSelectedIds] = useState<string[]>([])
+  const [importOpen, setImportOpen] = useState(false)
+  const [bundleMode, setBundleMode] = useState<"export" | "import" | null>(null)
   const { create: connectionCreate } = useConnection()
   const { create: groupCreate } = useGroup()
 
@@ -32,6 +36,8 @@ export function SidebarConnection() {
 
   const filteredDataset = useMemo(() => filterTree(dataset, keyword), [dataset, keyword])
 
+  const selectedConnections = useMemo(() => databases.filter(d => selectedIds.includes(d.id)).map(d => d.id), [databases, selectedIds])
+
   const refetch = () => {
     groupsRefetch()
     databasesRefetch()
@@ -45,6 +51,9 @@ export function SidebarConnection() {
             <div className="text-base font-medium">{t("connections")}</div>
             <div className="flex gap-2">
               <RefreshCcwIcon className="h-4 w-4 cursor-pointer" onClick={refetch} />
+              <ImportIcon className="h-4 w-4 cursor-pointer" onClick={() => setImportOpen(true)} />
+              <PackageOpenIcon className="h-4 w-4 cursor-pointer" onClick={() => setBundleMode("import")} />
+              <PackageIcon className="h-4 w-4 cursor-pointer" onClick={() => setBundleMode("export")} />
               <FolderPlusIcon className="h-4 w-4 cursor-pointer" onClick={groupCreate} />
               <PlusIcon className="h-4 w-4 cursor-pointer" onClick={connectionCreate} />
             </div>
@@ -68,6 +77,13 @@ export function SidebarConnection() {
           </SidebarGroup>
         </SidebarContent>
       </SidebarPanel>
+      <ConnectionImportDialog open={importOpen} onOpenChange={setImportOpen} />
+      <ConnectionBundleDialog
+        mode={bundleMode ?? "export"}
+        ids={selectedConnections}
+        open={bundleMode !== null}
+        onOpenChange={v => !v && setBundleMode(null)}
+      />
     </>
   )
 }
@@ -135,6 +151,18 @@ const ActionButton = memo(function ActionButton({ item, reload }: ActionButtonPr
   const { edit: groupEdit } = useGroup()
   const deleteConnection = useDeleteConnection()
   const deleteGroup = useDeleteGroup()
+  const exportUrl = useExportConnectionUrl()
+
+  const copyUrl = async () => {
+    try {
+      const url = await exportUrl.mutateAsync({ id: item.id, includeSecrets: false })
+      await navigator.clipboard.writeText(url)
+      toast.add({ title: t("copied"), type: "success" })
+    } catch (e: any) {
+      const msg = e instanceof Error ? e.message : typeof e === "string" ? e : t("unknown_error")
+      toast.add({ title: msg, type: "error" })
+    }
+  }
 
   const deleteItem = async () => {
     try {
@@ -197,6 +225,15 @@ const ActionButton = memo(function ActionButton({ item, reload }: ActionButtonPr
               <EditIcon className="h-4 w-4" />
               {t("update")}
             </DropdownMenuItem>
+            <DropdownMenuItem
+              onClick={e => {
+                e.stopPropagation()
+                copyUrl()
+              }}
+            >
+              <LinkIcon className="h-4 w-4" />
+              {t("copy_connection_url")}
+            </DropdownMenuItem>
           </>
         )}
         {item.isGroup && item.group && (
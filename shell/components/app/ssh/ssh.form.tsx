@@ -8,7 +8,7 @@ import { Select, SelectContent, SelectItem, SelectTrigger, SelectValue } from "@
 import { SshReq as SshDO } from "@/types"
 import { SshKindEnum } from "@/types/ssh-kind.enum"
 import { useDeleteSsh, useTestSsh, useUpsertSsh } from "@/hooks/api/ssh.api"
-import { Form, FormControl, FormField, FormItem, FormLabel, FormMessage } from "@tradalab/lyra/blocks"
+import { Form, FormControl, FormField, FormItem, FormLabel, FormMessage, useConfirm } from "@tradalab/lyra/blocks"
 import { useTranslation } from "react-i18next"
 import { z } from "zod"
 import { zodResolver } from "@hookform/resolvers/zod"
@@ -78,6 +78,7 @@ export const SshForm = forwardRef<SshFormRef, Props>(({ ssh, onPendingChange, on
   const upsertSsh = useUpsertSsh()
   const deleteSsh = useDeleteSsh()
   const testSsh = useTestSsh()
+  const confirm = useConfirm()
 
   const kind = form.watch("kind")
 
@@ -111,7 +112,23 @@ export const SshForm = forwardRef<SshFormRef, Props>(({ ssh, onPendingChange, on
 
   const testConn = form.handleSubmit(async values => {
     try {
-      await testSsh.mutateAsync(values)
+      let res = await testSsh.mutateAsync(values)
+      if (res.host_key_status !== "trusted") {
+        const changed = res.host_key_status === "mismatch"
+        const ok = await confirm({
+          title: t(changed ? "ssh_host_key_changed" : "ssh_host_key_unknown"),
+          description: t(changed ? "ssh_host_key_changed_desc" : "ssh_host_key_unknown_desc", {
+            host: res.host,
+            key_type: res.key_type,
+            fingerprint: res.fingerprint,
+            known_fingerprint: res.known_fingerprint,
+          }),
+          confirmText: t("ssh_host_key_trust"),
+          danger: changed,
+        })
+        if (!ok) return
+        res = await testSsh.mutateAsync({ ...values, accept_host_key: res.fingerprint })
+      }
       toast.add({ title: t("conn_success"), type: "success" })
     } catch (e) {
       const msg = e instanceof Error ? e.message : typeof e === "string" ? e : ""
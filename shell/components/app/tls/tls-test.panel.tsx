@@ -0,0 +1,91 @@
+"use client"
+
+import { useState } from "react"
+import { useTranslation } from "react-i18next"
+import { CheckCircle2Icon, PlugIcon, XCircleIcon } from "lucide-react"
+import { Badge, Button, Input, Spinner, toast } from "@tradalab/lyra/ui"
+import { TlsReq as TlsDO, TlsTestRes } from "@/types"
+import { useTestTls } from "@/hooks/api/tls.api"
+
+function Status({ ok, label, detail }: { ok: boolean; label: string; detail?: string }) {
+  return (
+    <div className="flex items-start gap-2 text-xs">
+      {ok ? <CheckCircle2Icon className="w-4 h-4 text-green-600 shrink-0" /> : <XCircleIcon className="w-4 h-4 text-destructive shrink-0" />}
+      <div>
+        <div className="font-medium">{label}</div>
+        {detail && <div className="text-muted-foreground break-all">{detail}</div>}
+      </div>
+    </div>
+  )
+}
+
+export function TlsTestPanel({ values }: { values: () => Partial<TlsDO> }) {
+  const { t } = useTranslation()
+  const testTls = useTestTls()
+  const [host, setHost] = useState("")
+  const [port, setPort] = useState(6379)
+  const [result, setResult] = useState<TlsTestRes | null>(null)
+
+  const run = async () => {
+    setResult(null)
+    try {
+      setResult(await testTls.mutateAsync({ tls: values() as TlsDO, host, port }))
+    } catch (e) {
+      const msg = e instanceof Error ? e.message : typeof e === "string" ? e : ""
+      toast.add({ title: t("conn_failed"), description: msg, type: "error" })
+    }
+  }
+
+  return (
+    <div className="border rounded-lg p-4 space-y-4">
+      <div className="font-semibold text-xs text-muted-foreground uppercase tracking-wider">{t("tls_test")}</div>
+      <div className="flex gap-2">
+        <Input value={host} onChange={e => setHost(e.target.value)} placeholder={t("host")} />
+        <Input type="number" className="w-28" value={port} onChange={e => setPort(Number(e.target.value))} placeholder={t("port")} />
+        <Button type="button" size="sm" variant="outline" disabled={!host || testTls.isPending} onClick={run}>
+          {testTls.isPending ? <Spinner /> : <PlugIcon />}
+          {t("test_conn")}
+        </Button>
+      </div>
+
+      {result && (
+        <div className="space-y-3">
+          <div className="flex gap-2">
+            <Badge variant="outline">{result.version}</Badge>
+            <Badge variant="outline">{result.cipher_suite}</Badge>
+          </div>
+          <Status ok={result.verified} label={t(result.verified ? "tls_verified" : "tls_verify_failed")} detail={result.verify_error} />
+          {result.pinned && <Status ok={result.pin_ok} label={t(result.pin_ok ? "tls_pin_ok" : "tls_pin_failed")} detail={result.pin_error} />}
+          <div className="text-xs font-medium">{t("tls_chain")}</div>
+          {(result.chain ?? []).map((c, i) => {
+            const expired = c.not_after < Date.now()
+            return (
+              <div key={c.fingerprint} className="border rounded-md p-2 text-xs space-y-1">
+                <div className="flex items-center gap-2">
+                  <span className="text-muted-foreground">#{i}</span>
+                  <span className="font-medium break-all">{c.subject}</span>
+                  {c.is_ca && <Badge variant="outline">CA</Badge>}
+                </div>
+                <div>
+                  <span className="text-muted-foreground">{t("tls_issuer")}: </span>
+                  {c.issuer}
+                </div>
+                {(c.sans ?? []).length > 0 && (
+                  <div className="break-all">
+                    <span className="text-muted-foreground">{t("tls_sans")}: </span>
+                    {c.sans!.join(", ")}
+                  </div>
+                )}
+                <div className={expired ? "text-destructive" : ""}>
+                  <span className="text-muted-foreground">{t("tls_expires")}: </span>
+                  {new Date(c.not_after).toLocaleString()}
+                </div>
+                <div className="font-mono break-all text-[11px] text-muted-foreground">{c.fingerprint}</div>
+              </div>
+            )
+          })}
+        </div>
+      )}
+    </div>
+  )
+}
@@ -33,6 +33,14 @@ export function TlsDialog({ open, onOpenChange }: Props) {
       ca_cert: "",
       cert: "",
       key: "",
+      ca_file: "",
+      cert_file: "",
+      key_file: "",
+      key_passphrase: "",
+      min_version: "",
+      max_version: "",
+      cipher_suites: "",
+      pin_sha256: "",
     })
   }
 
@@ -5,12 +5,16 @@ import { useForm } from "react-hook-form"
 import { Input, toast } from "@tradalab/lyra/ui"
 import { Textarea } from "@tradalab/lyra/ui"
 import { Switch } from "@tradalab/lyra/ui"
+import { Select, SelectContent, SelectItem, SelectTrigger, SelectValue } from "@tradalab/lyra/ui"
 import { TlsReq as TlsDO } from "@/types"
 import { useDeleteTls, useUpsertTls } from "@/hooks/api/tls.api"
 import { Form, FormControl, FormField, FormItem, FormLabel, FormMessage } from "@tradalab/lyra/blocks"
 import { z } from "zod"
 import { zodResolver } from "@hookform/resolvers/zod"
 import { useTranslation } from "react-i18next"
+import { TlsTestPanel } from "./tls-test.panel"
+
+const TLS_VERSIONS = ["1.0", "1.1", "1.2", "1.3"]
 
 export interface PendingState {
   save: boolean
@@ -43,6 +47,14 @@ export const TlsForm = forwardRef<TlsFormRef, Props>(({ tls, onPendingChange, on
     ca_cert: z.string().optional(),
     cert: z.string().optional(),
     key: z.string().optional(),
+    ca_file: z.string().optional(),
+    cert_file: z.string().optional(),
+    key_file: z.string().optional(),
+    key_passphrase: z.string().optional(),
+    min_version: z.string().optional(),
+    max_version: z.string().optional(),
+    cipher_suites: z.string().optional(),
+    pin_sha256: z.string().optional(),
   })
 
   type TlsFormValues = z.infer<typeof tlsSchema>
@@ -58,6 +70,14 @@ export const TlsForm = forwardRef<TlsFormRef, Props>(({ tls, onPendingChange, on
       ca_cert: tls.ca_cert || "",
       cert: tls.cert || "",
       key: tls.key || "",
+      ca_file: tls.ca_file || "",
+      cert_file: tls.cert_file || "",
+      key_file: tls.key_file || "",
+      key_passphrase: tls.key_passphrase || "",
+      min_version: tls.min_version || "",
+      max_version: tls.max_version || "",
+      cipher_suites: tls.cipher_suites || "",
+      pin_sha256: tls.pin_sha256 || "",
     }),
     [tls]
   )
@@ -219,6 +239,37 @@ export const TlsForm = forwardRef<TlsFormRef, Props>(({ tls, onPendingChange, on
               )}
             />
           </div>
+
+          <FormField
+            control={form.control}
+            name="ca_file"
+            render={({ field }) => (
+              <FormItem>
+                <FormLabel>{t("tls_ca_file")}</FormLabel>
+                <FormControl>
+                  <Input {...field} placeholder="/etc/redis/tls/ca.crt" />
+                </FormControl>
+                <FormMessage />
+              </FormItem>
+            )}
+          />
+
+          <hr className="border-border" />
+
+          <FormField
+            control={form.control}
+            name="pin_sha256"
+            render={({ field }) => (
+              <FormItem>
+                <FormLabel>{t("tls_pin_sha256")}</FormLabel>
+                <FormControl>
+                  <Input {...field} className="font-mono text-xs" placeholder="AB:CD:..." />
+                </FormControl>
+                <p className="text-[11px] text-muted-foreground leading-tight">{t("tls_pin_sha256_help")}</p>
+                <FormMessage />
+              </FormItem>
+            )}
+          />
         </div>
 
         {/* Client Authentication */}
@@ -281,10 +332,104 @@ export const TlsForm = forwardRef<TlsFormRef, Props>(({ tls, onPendingChange, on
                     </FormItem>
                   )}
                 />
+
+                <div className="grid grid-cols-2 gap-4">
+                  <FormField
+                    control={form.control}
+                    name="cert_file"
+                    render={({ field }) => (
+                      <FormItem>
+                        <FormLabel>{t("tls_cert_file")}</FormLabel>
+                        <FormControl>
+                          <Input {...field} placeholder="/etc/redis/tls/tls.crt" />
+                        </FormControl>
+                        <FormMessage />
+                      </FormItem>
+                    )}
+                  />
+                  <FormField
+                    control={form.control}
+                    name="key_file"
+                    render={({ field }) => (
+                      <FormItem>
+                        <FormLabel>{t("tls_key_file")}</FormLabel>
+                        <FormControl>
+                          <Input {...field} placeholder="/etc/redis/tls/tls.key" />
+                        </FormControl>
+                        <FormMessage />
+                      </FormItem>
+                    )}
+                  />
+                </div>
+                <p className="text-[11px] text-muted-foreground leading-tight -mt-4">{t("tls_file_help")}</p>
+
+                <FormField
+                  control={form.control}
+                  name="key_passphrase"
+                  render={({ field }) => (
+                    <FormItem>
+                      <FormLabel>{t("tls_key_passphrase")}</FormLabel>
+                      <FormControl>
+                        <Input type="password" {...field} value={field.value ?? ""} placeholder={editing ? t("secret_keep_hint") : undefined} />
+                      </FormControl>
+                      <FormMessage />
+                    </FormItem>
+                  )}
+                />
               </div>
             </>
           )}
         </div>
+
+        {/* Protocol */}
+        <div className="border rounded-lg p-4 space-y-6">
+          <div className="grid grid-cols-2 gap-4">
+            {(["min_version", "max_version"] as const).map(name => (
+              <FormField
+                key={name}
+                control={form.control}
+                name={name}
+                render={({ field }) => (
+                  <FormItem>
+                    <FormLabel>{t(`tls_${name}`)}</FormLabel>
+                    <Select value={field.value || "default"} onValueChange={v => field.onChange(v === "default" ? "" : v)}>
+                      <FormControl>
+                        <SelectTrigger className="w-full">
+                          <SelectValue />
+                        </SelectTrigger>
+                      </FormControl>
+                      <SelectContent>
+                        <SelectItem value="default">{t("tls_version_default")}</SelectItem>
+                        {TLS_VERSIONS.map(v => (
+                          <SelectItem key={v} value={v}>
+                            TLS {v}
+                          </SelectItem>
+                        ))}
+                      </SelectContent>
+                    </Select>
+                    <FormMessage />
+                  </FormItem>
+                )}
+              />
+            ))}
+          </div>
+          <FormField
+            control={form.control}
+            name="cipher_suites"
+            render={({ field }) => (
+              <FormItem>
+                <FormLabel>{t("tls_cipher_suites")}</FormLabel>
+                <FormControl>
+                  <Input {...field} className="font-mono text-xs" placeholder="TLS_ECDHE_RSA_WITH_AES_256_GCM_SHA384" />
+                </FormControl>
+                <p className="text-[11px] text-muted-foreground leading-tight">{t("tls_cipher_suites_help")}</p>
+                <FormMessage />
+              </FormItem>
+            )}
+          />
+        </div>
+
+        <TlsTestPanel values={() => ({ ...form.getValues(), id: tls.id }) as Partial<TlsDO>} />
       </form>
     </Form>
   )
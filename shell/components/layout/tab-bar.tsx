@@ -2,7 +2,7 @@
 
 import { type ElementType } from "react"
 import { useTranslation } from "react-i18next"
-import { Database, Key, Terminal, Activity, Radio, LayoutGrid, Monitor } from "lucide-react"
+import { Database, Key, Terminal, Activity, Radio, LayoutGrid, Monitor, Network, Boxes, HardDrive, FileSearch, Flame, Hourglass } from "lucide-react"
 import { TabBar as LyraTabBar, type TabItem } from "@tradalab/lyra/shell"
 import { useTabStore, TabType } from "@/stores/tab.store"
 
@@ -14,6 +14,12 @@ const IconMap: Record<TabType, ElementType> = {
   pubsub: Radio,
   "key-list": LayoutGrid,
   monitor: Monitor,
+  sentinel: Network,
+  cluster: Boxes,
+  "memory-report": HardDrive,
+  "rdb-analyzer": FileSearch,
+  "hot-keys": Flame,
+  "ttl-report": Hourglass,
 }
 
 export function TabBar() {
@@ -0,0 +1,12 @@
+"use client"
+
+import { useQuery } from "@tanstack/react-query"
+import { cluster } from "@/api"
+
+export function useClusterTopology(connectionId: string | undefined, databaseIdx: number) {
+  return useQuery({
+    queryKey: ["cluster-topology", connectionId, databaseIdx],
+    queryFn: () => cluster.topology({ connection_id: connectionId!, database_index: databaseIdx }),
+    enabled: !!connectionId,
+  })
+}
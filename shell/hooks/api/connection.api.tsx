@@ -2,7 +2,7 @@
 
 import { useMutation, useQuery, useQueryClient } from "@tanstack/react-query"
 import { connection, conn, client } from "@/api"
-import { ConnectionReq as ConnectionDO } from "@/types"
+import { ConnectionExportReq, ConnectionImportExternalReq, ConnectionImportReq, ConnectionReq as ConnectionDO } from "@/types"
 
 const QUERY_KEY = ["conn-list"]
 
@@ -62,6 +62,65 @@ export function useDeleteConnection() {
   })
 }
 
+export function useParseConnectionUrl() {
+  return useMutation({
+    mutationFn: async (url: string) => {
+      return connection.parseUrl({ url })
+    },
+  })
+}
+
+export function useExportConnectionUrl() {
+  return useMutation({
+    mutationFn: async (vars: { id: string; includeSecrets: boolean }) => {
+      const res = await connection.exportUrl({ id: vars.id, include_secrets: vars.includeSecrets })
+      return res.url
+    },
+  })
+}
+
+export function useImportExternalPreview() {
+  return useMutation({
+    mutationFn: async (values: ConnectionImportExternalReq) => {
+      return connection.importExternalPreview(values)
+    },
+  })
+}
+
+export function useImportExternal() {
+  const queryClient = useQueryClient()
+  return useMutation({
+    mutationFn: async (values: ConnectionImportExternalReq) => {
+      return connection.importExternal(values)
+    },
+    onSuccess: () => {
+      queryClient.invalidateQueries({ queryKey: QUERY_KEY })
+      queryClient.invalidateQueries({ queryKey: ["group-list"] })
+    },
+  })
+}
+
+export function useExportConnections() {
+  return useMutation({
+    mutationFn: async (values: ConnectionExportReq) => {
+      return connection.export(values)
+    },
+  })
+}
+
+export function useImportConnections() {
+  const queryClient = useQueryClient()
+  return useMutation({
+    mutationFn: async (values: ConnectionImportReq) => {
+      return connection.import(values)
+    },
+    onSuccess: () => {
+      queryClient.invalidateQueries({ queryKey: QUERY_KEY })
+      queryClient.invalidateQueries({ queryKey: ["group-list"] })
+    },
+  })
+}
+
 export function useTestConnection() {
   return useMutation({
     mutationFn: async (values: Partial<ConnectionDO>) => {
@@ -69,3 +128,11 @@ export function useTestConnection() {
     },
   })
 }
+
+export function useAddrMapPreview() {
+  return useMutation({
+    mutationFn: async (values: Partial<ConnectionDO>) => {
+      return conn.mapPreview(values as ConnectionDO)
+    },
+  })
+}
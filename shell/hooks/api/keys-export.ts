@@ -0,0 +1,82 @@
+"use client"
+
+import { useCallback, useEffect, useRef, useState } from "react"
+import { client } from "@/api"
+import type { ClientKeysExportProgressEvent, ClientKeysExportReq } from "@/types"
+
+export type KeysExportOptions = Partial<Omit<ClientKeysExportReq, "connection_id" | "database_index" | "path">>
+
+export type KeysExportState = {
+  /** The latest progress event; status "done" once the file is in place. */
+  progress: ClientKeysExportProgressEvent | null
+  isRunning: boolean
+  error: string | null
+  start: (path: string, opts?: KeysExportOptions) => void
+  /** Stop exporting; the server discards the partial file. */
+  cancel: () => void
+}
+
+export function useKeysExport(connectionId: string, databaseIdx: number): KeysExportState {
+  const [progress, setProgress] = useState<ClientKeysExportProgressEvent | null>(null)
+  const [isRunning, setIsRunning] = useState(false)
+  const [error, setError] = useState<string | null>(null)
+
+  const streamRef = useRef<{ cancel: () => void } | null>(null)
+  const runIdRef = useRef(0)
+
+  const cancel = useCallback(() => {
+    runIdRef.current++
+    streamRef.current?.cancel()
+    streamRef.current = null
+    setIsRunning(false)
+  }, [])
+
+  const start = useCallback(
+    async (path: string, opts: KeysExportOptions = {}) => {
+      if (!connectionId || !path) return
+
+      streamRef.current?.cancel()
+      const runId = ++runIdRef.current
+      setProgress(null)
+      setError(null)
+      setIsRunning(true)
+
+      const stream = client.keysExport({
+        connection_id: connectionId,
+        database_index: databaseIdx,
+        path,
+        filters: [],
+        match_all: false,
+        key_type: "",
+        keys: [],
+        format: "jsonl",
+        page_size: 0,
+        scan_count: 0,
+        rate: 0,
+        budget_ms: 0, // server defaults
+        ...opts,
+      })
+      streamRef.current = stream
+
+      try {
+        for await (const ev of stream) {
+          if (runId !== runIdRef.current) return
+          setProgress(ev)
+        }
+      } catch (e: unknown) {
+        if (runId !== runIdRef.current) return
+        setError(e instanceof Error ? e.message : String(e))
+      } finally {
+        if (runId === runIdRef.current) {
+          setIsRunning(false)
+          streamRef.current = null
+        }
+      }
+    },
+    [connectionId, databaseIdx]
+  )
+
+  useEffect(() => cancel, [cancel])
+
+  return { progress, isRunning, error, start, cancel }
+}
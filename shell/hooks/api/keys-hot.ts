@@ -0,0 +1,81 @@
+"use client"
+
+import { useCallback, useEffect, useRef, useState } from "react"
+import { client } from "@/api"
+import type { ClientKeysHotEvent, ClientKeysHotReq } from "@/types"
+
+export type KeysHotOptions = Partial<Omit<ClientKeysHotReq, "connection_id" | "database_index">>
+
+export type KeysHotState = {
+  /** The latest snapshot; every event replaces the whole report. */
+  report: ClientKeysHotEvent | null
+  isRunning: boolean
+  error: string | null
+  start: (opts?: KeysHotOptions) => void
+  /** Stop sampling or scanning and keep the last snapshot. */
+  cancel: () => void
+}
+
+export function useKeysHot(connectionId: string, databaseIdx: number): KeysHotState {
+  const [report, setReport] = useState<ClientKeysHotEvent | null>(null)
+  const [isRunning, setIsRunning] = useState(false)
+  const [error, setError] = useState<string | null>(null)
+
+  const streamRef = useRef<{ cancel: () => void } | null>(null)
+  const runIdRef = useRef(0)
+
+  const cancel = useCallback(() => {
+    runIdRef.current++
+    streamRef.current?.cancel()
+    streamRef.current = null
+    setIsRunning(false)
+  }, [])
+
+  const start = useCallback(
+    async (opts: KeysHotOptions = {}) => {
+      if (!connectionId) return
+
+      streamRef.current?.cancel()
+      const runId = ++runIdRef.current
+      setReport(null)
+      setError(null)
+      setIsRunning(true)
+
+      const stream = client.keysHot({
+        connection_id: connectionId,
+        database_index: databaseIdx,
+        filters: [],
+        match_all: false,
+        mode: "",
+        separator: "",
+        prefix_depth: 0,
+        top: 0,
+        window_ms: 0,
+        scan_count: 0,
+        rate: 0, // server defaults
+        ...opts,
+      })
+      streamRef.current = stream
+
+      try {
+        for await (const ev of stream) {
+          if (runId !== runIdRef.current) return
+          setReport(ev)
+        }
+      } catch (e: unknown) {
+        if (runId !== runIdRef.current) return
+        setError(e instanceof Error ? e.message : String(e))
+      } finally {
+        if (runId === runIdRef.current) {
+          setIsRunning(false)
+          streamRef.current = null
+        }
+      }
+    },
+    [connectionId, databaseIdx]
+  )
+
+  useEffect(() => cancel, [connectionId, databaseIdx, cancel])
+
+  return { report, isRunning, error, start, cancel }
+}
@@ -0,0 +1,81 @@
+"use client"
+
+import { useCallback, useEffect, useRef, useState } from "react"
+import { client } from "@/api"
+import type { ClientKeysImportProgressEvent, ClientKeysImportReq } from "@/types"
+
+export type KeysImportOptions = Partial<Omit<ClientKeysImportReq, "connection_id" | "database_index" | "path">>
+
+export type KeysImportState = {
+  /** The latest progress event; status "done" once the whole file is applied. */
+  progress: ClientKeysImportProgressEvent | null
+  isRunning: boolean
+  error: string | null
+  start: (path: string, opts?: KeysImportOptions) => void
+  /** Stop importing; batches already sent stay applied. */
+  cancel: () => void
+}
+
+export function useKeysImport(connectionId: string, databaseIdx: number): KeysImportState {
+  const [progress, setProgress] = useState<ClientKeysImportProgressEvent | null>(null)
+  const [isRunning, setIsRunning] = useState(false)
+  const [error, setError] = useState<string | null>(null)
+
+  const streamRef = useRef<{ cancel: () => void } | null>(null)
+  const runIdRef = useRef(0)
+
+  const cancel = useCallback(() => {
+    runIdRef.current++
+    streamRef.current?.cancel()
+    streamRef.current = null
+    setIsRunning(false)
+  }, [])
+
+  const start = useCallback(
+    async (path: string, opts: KeysImportOptions = {}) => {
+      if (!connectionId || !path) return
+
+      streamRef.current?.cancel()
+      const runId = ++runIdRef.current
+      setProgress(null)
+      setError(null)
+      setIsRunning(true)
+
+      const stream = client.keysImport({
+        connection_id: connectionId,
+        database_index: databaseIdx,
+        path,
+        format: "jsonl",
+        conflict: "skip",
+        batch_size: 0, // server default
+        csv_delimiter: "",
+        key_template: "",
+        key_type: "",
+        fields: [],
+        value_column: "",
+        ...opts,
+      })
+      streamRef.current = stream
+
+      try {
+        for await (const ev of stream) {
+          if (runId !== runIdRef.current) return
+          setProgress(ev)
+        }
+      } catch (e: unknown) {
+        if (runId !== runIdRef.current) return
+        setError(e instanceof Error ? e.message : String(e))
+      } finally {
+        if (runId === runIdRef.current) {
+          setIsRunning(false)
+          streamRef.current = null
+        }
+      }
+    },
+    [connectionId, databaseIdx]
+  )
+
+  useEffect(() => cancel, [cancel])
+
+  return { progress, isRunning, error, start, cancel }
+}
@@ -0,0 +1,82 @@
+"use client"
+
+import { useCallback, useEffect, useRef, useState } from "react"
+import { client } from "@/api"
+import type { ClientKeysReportEvent, ClientKeysReportReq } from "@/types"
+
+export type KeysReportOptions = Partial<Omit<ClientKeysReportReq, "connection_id" | "database_index">>
+
+export type KeysReportState = {
+  /** The latest snapshot; every event replaces the whole report. */
+  report: ClientKeysReportEvent | null
+  isRunning: boolean
+  error: string | null
+  start: (opts?: KeysReportOptions) => void
+  /** Stop the scan and keep the last snapshot. */
+  cancel: () => void
+}
+
+export function useKeysReport(connectionId: string, databaseIdx: number): KeysReportState {
+  const [report, setReport] = useState<ClientKeysReportEvent | null>(null)
+  const [isRunning, setIsRunning] = useState(false)
+  const [error, setError] = useState<string | null>(null)
+
+  const streamRef = useRef<{ cancel: () => void } | null>(null)
+  const runIdRef = useRef(0)
+
+  const cancel = useCallback(() => {
+    runIdRef.current++
+    streamRef.current?.cancel()
+    streamRef.current = null
+    setIsRunning(false)
+  }, [])
+
+  const start = useCallback(
+    async (opts: KeysReportOptions = {}) => {
+      if (!connectionId) return
+
+      streamRef.current?.cancel()
+      const runId = ++runIdRef.current
+      setReport(null)
+      setError(null)
+      setIsRunning(true)
+
+      const stream = client.keysReport({
+        connection_id: connectionId,
+        database_index: databaseIdx,
+        filters: [],
+        match_all: false,
+        key_type: "",
+        separator: "",
+        prefix_depth: 0,
+        top: 0,
+        memory_samples: 0,
+        scan_count: 0,
+        rate: 0,
+        budget_ms: 0, // server defaults
+        ...opts,
+      })
+      streamRef.current = stream
+
+      try {
+        for await (const ev of stream) {
+          if (runId !== runIdRef.current) return
+          setReport(ev)
+        }
+      } catch (e: unknown) {
+        if (runId !== runIdRef.current) return
+        setError(e instanceof Error ? e.message : String(e))
+      } finally {
+        if (runId === runIdRef.current) {
+          setIsRunning(false)
+          streamRef.current = null
+        }
+      }
+    },
+    [connectionId, databaseIdx]
+  )
+
+  useEffect(() => cancel, [connectionId, databaseIdx, cancel])
+
+  return { report, isRunning, error, start, cancel }
+}
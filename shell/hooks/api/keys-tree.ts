@@ -0,0 +1,119 @@
+"use client"
+
+import { useCallback, useEffect, useRef, useState } from "react"
+import { client } from "@/api"
+import type { KeyFilter, KeyTreeNode } from "@/types"
+
+export type KeysTreeLevel = {
+  /** Child namespaces, replaced wholesale by every event. */
+  nodes: KeyTreeNode[]
+  /** Keys directly under the prefix, up to the server's leaf limit. */
+  leaves: string[]
+  leafCount: number
+  scanned: number
+  isLoading: boolean
+  /** The scan stopped on its time budget, so counts are partial. */
+  truncated: boolean
+  error: string | null
+}
+
+export type KeysTreeState = {
+  /** Levels by prefix; the root is "". A prefix is only present once expanded. */
+  levels: Record<string, KeysTreeLevel>
+  expand: (prefix: string) => void
+  collapse: (prefix: string) => void
+  reload: () => void
+}
+
+type Options = {
+  separator?: string
+  filters?: KeyFilter[]
+  matchAll?: boolean
+  keyType?: string
+}
+
+const emptyLevel: KeysTreeLevel = { nodes: [], leaves: [], leafCount: 0, scanned: 0, isLoading: true, truncated: false, error: null }
+
+export function useKeysTree(connectionId: string, databaseIdx: number, opts: Options = {}): KeysTreeState {
+  const { separator = ":", filters, matchAll = false, keyType = "" } = opts
+
+  const [levels, setLevels] = useState<Record<string, KeysTreeLevel>>({})
+  const streamsRef = useRef<Map<string, { cancel: () => void }>>(new Map())
+
+  const filtersKey = JSON.stringify(filters ?? [])
+
+  const update = (prefix: string, fn: (l: KeysTreeLevel) => KeysTreeLevel) =>
+    setLevels(prev => (prev[prefix] ? { ...prev, [prefix]: fn(prev[prefix]) } : prev))
+
+  const cancelAll = useCallback(() => {
+    streamsRef.current.forEach(s => s.cancel())
+    streamsRef.current.clear()
+  }, [])
+
+  const expand = useCallback(
+    async (prefix: string) => {
+      if (!connectionId || streamsRef.current.has(prefix)) return
+
+      setLevels(prev => ({ ...prev, [prefix]: emptyLevel }))
+      const stream = client.keysTree({
+        connection_id: connectionId,
+        database_index: databaseIdx,
+        prefix,
+        separator,
+        filters: filters ?? [],
+        match_all: matchAll,
+        key_type: keyType,
+        scan_count: 0, // server defaults
+        budget_ms: 0,
+        memory_samples: 0,
+        leaf_limit: 0,
+      })
+      streamsRef.current.set(prefix, stream)
+
+      try {
+        for await (const ev of stream) {
+          if (streamsRef.current.get(prefix) !== stream) return
+          update(prefix, l => ({
+            ...l,
+            nodes: ev.nodes ?? [],
+            leaves: ev.leaves?.length ? [...l.leaves, ...ev.leaves] : l.leaves,
+            leafCount: Number(ev.leaf_count ?? 0),
+            scanned: Number(ev.scanned ?? 0),
+            truncated: Boolean(ev.truncated),
+          }))
+        }
+      } catch (e: unknown) {
+        if (streamsRef.current.get(prefix) !== stream) return
+        update(prefix, l => ({ ...l, error: e instanceof Error ? e.message : String(e) }))
+      } finally {
+        if (streamsRef.current.get(prefix) === stream) {
+          update(prefix, l => ({ ...l, isLoading: false }))
+        }
+      }
+    },
+    [connectionId, databaseIdx, separator, filtersKey, matchAll, keyType] // eslint-disable-line react-hooks/exhaustive-deps
+  )
+
+  const collapse = useCallback((prefix: string) => {
+    streamsRef.current.forEach((s, p) => {
+      if (p.startsWith(prefix) && p !== "") {
+        s.cancel()
+        streamsRef.current.delete(p)
+      }
+    })
+    setLevels(prev => Object.fromEntries(Object.entries(prev).filter(([p]) => p === "" || !p.startsWith(prefix))))
+  }, [])
+
+  const reload = useCallback(() => {
+    cancelAll()
+    setLevels({})
+    void expand("")
+  }, [cancelAll, expand])
+
+  useEffect(() => {
+    reload()
+    return cancelAll
+  }, [reload, cancelAll])
+
+  return { levels, expand, collapse, reload }
+}
@@ -2,7 +2,7 @@
 
 import { useMutation, useQuery, useQueryClient } from "@tanstack/react-query"
 import { proxy } from "@/api"
-import { ProxyReq as ProxyDO } from "@/types"
+import { ProxyReq as ProxyDO, ProxyTestReq } from "@/types"
 
 const QUERY_KEY = ["proxy-list"]
 
@@ -41,3 +41,11 @@ export function useDeleteProxy() {
     },
   })
 }
+
+export function useTestProxy() {
+  return useMutation({
+    mutationFn: async (params: ProxyTestReq) => {
+      return proxy.test(params)
+    },
+  })
+}
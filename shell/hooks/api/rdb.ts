@@ -0,0 +1,75 @@
+"use client"
+
+import { useCallback, useEffect, useRef, useState } from "react"
+import { rdb } from "@/api"
+import type { RdbAnalyzeEvent, RdbAnalyzeReq } from "@/types"
+
+export type RdbAnalyzeOptions = Partial<Omit<RdbAnalyzeReq, "path">>
+
+export type RdbAnalyzeState = {
+  /** The latest snapshot; every event replaces the whole report. */
+  result: RdbAnalyzeEvent | null
+  isRunning: boolean
+  error: string | null
+  start: (path: string, opts?: RdbAnalyzeOptions) => void
+  /** Stop reading the file and keep the last snapshot. */
+  cancel: () => void
+}
+
+export function useRdbAnalyze(): RdbAnalyzeState {
+  const [result, setResult] = useState<RdbAnalyzeEvent | null>(null)
+  const [isRunning, setIsRunning] = useState(false)
+  const [error, setError] = useState<string | null>(null)
+
+  const streamRef = useRef<{ cancel: () => void } | null>(null)
+  const runIdRef = useRef(0)
+
+  const cancel = useCallback(() => {
+    runIdRef.current++
+    streamRef.current?.cancel()
+    streamRef.current = null
+    setIsRunning(false)
+  }, [])
+
+  const start = useCallback(async (path: string, opts: RdbAnalyzeOptions = {}) => {
+    if (!path) return
+
+    streamRef.current?.cancel()
+    const runId = ++runIdRef.current
+    setResult(null)
+    setError(null)
+    setIsRunning(true)
+
+    const stream = rdb.analyze({
+      path,
+      database_index: -1, // every database
+      filters: [],
+      match_all: false,
+      key_type: "",
+      separator: "",
+      prefix_depth: 0,
+      top: 0, // server defaults
+      ...opts,
+    })
+    streamRef.current = stream
+
+    try {
+      for await (const ev of stream) {
+        if (runId !== runIdRef.current) return
+        setResult(ev)
+      }
+    } catch (e: unknown) {
+      if (runId !== runIdRef.current) return
+      setError(e instanceof Error ? e.message : String(e))
+    } finally {
+      if (runId === runIdRef.current) {
+        setIsRunning(false)
+        streamRef.current = null
+      }
+    }
+  }, [])
+
+  useEffect(() => cancel, [cancel])
+
+  return { result, isRunning, error, start, cancel }
+}
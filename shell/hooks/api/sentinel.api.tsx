@@ -0,0 +1,65 @@
+"use client"
+
+import { useCallback, useEffect, useRef, useState } from "react"
+import { useMutation, useQuery, useQueryClient } from "@tanstack/react-query"
+import { sentinel } from "@/api"
+import type { ServerStream } from "@/lib/scorix"
+import type { SentinelEvent } from "@/types"
+
+export function useSentinelTopology(connectionId: string | undefined, databaseIdx: number) {
+  return useQuery({
+    queryKey: ["sentinel-topology", connectionId, databaseIdx],
+    queryFn: () => sentinel.topology({ connection_id: connectionId!, database_index: databaseIdx }),
+    enabled: !!connectionId,
+  })
+}
+
+export function useSentinelFailover(connectionId: string, databaseIdx: number) {
+  const queryClient = useQueryClient()
+  return useMutation({
+    mutationFn: async (master: string) => {
+      return sentinel.failover({ connection_id: connectionId, database_index: databaseIdx, master })
+    },
+    onSuccess: () => {
+      queryClient.invalidateQueries({ queryKey: ["sentinel-topology", connectionId, databaseIdx] })
+    },
+  })
+}
+
+export function useSentinelEvents(connectionId: string, databaseIdx: number, onEvent: (ev: SentinelEvent) => void) {
+  const [active, setActive] = useState(false)
+  const streamRef = useRef<ServerStream<SentinelEvent> | null>(null)
+  const onEventRef = useRef(onEvent)
+  onEventRef.current = onEvent
+
+  const stop = useCallback(() => {
+    streamRef.current?.cancel()
+    streamRef.current = null
+    setActive(false)
+  }, [])
+
+  const start = useCallback(async () => {
+    if (streamRef.current) return
+    const stream = sentinel.events({ connection_id: connectionId, database_index: databaseIdx })
+    streamRef.current = stream
+    setActive(true)
+    try {
+      for await (const ev of stream) onEventRef.current(ev)
+    } catch (err) {
+      console.error("Sentinel event stream error:", err)
+    } finally {
+      if (streamRef.current === stream) streamRef.current = null
+      setActive(false)
+    }
+  }, [connectionId, databaseIdx])
+
+  useEffect(
+    () => () => {
+      streamRef.current?.cancel()
+      streamRef.current = null
+    },
+    [],
+  )
+
+  return { active, start, stop }
+}
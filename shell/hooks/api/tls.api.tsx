@@ -2,7 +2,7 @@
 
 import { useMutation, useQuery, useQueryClient } from "@tanstack/react-query"
 import { tls } from "@/api"
-import { TlsReq as TlsDO } from "@/types"
+import { TlsReq as TlsDO, TlsTestReq } from "@/types"
 
 const QUERY_KEY = ["tls-list"]
 
@@ -41,3 +41,11 @@ export function useDeleteTls() {
     },
   })
 }
+
+export function useTestTls() {
+  return useMutation({
+    mutationFn: async (params: TlsTestReq) => {
+      return tls.test(params)
+    },
+  })
+}
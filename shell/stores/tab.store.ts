@@ -1,6 +1,19 @@
 import { create } from "zustand"
 
-export type TabType = "general" | "console" | "key-detail" | "slow-query" | "pubsub" | "key-list" | "monitor"
+export type TabType =
+  | "general"
+  | "console"
+  | "key-detail"
+  | "slow-query"
+  | "pubsub"
+  | "key-list"
+  | "monitor"
+  | "sentinel"
+  | "cluster"
+  | "memory-report"
+  | "rdb-analyzer"
+  | "hot-keys"
+  | "ttl-report"
 
 export interface TabDO {
   id: string
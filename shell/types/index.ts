@@ -1,6 +1,31 @@
 /**
  * Code generated by scorix. DO NOT EDIT.
  */
+export interface ActiveClient {
+  connection_id: string;
+  connection_name: string;
+  database_index: number;
+  last_used: number;
+  idle_seconds: number;
+  monitor_active: boolean;
+  pubsub_active: boolean;
+  read_only: boolean;
+  ssh_hops: number;
+}
+
+export interface AddrMapPreviewRes {
+  rows?: AddrMapRow[];
+  errors?: string[];
+}
+
+export interface AddrMapRow {
+  addr: string;
+  dial_addr: string;
+  rule: string;
+  master: boolean;
+  slots?: ClusterSlotRange[];
+}
+
 export interface ClientConnectReq {
   connection_id: string;
   database_index: number;
@@ -79,6 +104,7 @@ export interface ClientKeysDeleteByPrefixReq {
   database_index: number;
   prefix: string;
   keys?: string[];
+  dry_run: boolean;
 }
 
 export interface ClientKeysDeleteByPrefixRes {
@@ -91,6 +117,98 @@ export interface ClientKeysDeleteProgressEvent {
   deleted: number;
   total: number;
   status: string;
+  matched: number;
+  dry_run: boolean;
+  node: string;
+  nodes?: DeleteNodeStat[];
+}
+
+export interface ClientKeysExportProgressEvent {
+  connection_id: string;
+  path: string;
+  exported: number;
+  total: number;
+  status: string;
+  matched: number;
+  skipped: number;
+  elements: number;
+  bytes: number;
+  key: string;
+  truncated: boolean;
+  nodes?: ScanNodeStat[];
+}
+
+export interface ClientKeysExportReq {
+  connection_id: string;
+  database_index: number;
+  filters?: KeyFilter[];
+  match_all: boolean;
+  key_type: string;
+  keys?: string[];
+  path: string;
+  format: string;
+  page_size: number;
+  scan_count: number;
+  rate: number;
+  budget_ms: number;
+}
+
+export interface ClientKeysHotEvent {
+  mode: string;
+  policy: string;
+  warning: string;
+  keys?: HotKey[];
+  prefixes?: HotKeyPrefix[];
+  clients?: HotKeyClient[];
+  scanned: number;
+  matched: number;
+  elapsed_ms: number;
+  window_ms: number;
+  done: boolean;
+  truncated: boolean;
+  node_stats?: ScanNodeStat[];
+}
+
+export interface ClientKeysHotReq {
+  connection_id: string;
+  database_index: number;
+  filters?: KeyFilter[];
+  match_all: boolean;
+  mode: string;
+  separator: string;
+  prefix_depth: number;
+  top: number;
+  window_ms: number;
+  scan_count: number;
+  rate: number;
+}
+
+export interface ClientKeysImportProgressEvent {
+  connection_id: string;
+  path: string;
+  read: number;
+  size: number;
+  imported: number;
+  skipped: number;
+  overwritten: number;
+  commands: number;
+  errors: number;
+  error_samples?: string[];
+  status: string;
+}
+
+export interface ClientKeysImportReq {
+  connection_id: string;
+  database_index: number;
+  path: string;
+  format: string;
+  conflict: string;
+  batch_size: number;
+  csv_delimiter: string;
+  key_template: string;
+  key_type: string;
+  fields?: string[];
+  value_column: string;
 }
 
 export interface ClientKeysMetadataReq {
@@ -103,6 +221,35 @@ export interface ClientKeysMetadataRes {
   items?: KeyMetadata[];
 }
 
+export interface ClientKeysReportEvent {
+  scanned: number;
+  matched: number;
+  keys: number;
+  memory: number;
+  types?: KeyReportType[];
+  prefixes?: KeyReportPrefix[];
+  elapsed_ms: number;
+  done: boolean;
+  truncated: boolean;
+  node_stats?: ScanNodeStat[];
+  ttl_hist?: KeyReportBucket[];
+}
+
+export interface ClientKeysReportReq {
+  connection_id: string;
+  database_index: number;
+  filters?: KeyFilter[];
+  match_all: boolean;
+  key_type: string;
+  separator: string;
+  prefix_depth: number;
+  top: number;
+  memory_samples: number;
+  scan_count: number;
+  rate: number;
+  budget_ms: number;
+}
+
 export interface ClientKeysScanByPrefixRes {
   keys?: string[];
   next_cursor: string;
@@ -115,6 +262,7 @@ export interface ClientKeysSearchEvent {
   cursor: string;
   done: boolean;
   truncated: boolean;
+  nodes?: ScanNodeStat[];
 }
 
 export interface ClientKeysSearchReq {
@@ -129,6 +277,66 @@ export interface ClientKeysSearchReq {
   budget_ms: number;
 }
 
+export interface ClientKeysTreeEvent {
+  prefix: string;
+  nodes?: KeyTreeNode[];
+  leaves?: string[];
+  leaf_count: number;
+  scanned: number;
+  matched: number;
+  done: boolean;
+  truncated: boolean;
+  node_stats?: ScanNodeStat[];
+}
+
+export interface ClientKeysTreeReq {
+  connection_id: string;
+  database_index: number;
+  prefix: string;
+  separator: string;
+  filters?: KeyFilter[];
+  match_all: boolean;
+  key_type: string;
+  scan_count: number;
+  budget_ms: number;
+  memory_samples: number;
+  leaf_limit: number;
+}
+
+export interface ClientKeysTtlEvent {
+  scanned: number;
+  matched: number;
+  keys: number;
+  no_expiry: number;
+  ttl_hist?: KeyReportBucket[];
+  prefixes?: TtlPrefix[];
+  leaks?: TtlPrefix[];
+  elapsed_ms: number;
+  done: boolean;
+  truncated: boolean;
+  node_stats?: ScanNodeStat[];
+}
+
+export interface ClientKeysTtlReq {
+  connection_id: string;
+  database_index: number;
+  filters?: KeyFilter[];
+  match_all: boolean;
+  key_type: string;
+  separator: string;
+  prefix_depth: number;
+  samples: number;
+  leak_ratio: number;
+  leak_min_keys: number;
+  scan_count: number;
+  rate: number;
+  budget_ms: number;
+}
+
+export interface ClientListActiveRes {
+  items?: ActiveClient[];
+}
+
 export interface ClientLoadAllKeysReq {
   connection_id: string;
   database_index: number;
@@ -191,10 +399,157 @@ export interface ClientSetReadOnlyReq {
   read_only: boolean;
 }
 
+export interface ClientStateEvent {
+  connection_id: string;
+  database_index: number;
+  state: string;
+  attempt: number;
+  error: string;
+}
+
+export interface ClusterNode {
+  id: string;
+  addr: string;
+  dial_addr: string;
+  hostname: string;
+  role: string;
+  master_id: string;
+  flags?: string[];
+  link_state: string;
+  health: string;
+  config_epoch: number;
+  repl_offset: number;
+  slots?: ClusterSlotRange[];
+  stats: ClusterNodeStats;
+  error: string;
+}
+
+export interface ClusterNodeStats {
+  used_memory: number;
+  max_memory: number;
+  frag_ratio: number;
+  ops_per_sec: number;
+  keyspace_hits: number;
+  keyspace_misses: number;
+  evicted_keys: number;
+  expired_keys: number;
+  keys: number;
+  expires: number;
+}
+
+export interface ClusterReq {
+  connection_id: string;
+  database_index: number;
+}
+
+export interface ClusterShard {
+  master: ClusterNode;
+  replicas?: ClusterNode[];
+  slots?: ClusterSlotRange[];
+  slot_count: number;
+  keys: number;
+  used_memory: number;
+}
+
+export interface ClusterSlotRange {
+  start: number;
+  end: number;
+}
+
+export interface ClusterTopologyRes {
+  source: string;
+  shards?: ClusterShard[];
+}
+
+export interface ConnTestNode {
+  addr: string;
+  dial_addr: string;
+  role: string;
+  ok: boolean;
+  error: string;
+  latency_ms: number;
+}
+
+export interface ConnTestRes {
+  ok: boolean;
+  stages?: ConnTestStage[];
+  server_version: string;
+  server_mode: string;
+  nodes?: ConnTestNode[];
+}
+
+export interface ConnTestStage {
+  name: string;
+  ok: boolean;
+  detail: string;
+  error: string;
+  latency_ms: number;
+}
+
+export interface ConnectionExportReq {
+  ids?: string[];
+  passphrase: string;
+}
+
+export interface ConnectionExportRes {
+  data: string;
+  connections: number;
+}
+
+export interface ConnectionExportUrlReq {
+  id: string;
+  include_secrets: boolean;
+}
+
+export interface ConnectionExportUrlRes {
+  url: string;
+}
+
+export interface ConnectionImportExternalItem {
+  name: string;
+  mode: string;
+  addr: string;
+  db: number;
+  group: string;
+  ssh: boolean;
+  tls: boolean;
+  duplicate_of: string;
+  warnings?: string[];
+}
+
+export interface ConnectionImportExternalReq {
+  source: string;
+  data: string;
+}
+
+export interface ConnectionImportExternalRes {
+  source: string;
+  created: number;
+  skipped: number;
+  items?: ConnectionImportExternalItem[];
+}
+
+export interface ConnectionImportReq {
+  data: string;
+  passphrase: string;
+}
+
+export interface ConnectionImportRes {
+  connections: number;
+  groups: number;
+  ssh: number;
+  tls: number;
+  proxies: number;
+}
+
 export interface ConnectionListRes {
   items?: ConnectionReq[];
 }
 
+export interface ConnectionParseUrlReq {
+  url: string;
+}
+
 export interface ConnectionReq {
   id: string;
   mode: string;
@@ -225,6 +580,12 @@ export interface ConnectionReq {
   group_id: string;
   last_db: number;
   read_only: boolean;
+  ssh_jump_ids?: string[];
+  url: string;
+  read_from: string;
+  cred_provider: string;
+  cred_source: string;
+  cred_ttl: number;
 }
 
 export interface ConsoleInputEvent {
@@ -249,6 +610,13 @@ export interface DbInfo {
   avg_ttl: number;
 }
 
+export interface DeleteNodeStat {
+  addr: string;
+  matched: number;
+  deleted: number;
+  done: boolean;
+}
+
 export interface Empty {
 }
 
@@ -268,6 +636,28 @@ export interface GroupUpsertReq {
   name: string;
 }
 
+export interface HotKey {
+  key: string;
+  hits: number;
+  reads: number;
+  writes: number;
+}
+
+export interface HotKeyClient {
+  addr: string;
+  commands: number;
+  reads: number;
+  writes: number;
+}
+
+export interface HotKeyPrefix {
+  prefix: string;
+  keys: number;
+  hits: number;
+  reads: number;
+  writes: number;
+}
+
 export interface IdReq {
   id: string;
 }
@@ -332,6 +722,37 @@ export interface KeyMetadata {
   size: number;
 }
 
+export interface KeyReportBucket {
+  min: number;
+  count: number;
+}
+
+export interface KeyReportKey {
+  key: string;
+  type: string;
+  memory: number;
+  elements: number;
+  ttl: number;
+}
+
+export interface KeyReportPrefix {
+  prefix: string;
+  keys: number;
+  memory: number;
+  elements: number;
+}
+
+export interface KeyReportType {
+  type: string;
+  keys: number;
+  memory: number;
+  elements: number;
+  top_memory?: KeyReportKey[];
+  top_elements?: KeyReportKey[];
+  memory_hist?: KeyReportBucket[];
+  elements_hist?: KeyReportBucket[];
+}
+
 export interface KeySetMemberDelReq {
   connection_id: string;
   database_index: number;
@@ -354,6 +775,21 @@ export interface KeyStreamEntryDelReq {
   entry_id: string;
 }
 
+export interface KeyTreeNode {
+  prefix: string;
+  name: string;
+  keys: number;
+  types?: KeyTreeTypeCount[];
+  no_ttl: number;
+  memory: number;
+  memory_sampled: number;
+}
+
+export interface KeyTreeTypeCount {
+  type: string;
+  count: number;
+}
+
 export interface KeyValue {
   key: string;
   value: string;
@@ -423,6 +859,24 @@ export interface ProxyReq {
   password: string;
 }
 
+export interface ProxyTestReq {
+  proxy: ProxyReq;
+  target: string;
+}
+
+export interface ProxyTestRes {
+  via: string;
+  steps?: ProxyTestStep[];
+}
+
+export interface ProxyTestStep {
+  name: string;
+  ok: boolean;
+  detail: string;
+  error: string;
+  latency_ms: number;
+}
+
 export interface PubSubPublishReq {
   connection_id: string;
   database_index: number;
@@ -448,6 +902,33 @@ export interface PubsubMessageEvent {
   pattern: string;
 }
 
+export interface RdbAnalyzeEvent {
+  version: number;
+  redis_version: string;
+  created_at: number;
+  read: number;
+  size: number;
+  report: ClientKeysReportEvent;
+}
+
+export interface RdbAnalyzeReq {
+  path: string;
+  database_index: number;
+  filters?: KeyFilter[];
+  match_all: boolean;
+  key_type: string;
+  separator: string;
+  prefix_depth: number;
+  top: number;
+}
+
+export interface ScanNodeStat {
+  addr: string;
+  scanned: number;
+  matched: number;
+  done: boolean;
+}
+
 export interface SearchPresetItem {
   id: string;
   name: string;
@@ -470,6 +951,60 @@ export interface SearchPresetUpsertReq {
   key_type: string;
 }
 
+export interface SentinelEvent {
+  connection_id: string;
+  sentinel: string;
+  event: string;
+  master: string;
+  message: string;
+  time: number;
+}
+
+export interface SentinelFailoverReq {
+  connection_id: string;
+  database_index: number;
+  master: string;
+}
+
+export interface SentinelMaster {
+  name: string;
+  addr: string;
+  flags: string;
+  quorum: number;
+  num_replicas: number;
+  num_other_sentinels: number;
+  config_epoch: number;
+  reported_by?: string[];
+  replicas?: SentinelPeer[];
+  sentinels?: SentinelPeer[];
+}
+
+export interface SentinelNode {
+  addr: string;
+  ok: boolean;
+  error: string;
+  latency_ms: number;
+}
+
+export interface SentinelPeer {
+  addr: string;
+  run_id: string;
+  flags: string;
+  link_status: string;
+  repl_offset: number;
+  last_ok_ping_ms: number;
+}
+
+export interface SentinelReq {
+  connection_id: string;
+  database_index: number;
+}
+
+export interface SentinelTopologyRes {
+  nodes?: SentinelNode[];
+  masters?: SentinelMaster[];
+}
+
 export interface SettingGetReq {
   key: string;
 }
@@ -515,6 +1050,18 @@ export interface SshReq {
   private_key: string;
   passphrase: string;
   timeout: number;
+  accept_host_key: string;
+  jump_ids?: string[];
+}
+
+export interface SshTestRes {
+  host: string;
+  host_key_status: string;
+  key_type: string;
+  fingerprint: string;
+  known_fingerprint: string;
+  hop: number;
+  hop_addr: string;
 }
 
 export interface StreamValue {
@@ -529,6 +1076,16 @@ export interface SystemInfoRes {
   runtime: string;
 }
 
+export interface TlsCertInfo {
+  subject: string;
+  issuer: string;
+  sans?: string[];
+  not_before: number;
+  not_after: number;
+  fingerprint: string;
+  is_ca: boolean;
+}
+
 export interface TlsListRes {
   items?: TlsReq[];
 }
@@ -543,6 +1100,41 @@ export interface TlsReq {
   ca_cert: string;
   cert: string;
   key: string;
+  ca_file: string;
+  cert_file: string;
+  key_file: string;
+  key_passphrase: string;
+  min_version: string;
+  max_version: string;
+  cipher_suites: string;
+  pin_sha256: string;
+}
+
+export interface TlsTestReq {
+  tls: TlsReq;
+  host: string;
+  port: number;
+}
+
+export interface TlsTestRes {
+  version: string;
+  cipher_suite: string;
+  chain?: TlsCertInfo[];
+  verified: boolean;
+  verify_error: string;
+  pinned: boolean;
+  pin_ok: boolean;
+  pin_error: string;
+}
+
+export interface TtlPrefix {
+  prefix: string;
+  keys: number;
+  memory: number;
+  no_expiry: number;
+  no_expiry_memory: number;
+  ttl_hist?: KeyReportBucket[];
+  samples?: string[];
 }
 
 export interface UpsertRes {